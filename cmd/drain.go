@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type drainCMD struct {
+	ctx     *WuKongIMContext
+	addr    string        // 管理端地址
+	token   string        // 管理者的token
+	nodeId  uint64        // 排空的节点
+	timeout time.Duration // 排空超时时间
+	noWait  bool          // 不等待排空完成
+}
+
+func newDrainCMD(ctx *WuKongIMContext) *drainCMD {
+	return &drainCMD{
+		ctx: ctx,
+	}
+}
+
+func (d *drainCMD) CMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drain",
+		Short: "drain the WuKongIM node: stop accepting connections, transfer leaders, disconnect clients with a reconnect hint and then stop",
+		RunE:  d.run,
+	}
+	cmd.Flags().StringVar(&d.addr, "addr", "", "manager address, default is the manager.addr of the config")
+	cmd.Flags().StringVar(&d.token, "token", "", "manager token, default is the managerToken of the config")
+	cmd.Flags().Uint64Var(&d.nodeId, "node", 0, "the node id to drain, default is the node of the manager address")
+	cmd.Flags().DurationVar(&d.timeout, "timeout", 0, "max time to wait for in-flight work, default is the drain.timeout of the config")
+	cmd.Flags().BoolVar(&d.noWait, "no-wait", false, "return after the drain is started")
+	return cmd
+}
+
+func (d *drainCMD) run(cmd *cobra.Command, args []string) error {
+	addr := d.managerAddr()
+	token := d.token
+	if token == "" {
+		token = serverOpts.ManagerToken
+	}
+	if strings.TrimSpace(token) == "" {
+		return errors.New("manager token is empty, please set --token or managerToken in the config")
+	}
+
+	req := map[string]interface{}{
+		"node_id": d.nodeId,
+	}
+	if d.timeout > 0 {
+		req["timeout"] = d.timeout.String()
+	}
+	data, _ := json.Marshal(req)
+	if _, err := d.request(http.MethodPost, fmt.Sprintf("%s/manager/drain", addr), token, data); err != nil {
+		return err
+	}
+	fmt.Println("WuKongIM node draining")
+	if d.noWait {
+		return nil
+	}
+
+	statusURL := fmt.Sprintf("%s/manager/drain", addr)
+	if d.nodeId != 0 {
+		statusURL = fmt.Sprintf("%s?node_id=%d", statusURL, d.nodeId)
+	}
+	for {
+		time.Sleep(time.Second)
+		body, err := d.request(http.MethodGet, statusURL, token, nil)
+		if err != nil {
+			if d.nodeId == 0 && (errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.EOF)) { // 当前节点已停止
+				break
+			}
+			return err
+		}
+		var status struct {
+			State              string `json:"state"`
+			ConnCount          int    `json:"conn_count"`
+			RetryCount         int    `json:"retry_count"`
+			DeliverCount       int    `json:"deliver_count"`
+			SlotLeaderCount    int    `json:"slot_leader_count"`
+			ChannelLeaderCount int    `json:"channel_leader_count"`
+		}
+		if err = json.Unmarshal(body, &status); err != nil {
+			return err
+		}
+		if status.State == "stopped" {
+			break
+		}
+		fmt.Printf("conns: %d retry: %d deliver: %d slot leaders: %d channel leaders: %d\n", status.ConnCount, status.RetryCount, status.DeliverCount, status.SlotLeaderCount, status.ChannelLeaderCount)
+	}
+	fmt.Println("WuKongIM node drained")
+	return nil
+}
+
+func (d *drainCMD) managerAddr() string {
+	addr := d.addr
+	if addr == "" {
+		addr = serverOpts.Manager.Addr
+		if strings.HasPrefix(addr, "0.0.0.0:") {
+			addr = strings.Replace(addr, "0.0.0.0", "127.0.0.1", 1)
+		}
+	}
+	if !strings.HasPrefix(addr, "http") {
+		addr = fmt.Sprintf("http://%s", addr)
+	}
+	return strings.TrimSuffix(addr, "/")
+}
+
+func (d *drainCMD) request(method string, url string, token string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("token", token)
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Timeout: time.Second * 10}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request %s failed, status: %d body: %s", url, resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
@@ -10,10 +10,12 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/WuKongIM/WuKongIM/internal/server"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
@@ -96,6 +98,13 @@ func cmdRun() error {
 	logOpts.Level = serverOpts.Logger.Level
 	logOpts.LogDir = serverOpts.Logger.Dir
 	logOpts.LineNum = serverOpts.Logger.LineNum
+	logOpts.Format = serverOpts.Logger.Format
+	logOpts.MaxSize = serverOpts.Logger.MaxSize
+	logOpts.MaxAge = serverOpts.Logger.MaxAge
+	logOpts.MaxBackups = serverOpts.Logger.MaxBackups
+	logOpts.Compress = serverOpts.Logger.Compress
+	logOpts.ModuleLevels = serverOpts.Logger.ModuleLevels
+	logOpts.NodeId = serverOpts.Cluster.NodeId
 	wklog.Configure(logOpts)
 
 	s := server.New(serverOpts)
@@ -137,7 +146,24 @@ func cmdRun() error {
 			}
 		}
 
-		select {}
+		// 收到SIGHUP时热加载配置文件
+		hupC := make(chan os.Signal, 1)
+		signal.Notify(hupC, syscall.SIGHUP)
+		defer signal.Stop(hupC)
+
+		// 节点排空完成后退出
+		for {
+			select {
+			case <-hupC:
+				if err := s.ReloadConfig(); err != nil {
+					wklog.Error("reload config failed", zap.Error(err))
+				} else {
+					wklog.Info("config reloaded")
+				}
+			case <-s.Drained():
+				return nil
+			}
+		}
 
 	}
 	return nil
@@ -258,6 +284,7 @@ func addCommand(cmd CMD) {
 func Execute() {
 	ctx := &WuKongIMContext{}
 	addCommand(newStopCMD(ctx))
+	addCommand(newDrainCMD(ctx))
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
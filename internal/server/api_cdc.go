@@ -0,0 +1,210 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// CDCAPI 变更数据捕获相关api
+type CDCAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewCDCAPI NewCDCAPI
+func NewCDCAPI(s *Server) *CDCAPI {
+	return &CDCAPI{
+		s:   s,
+		Log: wklog.NewWKLog("CDCAPI"),
+	}
+}
+
+// Route Route
+func (a *CDCAPI) Route(r *wkhttp.WKHttp) {
+	r.GET("/cdc/stream", a.stream)                   // 以NDJSON长连接持续推送变更事件
+	r.POST("/cdc/pull", a.pull)                      // 拉取一批变更事件
+	r.POST("/cdc/commit", a.commit)                  // 确认已消费完成的偏移量
+	r.POST("/cdc/consumer/remove", a.removeConsumer) // 移除消费者
+}
+
+// 以NDJSON长连接持续推送变更事件，每行一个事件，没有事件时定时推送心跳
+// 参数：consumer 消费者名称 categories 订阅的事件分类（逗号分隔） slot_ids 消费的槽（逗号分隔） limit 每个槽每次拉取的数量
+// 推送的事件不会自动确认，消费者处理完后需要调用 /cdc/commit 确认，断开重连后从已确认的偏移量之后继续推送
+func (a *CDCAPI) stream(c *wkhttp.Context) {
+	if !a.s.opts.CDC.On {
+		c.ResponseError(errors.New("变更数据捕获未开启！"))
+		return
+	}
+	req := &cdcPullReq{
+		Consumer: c.Query("consumer"),
+	}
+	if categories := strings.TrimSpace(c.Query("categories")); categories != "" {
+		req.Categories = strings.Split(categories, ",")
+	}
+	if slotIds := strings.TrimSpace(c.Query("slot_ids")); slotIds != "" {
+		for _, slotIdStr := range strings.Split(slotIds, ",") {
+			slotId, err := strconv.ParseUint(strings.TrimSpace(slotIdStr), 10, 32)
+			if err != nil {
+				c.ResponseError(errors.New("槽id格式有误！"))
+				return
+			}
+			req.SlotIds = append(req.SlotIds, uint32(slotId))
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		req.Limit, _ = strconv.Atoi(limit)
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if err := a.checkSlotIds(req.SlotIds); err != nil {
+		c.ResponseError(err)
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+
+	encoder := json.NewEncoder(c.Writer)
+	lastWriteAt := time.Now()
+	for {
+		resp, err := a.s.cdcManager.pull(req)
+		if err != nil {
+			a.Error("pull cdc events failed", zap.Error(err), zap.String("consumer", req.Consumer))
+			return
+		}
+		if req.Cursors == nil {
+			req.Cursors = make(map[uint32]uint64, len(resp.Cursors))
+		}
+		for slotId, cursor := range resp.Cursors {
+			req.Cursors[slotId] = cursor
+		}
+		for _, event := range resp.Events {
+			if err = encoder.Encode(event); err != nil {
+				a.Debug("write cdc event failed", zap.Error(err), zap.String("consumer", req.Consumer))
+				return
+			}
+		}
+		if len(resp.Events) > 0 {
+			c.Writer.Flush()
+			lastWriteAt = time.Now()
+			continue
+		}
+		if time.Since(lastWriteAt) >= a.s.opts.CDC.HeartbeatInterval {
+			if err = encoder.Encode(map[string]interface{}{
+				"type":      "heartbeat",
+				"timestamp": time.Now().UnixMilli(),
+			}); err != nil {
+				return
+			}
+			c.Writer.Flush()
+			lastWriteAt = time.Now()
+		}
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-a.s.ctx.Done():
+			return
+		case <-time.After(a.s.opts.CDC.PollInterval):
+		}
+	}
+}
+
+// 拉取一批变更事件，cursors为空时从已确认的偏移量之后开始拉取
+func (a *CDCAPI) pull(c *wkhttp.Context) {
+	if !a.s.opts.CDC.On {
+		c.ResponseError(errors.New("变更数据捕获未开启！"))
+		return
+	}
+	var req cdcPullReq
+	if err := c.BindJSON(&req); err != nil {
+		a.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if err := a.checkSlotIds(req.SlotIds); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	resp, err := a.s.cdcManager.pull(&req)
+	if err != nil {
+		a.Error("pull cdc events failed", zap.Error(err), zap.String("consumer", req.Consumer))
+		c.ResponseError(errors.New("拉取变更事件失败！"))
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// 确认已消费完成的偏移量，偏移量通过槽复制，重启或者槽领导切换后都不会丢失
+func (a *CDCAPI) commit(c *wkhttp.Context) {
+	var req cdcCommitReq
+	if err := c.BindJSON(&req); err != nil {
+		a.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	slotIds := make([]uint32, 0, len(req.Offsets))
+	for slotId := range req.Offsets {
+		slotIds = append(slotIds, slotId)
+	}
+	if err := a.checkSlotIds(slotIds); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if err := a.s.cdcManager.commit(req); err != nil {
+		a.Error("commit cdc offsets failed", zap.Error(err), zap.String("consumer", req.Consumer))
+		c.ResponseError(errors.New("确认偏移量失败！"))
+		return
+	}
+	c.ResponseOK()
+}
+
+// 移除消费者，移除后再次消费将从最早保留的事件开始
+func (a *CDCAPI) removeConsumer(c *wkhttp.Context) {
+	var req struct {
+		Consumer string `json:"consumer"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		a.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if strings.TrimSpace(req.Consumer) == "" {
+		c.ResponseError(errors.New("消费者名称不能为空！"))
+		return
+	}
+	if err := a.s.cdcManager.removeConsumer(req.Consumer); err != nil {
+		a.Error("remove cdc consumer failed", zap.Error(err), zap.String("consumer", req.Consumer))
+		c.ResponseError(errors.New("移除消费者失败！"))
+		return
+	}
+	c.ResponseOK()
+}
+
+func (a *CDCAPI) checkSlotIds(slotIds []uint32) error {
+	for _, slotId := range slotIds {
+		if slotId >= uint32(a.s.opts.Cluster.SlotCount) {
+			return errors.New("槽id超出范围！")
+		}
+	}
+	return nil
+}
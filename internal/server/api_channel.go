@@ -59,6 +59,15 @@ func (ch *ChannelAPI) Route(r *wkhttp.WKHttp) {
 	//	获取某个频道最大的消息序号
 	r.GET("/channel/max_message_seq", ch.getChannelMaxMessageSeq)
 
+	//################### 置顶消息 ###################
+	r.GET("/channel/pins", ch.pinsGet)            // 获取置顶消息
+	r.POST("/channel/pins", ch.pinsAdd)           // 置顶消息（已置顶的消息则更新排序）
+	r.POST("/channel/pins/remove", ch.pinsRemove) // 取消置顶消息
+
+	//################### 公告 ###################
+	r.GET("/channel/announcement", ch.announcementGet)  // 获取频道公告
+	r.POST("/channel/announcement", ch.announcementSet) // 设置频道公告
+
 }
 
 func (ch *ChannelAPI) channelCreateOrUpdate(c *wkhttp.Context) {
@@ -358,26 +367,33 @@ func (ch *ChannelAPI) removeSubscriber(c *wkhttp.Context) {
 		}
 	}
 
-	err = ch.s.store.RemoveSubscribers(req.ChannelID, req.ChannelType, req.Subscribers)
+	err = ch.removeSubscribers(req.ChannelID, req.ChannelType, req.Subscribers)
 	if err != nil {
-		ch.Error("移除订阅者失败！", zap.Error(err))
 		c.ResponseError(err)
 		return
 	}
 
-	channelKey := wkutil.ChannelToKey(req.ChannelID, req.ChannelType)
+	c.ResponseOK()
+}
+
+func (ch *ChannelAPI) removeSubscribers(channelId string, channelType uint8, subscribers []string) error {
+	err := ch.s.store.RemoveSubscribers(channelId, channelType, subscribers)
+	if err != nil {
+		ch.Error("移除订阅者失败！", zap.Error(err))
+		return err
+	}
+
+	channelKey := wkutil.ChannelToKey(channelId, channelType)
 	channel := ch.s.channelReactor.reactorSub(channelKey).channel(channelKey)
 	if channel != nil {
 		// 重新生成接收者标签
 		_, err = channel.makeReceiverTag()
 		if err != nil {
 			ch.Error("创建接收者标签失败！", zap.Error(err))
-			c.ResponseError(err)
-			return
+			return err
 		}
 	}
-
-	c.ResponseOK()
+	return nil
 }
 
 func (ch *ChannelAPI) blacklistAdd(c *wkhttp.Context) {
@@ -771,6 +787,7 @@ func (ch *ChannelAPI) syncMessages(c *wkhttp.Context) {
 		EndMessageSeq   uint64   `json:"end_message_seq"`   // 结束消息列号（结果不包含end_message_seq的消息）
 		Limit           int      `json:"limit"`             // 每次同步数量限制
 		PullMode        PullMode `json:"pull_mode"`         // 拉取模式 0:向下拉取 1:向上拉取
+		MinMessageSeq   uint64   `json:"min_message_seq"`   // 开启追随者读时，副本至少需要有的消息序号（副本数据落后则从领导读取）
 	}
 	bodyBytes, err := BindJSON(&req, c)
 	if err != nil {
@@ -804,7 +821,7 @@ func (ch *ChannelAPI) syncMessages(c *wkhttp.Context) {
 		fakeChannelID = GetFakeChannelIDWith(req.LoginUID, req.ChannelID)
 	}
 	if ch.s.opts.ClusterOn() {
-		leaderInfo, err := ch.s.cluster.LeaderOfChannelForRead(fakeChannelID, req.ChannelType) // 获取频道的领导节点
+		nodeInfo, err := ch.s.channelNodeForRead(fakeChannelID, req.ChannelType, req.MinMessageSeq) // 获取读取频道消息的节点
 		if errors.Is(err, cluster.ErrChannelClusterConfigNotFound) {
 			ch.Info("频道集群从未初始化，返回空消息.", zap.String("channelID", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
 			c.JSON(http.StatusOK, emptySyncMessageResp)
@@ -815,20 +832,21 @@ func (ch *ChannelAPI) syncMessages(c *wkhttp.Context) {
 			c.ResponseError(errors.New("获取频道所在节点失败！"))
 			return
 		}
-		leaderIsSelf := leaderInfo.Id == ch.s.opts.Cluster.NodeId
+		nodeIsSelf := nodeInfo.Id == ch.s.opts.Cluster.NodeId
 
-		if !leaderIsSelf {
-			ch.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
-			c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+		if !nodeIsSelf {
+			ch.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", nodeInfo.ApiServerAddr, c.Request.URL.Path)))
+			c.ForwardWithBody(fmt.Sprintf("%s%s", nodeInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
 			return
 		}
 	}
+	// 追随者读时只读取已应用的消息
 	if req.StartMessageSeq == 0 && req.EndMessageSeq == 0 {
-		messages, err = ch.s.store.LoadLastMsgs(fakeChannelID, req.ChannelType, limit)
+		messages, err = ch.s.loadReadableLastMsgsWithEnd(fakeChannelID, req.ChannelType, 0, limit)
 	} else if req.PullMode == PullModeUp { // 向上拉取
-		messages, err = ch.s.store.LoadNextRangeMsgs(fakeChannelID, req.ChannelType, req.StartMessageSeq, req.EndMessageSeq, limit)
+		messages, err = ch.s.loadReadableNextRangeMsgs(fakeChannelID, req.ChannelType, req.StartMessageSeq, req.EndMessageSeq, limit)
 	} else {
-		messages, err = ch.s.store.LoadPrevRangeMsgs(fakeChannelID, req.ChannelType, req.StartMessageSeq, req.EndMessageSeq, limit)
+		messages, err = ch.s.loadReadablePrevRangeMsgs(fakeChannelID, req.ChannelType, req.StartMessageSeq, req.EndMessageSeq, limit)
 	}
 	if err != nil {
 		ch.Error("获取消息失败！", zap.Error(err), zap.Any("req", req))
@@ -929,3 +947,293 @@ func (ch *ChannelAPI) addOrUpdateChannel(channelInfo wkdb.ChannelInfo) error {
 	}
 	return nil
 }
+
+// 频道置顶消息变更的cmd类型
+const channelPinsCMDType = "channelPinsUpdate"
+
+// 频道公告变更的cmd类型
+const channelAnnouncementCMDType = "channelAnnouncementUpdate"
+
+// 获取频道置顶消息
+func (ch *ChannelAPI) pinsGet(c *wkhttp.Context) {
+	channelId := c.Query("channel_id")
+	channelType := wkutil.ParseUint8(c.Query("channel_type"))
+	if strings.TrimSpace(channelId) == "" {
+		c.ResponseError(errors.New("channel_id不能为空！"))
+		return
+	}
+	if ch.forwardToChannelSlotLeaderIfNeed(c, channelId, channelType, nil) {
+		return
+	}
+	pins, err := ch.s.store.GetChannelPins(channelId, channelType)
+	if err != nil {
+		ch.Error("获取置顶消息失败！", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, pins)
+}
+
+// 置顶消息
+func (ch *ChannelAPI) pinsAdd(c *wkhttp.Context) {
+	var req channelPinReq
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		ch.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if ch.forwardToChannelSlotLeaderIfNeed(c, req.ChannelID, req.ChannelType, bodyBytes) {
+		return
+	}
+
+	lastMsgSeq, err := ch.s.channelLastMessageSeq(req.ChannelID, req.ChannelType)
+	if err != nil {
+		ch.Error("获取最大消息序号失败！", zap.Error(err), zap.String("channelId", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(errors.New("获取最大消息序号失败！"))
+		return
+	}
+	if req.MessageSeq > lastMsgSeq {
+		c.ResponseError(fmt.Errorf("消息[%d]不存在，频道最大消息序号为%d！", req.MessageSeq, lastMsgSeq))
+		return
+	}
+
+	// 置顶数量的判断和修改需要按频道串行执行
+	channelKey := wkutil.ChannelToKey(req.ChannelID, req.ChannelType)
+	ch.s.channelLock.Lock(channelKey)
+	defer ch.s.channelLock.Unlock(channelKey)
+
+	pins, err := ch.s.store.GetChannelPins(req.ChannelID, req.ChannelType)
+	if err != nil {
+		ch.Error("获取置顶消息失败！", zap.Error(err), zap.String("channelId", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(err)
+		return
+	}
+	pinnedAt := uint64(time.Now().Unix())
+	exist := false
+	for _, pin := range pins {
+		if pin.MessageSeq == req.MessageSeq {
+			exist = true
+			pinnedAt = pin.PinnedAt
+			break
+		}
+	}
+	if !exist && ch.s.opts.Channel.PinMaxCount > 0 && len(pins) >= ch.s.opts.Channel.PinMaxCount {
+		c.ResponseError(fmt.Errorf("置顶消息数量不能超过%d条！", ch.s.opts.Channel.PinMaxCount))
+		return
+	}
+
+	sort := req.Sort
+	if sort == 0 { // 没有指定排序号，则按置顶时间排序（最新置顶的在前面）
+		sort = uint64(time.Now().UnixMilli())
+	}
+	err = ch.s.store.AddOrUpdateChannelPin(wkdb.ChannelPin{
+		ChannelId:   req.ChannelID,
+		ChannelType: req.ChannelType,
+		MessageSeq:  req.MessageSeq,
+		PinnedBy:    req.PinnedBy,
+		PinnedAt:    pinnedAt,
+		Sort:        sort,
+	})
+	if err != nil {
+		ch.Error("置顶消息失败！", zap.Error(err), zap.String("channelId", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(err)
+		return
+	}
+	ch.notifyChannelCMD(req.ChannelID, req.ChannelType, map[string]interface{}{
+		"type":         channelPinsCMDType,
+		"channel_id":   req.ChannelID,
+		"channel_type": req.ChannelType,
+		"message_seq":  req.MessageSeq,
+		"pinned":       1,
+		"operator":     req.PinnedBy,
+	})
+	c.ResponseOK()
+}
+
+// 取消置顶消息
+func (ch *ChannelAPI) pinsRemove(c *wkhttp.Context) {
+	var req struct {
+		ChannelID   string   `json:"channel_id"`
+		ChannelType uint8    `json:"channel_type"`
+		MessageSeqs []uint64 `json:"message_seqs"` // 取消置顶的消息序号
+		Operator    string   `json:"operator"`     // 操作者
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		ch.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.ChannelID) == "" {
+		c.ResponseError(errors.New("channel_id不能为空！"))
+		return
+	}
+	if len(req.MessageSeqs) == 0 {
+		c.ResponseError(errors.New("message_seqs不能为空！"))
+		return
+	}
+	if ch.forwardToChannelSlotLeaderIfNeed(c, req.ChannelID, req.ChannelType, bodyBytes) {
+		return
+	}
+	err = ch.s.store.RemoveChannelPins(req.ChannelID, req.ChannelType, req.MessageSeqs)
+	if err != nil {
+		ch.Error("取消置顶消息失败！", zap.Error(err), zap.String("channelId", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(err)
+		return
+	}
+	ch.notifyChannelCMD(req.ChannelID, req.ChannelType, map[string]interface{}{
+		"type":         channelPinsCMDType,
+		"channel_id":   req.ChannelID,
+		"channel_type": req.ChannelType,
+		"message_seqs": req.MessageSeqs,
+		"pinned":       0,
+		"operator":     req.Operator,
+	})
+	c.ResponseOK()
+}
+
+// 获取频道公告
+func (ch *ChannelAPI) announcementGet(c *wkhttp.Context) {
+	channelId := c.Query("channel_id")
+	channelType := wkutil.ParseUint8(c.Query("channel_type"))
+	if strings.TrimSpace(channelId) == "" {
+		c.ResponseError(errors.New("channel_id不能为空！"))
+		return
+	}
+	if ch.forwardToChannelSlotLeaderIfNeed(c, channelId, channelType, nil) {
+		return
+	}
+	announcement, err := ch.s.store.GetChannelAnnouncement(channelId, channelType)
+	if err != nil && err != wkdb.ErrNotFound {
+		ch.Error("获取频道公告失败！", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+		c.ResponseError(err)
+		return
+	}
+	announcement.ChannelId = channelId
+	announcement.ChannelType = channelType
+	c.JSON(http.StatusOK, announcement)
+}
+
+// 设置频道公告（content为空表示清除公告）
+func (ch *ChannelAPI) announcementSet(c *wkhttp.Context) {
+	var req struct {
+		ChannelID   string `json:"channel_id"`
+		ChannelType uint8  `json:"channel_type"`
+		Content     string `json:"content"`    // 公告内容
+		UpdatedBy   string `json:"updated_by"` // 修改者
+		Version     uint64 `json:"version"`    // 客户端当前的公告版本，如果不为0并且与服务端的版本不一致则修改失败（防止覆盖别人的修改）
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		ch.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.ChannelID) == "" {
+		c.ResponseError(errors.New("channel_id不能为空！"))
+		return
+	}
+	if ch.forwardToChannelSlotLeaderIfNeed(c, req.ChannelID, req.ChannelType, bodyBytes) {
+		return
+	}
+
+	// 版本的比较和修改需要按频道串行执行，否则并发修改时会互相覆盖
+	channelKey := wkutil.ChannelToKey(req.ChannelID, req.ChannelType)
+	ch.s.channelLock.Lock(channelKey)
+	defer ch.s.channelLock.Unlock(channelKey)
+
+	announcement, err := ch.s.store.GetChannelAnnouncement(req.ChannelID, req.ChannelType)
+	if err != nil && err != wkdb.ErrNotFound {
+		ch.Error("获取频道公告失败！", zap.Error(err), zap.String("channelId", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(err)
+		return
+	}
+	if req.Version != 0 && req.Version != announcement.Version {
+		c.ResponseError(fmt.Errorf("公告已被修改，当前版本为%d！", announcement.Version))
+		return
+	}
+	announcement = wkdb.ChannelAnnouncement{
+		ChannelId:   req.ChannelID,
+		ChannelType: req.ChannelType,
+		Content:     req.Content,
+		UpdatedBy:   req.UpdatedBy,
+		UpdatedAt:   uint64(time.Now().Unix()),
+		Version:     announcement.Version + 1,
+	}
+	err = ch.s.store.SetChannelAnnouncement(announcement)
+	if err != nil {
+		ch.Error("设置频道公告失败！", zap.Error(err), zap.String("channelId", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(err)
+		return
+	}
+	ch.notifyChannelCMD(req.ChannelID, req.ChannelType, map[string]interface{}{
+		"type":         channelAnnouncementCMDType,
+		"channel_id":   req.ChannelID,
+		"channel_type": req.ChannelType,
+		"content":      announcement.Content,
+		"updated_by":   announcement.UpdatedBy,
+		"updated_at":   announcement.UpdatedAt,
+		"version":      announcement.Version,
+	})
+	c.JSON(http.StatusOK, announcement)
+}
+
+// 如果本节点不是频道所在槽的领导节点，则转发请求到领导节点（返回true表示已转发）
+func (ch *ChannelAPI) forwardToChannelSlotLeaderIfNeed(c *wkhttp.Context, channelId string, channelType uint8, bodyBytes []byte) bool {
+	if !ch.s.opts.ClusterOn() {
+		return false
+	}
+	leaderInfo, err := ch.s.cluster.SlotLeaderOfChannel(channelId, channelType) // 获取频道的领导节点
+	if err != nil {
+		ch.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", channelId), zap.Uint8("channelType", channelType))
+		c.ResponseError(errors.New("获取频道所在节点失败！"))
+		return true
+	}
+	if leaderInfo.Id == ch.s.opts.Cluster.NodeId {
+		return false
+	}
+	ch.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+	c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+	return true
+}
+
+// 通过cmd消息通知频道的订阅者（走正常的消息投递流程，离线的订阅者上线后可以同步到）
+func (ch *ChannelAPI) notifyChannelCMD(channelId string, channelType uint8, content map[string]interface{}) {
+	clientMsgNo := fmt.Sprintf("%s0", wkutil.GenUUID())
+	_, err := NewMessageAPI(ch.s).sendMessageToChannel(MessageSendReq{
+		Header: MessageHeader{
+			SyncOnce: 1,
+		},
+		FromUID: ch.s.opts.SystemUID,
+		Payload: []byte(wkutil.ToJSON(content)),
+	}, channelId, channelType, clientMsgNo, wkproto.StreamFlagIng)
+	if err != nil {
+		ch.Warn("send channel cmd failed", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+	}
+}
+
+type channelPinReq struct {
+	ChannelID   string `json:"channel_id"`
+	ChannelType uint8  `json:"channel_type"`
+	MessageSeq  uint64 `json:"message_seq"` // 置顶的消息序号
+	PinnedBy    string `json:"pinned_by"`   // 置顶操作者
+	Sort        uint64 `json:"sort"`        // 排序号（越大越靠前） 不传则按置顶时间排序
+}
+
+func (r channelPinReq) Check() error {
+	if strings.TrimSpace(r.ChannelID) == "" {
+		return errors.New("channel_id不能为空！")
+	}
+	if r.ChannelType == 0 {
+		return errors.New("channel_type不能为0！")
+	}
+	if r.MessageSeq == 0 {
+		return errors.New("message_seq不能为0！")
+	}
+	return nil
+}
@@ -157,10 +157,14 @@ func (s *Server) GetConnInfos(uid string, sortOpt SortOpt, offset, limit int) []
 		sort.Sort(byOutPacketBytes{Conns: connCtxs})
 	case ByOutPacketBytesDesc:
 		sort.Sort(byOutPacketBytesDesc{Conns: connCtxs})
-	// case ByPendingBytes:
-	// sort.Sort(byPendingBytes{Conns: connCtxs})
-	// case ByPendingBytesDesc:
-	// sort.Sort(byPendingBytesDesc{Conns: connCtxs})
+	case ByPendingBytes:
+		sort.Sort(byPendingBytes{Conns: connCtxs})
+	case ByPendingBytesDesc:
+		sort.Sort(byPendingBytesDesc{Conns: connCtxs})
+	case BySlowCount:
+		sort.Sort(bySlowCount{Conns: connCtxs})
+	case BySlowCountDesc:
+		sort.Sort(bySlowCountDesc{Conns: connCtxs})
 	case ByUptime:
 		sort.Sort(byUptime{Conns: connCtxs})
 	case ByUptimeDesc:
@@ -209,6 +213,13 @@ type ConnInfo struct {
 	Uptime          string    `json:"uptime"`            // 启动时间
 	Idle            string    `json:"idle"`              // 客户端闲置时间
 	PendingBytes    int       `json:"pending_bytes"`     // 等待发送的字节数
+	PendingPackets  int64     `json:"pending_packets"`   // 等待发送的包数
+	MaxPendingBytes int64     `json:"max_pending_bytes"` // 等待发送字节数的最大值
+	SlowCount       int64     `json:"slow_count"`        // 发送缓冲区溢出（慢消费）的次数
+	SlowAt          int64     `json:"slow_at"`           // 最后一次慢消费的时间（毫秒时间戳）
+	DroppedPackets  int64     `json:"dropped_packets"`   // 因慢消费丢弃的包数
+	DroppedBytes    int64     `json:"dropped_bytes"`     // 因慢消费丢弃的字节数
+	SlowClosing     bool      `json:"slow_closing"`      // 是否因慢消费正在断开
 	InMsgs          int64     `json:"in_msgs"`           // 流入的消息数
 	OutMsgs         int64     `json:"out_msgs"`          // 流出的消息数量
 	InMsgBytes      int64     `json:"in_msg_bytes"`      // 流入的消息字节数量
@@ -222,6 +233,7 @@ type ConnInfo struct {
 	Version         uint8     `json:"version"`           // 客户端协议版本
 	ProxyTypeFormat string    `json:"proxy_type_format"` // 代理类型
 	LeaderId        uint64    `json:"leader_id"`         // 领导节点id
+	Compression     string    `json:"compression"`       // 协商的消息内容压缩算法
 }
 
 func newConnInfo(connCtx *connContext) *ConnInfo {
@@ -237,27 +249,36 @@ func newConnInfo(connCtx *connContext) *ConnInfo {
 		host = hostStr
 	}
 	connStats := connCtx.connStats
+	wkConnStats := conn.ConnStats()
 
 	return &ConnInfo{
-		ID:           connCtx.connId,
-		UID:          connCtx.uid,
-		IP:           host,
-		Port:         port,
-		LastActivity: connCtx.lastActivity.Load(),
-		Uptime:       myUptime(now.Sub(connCtx.uptime.Load())),
-		Idle:         myUptime(now.Sub(connCtx.lastActivity.Load())),
-		// PendingBytes:   c.OutboundBuffer().BoundBufferSize(),
-		InMsgs:         connStats.inMsgCount.Load(),
-		OutMsgs:        connStats.outMsgCount.Load(),
-		InMsgBytes:     connStats.inMsgByteCount.Load(),
-		OutMsgBytes:    connStats.outMsgByteCount.Load(),
-		InPackets:      connStats.inPacketCount.Load(),
-		OutPackets:     connStats.outPacketCount.Load(),
-		InPacketBytes:  connStats.inPacketByteCount.Load(),
-		OutPacketBytes: connStats.outPacketByteCount.Load(),
-		Device:         device(connCtx),
-		DeviceID:       connCtx.deviceId,
-		Version:        connCtx.protoVersion,
+		ID:              connCtx.connId,
+		UID:             connCtx.uid,
+		IP:              host,
+		Port:            port,
+		LastActivity:    connCtx.lastActivity.Load(),
+		Uptime:          myUptime(now.Sub(connCtx.uptime.Load())),
+		Idle:            myUptime(now.Sub(connCtx.lastActivity.Load())),
+		PendingBytes:    conn.OutboundBuffer().BoundBufferSize(),
+		PendingPackets:  wkConnStats.PendingPackets.Load(),
+		MaxPendingBytes: wkConnStats.MaxPendingBytes.Load(),
+		SlowCount:       wkConnStats.SlowCount.Load(),
+		SlowAt:          wkConnStats.SlowAt.Load(),
+		DroppedPackets:  wkConnStats.DroppedPackets.Load(),
+		DroppedBytes:    wkConnStats.DroppedBytes.Load(),
+		SlowClosing:     connCtx.slowClosing.Load(),
+		InMsgs:          connStats.inMsgCount.Load(),
+		OutMsgs:         connStats.outMsgCount.Load(),
+		InMsgBytes:      connStats.inMsgByteCount.Load(),
+		OutMsgBytes:     connStats.outMsgByteCount.Load(),
+		InPackets:       connStats.inPacketCount.Load(),
+		OutPackets:      connStats.outPacketCount.Load(),
+		InPacketBytes:   connStats.inPacketByteCount.Load(),
+		OutPacketBytes:  connStats.outPacketByteCount.Load(),
+		Device:          device(connCtx),
+		DeviceID:        connCtx.deviceId,
+		Version:         connCtx.protoVersion,
+		Compression:     connCtx.compression.String(),
 	}
 }
 
@@ -296,6 +317,8 @@ const (
 	ByOutMsgBytesDesc    SortOpt = "outMsgBytesDesc"    // 通过发送字节数排序
 	ByPendingBytes       SortOpt = "pendingBytes"       // 通过等待发送字节数排序
 	ByPendingBytesDesc   SortOpt = "pendingBytesDesc"   // 通过等待发送字节数排序
+	BySlowCount          SortOpt = "slowCount"          // 通过慢消费次数排序
+	BySlowCountDesc      SortOpt = "slowCountDesc"      // 通过慢消费次数排序
 	ByUptime             SortOpt = "uptime"             // 通过启动时间排序
 	ByUptimeDesc         SortOpt = "uptimeDesc"         // 通过启动时间排序
 	ByOutPacket          SortOpt = "outPacket"          // 通过发送包排序
@@ -497,21 +520,39 @@ func (l byInPacketDesc) Swap(i, j int) { l.Conns[i], l.Conns[j] = l.Conns[j], l.
 
 // PendingBytes
 
-// type byPendingBytes struct{ Conns []*connContext }
+type byPendingBytes struct{ Conns []*connContext }
 
-// func (l byPendingBytes) Less(i, j int) bool {
-// 	return l.Conns[i].OutboundBuffer().BoundBufferSize() < l.Conns[j].OutboundBuffer().BoundBufferSize()
-// }
-// func (l byPendingBytes) Len() int      { return len(l.Conns) }
-// func (l byPendingBytes) Swap(i, j int) { l.Conns[i], l.Conns[j] = l.Conns[j], l.Conns[i] }
+func (l byPendingBytes) Less(i, j int) bool {
+	return l.Conns[i].conn.OutboundBuffer().BoundBufferSize() < l.Conns[j].conn.OutboundBuffer().BoundBufferSize()
+}
+func (l byPendingBytes) Len() int      { return len(l.Conns) }
+func (l byPendingBytes) Swap(i, j int) { l.Conns[i], l.Conns[j] = l.Conns[j], l.Conns[i] }
+
+type byPendingBytesDesc struct{ Conns []*connContext }
+
+func (l byPendingBytesDesc) Less(i, j int) bool {
+	return l.Conns[i].conn.OutboundBuffer().BoundBufferSize() > l.Conns[j].conn.OutboundBuffer().BoundBufferSize()
+}
+func (l byPendingBytesDesc) Len() int      { return len(l.Conns) }
+func (l byPendingBytesDesc) Swap(i, j int) { l.Conns[i], l.Conns[j] = l.Conns[j], l.Conns[i] }
+
+// slowCount
 
-// type byPendingBytesDesc struct{ Conns []*connContext }
+type bySlowCount struct{ Conns []*connContext }
 
-// func (l byPendingBytesDesc) Less(i, j int) bool {
-// 	return l.Conns[i].OutboundBuffer().BoundBufferSize() > l.Conns[j].OutboundBuffer().BoundBufferSize()
-// }
-// func (l byPendingBytesDesc) Len() int      { return len(l.Conns) }
-// func (l byPendingBytesDesc) Swap(i, j int) { l.Conns[i], l.Conns[j] = l.Conns[j], l.Conns[i] }
+func (l bySlowCount) Less(i, j int) bool {
+	return l.Conns[i].conn.ConnStats().SlowCount.Load() < l.Conns[j].conn.ConnStats().SlowCount.Load()
+}
+func (l bySlowCount) Len() int      { return len(l.Conns) }
+func (l bySlowCount) Swap(i, j int) { l.Conns[i], l.Conns[j] = l.Conns[j], l.Conns[i] }
+
+type bySlowCountDesc struct{ Conns []*connContext }
+
+func (l bySlowCountDesc) Less(i, j int) bool {
+	return l.Conns[i].conn.ConnStats().SlowCount.Load() > l.Conns[j].conn.ConnStats().SlowCount.Load()
+}
+func (l bySlowCountDesc) Len() int      { return len(l.Conns) }
+func (l bySlowCountDesc) Swap(i, j int) { l.Conns[i], l.Conns[j] = l.Conns[j], l.Conns[i] }
 
 // uptime
 
@@ -41,6 +41,8 @@ func (s *ConversationAPI) Route(r *wkhttp.WKHttp) {
 	r.POST("/conversations/delete", s.deleteConversation)           // 删除会话
 	r.POST("/conversation/sync", s.syncUserConversation)            // 同步会话
 	r.POST("/conversation/syncMessages", s.syncRecentMessages)      // 同步会话最近消息
+	r.POST("/conversation/setAttrs", s.setConversationAttrs)        // 设置会话属性（置顶、免打扰、草稿、扩展数据）
+	r.GET("/conversation/unread_total", s.unreadTotal)              // 获取用户的总未读数量
 }
 
 // // Get a list of recent conversations
@@ -147,6 +149,8 @@ func (s *ConversationAPI) clearConversationUnread(c *wkhttp.Context) {
 		conversation.ReadToMsgSeq = msgSeq
 
 	}
+	conversation.UnreadCount = 0
+	conversation.MentionCount = 0 // 清空未读的同时清空@数量
 
 	err = s.s.store.AddOrUpdateConversations(req.UID, []wkdb.Conversation{conversation})
 	if err != nil {
@@ -156,6 +160,7 @@ func (s *ConversationAPI) clearConversationUnread(c *wkhttp.Context) {
 	}
 
 	s.s.conversationManager.DeleteUserConversationFromCache(req.UID, fakeChannelId, req.ChannelType)
+	s.s.notifyConversationUpdate(req.UID, req.conversationDeviceTarget, conversationUnreadContent(req.ChannelID, req.ChannelType, conversation))
 
 	c.ResponseOK()
 }
@@ -167,6 +172,7 @@ func (s *ConversationAPI) setConversationUnread(c *wkhttp.Context) {
 		ChannelType uint8  `json:"channel_type"`
 		Unread      int    `json:"unread"`
 		MessageSeq  uint32 `json:"message_seq"` // messageSeq 只有超大群才会传 因为超大群最近会话服务器不会维护，需要客户端传递messageSeq进行主动维护
+		conversationDeviceTarget
 	}
 	bodyBytes, err := BindJSON(&req, c)
 	if err != nil {
@@ -245,6 +251,9 @@ func (s *ConversationAPI) setConversationUnread(c *wkhttp.Context) {
 
 	conversation.ReadToMsgSeq = readedMsgSeq
 	conversation.UnreadCount = unread
+	if readedMsgSeq >= conversation.LastMentionSeq {
+		conversation.MentionCount = 0
+	}
 
 	err = s.s.store.AddOrUpdateConversations(req.UID, []wkdb.Conversation{conversation})
 	if err != nil {
@@ -254,6 +263,7 @@ func (s *ConversationAPI) setConversationUnread(c *wkhttp.Context) {
 	}
 
 	s.s.conversationManager.DeleteUserConversationFromCache(req.UID, fakeChannelId, req.ChannelType)
+	s.s.notifyConversationUpdate(req.UID, req.conversationDeviceTarget, conversationUnreadContent(req.ChannelID, req.ChannelType, conversation))
 
 	c.ResponseOK()
 }
@@ -300,6 +310,92 @@ func (s *ConversationAPI) deleteConversation(c *wkhttp.Context) {
 	}
 
 	s.s.conversationManager.DeleteUserConversationFromCache(req.UID, fakeChannelId, req.ChannelType)
+	s.s.notifyConversationUpdate(req.UID, req.conversationDeviceTarget, map[string]interface{}{
+		"action":       conversationUpdateActionDelete,
+		"channel_id":   req.ChannelID,
+		"channel_type": req.ChannelType,
+	})
+
+	c.ResponseOK()
+}
+
+// 设置会话属性（置顶、免打扰、草稿、扩展数据）
+func (s *ConversationAPI) setConversationAttrs(c *wkhttp.Context) {
+	var req conversationSetAttrsReq
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		s.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+
+	if s.s.opts.ClusterOn() {
+		leaderInfo, err := s.s.cluster.SlotLeaderOfChannel(req.UID, wkproto.ChannelTypePerson) // 获取频道的领导节点
+		if err != nil {
+			s.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", req.UID), zap.Uint8("channelType", wkproto.ChannelTypePerson))
+			c.ResponseError(errors.New("获取频道所在节点失败！"))
+			return
+		}
+		leaderIsSelf := leaderInfo.Id == s.s.opts.Cluster.NodeId
+		if !leaderIsSelf {
+			s.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+			c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+			return
+		}
+	}
+
+	fakeChannelId := req.ChannelID
+	if req.ChannelType == wkproto.ChannelTypePerson {
+		fakeChannelId = GetFakeChannelIDWith(req.UID, req.ChannelID)
+	}
+
+	conversation, err := s.s.store.GetConversation(req.UID, fakeChannelId, req.ChannelType)
+	if err != nil && err != wkdb.ErrNotFound {
+		s.Error("Failed to query conversation", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+
+	attrs := conversation.Attrs()
+	attrs.ChannelId = fakeChannelId
+	attrs.ChannelType = req.ChannelType
+	req.apply(&attrs)
+
+	if wkdb.IsEmptyConversation(conversation) {
+		// 会话不存在（例如置顶一个还没有消息的频道），直接创建带属性的会话
+		createdAt := time.Now()
+		updatedAt := time.Now()
+		conversation = wkdb.Conversation{
+			Uid:         req.UID,
+			Type:        getConversationType(s.s.opts, fakeChannelId),
+			ChannelId:   fakeChannelId,
+			ChannelType: req.ChannelType,
+			CreatedAt:   &createdAt,
+			UpdatedAt:   &updatedAt,
+		}
+		conversation.SetAttrs(attrs)
+		err = s.s.store.AddOrUpdateConversations(req.UID, []wkdb.Conversation{conversation})
+	} else {
+		err = s.s.store.SetConversationAttrs(req.UID, []wkdb.ConversationAttrs{attrs})
+	}
+	if err != nil {
+		s.Error("设置会话属性失败！", zap.Error(err), zap.String("uid", req.UID), zap.String("channelId", fakeChannelId), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(err)
+		return
+	}
+	s.s.notifyConversationUpdate(req.UID, req.conversationDeviceTarget, map[string]interface{}{
+		"action":       conversationUpdateActionAttrs,
+		"channel_id":   req.ChannelID,
+		"channel_type": req.ChannelType,
+		"pinned":       wkutil.BoolToInt(attrs.Pinned),
+		"muted":        wkutil.BoolToInt(attrs.Muted),
+		"draft":        attrs.Draft,
+		"extra":        attrs.Extra,
+	})
 
 	c.ResponseOK()
 }
@@ -307,7 +403,7 @@ func (s *ConversationAPI) deleteConversation(c *wkhttp.Context) {
 func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 	var req struct {
 		UID         string `json:"uid"`
-		Version     int64  `json:"version"`       // 当前客户端的会话最大版本号(客户端最新会话的时间戳)
+		Version     int64  `json:"version"`       // 客户端已同步到的最大会话版本号（用户级的变更计数，不是时间戳），为0则全量同步
 		LastMsgSeqs string `json:"last_msg_seqs"` // 客户端所有会话的最后一条消息序列号 格式： channelID:channelType:last_msg_seq|channelID:channelType:last_msg_seq
 		MsgCount    int64  `json:"msg_count"`     // 每个会话消息数量
 	}
@@ -344,32 +440,73 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 	)
 
 	// ==================== 获取用户活跃的最近会话 ====================
-	conversations, err := s.s.store.GetLastConversations(req.UID, wkdb.ConversationTypeChat, 0, s.s.opts.Conversation.UserMaxCount)
-	if err != nil && err != wkdb.ErrNotFound {
-		s.Error("获取conversation失败！", zap.Error(err), zap.String("uid", req.UID))
-		c.ResponseError(errors.New("获取conversation失败！"))
+	// 会话版本号是用户级的变更计数（会话新增、新消息、已读、属性变更和删除都会使其增加），不是时间戳。
+	// 客户端传递的version不超过服务端当前的会话版本号并且不早于已清理的删除墓碑时进行增量同步，
+	// 否则（首次同步、墓碑已被清理或旧版本客户端传递的时间戳）进行全量同步
+	var (
+		incremental    bool
+		clientVersion  = uint64(req.Version)
+		currentVersion uint64
+		conversations  []wkdb.Conversation
+		tombstones     []wkdb.ConversationTombstone
+	)
+	currentVersion, err = s.s.store.GetConversationVersion(req.UID)
+	if err != nil {
+		s.Error("获取会话版本号失败！", zap.Error(err), zap.String("uid", req.UID))
+		c.ResponseError(errors.New("获取会话版本号失败！"))
 		return
 	}
+	if req.Version > 0 && clientVersion <= currentVersion {
+		prunedVersion, err := s.s.store.GetConversationPrunedVersion(req.UID)
+		if err != nil {
+			s.Error("获取会话已清理的版本号失败！", zap.Error(err), zap.String("uid", req.UID))
+			c.ResponseError(errors.New("获取会话版本号失败！"))
+			return
+		}
+		incremental = clientVersion >= prunedVersion
+	}
 
-	// 获取用户缓存的最近会话
-	cacheConversations := s.s.conversationManager.GetUserConversationFromCache(req.UID, wkdb.ConversationTypeChat)
-
-	for _, cacheConversation := range cacheConversations {
-		exist := false
-		for i, conversation := range conversations {
-			if cacheConversation.ChannelId == conversation.ChannelId && cacheConversation.ChannelType == conversation.ChannelType {
-				if cacheConversation.ReadToMsgSeq > conversation.ReadToMsgSeq {
-					conversations[i].ReadToMsgSeq = cacheConversation.ReadToMsgSeq
+	// 本次同步到的版本号，还未保存的缓存会话使用此版本号返回
+	syncVersion := currentVersion
+	userMaxCount := s.s.opts.Live().ConversationUserMaxCount
+	if incremental {
+		conversations, err = s.s.store.GetConversationsByVersion(req.UID, wkdb.ConversationTypeChat, clientVersion, userMaxCount)
+		if err != nil {
+			s.Error("获取变更的conversation失败！", zap.Error(err), zap.String("uid", req.UID))
+			c.ResponseError(errors.New("获取conversation失败！"))
+			return
+		}
+		tombstones, err = s.s.store.GetConversationTombstones(req.UID, clientVersion)
+		if err != nil {
+			s.Error("获取会话删除记录失败！", zap.Error(err), zap.String("uid", req.UID))
+			c.ResponseError(errors.New("获取会话删除记录失败！"))
+			return
+		}
+		// 变更的会话超过了单次同步的数量，墓碑只返回到最后一个会话的版本，剩余的由客户端下次同步获取
+		if userMaxCount > 0 && len(conversations) >= userMaxCount {
+			maxVersion := conversations[len(conversations)-1].Version
+			syncVersion = maxVersion
+			for i, tombstone := range tombstones {
+				if tombstone.Version > maxVersion {
+					tombstones = tombstones[:i]
+					break
 				}
-				exist = true
-				break
 			}
 		}
-		if !exist {
-			conversations = append(conversations, cacheConversation)
+	} else {
+		conversations, err = s.s.store.GetLastConversations(req.UID, wkdb.ConversationTypeChat, 0, userMaxCount)
+		if err != nil && err != wkdb.ErrNotFound {
+			s.Error("获取conversation失败！", zap.Error(err), zap.String("uid", req.UID))
+			c.ResponseError(errors.New("获取conversation失败！"))
+			return
 		}
 	}
 
+	// 获取用户缓存的最近会话
+	cacheConversations := s.s.conversationManager.GetUserConversationFromCache(req.UID, wkdb.ConversationTypeChat)
+
+	conversations = mergeCacheConversations(conversations, cacheConversations)
+
 	// 设置最近会话已读至的消息序列号
 	for _, conversation := range conversations {
 		realChannelId := conversation.ChannelId
@@ -398,9 +535,8 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 	}
 
 	// ==================== 获取最近会话的最近的消息列表 ====================
+	var channelRecentMessages []*channelRecentMessage
 	if req.MsgCount > 0 {
-		var channelRecentMessages []*channelRecentMessage
-
 		// 获取用户最近会话的最近消息
 		channelRecentMessages, err = s.s.getRecentMessagesForCluster(req.UID, int(req.MsgCount), channelRecentMessageReqs, true)
 		if err != nil {
@@ -408,48 +544,95 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 			c.ResponseError(errors.New("获取最近消息失败！"))
 			return
 		}
+	}
 
-		for i := 0; i < len(conversations); i++ {
-			conversation := conversations[i]
-			if conversation.ChannelType == wkproto.ChannelTypePerson && conversation.ChannelId == s.s.opts.SystemUID { // 系统消息不返回
-				continue
-			}
-			resp := newSyncUserConversationResp(conversation)
-
-			for _, channelRecentMessage := range channelRecentMessages {
-				if resp.ChannelId == channelRecentMessage.ChannelId && conversation.ChannelType == channelRecentMessage.ChannelType {
-					if len(channelRecentMessage.Messages) > 0 {
-						lastMsg := channelRecentMessage.Messages[0]
-						resp.LastMsgSeq = uint32(lastMsg.MessageSeq)
-						resp.LastClientMsgNo = lastMsg.ClientMsgNo
-						resp.Timestamp = int64(lastMsg.Timestamp)
-						if lastMsg.MessageSeq > uint64(resp.ReadedToMsgSeq) {
-							resp.Unread = int(lastMsg.MessageSeq - uint64(resp.ReadedToMsgSeq))
-						}
-
-						resp.Version = time.Unix(int64(lastMsg.Timestamp), 0).UnixNano()
-					}
+	for i := 0; i < len(conversations); i++ {
+		conversation := conversations[i]
+		if conversation.ChannelType == wkproto.ChannelTypePerson && conversation.ChannelId == s.s.opts.SystemUID { // 系统消息不返回
+			continue
+		}
+		resp := newSyncUserConversationResp(conversation)
+		if resp.Version == 0 { // 只在缓存中还未保存的会话
+			resp.Version = int64(syncVersion)
+		}
 
-					resp.Recents = channelRecentMessage.Messages
-					break
+		for _, channelRecentMessage := range channelRecentMessages {
+			if resp.ChannelId == channelRecentMessage.ChannelId && conversation.ChannelType == channelRecentMessage.ChannelType {
+				if len(channelRecentMessage.Messages) > 0 {
+					lastMsg := channelRecentMessage.Messages[0]
+					resp.LastMsgSeq = uint32(lastMsg.MessageSeq)
+					resp.LastClientMsgNo = lastMsg.ClientMsgNo
+					resp.Timestamp = int64(lastMsg.Timestamp)
 				}
+
+				resp.Recents = channelRecentMessage.Messages
+				break
 			}
+		}
+
+		// 增量同步时，版本号有变化的会话（例如修改了会话属性）即使没有新消息也需要返回
+		if incremental && conversation.Version > clientVersion {
+			resps = append(resps, resp)
+			continue
+		}
 
-			msgSeq := channelLastMsgMap[fmt.Sprintf("%s-%d", conversation.ChannelId, conversation.ChannelType)]
+		msgSeq := channelLastMsgMap[fmt.Sprintf("%s-%d", conversation.ChannelId, conversation.ChannelType)]
 
-			if msgSeq != 0 && msgSeq >= uint64(resp.LastMsgSeq) {
-				continue
-			}
+		if msgSeq != 0 && msgSeq >= uint64(resp.LastMsgSeq) {
+			continue
+		}
 
-			if len(resp.Recents) > 0 {
-				resps = append(resps, resp)
-			}
+		if len(resp.Recents) > 0 {
+			resps = append(resps, resp)
 		}
 	}
 
+	// ==================== 已删除的会话 ====================
+	for _, tombstone := range tombstones {
+		resps = append(resps, newSyncUserConversationRespWithTombstone(tombstone))
+	}
+
 	c.JSON(http.StatusOK, resps)
 }
 
+// 获取用户的总未读数量（不包含免打扰的会话）
+func (s *ConversationAPI) unreadTotal(c *wkhttp.Context) {
+	uid := strings.TrimSpace(c.Query("uid"))
+	if uid == "" {
+		c.ResponseError(errors.New("uid不能为空！"))
+		return
+	}
+
+	if s.s.opts.ClusterOn() {
+		leaderInfo, err := s.s.cluster.SlotLeaderOfChannel(uid, wkproto.ChannelTypePerson) // 获取频道的领导节点
+		if err != nil {
+			s.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", uid), zap.Uint8("channelType", wkproto.ChannelTypePerson))
+			c.ResponseError(errors.New("获取频道所在节点失败！"))
+			return
+		}
+		leaderIsSelf := leaderInfo.Id == s.s.opts.Cluster.NodeId
+		if !leaderIsSelf {
+			s.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+			c.Forward(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path))
+			return
+		}
+	}
+
+	conversations, err := s.s.store.GetConversationsByType(uid, wkdb.ConversationTypeChat)
+	if err != nil {
+		s.Error("获取conversation失败！", zap.Error(err), zap.String("uid", uid))
+		c.ResponseError(errors.New("获取conversation失败！"))
+		return
+	}
+	cacheConversations := s.s.conversationManager.GetUserConversationFromCache(uid, wkdb.ConversationTypeChat)
+	conversations = mergeCacheConversations(conversations, cacheConversations)
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"uid":          uid,
+		"unread_total": unreadTotalOfConversations(conversations, s.s.opts.SystemUID),
+	})
+}
+
 func (s *ConversationAPI) getChannelLastMsgSeqMap(lastMsgSeqs string) map[string]uint64 {
 	channelLastMsgSeqStrList := strings.Split(lastMsgSeqs, "|")
 	channelLastMsgMap := map[string]uint64{} // 频道对应的messageSeq
@@ -482,7 +665,16 @@ func (s *ConversationAPI) syncRecentMessages(c *wkhttp.Context) {
 	if msgCount <= 0 {
 		msgCount = 15
 	}
-	channelRecentMessages, err := s.s.getRecentMessages(req.UID, msgCount, req.Channels, wkutil.IntToBool(req.OrderByLast))
+	var (
+		channelRecentMessages []*channelRecentMessage
+		err                   error
+	)
+	if s.s.opts.ClusterOn() && s.s.opts.Cluster.FollowerRead {
+		// 开启追随者读时，请求可能是从非副本节点转发过来的，需要再次判断本节点数据是否落后，落后则从领导读取
+		channelRecentMessages, err = s.s.getRecentMessagesForCluster(req.UID, msgCount, req.Channels, wkutil.IntToBool(req.OrderByLast))
+	} else {
+		channelRecentMessages, err = s.s.getRecentMessages(req.UID, msgCount, req.Channels, wkutil.IntToBool(req.OrderByLast))
+	}
 	if err != nil {
 		s.Error("获取最近消息失败！", zap.Error(err))
 		c.ResponseError(errors.New("获取最近消息失败！"))
@@ -506,7 +698,7 @@ func (s *Server) getRecentMessagesForCluster(uid string, msgCount int, channels
 		if channelRecentMsgReq.ChannelType == wkproto.ChannelTypePerson {
 			fakeChannelId = GetFakeChannelIDWith(uid, channelRecentMsgReq.ChannelId)
 		}
-		leaderInfo, err := s.cluster.LeaderOfChannelForRead(fakeChannelId, channelRecentMsgReq.ChannelType) // 获取频道的领导节点
+		leaderInfo, err := s.channelNodeForRead(fakeChannelId, channelRecentMsgReq.ChannelType, channelRecentMsgReq.LastMsgSeq) // 获取读取频道消息的节点
 		if err != nil {
 			s.Warn("getRecentMessagesForCluster: 获取频道所在节点失败！", zap.Error(err), zap.String("channelId", fakeChannelId), zap.Uint8("channelType", channelRecentMsgReq.ChannelType))
 			continue
@@ -621,7 +813,7 @@ func (s *Server) getRecentMessages(uid string, msgCount int, channels []*channel
 					msgSeq = msgSeq - 1 // 这里减1的目的是为了获取到最后一条消息
 				}
 
-				recentMessages, err = s.store.LoadLastMsgsWithEnd(fakeChannelID, channel.ChannelType, msgSeq, msgCount)
+				recentMessages, err = s.loadReadableLastMsgsWithEnd(fakeChannelID, channel.ChannelType, msgSeq, msgCount)
 				if err != nil {
 					s.Error("查询最近消息失败！", zap.Error(err), zap.String("uid", uid), zap.String("fakeChannelID", fakeChannelID), zap.Uint8("channelType", channel.ChannelType), zap.Uint64("LastMsgSeq", channel.LastMsgSeq))
 					return nil, err
@@ -635,7 +827,7 @@ func (s *Server) getRecentMessages(uid string, msgCount int, channels []*channel
 				}
 				sort.Sort(sort.Reverse(messageResps))
 			} else {
-				recentMessages, err = s.store.LoadNextRangeMsgs(fakeChannelID, channel.ChannelType, msgSeq, 0, msgCount)
+				recentMessages, err = s.loadReadableNextRangeMsgs(fakeChannelID, channel.ChannelType, msgSeq, 0, msgCount)
 				if err != nil {
 					s.Error("查询最近消息失败！", zap.Error(err), zap.String("uid", uid), zap.String("fakeChannelID", fakeChannelID), zap.Uint8("channelType", channel.ChannelType), zap.Uint64("LastMsgSeq", channel.LastMsgSeq))
 					return nil, err
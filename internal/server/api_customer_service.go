@@ -0,0 +1,283 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// CustomerServiceAPI 客服相关API
+type CustomerServiceAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewCustomerServiceAPI NewCustomerServiceAPI
+func NewCustomerServiceAPI(s *Server) *CustomerServiceAPI {
+	return &CustomerServiceAPI{
+		s:   s,
+		Log: wklog.NewWKLog("CustomerServiceAPI"),
+	}
+}
+
+// Route 客服相关路由配置
+func (cs *CustomerServiceAPI) Route(r *wkhttp.WKHttp) {
+	//################### 队列 ###################
+	r.GET("/customerservice/queue", cs.queueGet)            // 获取客服队列
+	r.POST("/customerservice/queue", cs.queueSave)          // 创建或更新客服队列
+	r.POST("/customerservice/queue/delete", cs.queueDelete) // 删除客服队列（关闭队列下的所有会话）
+
+	//################### 会话 ###################
+	r.GET("/customerservice/sessions", cs.sessions)   // 获取队列下的会话（排队中和接待中）
+	r.POST("/customerservice/transfer", cs.transfer)  // 转接会话
+	r.POST("/customerservice/close", cs.closeSession) // 关闭会话
+}
+
+// 获取客服队列
+func (cs *CustomerServiceAPI) queueGet(c *wkhttp.Context) {
+	queueId := c.Query("queue_id")
+	if strings.TrimSpace(queueId) == "" {
+		c.ResponseError(errors.New("queue_id不能为空！"))
+		return
+	}
+	if cs.forwardToQueueLeaderIfNeed(c, queueId, nil) {
+		return
+	}
+	queue, err := cs.s.store.GetCustomerServiceQueue(queueId)
+	if err != nil {
+		if err == wkdb.ErrNotFound {
+			c.ResponseError(errors.New("客服队列不存在！"))
+			return
+		}
+		cs.Error("获取客服队列失败！", zap.Error(err), zap.String("queueId", queueId))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, queue)
+}
+
+// 创建或更新客服队列
+func (cs *CustomerServiceAPI) queueSave(c *wkhttp.Context) {
+	var req customerServiceQueueReq
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		cs.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if cs.forwardToQueueLeaderIfNeed(c, req.QueueId, bodyBytes) {
+		return
+	}
+
+	now := uint64(time.Now().Unix())
+	queue, err := cs.s.store.GetCustomerServiceQueue(req.QueueId)
+	if err != nil && err != wkdb.ErrNotFound {
+		cs.Error("获取客服队列失败！", zap.Error(err), zap.String("queueId", req.QueueId))
+		c.ResponseError(err)
+		return
+	}
+	if err == wkdb.ErrNotFound {
+		queue.QueueId = req.QueueId
+		queue.CreatedAt = now
+	}
+	queue.Name = req.Name
+	queue.Strategy = req.Strategy
+	if queue.Strategy == "" {
+		queue.Strategy = CustomerServiceStrategyRoundRobin
+	}
+	queue.MaxSessions = req.MaxSessions
+	queue.Agents = make([]string, 0, len(req.Agents))
+	for _, agent := range req.Agents {
+		agent = strings.TrimSpace(agent)
+		if agent == "" || wkutil.ArrayContains(queue.Agents, agent) {
+			continue
+		}
+		queue.Agents = append(queue.Agents, agent)
+	}
+	queue.UpdatedAt = now
+
+	err = cs.s.customerServiceManager.saveQueue(queue)
+	if err != nil {
+		cs.Error("保存客服队列失败！", zap.Error(err), zap.String("queueId", req.QueueId))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+// 删除客服队列
+func (cs *CustomerServiceAPI) queueDelete(c *wkhttp.Context) {
+	var req struct {
+		QueueId  string `json:"queue_id"`
+		Operator string `json:"operator"` // 操作者
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		cs.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.QueueId) == "" {
+		c.ResponseError(errors.New("queue_id不能为空！"))
+		return
+	}
+	if cs.forwardToQueueLeaderIfNeed(c, req.QueueId, bodyBytes) {
+		return
+	}
+	err = cs.s.customerServiceManager.deleteQueue(req.QueueId, req.Operator)
+	if err != nil {
+		cs.Error("删除客服队列失败！", zap.Error(err), zap.String("queueId", req.QueueId))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+// 获取队列下的会话
+func (cs *CustomerServiceAPI) sessions(c *wkhttp.Context) {
+	queueId := c.Query("queue_id")
+	agentUid := c.Query("agent_uid") // 只获取指定客服接待的会话
+	if strings.TrimSpace(queueId) == "" {
+		c.ResponseError(errors.New("queue_id不能为空！"))
+		return
+	}
+	if cs.forwardToQueueLeaderIfNeed(c, queueId, nil) {
+		return
+	}
+	sessions, err := cs.s.store.GetCustomerServiceSessions(queueId)
+	if err != nil {
+		cs.Error("获取客服会话失败！", zap.Error(err), zap.String("queueId", queueId))
+		c.ResponseError(err)
+		return
+	}
+	if agentUid != "" {
+		agentSessions := make([]wkdb.CustomerServiceSession, 0, len(sessions))
+		for _, session := range sessions {
+			if session.AgentUid == agentUid {
+				agentSessions = append(agentSessions, session)
+			}
+		}
+		sessions = agentSessions
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// 转接会话
+func (cs *CustomerServiceAPI) transfer(c *wkhttp.Context) {
+	var req struct {
+		ChannelId string `json:"channel_id"` // 客服频道id
+		ToAgent   string `json:"to_agent"`   // 转接给的客服uid，为空则按队列的分配策略选择其他客服
+		Operator  string `json:"operator"`   // 操作者
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		cs.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	_, queueId, ok := parseCustomerServiceChannel(req.ChannelId)
+	if !ok {
+		c.ResponseError(errors.New("channel_id格式有误，格式为：访客uid|队列id！"))
+		return
+	}
+	if cs.forwardToQueueLeaderIfNeed(c, queueId, bodyBytes) {
+		return
+	}
+	session, err := cs.s.customerServiceManager.transfer(req.ChannelId, strings.TrimSpace(req.ToAgent), req.Operator)
+	if err != nil {
+		if err == wkdb.ErrNotFound {
+			c.ResponseError(errors.New("客服会话不存在！"))
+			return
+		}
+		cs.Error("转接客服会话失败！", zap.Error(err), zap.String("channelId", req.ChannelId))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// 关闭会话
+func (cs *CustomerServiceAPI) closeSession(c *wkhttp.Context) {
+	var req struct {
+		ChannelId string `json:"channel_id"` // 客服频道id
+		Operator  string `json:"operator"`   // 操作者
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		cs.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	_, queueId, ok := parseCustomerServiceChannel(req.ChannelId)
+	if !ok {
+		c.ResponseError(errors.New("channel_id格式有误，格式为：访客uid|队列id！"))
+		return
+	}
+	if cs.forwardToQueueLeaderIfNeed(c, queueId, bodyBytes) {
+		return
+	}
+	err = cs.s.customerServiceManager.close(req.ChannelId, req.Operator)
+	if err != nil {
+		if err == wkdb.ErrNotFound {
+			c.ResponseError(errors.New("客服会话不存在！"))
+			return
+		}
+		cs.Error("关闭客服会话失败！", zap.Error(err), zap.String("channelId", req.ChannelId))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+// forwardToQueueLeaderIfNeed 队列的数据由队列所在槽的领导节点处理，不是领导节点则转发请求
+func (cs *CustomerServiceAPI) forwardToQueueLeaderIfNeed(c *wkhttp.Context, queueId string, bodyBytes []byte) bool {
+	if !cs.s.opts.ClusterOn() {
+		return false
+	}
+	leaderInfo, err := cs.s.cluster.SlotLeaderOfChannel(queueId, wkproto.ChannelTypeCustomerService)
+	if err != nil {
+		cs.Error("获取队列所在节点失败！", zap.Error(err), zap.String("queueId", queueId))
+		c.ResponseError(errors.New("获取队列所在节点失败！"))
+		return true
+	}
+	if leaderInfo.Id == cs.s.opts.Cluster.NodeId {
+		return false
+	}
+	cs.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+	c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+	return true
+}
+
+type customerServiceQueueReq struct {
+	QueueId     string   `json:"queue_id"`     // 队列id
+	Name        string   `json:"name"`         // 队列名称
+	Strategy    string   `json:"strategy"`     // 分配策略（roundRobin：轮询，leastBusy：最空闲） 默认为roundRobin
+	MaxSessions uint32   `json:"max_sessions"` // 每个客服同时接待的最大会话数（0表示不限制）
+	Agents      []string `json:"agents"`       // 客服uid列表（轮询按列表顺序）
+}
+
+func (r customerServiceQueueReq) Check() error {
+	if strings.TrimSpace(r.QueueId) == "" {
+		return errors.New("queue_id不能为空！")
+	}
+	if strings.Contains(r.QueueId, "|") {
+		return errors.New("queue_id不能包含“|”！")
+	}
+	if r.Strategy != "" && r.Strategy != CustomerServiceStrategyRoundRobin && r.Strategy != CustomerServiceStrategyLeastBusy {
+		return fmt.Errorf("不支持的分配策略[%s]！", r.Strategy)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// DatasourceAPI 数据源相关api
+type DatasourceAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewDatasourceAPI NewDatasourceAPI
+func NewDatasourceAPI(s *Server) *DatasourceAPI {
+	return &DatasourceAPI{
+		s:   s,
+		Log: wklog.NewWKLog("DatasourceAPI"),
+	}
+}
+
+// Route Route
+func (d *DatasourceAPI) Route(r *wkhttp.WKHttp) {
+	r.POST("/datasource/invalidate", d.invalidate) // 清除数据源缓存（第三方数据变化时调用）
+}
+
+// 清除数据源缓存，每个节点都有自己的缓存，所以需要通知所有节点
+func (d *DatasourceAPI) invalidate(c *wkhttp.Context) {
+	var req datasourceInvalidateReq
+	if err := c.BindJSON(&req); err != nil {
+		d.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+
+	d.s.invalidateDatasource(req)
+
+	for _, node := range d.s.clusterServer.GetConfig().Nodes {
+		if node.Id == d.s.opts.Cluster.NodeId || !node.Online {
+			continue
+		}
+		if err := d.s.requestDatasourceInvalidate(node.Id, req); err != nil {
+			d.Warn("requestDatasourceInvalidate failed", zap.Uint64("nodeId", node.Id), zap.Error(err))
+		}
+	}
+	c.ResponseOK()
+}
+
+// invalidateDatasource 清除本节点的数据源缓存，并重新生成频道的接收者标签
+func (s *Server) invalidateDatasource(req datasourceInvalidateReq) {
+	invalidator, ok := s.datasource.(datasourceInvalidator)
+	if !ok {
+		return
+	}
+	if req.All {
+		invalidator.InvalidateAll()
+		return
+	}
+	invalidator.Invalidate(req.ChannelId, req.ChannelType)
+
+	channelKey := wkutil.ChannelToKey(req.ChannelId, req.ChannelType)
+	channel := s.channelReactor.reactorSub(channelKey).channel(channelKey)
+	if channel != nil {
+		if _, err := channel.makeReceiverTag(); err != nil {
+			s.Error("invalidateDatasource: makeReceiverTag failed", zap.Error(err), zap.String("channelId", req.ChannelId), zap.Uint8("channelType", req.ChannelType))
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// EventAPI 临时事件相关API（正在输入、音视频信令等）
+type EventAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewEventAPI NewEventAPI
+func NewEventAPI(s *Server) *EventAPI {
+	return &EventAPI{
+		s:   s,
+		Log: wklog.NewWKLog("EventAPI"),
+	}
+}
+
+// Route 临时事件相关路由配置
+func (e *EventAPI) Route(r *wkhttp.WKHttp) {
+	r.POST("/event/send", e.send) // 发送临时事件
+}
+
+// 发送临时事件（不存储、不更新最近会话、不重试、不触发离线webhook，只投递给在线的连接）
+func (e *EventAPI) send(c *wkhttp.Context) {
+	var req EventSendReq
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		e.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.FromUID) == "" {
+		req.FromUID = e.s.opts.SystemUID
+	}
+
+	// 统一转发到频道所在槽的领导节点处理（群频道需要在领导节点获取订阅者，频率限制也只在领导节点统计）
+	fakeChannelId := req.ChannelID
+	if req.ChannelType == wkproto.ChannelTypePerson {
+		fakeChannelId = GetFakeChannelIDWith(req.FromUID, req.ChannelID)
+	}
+	if e.s.opts.ClusterOn() {
+		leaderInfo, err := e.s.cluster.SlotLeaderOfChannel(fakeChannelId, req.ChannelType)
+		if err != nil {
+			e.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", fakeChannelId), zap.Uint8("channelType", req.ChannelType))
+			c.ResponseError(errors.New("获取频道所在节点失败！"))
+			return
+		}
+		if leaderInfo.Id != e.s.opts.Cluster.NodeId {
+			e.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+			c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+			return
+		}
+	}
+
+	if !e.s.eventManager.allow(req.FromUID, fakeChannelId, req.ChannelType) {
+		e.Debug("发送事件太频繁！", zap.String("fromUid", req.FromUID), zap.String("channelId", fakeChannelId), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseData(http.StatusTooManyRequests, map[string]interface{}{
+			"msg":    "发送事件太频繁！",
+			"status": http.StatusTooManyRequests,
+		})
+		return
+	}
+
+	uids, err := e.getReceivers(req)
+	if err != nil {
+		e.Error("获取事件接收者失败！", zap.Error(err), zap.String("channelId", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(errors.New("获取事件接收者失败！"))
+		return
+	}
+
+	err = e.s.eventManager.send(&ephemeralEvent{
+		fromUid:     req.FromUID,
+		channelId:   req.ChannelID,
+		channelType: req.ChannelType,
+		clientMsgNo: req.ClientMsgNo,
+		timestamp:   time.Now().UnixMilli(),
+		payload:     req.Payload,
+	}, uids)
+	if err != nil {
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+// 获取事件的接收者
+func (e *EventAPI) getReceivers(req EventSendReq) ([]string, error) {
+	if len(req.Subscribers) > 0 {
+		return req.Subscribers, nil
+	}
+	if req.ChannelType == wkproto.ChannelTypePerson {
+		return []string{req.ChannelID}, nil
+	}
+	members, err := e.s.store.GetSubscribers(req.ChannelID, req.ChannelType)
+	if err != nil {
+		return nil, err
+	}
+	uids := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.Uid == req.FromUID { // 不发给自己
+			continue
+		}
+		uids = append(uids, member.Uid)
+	}
+	return uids, nil
+}
+
+// EventSendReq 发送临时事件请求
+type EventSendReq struct {
+	ClientMsgNo string   `json:"client_msg_no"` // 客户端消息编号
+	FromUID     string   `json:"from_uid"`      // 发送者UID
+	ChannelID   string   `json:"channel_id"`    // 频道ID
+	ChannelType uint8    `json:"channel_type"`  // 频道类型
+	Subscribers []string `json:"subscribers"`   // 订阅者 如果此字段有值，表示事件只发给指定的订阅者
+	Payload     []byte   `json:"payload"`       // 事件内容
+}
+
+// Check 检查输入
+func (e EventSendReq) Check() error {
+	if strings.TrimSpace(e.ChannelID) == "" {
+		return errors.New("channel_id不能为空！")
+	}
+	if e.ChannelType == 0 {
+		return errors.New("channel_type不能为0！")
+	}
+	if len(e.Payload) == 0 {
+		return errors.New("payload不能为空！")
+	}
+	return nil
+}
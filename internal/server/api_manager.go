@@ -6,8 +6,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/auth"
+	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
 	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
@@ -29,6 +32,14 @@ func NewManagerAPI(s *Server) *ManagerAPI {
 func (m *ManagerAPI) Route(r *wkhttp.WKHttp) {
 
 	r.POST("/manager/login", m.login) // 登录
+
+	r.GET("/manager/log_level", m.getLogLevel)  // 获取日志级别
+	r.POST("/manager/log_level", m.setLogLevel) // 设置日志级别（运行时生效）
+	r.GET("/manager/logs", m.logs)              // 查询节点日志
+	r.POST("/manager/drain", m.drain)           // 排空节点（停止接收连接、转移领导、断开客户端后停止）
+	r.GET("/manager/drain", m.drainStatus)      // 查询节点排空状态
+	r.GET("/manager/config", m.config)          // 查询节点生效中的配置
+	r.POST("/manager/config/reload", m.reload)  // 热加载配置文件（先校验所有节点，全部通过后再应用）
 }
 
 func (m *ManagerAPI) login(c *wkhttp.Context) {
@@ -92,3 +103,179 @@ func (m *ManagerAPI) login(c *wkhttp.Context) {
 	})
 
 }
+
+func (m *ManagerAPI) getLogLevel(c *wkhttp.Context) {
+	c.JSON(http.StatusOK, newLogLevelResp(m.s.opts.Cluster.NodeId))
+}
+
+func (m *ManagerAPI) setLogLevel(c *wkhttp.Context) {
+	var req struct {
+		logLevelReq
+		NodeId uint64 `json:"node_id"` // 指定节点，为0时设置集群内所有节点
+	}
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Log.Level, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if err := req.check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+
+	nodeIds := make([]uint64, 0)
+	if req.NodeId != 0 {
+		nodeIds = append(nodeIds, req.NodeId)
+	} else {
+		nodeIds = m.s.onlineNodeIds()
+	}
+
+	results := make([]*logLevelResult, 0, len(nodeIds))
+	for _, nodeId := range nodeIds {
+		result := &logLevelResult{NodeId: nodeId}
+		var err error
+		if nodeId == m.s.opts.Cluster.NodeId {
+			err = req.apply()
+		} else {
+			err = m.s.requestSetLogLevel(nodeId, &req.logLevelReq)
+		}
+		if err != nil {
+			m.Warn("set log level failed", zap.Error(err), zap.Uint64("nodeId", nodeId))
+			result.Err = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	m.Info("log level changed", zap.String("level", req.Level), zap.String("module", req.Module), zap.Bool("remove", req.Remove), zap.Uint64("nodeId", req.NodeId))
+
+	c.JSON(http.StatusOK, results)
+}
+
+// 排空节点，node_id为0时排空当前节点
+func (m *ManagerAPI) drain(c *wkhttp.Context) {
+	var req drainReq
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Node.Drain, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	timeout, err := req.timeout()
+	if err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if req.NodeId != 0 && req.NodeId != m.s.opts.Cluster.NodeId {
+		err = m.s.requestDrain(req.NodeId, &req)
+	} else {
+		err = m.s.drainManager.drain(timeout)
+	}
+	if err != nil {
+		m.Error("drain failed", zap.Error(err), zap.Uint64("nodeId", req.NodeId))
+		c.ResponseError(err)
+		return
+	}
+	m.Info("node drain started", zap.Uint64("nodeId", req.NodeId), zap.Duration("timeout", timeout))
+	c.ResponseOK()
+}
+
+// 查询节点排空状态
+func (m *ManagerAPI) drainStatus(c *wkhttp.Context) {
+	nodeId := wkutil.ParseUint64(c.Query("node_id"))
+	if nodeId != 0 && nodeId != m.s.opts.Cluster.NodeId {
+		resp, err := m.s.requestDrainStatus(nodeId)
+		if err != nil {
+			m.Error("request drain status failed", zap.Error(err), zap.Uint64("nodeId", nodeId))
+			c.ResponseError(err)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	c.JSON(http.StatusOK, m.s.drainManager.status())
+}
+
+// 查询节点生效中的配置，node_id为0时查询集群内所有在线节点
+func (m *ManagerAPI) config(c *wkhttp.Context) {
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Config.View, auth.ActionRead) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	nodeIds := []uint64{wkutil.ParseUint64(c.Query("node_id"))}
+	if nodeIds[0] == 0 {
+		nodeIds = m.s.onlineNodeIds()
+	}
+	views := make([]*configViewResp, 0, len(nodeIds))
+	for _, nodeId := range nodeIds {
+		if nodeId == m.s.opts.Cluster.NodeId {
+			views = append(views, m.s.configManager.view())
+			continue
+		}
+		view, err := m.s.requestConfigView(nodeId)
+		if err != nil {
+			m.Warn("request config view failed", zap.Error(err), zap.Uint64("nodeId", nodeId))
+			view = &configViewResp{NodeId: nodeId, Err: err.Error()}
+		}
+		views = append(views, view)
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+// 热加载配置文件，node_id为0时热加载集群内所有在线节点
+func (m *ManagerAPI) reload(c *wkhttp.Context) {
+	var req struct {
+		NodeId uint64 `json:"node_id"`
+	}
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Config.Reload, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	results, ok := m.s.configManager.reload(req.NodeId)
+	if !ok {
+		m.Warn("reload config failed", zap.Uint64("nodeId", req.NodeId))
+		c.JSON(http.StatusBadRequest, results)
+		return
+	}
+	m.Info("config reloaded", zap.Uint64("nodeId", req.NodeId))
+	c.JSON(http.StatusOK, results)
+}
+
+func (m *ManagerAPI) logs(c *wkhttp.Context) {
+	nodeId := wkutil.ParseUint64(c.Query("node_id"))
+	req := &logQueryReq{
+		File:    c.Query("level"),
+		Module:  c.Query("module"),
+		Keyword: c.Query("keyword"),
+		Start:   wkutil.ParseInt64(c.Query("start")),
+		End:     wkutil.ParseInt64(c.Query("end")),
+		Limit:   wkutil.ParseInt(c.Query("limit")),
+	}
+
+	if nodeId != 0 && nodeId != m.s.opts.Cluster.NodeId {
+		logs, err := m.s.requestQueryLogs(nodeId, req)
+		if err != nil {
+			m.Error("request query logs failed", zap.Error(err), zap.Uint64("nodeId", nodeId))
+			c.ResponseError(err)
+			return
+		}
+		c.JSON(http.StatusOK, logs)
+		return
+	}
+
+	logs, err := wklog.QueryLogs(req.toLogQuery())
+	if err != nil {
+		m.Error("query logs failed", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
@@ -51,6 +51,10 @@ func (m *MessageAPI) Route(r *wkhttp.WKHttp) {
 
 	r.POST("/message", m.searchMessage) // 搜索单条消息
 
+	r.POST("/message/scheduled", m.scheduledMessages)                     // 查询频道的定时消息
+	r.POST("/message/scheduled/cancel", m.scheduledMessageCancel)         // 取消定时消息
+	r.POST("/message/scheduled/reschedule", m.scheduledMessageReschedule) // 重新设置定时消息的发送时间
+
 }
 
 func (m *MessageAPI) send(c *wkhttp.Context) {
@@ -64,6 +68,17 @@ func (m *MessageAPI) send(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	req.ctx = c.Request.Context()
+
+	// 将@信息写入payload，与客户端sdk发送的消息格式保持一致
+	if !req.Mention.IsEmpty() {
+		payload, err := payloadWithMention(req.Payload, req.Mention)
+		if err != nil {
+			c.ResponseError(err)
+			return
+		}
+		req.Payload = payload
+	}
 
 	if strings.TrimSpace(req.FromUID) == "" {
 		req.FromUID = m.s.opts.SystemUID
@@ -80,6 +95,10 @@ func (m *MessageAPI) send(c *wkhttp.Context) {
 	}
 
 	if strings.TrimSpace(channelId) == "" && len(req.Subscribers) > 0 {
+		if req.SendAt > 0 {
+			c.ResponseError(errors.New("定时消息必须指定channel_id！"))
+			return
+		}
 		if req.Header.SyncOnce != 1 {
 			m.Error("subscribers有值的情况下，消息必须是syncOnce消息", zap.Any("req", req))
 			c.ResponseError(errors.New("无法处理发送消息请求！"))
@@ -106,6 +125,28 @@ func (m *MessageAPI) send(c *wkhttp.Context) {
 		clientMsgNo = fmt.Sprintf("%s0", wkutil.GenUUID())
 	}
 
+	// 定时消息
+	if req.SendAt > time.Now().Unix() {
+		scheduledMessage, err := m.s.scheduledMessageManager.schedule(req, clientMsgNo)
+		if err != nil {
+			m.Error("添加定时消息失败！", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+			c.ResponseError(errors.New("添加定时消息失败！"))
+			return
+		}
+		c.ResponseOKWithData(map[string]interface{}{
+			"scheduled_id":  scheduledMessage.Id,
+			"send_at":       scheduledMessage.SendAt,
+			"client_msg_no": clientMsgNo,
+		})
+		return
+	}
+
+	// 带了clientMsgNo的消息可能是重复发送，需要等待发送结果，重复发送时返回原消息的id和seq
+	if m.s.opts.MessageDedup.On && strings.TrimSpace(req.ClientMsgNo) != "" {
+		m.sendAndWait(c, req, channelId, channelType, clientMsgNo)
+		return
+	}
+
 	// 发送消息
 	messageId, err := m.sendMessageToChannel(req, channelId, channelType, clientMsgNo, wkproto.StreamFlagIng)
 	if err != nil {
@@ -118,7 +159,47 @@ func (m *MessageAPI) send(c *wkhttp.Context) {
 	})
 }
 
+// sendAndWait 发送消息并等待发送回执
+// 超时后消息可能还未存储，也可能作为重复消息被丢弃，所以不返回提案时生成的消息id，只返回pending，客户端可用client_msg_no重试或查询
+func (m *MessageAPI) sendAndWait(c *wkhttp.Context, req MessageSendReq, channelId string, channelType uint8, clientMsgNo string) {
+	// 需要在提案之前等待，否则回执可能先于等待到达
+	sendackC, cancel := m.s.messageDedupManager.waitSendack(req.FromUID, clientMsgNo)
+	defer cancel()
+
+	_, err := m.proposeMessageToChannel(req, channelId, channelType, clientMsgNo, true)
+	if err != nil {
+		c.ResponseError(err)
+		return
+	}
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(c.Request.Context(), m.s.opts.MessageDedup.WaitTimeout)
+	defer timeoutCancel()
+	select {
+	case sendack := <-sendackC:
+		if sendack.ReasonCode != wkproto.ReasonSuccess {
+			c.ResponseError(fmt.Errorf("发送消息失败！reasonCode: %s", sendack.ReasonCode.String()))
+			return
+		}
+		c.ResponseOKWithData(map[string]interface{}{
+			"message_id":    sendack.MessageID,
+			"message_seq":   sendack.MessageSeq,
+			"client_msg_no": clientMsgNo,
+		})
+	case <-timeoutCtx.Done():
+		m.Warn("wait sendack timeout", zap.String("fromUid", req.FromUID), zap.String("channelId", channelId), zap.Uint8("channelType", channelType), zap.String("clientMsgNo", clientMsgNo))
+		c.ResponseOKWithData(map[string]interface{}{
+			"client_msg_no": clientMsgNo,
+			"pending":       1,
+		})
+	}
+}
+
 func (m *MessageAPI) sendMessageToChannel(req MessageSendReq, channelId string, channelType uint8, clientMsgNo string, streamFlag wkproto.StreamFlag) (int64, error) {
+	return m.proposeMessageToChannel(req, channelId, channelType, clientMsgNo, false)
+}
+
+// proposeMessageToChannel 将消息提案到频道，ackRequired为true时频道领导会回执发送结果（见messageDedupManager.waitSendack）
+func (m *MessageAPI) proposeMessageToChannel(req MessageSendReq, channelId string, channelType uint8, clientMsgNo string, ackRequired bool) (int64, error) {
 
 	// m.s.monitor.SendPacketInc(req.Header.NoPersist != 1)
 	// m.s.monitor.SendSystemMsgInc()
@@ -146,30 +227,38 @@ func (m *MessageAPI) sendMessageToChannel(req MessageSendReq, channelId string,
 		setting = setting.Set(wkproto.SettingStream)
 	}
 
-	ctx, span := trace.GlobalTrace.StartSpan(context.Background(), "recvMessageFromApi")
+	parentCtx := req.ctx
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+	ctx, span := trace.GlobalTrace.StartSpan(parentCtx, "recvMessageFromApi")
 	span.SetString("clientMsgNo", req.ClientMsgNo)
 
 	defer span.End()
 
 	// 将消息提交到频道
 	systemDeviceId := req.FromUID
-	messageId, err := channel.proposeSend(ctx, req.FromUID, systemDeviceId, 0, m.s.opts.Cluster.NodeId, false, &wkproto.SendPacket{
-		Framer: wkproto.Framer{
-			RedDot:    wkutil.IntToBool(req.Header.RedDot),
-			SyncOnce:  wkutil.IntToBool(req.Header.SyncOnce),
-			NoPersist: wkutil.IntToBool(req.Header.NoPersist),
+	messageId := channel.proposeMessage(ReactorChannelMessage{
+		ctx:          ctx,
+		FromUid:      req.FromUID,
+		FromDeviceId: systemDeviceId,
+		FromNodeId:   m.s.opts.Cluster.NodeId,
+		AckRequired:  ackRequired,
+		SendPacket: &wkproto.SendPacket{
+			Framer: wkproto.Framer{
+				RedDot:    wkutil.IntToBool(req.Header.RedDot),
+				SyncOnce:  wkutil.IntToBool(req.Header.SyncOnce),
+				NoPersist: wkutil.IntToBool(req.Header.NoPersist),
+			},
+			Setting:     setting,
+			Expire:      req.Expire,
+			StreamNo:    req.StreamNo,
+			ClientMsgNo: clientMsgNo,
+			ChannelID:   channelId,
+			ChannelType: channelType,
+			Payload:     req.Payload,
 		},
-		Setting:     setting,
-		Expire:      req.Expire,
-		StreamNo:    req.StreamNo,
-		ClientMsgNo: clientMsgNo,
-		ChannelID:   channelId,
-		ChannelType: channelType,
-		Payload:     req.Payload,
 	})
-	if err != nil {
-		return messageId, err
-	}
 
 	return messageId, nil
 }
@@ -180,6 +269,7 @@ func (m *MessageAPI) sendBatch(c *wkhttp.Context) {
 		FromUID     string        `json:"from_uid"`    // 发送者UID
 		Subscribers []string      `json:"subscribers"` // 订阅者 如果此字段有值，表示消息只发给指定的订阅者
 		Payload     []byte        `json:"payload"`     // 消息内容
+		SendAt      int64         `json:"send_at"`     // 定时发送时间（10位时间戳），大于当前时间则为定时消息
 	}
 	if err := c.BindJSON(&req); err != nil {
 		m.Error("数据格式有误！", zap.Error(err))
@@ -200,15 +290,24 @@ func (m *MessageAPI) sendBatch(c *wkhttp.Context) {
 	}
 	failUids := make([]string, 0)
 	reasons := make([]string, 0)
+	scheduled := req.SendAt > time.Now().Unix() // 是否是定时消息
 	for _, subscriber := range req.Subscribers {
 		clientMsgNo := fmt.Sprintf("%s0", wkutil.GenUUID())
-		_, err := m.sendMessageToChannel(MessageSendReq{
+		sendReq := MessageSendReq{
 			Header:      req.Header,
 			FromUID:     req.FromUID,
 			ChannelID:   subscriber,
 			ChannelType: wkproto.ChannelTypePerson,
 			Payload:     req.Payload,
-		}, subscriber, wkproto.ChannelTypePerson, clientMsgNo, wkproto.StreamFlagIng)
+			ctx:         c.Request.Context(),
+		}
+		var err error
+		if scheduled {
+			sendReq.SendAt = req.SendAt
+			_, err = m.s.scheduledMessageManager.schedule(sendReq, clientMsgNo)
+		} else {
+			_, err = m.sendMessageToChannel(sendReq, subscriber, wkproto.ChannelTypePerson, clientMsgNo, wkproto.StreamFlagIng)
+		}
 		if err != nil {
 			failUids = append(failUids, subscriber)
 			reasons = append(reasons, err.Error())
@@ -638,3 +737,188 @@ func (m *MessageAPI) searchMessage(c *wkhttp.Context) {
 	resp.from(messages[0], m.s)
 	c.JSON(http.StatusOK, resp)
 }
+
+// 查询频道的定时消息
+func (m *MessageAPI) scheduledMessages(c *wkhttp.Context) {
+	var req struct {
+		ChannelId   string `json:"channel_id"`
+		ChannelType uint8  `json:"channel_type"`
+		Limit       int    `json:"limit"`
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		m.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if strings.TrimSpace(req.ChannelId) == "" {
+		c.ResponseError(errors.New("channel_id不能为空！"))
+		return
+	}
+	if m.forwardToChannelSlotLeaderIfNeed(c, req.ChannelId, req.ChannelType, bodyBytes) {
+		return
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	scheduledMessages, err := m.s.store.GetScheduledMessages(req.ChannelId, req.ChannelType, limit)
+	if err != nil {
+		m.Error("查询定时消息失败！", zap.Error(err), zap.String("channelId", req.ChannelId), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(errors.New("查询定时消息失败！"))
+		return
+	}
+	resps := make([]*scheduledMessageResp, 0, len(scheduledMessages))
+	for _, scheduledMessage := range scheduledMessages {
+		resps = append(resps, newScheduledMessageResp(scheduledMessage))
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+// 取消定时消息
+func (m *MessageAPI) scheduledMessageCancel(c *wkhttp.Context) {
+	var req struct {
+		ChannelId   string `json:"channel_id"`
+		ChannelType uint8  `json:"channel_type"`
+		Id          uint64 `json:"id"` // 定时消息id
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		m.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if strings.TrimSpace(req.ChannelId) == "" || req.Id == 0 {
+		c.ResponseError(errors.New("channel_id和id不能为空！"))
+		return
+	}
+	if m.forwardToChannelSlotLeaderIfNeed(c, req.ChannelId, req.ChannelType, bodyBytes) {
+		return
+	}
+	scheduledMessage, err := m.s.store.GetScheduledMessage(req.Id)
+	if err != nil {
+		if err == wkdb.ErrNotFound {
+			c.ResponseError(errors.New("定时消息不存在！"))
+			return
+		}
+		m.Error("获取定时消息失败！", zap.Error(err), zap.Uint64("id", req.Id))
+		c.ResponseError(errors.New("获取定时消息失败！"))
+		return
+	}
+	if scheduledMessage.ChannelId != req.ChannelId || scheduledMessage.ChannelType != req.ChannelType {
+		c.ResponseError(errors.New("定时消息不存在！"))
+		return
+	}
+	err = m.s.store.RemoveScheduledMessages(req.ChannelId, req.ChannelType, []uint64{req.Id})
+	if err != nil {
+		m.Error("取消定时消息失败！", zap.Error(err), zap.Uint64("id", req.Id))
+		c.ResponseError(errors.New("取消定时消息失败！"))
+		return
+	}
+	c.ResponseOK()
+}
+
+// 重新设置定时消息的发送时间
+func (m *MessageAPI) scheduledMessageReschedule(c *wkhttp.Context) {
+	var req struct {
+		ChannelId   string `json:"channel_id"`
+		ChannelType uint8  `json:"channel_type"`
+		Id          uint64 `json:"id"`      // 定时消息id
+		SendAt      int64  `json:"send_at"` // 新的发送时间（10位时间戳）
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		m.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if strings.TrimSpace(req.ChannelId) == "" || req.Id == 0 {
+		c.ResponseError(errors.New("channel_id和id不能为空！"))
+		return
+	}
+	if req.SendAt <= time.Now().Unix() {
+		c.ResponseError(errors.New("send_at必须大于当前时间！"))
+		return
+	}
+	if m.forwardToChannelSlotLeaderIfNeed(c, req.ChannelId, req.ChannelType, bodyBytes) {
+		return
+	}
+	scheduledMessage, err := m.s.store.GetScheduledMessage(req.Id)
+	if err != nil {
+		if err == wkdb.ErrNotFound {
+			c.ResponseError(errors.New("定时消息不存在！"))
+			return
+		}
+		m.Error("获取定时消息失败！", zap.Error(err), zap.Uint64("id", req.Id))
+		c.ResponseError(errors.New("获取定时消息失败！"))
+		return
+	}
+	if scheduledMessage.ChannelId != req.ChannelId || scheduledMessage.ChannelType != req.ChannelType {
+		c.ResponseError(errors.New("定时消息不存在！"))
+		return
+	}
+	scheduledMessage.SendAt = uint64(req.SendAt)
+	// 死信重新设置发送时间后恢复
+	scheduledMessage.FailCount = 0
+	scheduledMessage.Dead = false
+	err = m.s.store.AddOrUpdateScheduledMessage(scheduledMessage)
+	if err != nil {
+		m.Error("更新定时消息失败！", zap.Error(err), zap.Uint64("id", req.Id))
+		c.ResponseError(errors.New("更新定时消息失败！"))
+		return
+	}
+	c.ResponseOK()
+}
+
+// 定时消息的数据存储在频道所在槽的节点上，如果本节点不是槽的领导则转发请求
+func (m *MessageAPI) forwardToChannelSlotLeaderIfNeed(c *wkhttp.Context, channelId string, channelType uint8, bodyBytes []byte) bool {
+	if !m.s.opts.ClusterOn() {
+		return false
+	}
+	leaderInfo, err := m.s.cluster.SlotLeaderOfChannel(channelId, channelType)
+	if err != nil {
+		m.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", channelId), zap.Uint8("channelType", channelType))
+		c.ResponseError(errors.New("获取频道所在节点失败！"))
+		return true
+	}
+	if leaderInfo.Id == m.s.opts.Cluster.NodeId {
+		return false
+	}
+	m.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+	c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+	return true
+}
+
+type scheduledMessageResp struct {
+	Id          uint64        `json:"id"`            // 定时消息id
+	ChannelId   string        `json:"channel_id"`    // 频道id
+	ChannelType uint8         `json:"channel_type"`  // 频道类型
+	FromUid     string        `json:"from_uid"`      // 发送者
+	ClientMsgNo string        `json:"client_msg_no"` // 客户端消息编号
+	Header      MessageHeader `json:"header"`        // 消息头
+	Payload     []byte        `json:"payload"`       // 消息内容
+	SendAt      uint64        `json:"send_at"`       // 发送时间（10位时间戳）
+	CreatedAt   uint64        `json:"created_at"`    // 创建时间（10位时间戳）
+	FailCount   uint32        `json:"fail_count"`    // 发送失败次数
+	Dead        int           `json:"dead"`          // 是否已进入死信 1.是 0.否（重新设置发送时间后恢复）
+}
+
+func newScheduledMessageResp(m wkdb.ScheduledMessage) *scheduledMessageResp {
+	resp := &scheduledMessageResp{
+		Id:          m.Id,
+		ChannelId:   m.ChannelId,
+		ChannelType: m.ChannelType,
+		FromUid:     m.FromUid,
+		SendAt:      m.SendAt,
+		CreatedAt:   m.CreatedAt,
+		FailCount:   m.FailCount,
+		Dead:        wkutil.BoolToInt(m.Dead),
+	}
+	var req MessageSendReq
+	if err := wkutil.ReadJSONByByte(m.Data, &req); err == nil {
+		resp.ClientMsgNo = req.ClientMsgNo
+		resp.Header = req.Header
+		resp.Payload = req.Payload
+	}
+	return resp
+}
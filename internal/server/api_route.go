@@ -2,6 +2,7 @@ package server
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
@@ -10,7 +11,7 @@ import (
 	"go.uber.org/zap"
 )
 
-// 这个主要为了模拟proxy模式。
+// RouteAPI 连接路由，为用户挑选负载最低的节点
 type RouteAPI struct {
 	s *Server
 	wklog.Log
@@ -26,17 +27,22 @@ func NewRouteAPI(s *Server) *RouteAPI {
 
 // Route Route
 func (a *RouteAPI) Route(r *wkhttp.WKHttp) {
-	r.GET("/route", a.routeUserIMAddr)               // 获取用户所在节点的连接信息
-	r.POST("/route/batch", a.routeUserIMAddrOfBatch) // 批量获取用户所在节点的连接信息
+	r.GET("/route", a.routeUserIMAddr)               // 获取用户连接节点的连接信息
+	r.POST("/route/batch", a.routeUserIMAddrOfBatch) // 批量获取用户连接节点的连接信息
 }
 
-// 路由用户的IM连接地址
+// 路由用户的IM连接地址，zone为客户端所在可用区（可选）
 func (a *RouteAPI) routeUserIMAddr(c *wkhttp.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"tcp_addr": a.s.opts.External.TCPAddr,
-		"ws_addr":  a.s.opts.External.WSAddr,
-		"wss_addr": a.s.opts.External.WSSAddr,
-	})
+	uid := c.Query("uid")
+	for _, result := range a.s.routeManager.route([]string{uid}, c.Query("zone")) {
+		c.JSON(http.StatusOK, gin.H{
+			"node_id":  result.NodeId,
+			"tcp_addr": result.TCPAddr,
+			"ws_addr":  result.WSAddr,
+			"wss_addr": result.WSSAddr,
+		})
+		return
+	}
 }
 
 // 批量获取用户所在节点地址
@@ -48,17 +54,19 @@ func (a *RouteAPI) routeUserIMAddrOfBatch(c *wkhttp.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, []userAddrResp{
-		{
-			UIDs:    uids,
-			TCPAddr: a.s.opts.External.TCPAddr,
-			WSAddr:  a.s.opts.External.WSAddr,
-			WSSAddr: a.s.opts.External.WSSAddr,
-		},
+	results := a.s.routeManager.route(uids, c.Query("zone"))
+	resps := make([]*userAddrResp, 0, len(results))
+	for _, result := range results {
+		resps = append(resps, result)
+	}
+	sort.Slice(resps, func(i, j int) bool {
+		return resps[i].NodeId < resps[j].NodeId
 	})
+	c.JSON(http.StatusOK, resps)
 }
 
 type userAddrResp struct {
+	NodeId  uint64   `json:"node_id"`
 	TCPAddr string   `json:"tcp_addr"`
 	WSAddr  string   `json:"ws_addr"`
 	WSSAddr string   `json:"wss_addr"`
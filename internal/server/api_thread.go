@@ -0,0 +1,556 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	cluster "github.com/WuKongIM/WuKongIM/pkg/cluster/clusterserver"
+	"github.com/WuKongIM/WuKongIM/pkg/network"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// ThreadAPI 子区相关API
+type ThreadAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewThreadAPI NewThreadAPI
+func NewThreadAPI(s *Server) *ThreadAPI {
+	return &ThreadAPI{
+		s:   s,
+		Log: wklog.NewWKLog("ThreadAPI"),
+	}
+}
+
+// Route 子区相关路由配置
+func (t *ThreadAPI) Route(r *wkhttp.WKHttp) {
+	r.POST("/thread/sync", t.sync)         // 同步用户参与的子区（子区会话）
+	r.POST("/thread/messages", t.messages) // 获取子区的消息
+	r.POST("/thread/list", t.list)         // 获取频道的子区列表
+	r.POST("/thread/info", t.info)         // 批量获取子区的统计数据
+}
+
+// 同步用户参与的子区（子区会话），返回子区的未读数量、最近消息和子区的统计数据
+func (t *ThreadAPI) sync(c *wkhttp.Context) {
+	var req struct {
+		UID         string `json:"uid"`
+		LastMsgSeqs string `json:"last_msg_seqs"` // 客户端所有子区的最后一条消息序列号 格式： threadChannelID:channelType:last_msg_seq|threadChannelID:channelType:last_msg_seq
+		MsgCount    int64  `json:"msg_count"`     // 每个子区返回的最近消息数量
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		t.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.UID) == "" {
+		c.ResponseError(errors.New("uid不能为空！"))
+		return
+	}
+
+	leaderInfo, err := t.s.cluster.SlotLeaderOfChannel(req.UID, wkproto.ChannelTypePerson) // 获取用户的领导节点
+	if err != nil {
+		t.Error("获取频道所在节点失败！!", zap.Error(err), zap.String("channelID", req.UID), zap.Uint8("channelType", wkproto.ChannelTypePerson))
+		c.ResponseError(errors.New("获取频道所在节点失败！"))
+		return
+	}
+	if leaderInfo.Id != t.s.opts.Cluster.NodeId {
+		t.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+		c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+		return
+	}
+
+	conversations, err := t.getThreadConversations(req.UID)
+	if err != nil {
+		t.Error("获取子区会话失败！", zap.Error(err), zap.String("uid", req.UID))
+		c.ResponseError(errors.New("获取子区会话失败！"))
+		return
+	}
+	conversations, err = t.filterByParentChannel(req.UID, conversations)
+	if err != nil {
+		t.Error("过滤子区会话失败！", zap.Error(err), zap.String("uid", req.UID))
+		c.ResponseError(errors.New("获取子区会话失败！"))
+		return
+	}
+	if len(conversations) == 0 {
+		c.JSON(http.StatusOK, []*syncThreadResp{})
+		return
+	}
+
+	var (
+		channelLastMsgMap        = NewConversationAPI(t.s).getChannelLastMsgSeqMap(req.LastMsgSeqs)
+		channelRecentMessageReqs = make([]*channelRecentMessageReq, 0, len(conversations))
+		threadReqs               = make([]*threadReq, 0, len(conversations))
+	)
+	for _, conversation := range conversations {
+		msgSeq := channelLastMsgMap[fmt.Sprintf("%s-%d", conversation.ChannelId, conversation.ChannelType)]
+		if msgSeq != 0 {
+			msgSeq = msgSeq + 1 // 如果客户端传递了messageSeq，则需要获取这个messageSeq之后的消息
+		}
+		channelRecentMessageReqs = append(channelRecentMessageReqs, &channelRecentMessageReq{
+			ChannelId:   conversation.ChannelId,
+			ChannelType: conversation.ChannelType,
+			LastMsgSeq:  msgSeq,
+		})
+		parentChannelId, rootMessageSeq, _ := t.s.opts.ThreadChannelConvertParentChannel(conversation.ChannelId)
+		threadReqs = append(threadReqs, &threadReq{
+			ChannelId:      parentChannelId,
+			ChannelType:    conversation.ChannelType,
+			RootMessageSeq: rootMessageSeq,
+		})
+	}
+
+	// 至少获取一条最近消息，用于获取会话的最后一条消息
+	msgCount := int(req.MsgCount)
+	if msgCount <= 0 {
+		msgCount = 1
+	}
+	channelRecentMessages, err := t.s.getRecentMessagesForCluster(req.UID, msgCount, channelRecentMessageReqs, true)
+	if err != nil {
+		t.Error("获取最近消息失败！", zap.Error(err), zap.String("uid", req.UID))
+		c.ResponseError(errors.New("获取最近消息失败！"))
+		return
+	}
+
+	threads, err := t.getThreads(threadReqs)
+	if err != nil {
+		t.Error("获取子区失败！", zap.Error(err), zap.String("uid", req.UID))
+		c.ResponseError(errors.New("获取子区失败！"))
+		return
+	}
+
+	resps := make([]*syncThreadResp, 0, len(conversations))
+	for _, conversation := range conversations {
+		resp := &syncThreadResp{
+			syncUserConversationResp: newSyncUserConversationResp(conversation),
+		}
+		for _, channelRecentMessage := range channelRecentMessages {
+			if conversation.ChannelId != channelRecentMessage.ChannelId || conversation.ChannelType != channelRecentMessage.ChannelType {
+				continue
+			}
+			if len(channelRecentMessage.Messages) > 0 {
+				lastMsg := channelRecentMessage.Messages[0]
+				resp.LastMsgSeq = uint32(lastMsg.MessageSeq)
+				resp.LastClientMsgNo = lastMsg.ClientMsgNo
+				resp.Timestamp = int64(lastMsg.Timestamp)
+			}
+			if req.MsgCount > 0 {
+				resp.Recents = channelRecentMessage.Messages
+			}
+			break
+		}
+
+		msgSeq := channelLastMsgMap[fmt.Sprintf("%s-%d", conversation.ChannelId, conversation.ChannelType)]
+		if msgSeq != 0 && msgSeq >= uint64(resp.LastMsgSeq) { // 客户端已经是最新的
+			continue
+		}
+
+		for _, thread := range threads {
+			if thread.ThreadChannelId == conversation.ChannelId && thread.ChannelType == conversation.ChannelType {
+				resp.Thread = thread
+				break
+			}
+		}
+		resps = append(resps, resp)
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+// 获取用户的子区会话（包含缓存中还未存储的会话）
+func (t *ThreadAPI) getThreadConversations(uid string) ([]wkdb.Conversation, error) {
+	conversations, err := t.s.store.GetLastConversations(uid, wkdb.ConversationTypeThread, 0, t.s.opts.Live().ConversationUserMaxCount)
+	if err != nil && err != wkdb.ErrNotFound {
+		return nil, err
+	}
+	cacheConversations := t.s.conversationManager.GetUserConversationFromCache(uid, wkdb.ConversationTypeThread)
+	return mergeCacheConversations(conversations, cacheConversations), nil
+}
+
+// filterByParentChannel 只保留父频道未解散并且用户仍是父频道订阅者的子区会话（与 /thread/messages 的权限一致）
+// 配置了数据源则一次批量获取所有父频道的订阅者和频道信息
+func (t *ThreadAPI) filterByParentChannel(uid string, conversations []wkdb.Conversation) ([]wkdb.Conversation, error) {
+	parentChannels := make([]wkdb.Channel, 0, len(conversations))
+	parentKeys := make(map[string]struct{}, len(conversations))
+	for _, conversation := range conversations {
+		parentChannelId, _, ok := t.s.opts.ThreadChannelConvertParentChannel(conversation.ChannelId)
+		if !ok {
+			continue
+		}
+		parentKey := wkutil.ChannelToKey(parentChannelId, conversation.ChannelType)
+		if _, ok := parentKeys[parentKey]; ok {
+			continue
+		}
+		parentKeys[parentKey] = struct{}{}
+		parentChannels = append(parentChannels, wkdb.Channel{ChannelId: parentChannelId, ChannelType: conversation.ChannelType})
+	}
+
+	allowed := make(map[string]bool, len(parentChannels))
+	if t.s.opts.HasDatasource() {
+		subscribersMap, err := t.s.datasource.GetSubscribersBatch(parentChannels)
+		if err != nil {
+			return nil, err
+		}
+		for _, parentChannel := range parentChannels {
+			parentKey := wkutil.ChannelToKey(parentChannel.ChannelId, parentChannel.ChannelType)
+			allowed[parentKey] = wkutil.ArrayContains(subscribersMap[parentKey], uid)
+		}
+	} else {
+		for _, parentChannel := range parentChannels {
+			isSubscriber, err := t.s.store.ExistSubscriber(parentChannel.ChannelId, parentChannel.ChannelType, uid)
+			if err != nil {
+				return nil, err
+			}
+			allowed[wkutil.ChannelToKey(parentChannel.ChannelId, parentChannel.ChannelType)] = isSubscriber
+		}
+	}
+
+	if t.s.opts.HasDatasource() && t.s.opts.Datasource.ChannelInfoOn {
+		channelInfos, err := t.s.datasource.GetChannelInfoBatch(parentChannels)
+		if err != nil {
+			return nil, err
+		}
+		for _, channelInfo := range channelInfos {
+			if channelInfo.Disband {
+				allowed[wkutil.ChannelToKey(channelInfo.ChannelId, channelInfo.ChannelType)] = false
+			}
+		}
+	} else {
+		for _, parentChannel := range parentChannels {
+			parentKey := wkutil.ChannelToKey(parentChannel.ChannelId, parentChannel.ChannelType)
+			if !allowed[parentKey] {
+				continue
+			}
+			channelInfo, err := t.s.store.GetChannel(parentChannel.ChannelId, parentChannel.ChannelType)
+			if err != nil && err != wkdb.ErrNotFound {
+				return nil, err
+			}
+			if channelInfo.Disband {
+				allowed[parentKey] = false
+			}
+		}
+	}
+
+	filtered := make([]wkdb.Conversation, 0, len(conversations))
+	for _, conversation := range conversations {
+		parentChannelId, _, ok := t.s.opts.ThreadChannelConvertParentChannel(conversation.ChannelId)
+		if !ok || !allowed[wkutil.ChannelToKey(parentChannelId, conversation.ChannelType)] {
+			continue
+		}
+		filtered = append(filtered, conversation)
+	}
+	return filtered, nil
+}
+
+// 获取子区的消息
+func (t *ThreadAPI) messages(c *wkhttp.Context) {
+	var req struct {
+		LoginUID        string   `json:"login_uid"`         // 当前登录用户的uid
+		ChannelID       string   `json:"channel_id"`        // 父频道id
+		ChannelType     uint8    `json:"channel_type"`      // 父频道类型
+		RootMessageSeq  uint64   `json:"root_message_seq"`  // 根消息序号
+		StartMessageSeq uint64   `json:"start_message_seq"` //开始消息列号（结果包含start_message_seq的消息）
+		EndMessageSeq   uint64   `json:"end_message_seq"`   // 结束消息列号（结果不包含end_message_seq的消息）
+		Limit           int      `json:"limit"`             // 每次同步数量限制
+		PullMode        PullMode `json:"pull_mode"`         // 拉取模式 0:向下拉取 1:向上拉取
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		t.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.ChannelID) == "" {
+		c.ResponseError(errors.New("channel_id不能为空！"))
+		return
+	}
+	if strings.TrimSpace(req.LoginUID) == "" {
+		c.ResponseError(errors.New("login_uid不能为空！"))
+		return
+	}
+	if req.RootMessageSeq == 0 {
+		c.ResponseError(errors.New("root_message_seq不能为0！"))
+		return
+	}
+	if req.ChannelType == wkproto.ChannelTypePerson {
+		c.ResponseError(errors.New("个人频道不支持子区！"))
+		return
+	}
+
+	var (
+		limit           = req.Limit
+		threadChannelId = t.s.opts.ParentConvertThreadChannel(req.ChannelID, req.RootMessageSeq)
+		messages        []wkdb.Message
+	)
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 10000 {
+		limit = 10000
+	}
+
+	if t.s.opts.ClusterOn() {
+		leaderInfo, err := t.s.cluster.LeaderOfChannelForRead(threadChannelId, req.ChannelType) // 获取子区频道的领导节点
+		if errors.Is(err, cluster.ErrChannelClusterConfigNotFound) {
+			t.Debug("子区频道从未初始化，返回空消息.", zap.String("threadChannelId", threadChannelId), zap.Uint8("channelType", req.ChannelType))
+			c.JSON(http.StatusOK, emptySyncMessageResp)
+			return
+		}
+		if err != nil {
+			t.Error("获取频道所在节点失败！", zap.Error(err), zap.String("threadChannelId", threadChannelId), zap.Uint8("channelType", req.ChannelType))
+			c.ResponseError(errors.New("获取频道所在节点失败！"))
+			return
+		}
+		if leaderInfo.Id != t.s.opts.Cluster.NodeId {
+			t.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+			c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+			return
+		}
+	}
+
+	// 只有父频道的订阅者才能获取子区的消息
+	isSubscriber, err := t.s.channelReactor.existSubscriber(req.ChannelID, req.ChannelType, req.LoginUID)
+	if err != nil {
+		t.Error("判断是否是父频道订阅者失败！", zap.Error(err), zap.String("channelId", req.ChannelID), zap.Uint8("channelType", req.ChannelType), zap.String("loginUid", req.LoginUID))
+		c.ResponseError(errors.New("判断是否是父频道订阅者失败！"))
+		return
+	}
+	if !isSubscriber {
+		c.ResponseError(errors.New("不是父频道的订阅者，无权获取子区消息！"))
+		return
+	}
+
+	if req.StartMessageSeq == 0 && req.EndMessageSeq == 0 {
+		messages, err = t.s.store.LoadLastMsgs(threadChannelId, req.ChannelType, limit)
+	} else if req.PullMode == PullModeUp { // 向上拉取
+		messages, err = t.s.store.LoadNextRangeMsgs(threadChannelId, req.ChannelType, req.StartMessageSeq, req.EndMessageSeq, limit)
+	} else {
+		messages, err = t.s.store.LoadPrevRangeMsgs(threadChannelId, req.ChannelType, req.StartMessageSeq, req.EndMessageSeq, limit)
+	}
+	if err != nil {
+		t.Error("获取子区消息失败！", zap.Error(err), zap.String("threadChannelId", threadChannelId))
+		c.ResponseError(err)
+		return
+	}
+	messageResps := make([]*MessageResp, 0, len(messages))
+	for _, message := range messages {
+		messageResp := &MessageResp{}
+		messageResp.from(message, t.s)
+		messageResps = append(messageResps, messageResp)
+	}
+	more := len(messageResps) >= limit
+	if len(messageResps) > 0 && req.EndMessageSeq != 0 {
+		messageSeq := messageResps[len(messageResps)-1].MessageSeq
+		if req.PullMode == PullModeDown {
+			messageSeq = messageResps[0].MessageSeq
+		}
+		if req.EndMessageSeq == messageSeq {
+			more = false
+		}
+	}
+	c.JSON(http.StatusOK, syncMessageResp{
+		StartMessageSeq: req.StartMessageSeq,
+		EndMessageSeq:   req.EndMessageSeq,
+		More:            wkutil.BoolToInt(more),
+		Messages:        messageResps,
+	})
+}
+
+// 获取频道的子区列表（按最后回复时间降序）
+func (t *ThreadAPI) list(c *wkhttp.Context) {
+	var req struct {
+		ChannelID   string `json:"channel_id"`
+		ChannelType uint8  `json:"channel_type"`
+		Limit       int    `json:"limit"`
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		t.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.ChannelID) == "" {
+		c.ResponseError(errors.New("channel_id不能为空！"))
+		return
+	}
+	if t.s.opts.ClusterOn() {
+		leaderInfo, err := t.s.cluster.SlotLeaderOfChannel(req.ChannelID, req.ChannelType)
+		if err != nil {
+			t.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+			c.ResponseError(errors.New("获取频道所在节点失败！"))
+			return
+		}
+		if leaderInfo.Id != t.s.opts.Cluster.NodeId {
+			t.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+			c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+			return
+		}
+	}
+	threads, err := t.s.store.GetThreads(req.ChannelID, req.ChannelType, req.Limit)
+	if err != nil {
+		t.Error("获取子区列表失败！", zap.Error(err), zap.String("channelID", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
+		c.ResponseError(errors.New("获取子区列表失败！"))
+		return
+	}
+	resps := make([]*threadResp, 0, len(threads))
+	for _, thread := range threads {
+		resps = append(resps, newThreadResp(thread, t.s.opts))
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+// 批量获取子区的统计数据
+func (t *ThreadAPI) info(c *wkhttp.Context) {
+	var reqs []*threadReq
+	if err := c.BindJSON(&reqs); err != nil {
+		t.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	resps, err := t.getThreads(reqs)
+	if err != nil {
+		t.Error("获取子区失败！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+// 获取子区的统计数据（按父频道所在槽的领导节点分组请求）
+func (t *ThreadAPI) getThreads(reqs []*threadReq) ([]*threadResp, error) {
+	if len(reqs) == 0 {
+		return []*threadResp{}, nil
+	}
+	if !t.s.opts.ClusterOn() {
+		return t.getLocalThreads(reqs)
+	}
+
+	localReqs := make([]*threadReq, 0, len(reqs))
+	peerReqsMap := make(map[uint64][]*threadReq)
+	for _, req := range reqs {
+		leaderInfo, err := t.s.cluster.SlotLeaderOfChannel(req.ChannelId, req.ChannelType)
+		if err != nil {
+			t.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", req.ChannelId), zap.Uint8("channelType", req.ChannelType))
+			return nil, errors.New("获取频道所在节点失败！")
+		}
+		if leaderInfo.Id == t.s.opts.Cluster.NodeId {
+			localReqs = append(localReqs, req)
+			continue
+		}
+		peerReqsMap[leaderInfo.Id] = append(peerReqsMap[leaderInfo.Id], req)
+	}
+
+	resps, err := t.getLocalThreads(localReqs)
+	if err != nil {
+		return nil, err
+	}
+	var respsLock sync.Mutex
+	timeoutCtx, cancel := context.WithTimeout(t.s.ctx, time.Second*5)
+	defer cancel()
+	requestGroup, _ := errgroup.WithContext(timeoutCtx)
+	for nodeId, peerReqs := range peerReqsMap {
+		nodeId, peerReqs := nodeId, peerReqs
+		requestGroup.Go(func() error {
+			results, err := t.requestThreads(nodeId, peerReqs)
+			if err != nil {
+				return err
+			}
+			respsLock.Lock()
+			resps = append(resps, results...)
+			respsLock.Unlock()
+			return nil
+		})
+	}
+	if err = requestGroup.Wait(); err != nil {
+		return nil, err
+	}
+	return resps, nil
+}
+
+func (t *ThreadAPI) requestThreads(nodeId uint64, reqs []*threadReq) ([]*threadResp, error) {
+	nodeInfo, err := t.s.cluster.NodeInfoById(nodeId)
+	if err != nil {
+		t.Error("获取节点信息失败！", zap.Error(err), zap.Uint64("nodeId", nodeId))
+		return nil, errors.New("获取节点信息失败！")
+	}
+	reqURL := fmt.Sprintf("%s/thread/info", nodeInfo.ApiServerAddr)
+	resp, err := network.Post(reqURL, []byte(wkutil.ToJSON(reqs)), nil)
+	if err != nil {
+		t.Error("获取子区失败！", zap.Error(err), zap.String("reqURL", reqURL))
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取子区请求状态错误！[%d]", resp.StatusCode)
+	}
+	var results []*threadResp
+	err = wkutil.ReadJSONByByte([]byte(resp.Body), &results)
+	if err != nil {
+		t.Error("解析子区数据失败！", zap.Error(err))
+		return nil, err
+	}
+	return results, nil
+}
+
+// 获取本节点（父频道所在槽的领导节点）上的子区统计数据，子区还没有回复的不返回
+func (t *ThreadAPI) getLocalThreads(reqs []*threadReq) ([]*threadResp, error) {
+	resps := make([]*threadResp, 0, len(reqs))
+	for _, req := range reqs {
+		thread, err := t.s.store.GetThread(req.ChannelId, req.ChannelType, req.RootMessageSeq)
+		if err != nil {
+			if err == wkdb.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		resps = append(resps, newThreadResp(thread, t.s.opts))
+	}
+	return resps, nil
+}
+
+type threadReq struct {
+	ChannelId      string `json:"channel_id"`       // 父频道id
+	ChannelType    uint8  `json:"channel_type"`     // 父频道类型
+	RootMessageSeq uint64 `json:"root_message_seq"` // 根消息序号
+}
+
+type threadResp struct {
+	ChannelId       string   `json:"channel_id"`        // 父频道id
+	ChannelType     uint8    `json:"channel_type"`      // 父频道类型
+	RootMessageSeq  uint64   `json:"root_message_seq"`  // 根消息序号
+	ThreadChannelId string   `json:"thread_channel_id"` // 子区频道id
+	ReplyCount      uint32   `json:"reply_count"`       // 回复数量
+	LastReplyUid    string   `json:"last_reply_uid"`    // 最后回复的用户
+	LastReplySeq    uint64   `json:"last_reply_seq"`    // 最后一条回复的序号
+	LastReplyAt     uint64   `json:"last_reply_at"`     // 最后回复时间
+	Participants    []string `json:"participants"`      // 最近参与回复的用户
+}
+
+func newThreadResp(thread wkdb.Thread, opts *Options) *threadResp {
+	return &threadResp{
+		ChannelId:       thread.ChannelId,
+		ChannelType:     thread.ChannelType,
+		RootMessageSeq:  thread.RootMessageSeq,
+		ThreadChannelId: opts.ParentConvertThreadChannel(thread.ChannelId, thread.RootMessageSeq),
+		ReplyCount:      thread.ReplyCount,
+		LastReplyUid:    thread.LastReplyUid,
+		LastReplySeq:    thread.LastReplySeq,
+		LastReplyAt:     thread.LastReplyAt,
+		Participants:    thread.Participants,
+	}
+}
+
+type syncThreadResp struct {
+	*syncUserConversationResp
+	Thread *threadResp `json:"thread"` // 子区的统计数据
+}
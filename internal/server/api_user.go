@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,6 +47,11 @@ func (u *UserAPI) Route(r *wkhttp.WKHttp) {
 	r.POST("/user/systemuids_add_to_cache", u.systemUidsAddToCache)           // 仅仅添加系统账号至缓存
 	r.POST("/user/systemuids_remove_from_cache", u.systemUidsRemoveFromCache) // 仅仅从缓存中移除系统账号
 
+	r.POST("/user/presence", u.getPresence)                     // 批量获取用户在线状态和最后上下线时间
+	r.POST("/user/presence/subscribe", u.presenceSubscribe)     // 订阅用户在线状态
+	r.POST("/user/presence/unsubscribe", u.presenceUnsubscribe) // 取消订阅用户在线状态
+	r.POST("/user/presence/hidden", u.presenceHidden)           // 设置是否对订阅者隐藏在线状态
+
 }
 
 // 强制设备退出
@@ -608,6 +614,207 @@ func (u *UserAPI) getSystemUids(c *wkhttp.Context) {
 	c.JSON(http.StatusOK, uids)
 }
 
+// 订阅用户在线状态（uid订阅uids的在线状态）
+func (u *UserAPI) presenceSubscribe(c *wkhttp.Context) {
+	var req presenceSubscribeReq
+	if err := c.BindJSON(&req); err != nil {
+		u.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if err := req.check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	for _, toUid := range req.UIDs {
+		if toUid == req.UID {
+			continue
+		}
+		err := u.s.store.AddPresenceSubscribers(toUid, []string{req.UID})
+		if err != nil {
+			u.Error("添加在线状态订阅者失败！", zap.Error(err), zap.String("uid", toUid), zap.String("subscriber", req.UID))
+			c.ResponseError(errors.New("添加在线状态订阅者失败！"))
+			return
+		}
+	}
+	c.ResponseOK()
+}
+
+// 取消订阅用户在线状态
+func (u *UserAPI) presenceUnsubscribe(c *wkhttp.Context) {
+	var req presenceSubscribeReq
+	if err := c.BindJSON(&req); err != nil {
+		u.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if err := req.check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	for _, toUid := range req.UIDs {
+		err := u.s.store.RemovePresenceSubscribers(toUid, []string{req.UID})
+		if err != nil {
+			u.Error("移除在线状态订阅者失败！", zap.Error(err), zap.String("uid", toUid), zap.String("subscriber", req.UID))
+			c.ResponseError(errors.New("移除在线状态订阅者失败！"))
+			return
+		}
+	}
+	c.ResponseOK()
+}
+
+// 设置是否对订阅者隐藏在线状态
+func (u *UserAPI) presenceHidden(c *wkhttp.Context) {
+	var req struct {
+		UID    string `json:"uid"`
+		Hidden int    `json:"hidden"` // 1.隐藏 0.不隐藏
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		u.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.New("数据格式有误！"))
+		return
+	}
+	if strings.TrimSpace(req.UID) == "" {
+		c.ResponseError(errors.New("uid不能为空！"))
+		return
+	}
+	if u.s.opts.ClusterOn() {
+		leaderInfo, err := u.s.cluster.SlotLeaderOfChannel(req.UID, wkproto.ChannelTypePerson) // 获取频道的领导节点
+		if err != nil {
+			u.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", req.UID), zap.Uint8("channelType", wkproto.ChannelTypePerson))
+			c.ResponseError(errors.New("获取频道所在节点失败！"))
+			return
+		}
+		leaderIsSelf := leaderInfo.Id == u.s.opts.Cluster.NodeId
+		if !leaderIsSelf {
+			u.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+			c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+			return
+		}
+	}
+
+	err = u.s.store.UpdateUserPresenceHidden(req.UID, req.Hidden == 1)
+	if err != nil {
+		u.Error("更新用户信息失败！", zap.Error(err), zap.String("uid", req.UID))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+// 批量获取用户在线状态和最后上下线时间
+func (u *UserAPI) getPresence(c *wkhttp.Context) {
+	var uids []string
+	err := c.BindJSON(&uids)
+	if err != nil {
+		u.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if len(uids) == 0 {
+		c.JSON(http.StatusOK, []*presenceResp{})
+		return
+	}
+	if !u.s.opts.ClusterOn() {
+		c.JSON(http.StatusOK, u.getLocalPresences(uids))
+		return
+	}
+
+	uidInPeerMap := make(map[uint64][]string)
+	localUids := make([]string, 0)
+	for _, uid := range uids {
+		leaderInfo, err := u.s.cluster.SlotLeaderOfChannel(uid, wkproto.ChannelTypePerson) // 获取频道的领导节点
+		if err != nil {
+			u.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", uid), zap.Uint8("channelType", wkproto.ChannelTypePerson))
+			c.ResponseError(errors.New("获取频道所在节点失败！"))
+			return
+		}
+		if leaderInfo.Id == u.s.opts.Cluster.NodeId {
+			localUids = append(localUids, uid)
+			continue
+		}
+		uidInPeerMap[leaderInfo.Id] = append(uidInPeerMap[leaderInfo.Id], uid)
+	}
+
+	var (
+		resps     = u.getLocalPresences(localUids)
+		respsLock sync.Mutex
+	)
+	timeoutCtx, cancel := context.WithTimeout(u.s.ctx, time.Second*5)
+	defer cancel()
+	requestGroup, _ := errgroup.WithContext(timeoutCtx)
+	for nodeId, uidList := range uidInPeerMap {
+		nodeId, uidList := nodeId, uidList
+		requestGroup.Go(func() error {
+			results, err := u.requestPresence(nodeId, uidList)
+			if err != nil {
+				return err
+			}
+			respsLock.Lock()
+			resps = append(resps, results...)
+			respsLock.Unlock()
+			return nil
+		})
+	}
+	if err = requestGroup.Wait(); err != nil {
+		u.Error("获取用户在线状态失败！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+func (u *UserAPI) requestPresence(nodeId uint64, uids []string) ([]*presenceResp, error) {
+	nodeInfo, err := u.s.cluster.NodeInfoById(nodeId)
+	if err != nil {
+		u.Error("获取节点信息失败！", zap.Error(err), zap.Uint64("nodeId", nodeId))
+		return nil, errors.New("获取节点信息失败！")
+	}
+	reqURL := fmt.Sprintf("%s/user/presence", nodeInfo.ApiServerAddr)
+	resp, err := network.Post(reqURL, []byte(wkutil.ToJSON(uids)), nil)
+	if err != nil {
+		u.Error("获取用户在线状态失败！", zap.Error(err), zap.String("reqURL", reqURL))
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取用户在线状态请求状态错误！[%d]", resp.StatusCode)
+	}
+	var results []*presenceResp
+	err = wkutil.ReadJSONByByte([]byte(resp.Body), &results)
+	if err != nil {
+		u.Error("解析用户在线状态失败！", zap.Error(err))
+		return nil, err
+	}
+	return results, nil
+}
+
+// 获取本节点（用户领导节点）上的用户在线状态
+func (u *UserAPI) getLocalPresences(uids []string) []*presenceResp {
+	resps := make([]*presenceResp, 0, len(uids))
+	for _, uid := range uids {
+		resp := &presenceResp{
+			UID: uid,
+		}
+		resps = append(resps, resp)
+
+		user, err := u.s.store.GetUser(uid)
+		if err != nil && err != wkdb.ErrNotFound {
+			u.Warn("获取用户信息失败！", zap.Error(err), zap.String("uid", uid))
+			continue
+		}
+		if user.PresenceHidden { // 隐藏了在线状态，一律显示为离线
+			continue
+		}
+		if u.s.userReactor.getConnContextCount(uid) > 0 {
+			resp.Online = 1
+		}
+		resp.LastOnlineAt = user.LastOnlineAt
+		resp.LastOfflineAt = user.LastOfflineAt
+	}
+	return resps
+}
+
 // UpdateTokenReq 更新token请求
 type UpdateTokenReq struct {
 	UID         string              `json:"uid"`          // 用户唯一uid
@@ -645,3 +852,25 @@ type OnlinestatusResp struct {
 	DeviceFlag uint8  `json:"device_flag"` // 设备标记 0. APP 1.web
 	Online     int    `json:"online"`      // 是否在线
 }
+
+type presenceSubscribeReq struct {
+	UID  string   `json:"uid"`  // 订阅者uid
+	UIDs []string `json:"uids"` // 被订阅的用户uid集合
+}
+
+func (p presenceSubscribeReq) check() error {
+	if strings.TrimSpace(p.UID) == "" {
+		return errors.New("uid不能为空！")
+	}
+	if len(p.UIDs) == 0 {
+		return errors.New("uids不能为空！")
+	}
+	return nil
+}
+
+type presenceResp struct {
+	UID           string `json:"uid"`             // 用户uid
+	Online        int    `json:"online"`          // 是否在线
+	LastOnlineAt  uint64 `json:"last_online_at"`  // 最后一次上线时间（10位时间戳）
+	LastOfflineAt uint64 `json:"last_offline_at"` // 最后一次离线时间（10位时间戳）
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RussellLuo/timingwheel"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterstore"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// errCDCMessagesUnavailable 暂时读取不到变更事件引用的消息（例如频道领导不可用）
+var errCDCMessagesUnavailable = errors.New("cdc messages unavailable")
+
+// cdcManager 变更数据捕获管理
+// 变更事件在槽日志应用时记录，偏移量为槽日志的下标，消费者的偏移量也通过槽复制，
+// 所以拉取和确认都以槽领导节点的数据为准，槽领导切换后消费者可以无缝续读
+type cdcManager struct {
+	s *Server
+	wklog.Log
+	timer    *timingwheel.Timer
+	cleaning atomic.Bool // 是否正在清理过期事件
+}
+
+func newCDCManager(s *Server) *cdcManager {
+	return &cdcManager{
+		s:   s,
+		Log: wklog.NewWKLog("cdcManager"),
+	}
+}
+
+func (c *cdcManager) start() {
+	if !c.s.opts.CDC.On {
+		return
+	}
+	c.timer = c.s.Schedule(c.s.opts.CDC.CleanInterval, func() {
+		if !c.cleaning.CompareAndSwap(false, true) {
+			return
+		}
+		defer c.cleaning.Store(false)
+		c.cleanExpiredEvents()
+	})
+}
+
+func (c *cdcManager) stop() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+}
+
+// appendMessages 记录频道存储成功的消息（消息序号范围，包含startMessageSeq和endMessageSeq）
+func (c *cdcManager) appendMessages(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64) error {
+	if !c.s.opts.CDC.On {
+		return nil
+	}
+	return c.s.store.AppendCDCMessages(channelId, channelType, startMessageSeq, endMessageSeq)
+}
+
+// pull 拉取变更事件，本节点是槽领导的从本地读取，其他的槽向槽领导请求
+func (c *cdcManager) pull(req *cdcPullReq) (*cdcPullResp, error) {
+	slotIds := req.SlotIds
+	if len(slotIds) == 0 {
+		slotIds = c.allSlotIds()
+	}
+
+	localSlotIds := make([]uint32, 0)
+	remoteSlotIds := make(map[uint64][]uint32)
+	for _, slotId := range slotIds {
+		leader, err := c.s.cluster.SlotLeaderNodeInfo(slotId)
+		if err != nil {
+			c.Warn("get slot leader failed", zap.Error(err), zap.Uint32("slotId", slotId))
+			continue
+		}
+		if leader.Id == c.s.opts.Cluster.NodeId {
+			localSlotIds = append(localSlotIds, slotId)
+			continue
+		}
+		remoteSlotIds[leader.Id] = append(remoteSlotIds[leader.Id], slotId)
+	}
+
+	localReq := *req
+	localReq.SlotIds = localSlotIds
+	resp, err := c.pullLocal(&localReq)
+	if err != nil {
+		return nil, err
+	}
+
+	for nodeId, nodeSlotIds := range remoteSlotIds {
+		nodeReq := *req
+		nodeReq.SlotIds = nodeSlotIds
+		nodeResp, err := c.s.requestCDCPull(nodeId, &nodeReq)
+		if err != nil { // 这些槽本次不返回游标，消费者下次会从原来的位置继续拉取
+			c.Warn("request cdc pull failed", zap.Error(err), zap.Uint64("nodeId", nodeId), zap.Uint32s("slotIds", nodeSlotIds))
+			continue
+		}
+		resp.Events = append(resp.Events, nodeResp.Events...)
+		for slotId, cursor := range nodeResp.Cursors {
+			resp.Cursors[slotId] = cursor
+		}
+	}
+	return resp, nil
+}
+
+// pullLocal 从本地拉取指定槽的变更事件
+func (c *cdcManager) pullLocal(req *cdcPullReq) (*cdcPullResp, error) {
+	limit := req.Limit
+	if limit <= 0 || limit > c.s.opts.CDC.PullLimit {
+		limit = c.s.opts.CDC.PullLimit
+	}
+	resp := &cdcPullResp{
+		Events:  make([]*cdcEventResp, 0),
+		Cursors: make(map[uint32]uint64, len(req.SlotIds)),
+	}
+	for _, slotId := range req.SlotIds {
+		cursor, ok := req.Cursors[slotId]
+		if !ok {
+			committed, err := c.s.store.GetCDCConsumerOffset(req.Consumer, slotId)
+			if err != nil {
+				return nil, err
+			}
+			cursor = committed
+		}
+		events, err := c.s.store.GetCDCEvents(slotId, cursor+1, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			if req.hasCategory(clusterstore.CMDType(event.Type).CDCCategory()) {
+				eventResp, err := c.toEventResp(event)
+				if errors.Is(err, errCDCMessagesUnavailable) { // 暂时读取不到消息，这个槽停在这里，下次拉取重试
+					c.Warn("load cdc messages failed", zap.Error(err), zap.Uint32("slotId", slotId), zap.Uint64("offset", event.Offset))
+					break
+				}
+				if err != nil {
+					c.Error("decode cdc event failed", zap.Error(err), zap.Uint32("slotId", slotId), zap.Uint64("offset", event.Offset))
+				} else {
+					resp.Events = append(resp.Events, eventResp)
+				}
+			}
+			cursor = event.Offset
+		}
+		resp.Cursors[slotId] = cursor
+	}
+	return resp, nil
+}
+
+// commit 确认消费者在各个槽上已消费完成的偏移量
+func (c *cdcManager) commit(req cdcCommitReq) error {
+	for slotId, offset := range req.Offsets {
+		if err := c.s.store.SetCDCConsumerOffset(req.Consumer, slotId, offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeConsumer 移除消费者在所有槽上的偏移量
+func (c *cdcManager) removeConsumer(consumer string) error {
+	for _, slotId := range c.allSlotIds() {
+		if err := c.s.store.RemoveCDCConsumer(consumer, slotId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cdcManager) toEventResp(event wkdb.CDCEvent) (*cdcEventResp, error) {
+	cmd := clusterstore.NewCMD(clusterstore.CMDType(event.Type), event.Data)
+	resp := &cdcEventResp{
+		SlotId:    event.SlotId,
+		Offset:    event.Offset,
+		Category:  string(cmd.CmdType.CDCCategory()),
+		Type:      cmd.CmdType.CDCEventType(),
+		Timestamp: event.CreatedAt / int64(time.Millisecond),
+	}
+	if cmd.CmdType == clusterstore.CMDAppendCDCMessages { // 消息使用和webhook一致的格式
+		channelId, channelType, startMessageSeq, endMessageSeq, err := cmd.DecodeCMDAppendCDCMessages()
+		if err != nil {
+			return nil, err
+		}
+		data, err := c.loadMessages(channelId, channelType, startMessageSeq, endMessageSeq)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errCDCMessagesUnavailable, err)
+		}
+		resp.Data = data
+		return resp, nil
+	}
+	content, err := cmd.CMDContent()
+	if err != nil {
+		return nil, err
+	}
+	if content == "" {
+		content = "null"
+	}
+	resp.Data = json.RawMessage(content)
+	return resp, nil
+}
+
+// loadMessages 从频道日志读取变更事件引用的消息，本节点不可读取时向频道的领导（开启追随者读时为副本）请求
+func (c *cdcManager) loadMessages(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64) ([]byte, error) {
+	if c.s.opts.ClusterOn() {
+		nodeInfo, err := c.s.channelNodeForRead(channelId, channelType, endMessageSeq)
+		if err != nil {
+			return nil, err
+		}
+		if nodeInfo.Id != c.s.opts.Cluster.NodeId {
+			return c.s.requestCDCMessages(nodeInfo.Id, &cdcMessagesReq{
+				ChannelId:       channelId,
+				ChannelType:     channelType,
+				StartMessageSeq: startMessageSeq,
+				EndMessageSeq:   endMessageSeq,
+			})
+		}
+	}
+	return c.loadLocalMessages(channelId, channelType, startMessageSeq, endMessageSeq)
+}
+
+// loadLocalMessages 从本地的频道日志读取消息（消息可能已被删除，读取不到的不返回）
+func (c *cdcManager) loadLocalMessages(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64) ([]byte, error) {
+	messages, err := c.s.store.LoadNextRangeMsgs(channelId, channelType, startMessageSeq, endMessageSeq+1, int(endMessageSeq-startMessageSeq+1))
+	if err != nil && err != wkdb.ErrNotFound {
+		return nil, err
+	}
+	messageResps := make([]*MessageResp, 0, len(messages))
+	for _, message := range messages {
+		messageResp := &MessageResp{}
+		messageResp.from(message, c.s)
+		messageResps = append(messageResps, messageResp)
+	}
+	return json.Marshal(messageResps)
+}
+
+// cleanExpiredEvents 清理本节点上超过保留时长的变更事件
+func (c *cdcManager) cleanExpiredEvents() {
+	expireAt := time.Now().Add(-c.s.opts.CDC.Retention).UnixNano()
+	for _, slotId := range c.allSlotIds() {
+		for {
+			count, err := c.s.store.RemoveExpiredCDCEvents(slotId, expireAt, 1000)
+			if err != nil {
+				c.Error("remove expired cdc events failed", zap.Error(err), zap.Uint32("slotId", slotId))
+				break
+			}
+			if count < 1000 {
+				break
+			}
+		}
+	}
+}
+
+func (c *cdcManager) allSlotIds() []uint32 {
+	slotIds := make([]uint32, 0, c.s.opts.Cluster.SlotCount)
+	for i := 0; i < c.s.opts.Cluster.SlotCount; i++ {
+		slotIds = append(slotIds, uint32(i))
+	}
+	return slotIds
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterstore"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/icluster"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCDCPullReqCategories(t *testing.T) {
+	req := cdcPullReq{Consumer: "search"}
+	assert.NoError(t, req.Check())
+	assert.True(t, req.hasCategory(clusterstore.CDCCategoryMessage))
+	assert.True(t, req.hasCategory(clusterstore.CDCCategoryUser))
+
+	req.Categories = []string{"message", "conversation"}
+	assert.NoError(t, req.Check())
+	assert.True(t, req.hasCategory(clusterstore.CDCCategoryMessage))
+	assert.False(t, req.hasCategory(clusterstore.CDCCategoryChannel))
+
+	req.Categories = []string{"unknown"}
+	assert.Error(t, req.Check())
+
+	req = cdcPullReq{}
+	assert.Error(t, req.Check())
+}
+
+func TestCDCCategoryOfCMD(t *testing.T) {
+	assert.Equal(t, clusterstore.CDCCategoryMessage, clusterstore.CMDAppendCDCMessages.CDCCategory())
+	assert.Equal(t, clusterstore.CDCCategoryConversation, clusterstore.CMDSetConversationAttrs.CDCCategory())
+	assert.Equal(t, clusterstore.CDCCategoryChannel, clusterstore.CMDAddSubscribers.CDCCategory())
+	assert.Equal(t, clusterstore.CDCCategoryUser, clusterstore.CMDUpdateDevice.CDCCategory())
+	assert.Equal(t, clusterstore.CDCCategoryNone, clusterstore.CMDSetCDCConsumerOffset.CDCCategory())
+	assert.Equal(t, "subscriber.add", clusterstore.CMDAddSubscribers.CDCEventType())
+}
+
+// testCDCCluster 模拟频道领导节点
+type testCDCCluster struct {
+	icluster.Cluster
+	leader *pb.Node
+	err    error
+}
+
+func (t *testCDCCluster) LeaderOfChannelForRead(channelId string, channelType uint8) (*pb.Node, error) {
+	return t.leader, t.err
+}
+
+func TestCDCPullMessagesFromChannelLog(t *testing.T) {
+	s := NewTestServer(t)
+	err := s.store.Open()
+	assert.NoError(t, err)
+	defer s.store.Close()
+
+	messages := make([]wkdb.Message, 0, 3)
+	for i := 1; i <= 3; i++ {
+		messages = append(messages, wkdb.Message{RecvPacket: wkproto.RecvPacket{MessageID: int64(i), MessageSeq: uint32(i), FromUID: "u1", ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup}})
+	}
+	err = s.store.DB().AppendMessages("g1", wkproto.ChannelTypeGroup, messages)
+	assert.NoError(t, err)
+
+	// 事件只记录消息序号范围
+	err = s.store.DB().AppendCDCEvents([]wkdb.CDCEvent{
+		{SlotId: 1, Offset: 1, Type: clusterstore.CMDAppendCDCMessages.Uint16(), Data: clusterstore.EncodeCMDAppendCDCMessages("g1", wkproto.ChannelTypeGroup, 2, 3)},
+	})
+	assert.NoError(t, err)
+
+	s.opts.Cluster.FollowerRead = false
+	cluster := &testCDCCluster{Cluster: s.cluster, leader: &pb.Node{Id: s.opts.Cluster.NodeId}}
+	s.cluster = cluster
+
+	req := &cdcPullReq{Consumer: "search", SlotIds: []uint32{1}, Cursors: map[uint32]uint64{1: 0}}
+	resp, err := s.cdcManager.pullLocal(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp.Events))
+	assert.Equal(t, uint64(1), resp.Cursors[1])
+	var messageResps []*MessageResp
+	err = json.Unmarshal(resp.Events[0].Data, &messageResps)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(messageResps))
+	assert.Equal(t, uint64(2), messageResps[0].MessageSeq)
+	assert.Equal(t, uint64(3), messageResps[1].MessageSeq)
+
+	// 读取不到消息时不跳过事件，下次拉取重试
+	cluster.err = errors.New("leader not found")
+	resp, err = s.cdcManager.pullLocal(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(resp.Events))
+	assert.Equal(t, uint64(0), resp.Cursors[1])
+}
@@ -47,6 +47,8 @@ type channel struct {
 
 	receiverTagKey atomic.String // 当前频道的接受者的tag key
 
+	threadRootVerified atomic.Bool // 子区频道的根消息已验证存在于父频道
+
 	wklog.Log
 
 	stepFnc func(*ChannelAction) error
@@ -341,21 +343,24 @@ func (c *channel) tickProxy() {
 }
 
 func (c *channel) proposeSend(ctx context.Context, fromUid string, fromDeviceId string, fromConnId int64, fromNodeId uint64, isEncrypt bool, sendPacket *wkproto.SendPacket) (int64, error) {
-
-	c.sendTick = 0
-
-	messageId := c.r.messageIDGen.Generate().Int64() // 生成唯一消息ID
-	message := ReactorChannelMessage{
+	return c.proposeMessage(ReactorChannelMessage{
 		ctx:          ctx,
 		FromConnId:   fromConnId,
 		FromUid:      fromUid,
 		FromDeviceId: fromDeviceId,
 		FromNodeId:   fromNodeId,
 		SendPacket:   sendPacket,
-		MessageId:    messageId,
 		IsEncrypt:    isEncrypt,
-		ReasonCode:   wkproto.ReasonSuccess, // 初始状态为成功
-	}
+	}), nil
+}
+
+// proposeMessage 提案频道消息，生成消息id并返回
+func (c *channel) proposeMessage(message ReactorChannelMessage) int64 {
+
+	c.sendTick = 0
+
+	message.MessageId = c.r.messageIDGen.Generate().Int64() // 生成唯一消息ID
+	message.ReasonCode = wkproto.ReasonSuccess              // 初始状态为成功
 
 	c.sub.step(c, &ChannelAction{
 		UniqueNo:   c.uniqueNo,
@@ -363,7 +368,7 @@ func (c *channel) proposeSend(ctx context.Context, fromUid string, fromDeviceId
 		Messages:   []ReactorChannelMessage{message},
 	})
 
-	return messageId, nil
+	return message.MessageId
 }
 
 func (c *channel) becomeLeader() {
@@ -467,12 +472,30 @@ func (c *channel) makeReceiverTag() (*tag, error) {
 		if c.r.s.opts.IsCmdChannel(c.channelId) {
 			realChannelId = c.r.opts.CmdChannelConvertOrginalChannel(c.channelId)
 		}
-		members, err := c.r.s.store.GetSubscribers(realChannelId, c.channelType)
-		if err != nil {
-			return nil, err
+		// 子区的消息投递给父频道的订阅者（只有参与过子区的用户才会生成子区会话）
+		if parentChannelId, _, ok := c.r.opts.ThreadChannelConvertParentChannel(realChannelId); ok {
+			realChannelId = parentChannelId
+		}
+		if c.r.s.opts.HasDatasource() { // 订阅者从数据源获取
+			uids, err := c.r.s.datasource.GetSubscribers(realChannelId, c.channelType)
+			if err != nil {
+				return nil, err
+			}
+			subscribers = append(subscribers, uids...)
+		} else {
+			members, err := c.r.s.store.GetSubscribers(realChannelId, c.channelType)
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				subscribers = append(subscribers, member.Uid)
+			}
 		}
-		for _, member := range members {
-			subscribers = append(subscribers, member.Uid)
+		// 客服频道的访客始终是接收者，接待的客服是订阅者
+		if c.channelType == wkproto.ChannelTypeCustomerService {
+			if visitorUid, ok := c.r.opts.GetCustomerServiceVisitorUID(realChannelId); ok && !wkutil.ArrayContains(subscribers, visitorUid) {
+				subscribers = append(subscribers, visitorUid)
+			}
 		}
 	}
 
@@ -9,6 +9,7 @@ import (
 	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	wkproto "github.com/WuKongIM/WuKongIMGoProto"
 	"go.uber.org/zap"
 )
@@ -218,13 +219,17 @@ func (r *channelReactor) processForward(reqs []*forwardReq) {
 			}
 
 			spans := make([]trace.Span, 0, len(req.messages))
-			for _, msg := range req.messages {
-				_, span := trace.GlobalTrace.StartSpan(msg.ctx, "processForward")
+			forwardCtx := r.s.ctx // 转发请求的链路跟随第一条消息（每条消息的链路在消息数据中传递）
+			for i, msg := range req.messages {
+				spanCtx, span := trace.GlobalTrace.StartSpan(msg.ctx, "processForward")
 				span.SetUint64("leaderId", req.leaderId)
+				if i == 0 {
+					forwardCtx = trace.ContextWithSpanOf(r.s.ctx, spanCtx)
+				}
 				spans = append(spans, span)
 			}
 
-			newLeaderId, err = r.handleForward(req)
+			newLeaderId, err = r.handleForward(forwardCtx, req)
 			if err != nil {
 				r.Warn("handleForward error", zap.Error(err))
 			}
@@ -263,7 +268,7 @@ func (r *channelReactor) processForward(reqs []*forwardReq) {
 
 }
 
-func (r *channelReactor) handleForward(req *forwardReq) (uint64, error) {
+func (r *channelReactor) handleForward(ctx context.Context, req *forwardReq) (uint64, error) {
 	if len(req.messages) == 0 {
 		return 0, nil
 	}
@@ -273,7 +278,7 @@ func (r *channelReactor) handleForward(req *forwardReq) (uint64, error) {
 		return 0, errors.New("leaderId is 0")
 	}
 
-	needChangeLeader, err := r.requestChannelFoward(req.leaderId, ChannelFowardReq{
+	needChangeLeader, err := r.requestChannelFoward(ctx, req.leaderId, ChannelFowardReq{
 		ChannelId:   req.ch.channelId,
 		ChannelType: req.ch.channelType,
 		Messages:    req.messages,
@@ -297,8 +302,8 @@ func (r *channelReactor) handleForward(req *forwardReq) (uint64, error) {
 	return 0, nil
 }
 
-func (r *channelReactor) requestChannelFoward(nodeId uint64, req ChannelFowardReq) (bool, error) {
-	timeoutCtx, cancel := context.WithTimeout(r.s.ctx, time.Second*5)
+func (r *channelReactor) requestChannelFoward(ctx context.Context, nodeId uint64, req ChannelFowardReq) (bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
 	data, err := req.Marshal()
@@ -412,6 +417,11 @@ func (r *channelReactor) processPermission(req *permissionReq) {
 
 func (r *channelReactor) hasPermission(channelId string, channelType uint8, fromUid string, ch *channel) (wkproto.ReasonCode, error) {
 
+	// 子区频道继承父频道的权限
+	if parentChannelId, rootMessageSeq, ok := r.opts.ThreadChannelConvertParentChannel(r.opts.CmdChannelConvertOrginalChannel(channelId)); ok {
+		return r.hasThreadPermission(parentChannelId, rootMessageSeq, channelType, fromUid, ch)
+	}
+
 	if channelType == wkproto.ChannelTypeInfo { // 资讯频道是公开的，直接通过
 		return wkproto.ReasonSuccess, nil
 	}
@@ -446,6 +456,14 @@ func (r *channelReactor) hasPermission(channelId string, channelType uint8, from
 	}
 
 	channelInfo := ch.info
+	if r.opts.HasDatasource() && r.opts.Datasource.ChannelInfoOn { // 频道信息从数据源获取
+		var err error
+		channelInfo, err = r.s.datasource.GetChannelInfo(channelId, channelType)
+		if err != nil {
+			r.Error("datasource GetChannelInfo error", zap.Error(err))
+			return wkproto.ReasonSystemError, err
+		}
+	}
 
 	if channelInfo.Ban { // 频道被封禁
 		return wkproto.ReasonBan, nil
@@ -462,7 +480,7 @@ func (r *channelReactor) hasPermission(channelId string, channelType uint8, from
 	}
 
 	// 判断是否是黑名单内
-	isDenylist, err := r.s.store.ExistDenylist(realChannelId, channelType, fromUid)
+	isDenylist, err := r.existDenylist(realChannelId, channelType, fromUid)
 	if err != nil {
 		r.Error("ExistDenylist error", zap.Error(err))
 		return wkproto.ReasonSystemError, err
@@ -471,26 +489,28 @@ func (r *channelReactor) hasPermission(channelId string, channelType uint8, from
 		return wkproto.ReasonInBlacklist, nil
 	}
 
-	// 判断是否是订阅者
-	isSubscriber, err := r.s.store.ExistSubscriber(realChannelId, channelType, fromUid)
-	if err != nil {
-		r.Error("ExistSubscriber error", zap.Error(err))
-		return wkproto.ReasonSystemError, err
-	}
-	if !isSubscriber {
-		return wkproto.ReasonSubscriberNotExist, nil
+	// 判断是否是订阅者（客服频道的访客不需要是订阅者）
+	if !r.isCustomerServiceVisitor(realChannelId, channelType, fromUid) {
+		isSubscriber, err := r.existSubscriber(realChannelId, channelType, fromUid)
+		if err != nil {
+			r.Error("ExistSubscriber error", zap.Error(err))
+			return wkproto.ReasonSystemError, err
+		}
+		if !isSubscriber {
+			return wkproto.ReasonSubscriberNotExist, nil
+		}
 	}
 
 	// 判断是否在白名单内
 	if !r.opts.WhitelistOffOfPerson || channelType != wkproto.ChannelTypePerson { // 如果不是个人频道或者个人频道白名单开关打开，则判断是否在白名单内
-		hasAllowlist, err := r.s.store.HasAllowlist(realChannelId, channelType)
+		hasAllowlist, err := r.hasAllowlist(realChannelId, channelType)
 		if err != nil {
 			r.Error("HasAllowlist error", zap.Error(err))
 			return wkproto.ReasonSystemError, err
 		}
 
 		if hasAllowlist { // 如果频道有白名单，则判断是否在白名单内
-			isAllowlist, err := r.s.store.ExistAllowlist(realChannelId, channelType, fromUid)
+			isAllowlist, err := r.existAllowlist(realChannelId, channelType, fromUid)
 			if err != nil {
 				r.Error("ExistAllowlist error", zap.Error(err))
 				return wkproto.ReasonSystemError, err
@@ -504,6 +524,100 @@ func (r *channelReactor) hasPermission(channelId string, channelType uint8, from
 	return wkproto.ReasonSuccess, nil
 }
 
+// isCustomerServiceVisitor 是否是客服频道的访客
+func (r *channelReactor) isCustomerServiceVisitor(channelId string, channelType uint8, uid string) bool {
+	if channelType != wkproto.ChannelTypeCustomerService {
+		return false
+	}
+	visitorUid, ok := r.opts.GetCustomerServiceVisitorUID(channelId)
+	return ok && visitorUid == uid
+}
+
+// existDenylist 是否在黑名单内（配置了数据源则从数据源获取）
+func (r *channelReactor) existDenylist(channelId string, channelType uint8, uid string) (bool, error) {
+	if r.opts.HasDatasource() {
+		uids, err := r.s.datasource.GetBlacklist(channelId, channelType)
+		if err != nil {
+			return false, err
+		}
+		return wkutil.ArrayContains(uids, uid), nil
+	}
+	return r.s.store.ExistDenylist(channelId, channelType, uid)
+}
+
+// existSubscriber 是否是订阅者（配置了数据源则从数据源获取）
+func (r *channelReactor) existSubscriber(channelId string, channelType uint8, uid string) (bool, error) {
+	if r.opts.HasDatasource() {
+		uids, err := r.s.datasource.GetSubscribers(channelId, channelType)
+		if err != nil {
+			return false, err
+		}
+		return wkutil.ArrayContains(uids, uid), nil
+	}
+	return r.s.store.ExistSubscriber(channelId, channelType, uid)
+}
+
+// hasAllowlist 频道是否有白名单（配置了数据源则从数据源获取）
+func (r *channelReactor) hasAllowlist(channelId string, channelType uint8) (bool, error) {
+	if r.opts.HasDatasource() {
+		uids, err := r.s.datasource.GetWhitelist(channelId, channelType)
+		if err != nil {
+			return false, err
+		}
+		return len(uids) > 0, nil
+	}
+	return r.s.store.HasAllowlist(channelId, channelType)
+}
+
+// existAllowlist 是否在白名单内（配置了数据源则从数据源获取）
+func (r *channelReactor) existAllowlist(channelId string, channelType uint8, uid string) (bool, error) {
+	if r.opts.HasDatasource() {
+		uids, err := r.s.datasource.GetWhitelist(channelId, channelType)
+		if err != nil {
+			return false, err
+		}
+		return wkutil.ArrayContains(uids, uid), nil
+	}
+	return r.s.store.ExistAllowlist(channelId, channelType, uid)
+}
+
+// 子区的权限判断（子区频道使用父频道的黑名单、白名单和订阅者）
+func (r *channelReactor) hasThreadPermission(parentChannelId string, rootMessageSeq uint64, channelType uint8, fromUid string, ch *channel) (wkproto.ReasonCode, error) {
+	if channelType == wkproto.ChannelTypePerson { // 个人频道暂不支持子区
+		return wkproto.ReasonNotSupportChannelType, nil
+	}
+	if r.opts.IsThreadChannel(parentChannelId) { // 根消息是子区的回复，不支持子区嵌套
+		return wkproto.ReasonNotSupportChannelType, nil
+	}
+
+	// 根消息必须是父频道内已存在的消息（根消息序号不变，验证通过后不再重复验证）
+	if !ch.threadRootVerified.Load() {
+		lastMsgSeq, err := r.s.channelLastMessageSeq(parentChannelId, channelType)
+		if err != nil {
+			r.Error("get parent channel last message seq error", zap.Error(err), zap.String("parentChannelId", parentChannelId), zap.Uint8("channelType", channelType))
+			return wkproto.ReasonSystemError, err
+		}
+		if rootMessageSeq == 0 || rootMessageSeq > lastMsgSeq {
+			r.Warn("thread root message not exist", zap.String("parentChannelId", parentChannelId), zap.Uint64("rootMessageSeq", rootMessageSeq), zap.Uint64("lastMsgSeq", lastMsgSeq))
+			return wkproto.ReasonChannelNotExist, nil
+		}
+		ch.threadRootVerified.Store(true)
+	}
+
+	parentChannelInfo, err := r.s.store.GetChannel(parentChannelId, channelType)
+	if err != nil && err != wkdb.ErrNotFound {
+		r.Error("GetChannel error", zap.Error(err), zap.String("parentChannelId", parentChannelId))
+		return wkproto.ReasonSystemError, err
+	}
+	if parentChannelInfo.Ban { // 父频道被封禁
+		return wkproto.ReasonBan, nil
+	}
+	if parentChannelInfo.Disband { // 父频道已解散
+		return wkproto.ReasonDisband, nil
+	}
+	return r.hasPermission(parentChannelId, channelType, fromUid, ch)
+}
+
 func (r *channelReactor) requestAllowSend(from, to string) (wkproto.ReasonCode, error) {
 
 	leaderNode, err := r.s.cluster.SlotLeaderOfChannel(to, wkproto.ChannelTypePerson)
@@ -625,14 +739,20 @@ func (r *channelReactor) processStorage(reqs []*storageReq) {
 		messages := make([]wkdb.Message, 0, len(req.messages))
 		sotreMessages := make([]wkdb.Message, 0, len(messages))
 		spans := make([]trace.Span, 0, len(messages))
+		storeCtx := r.s.ctx // 批量存储时提案的链路跟随第一条消息
+		// 重复发送的消息（客户端超时重试、业务端重复调用）不再存储，发送回执返回原消息的id和seq
+		batchDuplicates := r.s.messageDedupManager.markDuplicates(req.ch.channelId, req.ch.channelType, req.messages)
 		// 将reactorChannelMessage转换为wkdb.Message
-		for _, reactorMsg := range req.messages {
+		for i, reactorMsg := range req.messages {
 
 			if reactorMsg.ReasonCode != wkproto.ReasonSuccess {
 				r.Debug("msg reasonCode is not success, no storage", zap.Uint64("messageId", uint64(reactorMsg.MessageId)), zap.String("channelId", req.ch.channelId), zap.Uint8("channelType", req.ch.channelType))
 				continue
 
 			}
+			if reactorMsg.DuplicateOf != 0 {
+				continue
+			}
 
 			msg := wkdb.Message{
 				RecvPacket: wkproto.RecvPacket{
@@ -664,9 +784,17 @@ func (r *channelReactor) processStorage(reqs []*storageReq) {
 			if msg.NoPersist { // 不需要存储，跳过
 				continue
 			}
+
+			// 记录消息的@信息，用于维护被@用户的最近会话
+			if !msg.SyncOnce {
+				req.messages[i].Mention = parseMessageMention(msg.Payload)
+			}
 			sotreMessages = append(sotreMessages, msg)
 
-			_, span := trace.GlobalTrace.StartSpan(reactorMsg.ctx, "storeMessages")
+			spanCtx, span := trace.GlobalTrace.StartSpan(reactorMsg.ctx, "storeMessages")
+			if len(spans) == 0 {
+				storeCtx = trace.ContextWithSpanOf(r.s.ctx, spanCtx)
+			}
 			spans = append(spans, span)
 		}
 
@@ -678,7 +806,7 @@ func (r *channelReactor) processStorage(reqs []*storageReq) {
 			} else {
 				r.Debug("store messages", zap.Int("msgCount", len(sotreMessages)), zap.String("channelId", req.ch.channelId), zap.Uint8("channelType", req.ch.channelType))
 			}
-			results, err := r.s.store.AppendMessages(r.s.ctx, req.ch.channelId, req.ch.channelType, sotreMessages)
+			results, err := r.s.store.AppendMessages(storeCtx, req.ch.channelId, req.ch.channelType, sotreMessages)
 			if err != nil {
 				r.Error("AppendMessages error", zap.Error(err))
 			}
@@ -714,6 +842,20 @@ func (r *channelReactor) processStorage(reqs []*storageReq) {
 			}
 		}
 
+		if reason == ReasonSuccess {
+			r.s.messageDedupManager.fillBatchDuplicates(req.messages, batchDuplicates)
+		}
+
+		// 子区频道的消息存储成功后，更新子区的统计数据
+		if reason == ReasonSuccess && len(sotreMessages) > 0 && r.opts.IsThreadChannel(req.ch.channelId) {
+			r.s.threadManager.addReplies(req.ch.channelId, req.ch.channelType, req.messages)
+		}
+
+		// 客服频道的访客消息存储成功后，在客服队列中打开会话
+		if reason == ReasonSuccess && len(sotreMessages) > 0 && req.ch.channelType == wkproto.ChannelTypeCustomerService {
+			r.s.customerServiceManager.onVisitorMessages(req.ch.channelId, req.messages)
+		}
+
 		if r.opts.WebhookOn() {
 			// 赋值messageeq
 			for i, msg := range messages {
@@ -750,6 +892,28 @@ func (r *channelReactor) processStorage(reqs []*storageReq) {
 				span.End()
 			}
 		}
+		// 记录消息变更事件，供 /cdc/stream 的消费者消费
+		// 消息已经存储成功，记录失败只打印日志，不能影响发送结果（否则发送者重试会产生重复消息）
+		if r.opts.CDC.On && reason == ReasonSuccess && len(sotreMessages) > 0 {
+			var startMessageSeq, endMessageSeq uint64
+			for _, msg := range sotreMessages {
+				for _, reactorMsg := range req.messages {
+					if msg.MessageID == reactorMsg.MessageId {
+						seq := uint64(reactorMsg.MessageSeq)
+						if startMessageSeq == 0 || seq < startMessageSeq {
+							startMessageSeq = seq
+						}
+						if seq > endMessageSeq {
+							endMessageSeq = seq
+						}
+						break
+					}
+				}
+			}
+			if err := r.s.cdcManager.appendMessages(req.ch.channelId, req.ch.channelType, startMessageSeq, endMessageSeq); err != nil {
+				r.Error("append cdc messages error", zap.Error(err), zap.String("channelId", req.ch.channelId), zap.Uint8("channelType", req.ch.channelType), zap.Uint64("startMessageSeq", startMessageSeq), zap.Uint64("endMessageSeq", endMessageSeq))
+			}
+		}
 		// 返回存储结果
 		r.respStoreResult(req, reason)
 
@@ -813,30 +977,40 @@ func (r *channelReactor) processSendackLoop() {
 func (r *channelReactor) processSendack(reqs []*sendackReq) {
 	var err error
 	nodeFowardSendackPacketMap := map[uint64][]*ForwardSendackPacket{}
+	nodeFowardSendackCtxMap := map[uint64]context.Context{} // 转发请求的链路跟随第一条消息
 	for _, req := range reqs {
 		for _, msg := range req.messages {
 
-			if msg.FromUid == r.opts.SystemUID { // 如果是系统消息，不需要发送ack
+			if msg.FromUid == r.opts.SystemUID && !msg.AckRequired { // 如果是系统消息，不需要发送ack
 				continue
 			}
 
-			_, span := trace.GlobalTrace.StartSpan(msg.ctx, "sendack")
+			spanCtx, span := trace.GlobalTrace.StartSpan(msg.ctx, "sendack")
 
+			messageId := msg.MessageId
+			if msg.DuplicateOf != 0 { // 重复发送的消息返回原消息的id
+				messageId = msg.DuplicateOf
+			}
 			sendack := &wkproto.SendackPacket{
 				Framer:      msg.SendPacket.Framer,
-				MessageID:   msg.MessageId,
+				MessageID:   messageId,
 				MessageSeq:  msg.MessageSeq,
 				ClientSeq:   msg.SendPacket.ClientSeq,
 				ClientMsgNo: msg.SendPacket.ClientMsgNo,
 				ReasonCode:  msg.ReasonCode,
 			}
-			if msg.FromNodeId == r.opts.Cluster.NodeId { // 连接在本节点
+			if msg.AckRequired && msg.FromNodeId == r.opts.Cluster.NodeId { // /message/send 在本节点等待发送结果
+				r.s.messageDedupManager.notifySendack(msg.FromUid, sendack)
+			} else if msg.FromNodeId == r.opts.Cluster.NodeId { // 连接在本节点
 				err = r.s.userReactor.writePacketByConnId(msg.FromUid, msg.FromConnId, sendack)
 				if err != nil {
 					r.Error("writePacketByConnId error", zap.Error(err), zap.Uint64("nodeId", msg.FromNodeId), zap.Int64("connId", msg.FromConnId))
 				}
 			} else { // 连接在其他节点，需要将消息转发出去
 				packets := nodeFowardSendackPacketMap[msg.FromNodeId]
+				if len(packets) == 0 {
+					nodeFowardSendackCtxMap[msg.FromNodeId] = trace.ContextWithSpanOf(r.s.ctx, spanCtx)
+				}
 				packets = append(packets, &ForwardSendackPacket{
 					Uid:      msg.FromUid,
 					DeviceId: msg.FromDeviceId,
@@ -857,15 +1031,15 @@ func (r *channelReactor) processSendack(reqs []*sendackReq) {
 	}
 
 	for nodeId, forwardSendackPackets := range nodeFowardSendackPacketMap {
-		err = r.requestForwardSendack(nodeId, forwardSendackPackets)
+		err = r.requestForwardSendack(nodeFowardSendackCtxMap[nodeId], nodeId, forwardSendackPackets)
 		if err != nil {
 			r.Error("requestForwardSendack error", zap.Error(err), zap.Uint64("nodeId", nodeId))
 		}
 	}
 }
 
-func (r *channelReactor) requestForwardSendack(nodeId uint64, packets []*ForwardSendackPacket) error {
-	timeoutCtx, cancel := context.WithTimeout(r.s.ctx, time.Second*5)
+func (r *channelReactor) requestForwardSendack(ctx context.Context, nodeId uint64, packets []*ForwardSendackPacket) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
 	packetSet := ForwardSendackPacketSet(packets)
@@ -952,6 +1126,9 @@ func (r *channelReactor) processDeliver(reqs []*deliverReq) {
 				r.Debug("msg reasonCode is not success, no deliver", zap.Uint64("messageId", uint64(msg.MessageId)), zap.String("channelId", req.channelId), zap.Uint8("channelType", req.channelType))
 				continue
 			}
+			if msg.DuplicateOf != 0 { // 重复发送的消息，原消息已经投递过
+				continue
+			}
 			deliverMessages = append(deliverMessages, msg)
 		}
 
@@ -150,6 +150,8 @@ func (c *channel) stepLeader(a *ChannelAction) error {
 				storedMsg := a.Messages[j]
 				if msg.MessageId == storedMsg.MessageId {
 					msg.MessageSeq = storedMsg.MessageSeq
+					msg.Mention = storedMsg.Mention
+					msg.DuplicateOf = storedMsg.DuplicateOf
 					c.msgQueue.messages[i] = msg
 					break
 				}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+)
+
+// tcp连接消息内容压缩（客户端协议版本4及以上）
+//
+// 协商：客户端在CONNECT包固定报头的标记位中声明支持的压缩算法，第1位（RedDot位）为deflate，第2位（SyncOnce位）保留给zstd，
+// 服务端开启了压缩且客户端支持时，在认证成功的CONNACK包固定报头的相同标记位中返回选中的算法。
+//
+// 协商成功后，RECV包的Payload在加密前第一个字节表示压缩方式：0为未压缩，1为deflate（RFC 1951，不带zlib/gzip头），后面为内容。
+// 低于压缩阈值或压缩后没有变小的内容不压缩。客户端发送的SEND包内容不压缩。
+type payloadCompression uint8
+
+const (
+	payloadCompressionNone    payloadCompression = 0
+	payloadCompressionDeflate payloadCompression = 1
+)
+
+const (
+	compressionMinProtoVersion = 4    // 支持协商压缩的最低协议版本
+	compressionFlagDeflate     = 0x02 // CONNECT/CONNACK固定报头中deflate的标记位
+)
+
+func (p payloadCompression) String() string {
+	switch p {
+	case payloadCompressionDeflate:
+		return CompressionAlgorithmDeflate
+	}
+	return ""
+}
+
+var deflateWriterPool sync.Pool
+
+// negotiateCompression 根据客户端的CONNECT包协商消息内容的压缩方式
+func (s *Server) negotiateCompression(connectPacket *wkproto.ConnectPacket) payloadCompression {
+	if !s.opts.Compression.TCP.On || connectPacket.Version < compressionMinProtoVersion {
+		return payloadCompressionNone
+	}
+	if s.opts.Compression.TCP.Algorithm == CompressionAlgorithmDeflate && connectPacket.RedDot {
+		return payloadCompressionDeflate
+	}
+	return payloadCompressionNone
+}
+
+// encodeConnack 编码CONNACK包，认证成功且协商了压缩时在固定报头中标记选中的算法
+func (c *connContext) encodeConnack(packet *wkproto.ConnackPacket) ([]byte, error) {
+	return encodeConnack(c.subReactor.r.s.opts.Proto, packet, c.protoVersion, c.compression)
+}
+
+func encodeConnack(proto wkproto.Protocol, packet *wkproto.ConnackPacket, version uint8, compression payloadCompression) ([]byte, error) {
+	data, err := proto.EncodeFrame(packet, version)
+	if err != nil {
+		return nil, err
+	}
+	if packet.ReasonCode == wkproto.ReasonSuccess && compression == payloadCompressionDeflate {
+		data[0] |= compressionFlagDeflate
+	}
+	return data, nil
+}
+
+// compressPayload 按连接协商的压缩方式处理消息内容，没有协商压缩时原样返回
+func (c *connContext) compressPayload(payload []byte) ([]byte, error) {
+	if c.compression == payloadCompressionNone {
+		return payload, nil
+	}
+	opts := c.subReactor.r.s.opts.Compression.TCP
+	if len(payload) >= opts.Threshold {
+		start := time.Now()
+		compressed, err := deflatePayload(payload, opts.Level)
+		if err != nil {
+			return nil, err
+		}
+		trace.GlobalTrace.Metrics.App().CompressAdd(int64(len(payload)), int64(len(compressed)), time.Since(start))
+		if len(compressed) < len(payload)+1 {
+			return compressed, nil
+		}
+	}
+	data := make([]byte, 0, len(payload)+1)
+	data = append(data, byte(payloadCompressionNone))
+	return append(data, payload...), nil
+}
+
+// deflatePayload deflate压缩内容，返回的数据第一个字节为压缩方式
+func deflatePayload(payload []byte, level int) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, len(payload)/2+1))
+	buf.WriteByte(byte(payloadCompressionDeflate))
+
+	w, _ := deflateWriterPool.Get().(*flate.Writer)
+	if w == nil {
+		var err error
+		if w, err = flate.NewWriter(buf, level); err != nil {
+			return nil, err
+		}
+	} else {
+		w.Reset(buf)
+	}
+	defer deflateWriterPool.Put(w)
+
+	if _, err := w.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
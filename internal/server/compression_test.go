@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"testing"
+
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateCompression(t *testing.T) {
+	s := &Server{opts: NewOptions()}
+	connectPacket := &wkproto.ConnectPacket{Framer: wkproto.Framer{RedDot: true}, Version: wkproto.LatestVersion}
+
+	// 默认不开启
+	assert.Equal(t, payloadCompressionNone, s.negotiateCompression(connectPacket))
+
+	s.opts.Compression.TCP.On = true
+	assert.Equal(t, payloadCompressionDeflate, s.negotiateCompression(connectPacket))
+
+	// 客户端没有声明支持
+	assert.Equal(t, payloadCompressionNone, s.negotiateCompression(&wkproto.ConnectPacket{Version: wkproto.LatestVersion}))
+
+	// 协议版本过低
+	assert.Equal(t, payloadCompressionNone, s.negotiateCompression(&wkproto.ConnectPacket{Framer: wkproto.Framer{RedDot: true}, Version: 3}))
+}
+
+func TestEncodeConnackCompression(t *testing.T) {
+	proto := wkproto.New()
+	connack := &wkproto.ConnackPacket{Framer: wkproto.Framer{HasServerVersion: true}, ServerVersion: wkproto.LatestVersion, ReasonCode: wkproto.ReasonSuccess}
+
+	data, err := encodeConnack(proto, connack, wkproto.LatestVersion, payloadCompressionDeflate)
+	assert.NoError(t, err)
+	frame, _, err := proto.DecodeFrame(data, wkproto.LatestVersion)
+	assert.NoError(t, err)
+	resultConnack := frame.(*wkproto.ConnackPacket)
+	assert.True(t, resultConnack.RedDot)
+	assert.True(t, resultConnack.HasServerVersion)
+	assert.Equal(t, uint8(wkproto.LatestVersion), resultConnack.ServerVersion)
+
+	data, err = encodeConnack(proto, connack, wkproto.LatestVersion, payloadCompressionNone)
+	assert.NoError(t, err)
+	frame, _, err = proto.DecodeFrame(data, wkproto.LatestVersion)
+	assert.NoError(t, err)
+	assert.False(t, frame.(*wkproto.ConnackPacket).RedDot)
+
+	// 认证失败不标记
+	data, err = encodeConnack(proto, &wkproto.ConnackPacket{ReasonCode: wkproto.ReasonAuthFail}, wkproto.LatestVersion, payloadCompressionDeflate)
+	assert.NoError(t, err)
+	frame, _, err = proto.DecodeFrame(data, wkproto.LatestVersion)
+	assert.NoError(t, err)
+	assert.False(t, frame.(*wkproto.ConnackPacket).RedDot)
+}
+
+func TestDeflatePayload(t *testing.T) {
+	payload := bytes.Repeat([]byte(`{"type":1,"content":"hello"}`), 20)
+	for i := 0; i < 2; i++ { // 第二次使用池里的压缩器
+		data, err := deflatePayload(payload, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, byte(payloadCompressionDeflate), data[0])
+		assert.Less(t, len(data), len(payload))
+
+		result, err := io.ReadAll(flate.NewReader(bytes.NewReader(data[1:])))
+		assert.NoError(t, err)
+		assert.Equal(t, payload, result)
+	}
+}
+
+func TestUserAuthResultCompression(t *testing.T) {
+	result := &UserAuthResult{Uid: "u1", DeviceId: "d1", ProtoVersion: wkproto.LatestVersion, Compression: payloadCompressionDeflate}
+	data, err := result.Marshal()
+	assert.NoError(t, err)
+
+	result2 := &UserAuthResult{}
+	assert.NoError(t, result2.Unmarshal(data))
+	assert.Equal(t, result, result2)
+
+	// 兼容没有compression字段的旧版本数据
+	result3 := &UserAuthResult{}
+	assert.NoError(t, result3.Unmarshal(data[:len(data)-1]))
+	assert.Equal(t, payloadCompressionNone, result3.Compression)
+}
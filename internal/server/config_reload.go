@@ -0,0 +1,144 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"go.uber.org/zap"
+)
+
+// configManager 配置热加载
+// 热加载分两个阶段：先让所有节点重新读取各自的配置文件并校验，全部通过后再让所有节点应用，
+// 任何一个节点校验失败都不会应用，避免集群内的节点配置不一致
+type configManager struct {
+	s *Server
+	wklog.Log
+	mu sync.Mutex
+}
+
+func newConfigManager(s *Server) *configManager {
+	return &configManager{
+		s:   s,
+		Log: wklog.NewWKLog("configManager"),
+	}
+}
+
+// reload 热加载指定节点的配置，nodeId为0时热加载集群内所有在线节点的配置
+func (c *configManager) reload(nodeId uint64) ([]*configReloadResult, bool) {
+	nodeIds := []uint64{nodeId}
+	if nodeId == 0 {
+		nodeIds = c.s.onlineNodeIds()
+	}
+
+	// 校验
+	results := make([]*configReloadResult, 0, len(nodeIds))
+	ok := true
+	for _, id := range nodeIds {
+		result := &configReloadResult{NodeId: id}
+		var (
+			changedKeys []string
+			err         error
+		)
+		if id == c.s.opts.Cluster.NodeId {
+			changedKeys, err = c.validate()
+		} else {
+			changedKeys, err = c.s.requestConfigReload(id, "/wk/configValidate")
+		}
+		if err != nil {
+			c.Warn("validate config failed", zap.Error(err), zap.Uint64("nodeId", id))
+			result.Err = err.Error()
+			ok = false
+		}
+		result.ChangedKeys = changedKeys
+		results = append(results, result)
+	}
+	if !ok {
+		return results, false
+	}
+
+	// 应用
+	for _, result := range results {
+		var (
+			changedKeys []string
+			err         error
+		)
+		if result.NodeId == c.s.opts.Cluster.NodeId {
+			changedKeys, err = c.apply()
+		} else {
+			changedKeys, err = c.s.requestConfigReload(result.NodeId, "/wk/configApply")
+		}
+		if err != nil {
+			c.Warn("apply config failed", zap.Error(err), zap.Uint64("nodeId", result.NodeId))
+			result.Err = err.Error()
+			ok = false
+		}
+		result.ChangedKeys = changedKeys
+		result.Applied = err == nil
+	}
+	return results, ok
+}
+
+// load 重新读取本节点的配置文件并校验，返回新的配置和发生变化的配置名
+func (c *configManager) load() (ReloadableOptions, []string, error) {
+	vp, err := c.s.opts.ReadConfigFile()
+	if err != nil {
+		return ReloadableOptions{}, nil, err
+	}
+	r := c.s.opts.LoadReloadable(vp)
+	if err = r.Check(); err != nil {
+		return ReloadableOptions{}, nil, err
+	}
+	return r, c.s.opts.Reloadable().ChangedKeys(r), nil
+}
+
+// validate 校验本节点的配置文件
+func (c *configManager) validate() ([]string, error) {
+	_, changedKeys, err := c.load()
+	return changedKeys, err
+}
+
+// apply 应用本节点的配置文件
+func (c *configManager) apply() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, _, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	old := c.s.opts.Reloadable()
+	changedKeys := c.s.opts.ApplyReloadable(r)
+	for _, key := range changedKeys {
+		switch key {
+		case "logger.level":
+			wklog.SetLevel(r.LoggerLevel)
+		case "logger.modules":
+			for module := range old.LoggerModuleLevels {
+				if _, ok := r.LoggerModuleLevels[module]; !ok {
+					wklog.RemoveModuleLevel(module)
+				}
+			}
+			for module, level := range r.LoggerModuleLevels {
+				wklog.SetModuleLevel(module, level)
+			}
+		case "webhook.grpcAddr":
+			c.s.webhook.resetGRPCPool()
+		case "messageRetry.scanInterval":
+			c.s.retryManager.resetScanInterval()
+		}
+	}
+	if len(changedKeys) > 0 {
+		c.Info("config reloaded", zap.Strings("changedKeys", changedKeys))
+	}
+	return changedKeys, nil
+}
+
+// view 本节点生效中的配置
+func (c *configManager) view() *configViewResp {
+	return &configViewResp{
+		NodeId:         c.s.opts.Cluster.NodeId,
+		ConfigFile:     c.s.opts.ConfigFileUsed(),
+		ReloadableKeys: ReloadableOptionKeys(),
+		Config:         c.s.opts.View(),
+	}
+}
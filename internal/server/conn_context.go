@@ -59,6 +59,10 @@ type connContext struct {
 
 	lastActivity atomic.Time // 最后活动时间
 
+	slowClosing atomic.Bool // 慢消费者断开中
+
+	compression payloadCompression // 协商的消息内容压缩方式
+
 	wklog.Log
 }
 
@@ -196,11 +200,17 @@ func (c *connContext) writePacket(packet wkproto.Frame) error {
 }
 
 func (c *connContext) write(d []byte, frameType wkproto.FrameType) error {
+	return c.writeWithContext(context.Background(), d, frameType)
+}
+
+// writeWithContext 写入数据，ctx为数据对应的链路（转发到其他节点时传递）
+func (c *connContext) writeWithContext(ctx context.Context, d []byte, frameType wkproto.FrameType) error {
 
 	c.subReactor.step(c.uid, UserAction{
 		ActionType: UserActionRecv,
 		Messages: []ReactorUserMessage{
 			{
+				ctx:        ctx,
 				ConnId:     c.connId,
 				DeviceId:   c.deviceId,
 				FrameType:  frameType,
@@ -230,6 +240,22 @@ func (c *connContext) writeDirectlyPacket(packet wkproto.Frame) error {
 // 直接写入连接
 func (c *connContext) writeDirectly(data []byte, recvFrameCount uint32) error {
 
+	if c.conn == nil {
+		c.Error("writeDirectly failed, conn is nil", zap.String("conn", c.String()))
+		return errors.New("writeDirectly failed, conn is nil")
+	}
+	if c.slowClosing.Load() { // 慢消费者断开中，丢弃数据
+		c.conn.ConnStats().Dropped(1, len(data))
+		return nil
+	}
+	if c.conn.OutboundOverflow(len(data), 1) { // 超出发送缓冲区的限制
+		var ok bool
+		data, recvFrameCount, ok = c.handleOutboundOverflow(data, recvFrameCount)
+		if !ok || len(data) == 0 {
+			return nil
+		}
+	}
+
 	dataSize := int64(len(data))
 	if recvFrameCount > 0 {
 		c.outMsgCount.Add(int64(recvFrameCount))
@@ -242,22 +268,34 @@ func (c *connContext) writeDirectly(data []byte, recvFrameCount uint32) error {
 	c.outPacketCount.Add(1)
 	c.outPacketByteCount.Add(dataSize)
 
-	if c.conn == nil {
-		c.Error("writeDirectly failed, conn is nil", zap.String("conn", c.String()))
-		return errors.New("writeDirectly failed, conn is nil")
+	err := c.writeConn(data)
+	if errors.Is(err, wknet.ErrOutboundOverflow) { // 并发写入时检查后仍然可能超出限制
+		c.disconnectSlowConsumer()
+		return nil
 	}
+	return err
+}
+
+// writeConn 写入连接的发送缓冲区并唤醒写事件
+func (c *connContext) writeConn(data []byte) error {
 	conn := c.conn
 	wsConn, wsok := conn.(wknet.IWSConn) // websocket连接
 	if wsok {
 		err := wsConn.WriteServerBinary(data)
 		if err != nil {
 			c.Warn("Failed to write the message", zap.Error(err))
+			if errors.Is(err, wknet.ErrOutboundOverflow) {
+				return err
+			}
 		}
 
 	} else {
 		_, err := conn.WriteToOutboundBuffer(data)
 		if err != nil {
 			c.Warn("Failed to write the message", zap.Error(err))
+			if errors.Is(err, wknet.ErrOutboundOverflow) {
+				return err
+			}
 		}
 	}
 	return conn.WakeWrite()
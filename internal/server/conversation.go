@@ -9,12 +9,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/RussellLuo/timingwheel"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	wkproto "github.com/WuKongIM/WuKongIMGoProto"
 	"github.com/lni/goutils/syncutil"
 	"github.com/sasha-s/go-deadlock"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
 
@@ -25,6 +27,9 @@ type ConversationManager struct {
 
 	workers []*conversationWorker
 
+	cleanTimer *timingwheel.Timer
+	cleaning   atomic.Bool // 是否正在清理过期的会话删除墓碑
+
 	deadlock.RWMutex
 }
 
@@ -68,6 +73,9 @@ func (c *ConversationManager) Push(fakeChannelId string, channelType uint8, uids
 		worker.getOrCreateUserConversation(message.FromUid).updateOrAddConversation(fakeChannelId, channelType, message.MessageSeq)
 	}
 
+	isThread := c.s.opts.IsThreadChannel(fakeChannelId)
+	lastMsgSeq := lastMsgSeqOf(messages)
+
 	// 处理接受者的最近会话
 	for _, uid := range uids {
 
@@ -78,6 +86,9 @@ func (c *ConversationManager) Push(fakeChannelId string, channelType uint8, uids
 		worker := c.worker(uid)
 		userConversation := worker.getOrCreateUserConversation(uid)
 
+		unreadSeqs := unreadSeqsOfUser(uid, messages)
+		mentionCount, lastMentionSeq := mentionOfUser(uid, messages)
+
 		// 如果用户最近会话缓存中不存在，则加入到缓存，如果存在可以直接忽略
 		if !userConversation.existConversation(fakeChannelId, channelType) {
 			// 如果数据库中存在会话，则仅仅添加到缓存，不需要更新数据库
@@ -90,14 +101,76 @@ func (c *ConversationManager) Push(fakeChannelId string, channelType uint8, uids
 				channelConversation := userConversation.addConversationIfNotExist(existConversation.Id, fakeChannelId, channelType, uint32(existConversation.ReadToMsgSeq))
 				if channelConversation != nil { // 如果db中存在会话，则不需要更新
 					channelConversation.NeedUpdate = false
+					channelConversation.UnreadCount = existConversation.UnreadCount
+					channelConversation.MentionCount = existConversation.MentionCount
+					channelConversation.LastMentionSeq = uint32(existConversation.LastMentionSeq)
 				}
-			} else {
+			} else if !isThread || mentionCount > 0 { // 子区会话只有参与过子区或者被@的用户才有（发送者的会话在上面已经处理）
 				userConversation.addConversationIfNotExist(0, fakeChannelId, channelType, 0) // 只有缓存中不存在的时候才添加
 			}
 		}
 
+		if len(unreadSeqs) > 0 {
+			userConversation.addUnread(fakeChannelId, channelType, unreadSeqs)
+		}
+
+		if mentionCount > 0 {
+			userConversation.addMention(fakeChannelId, channelType, mentionCount, lastMentionSeq)
+		}
+
+		if lastMsgSeq > 0 {
+			userConversation.updateLastMsgSeq(fakeChannelId, channelType, lastMsgSeq)
+		}
+
+	}
+
+}
+
+// lastMsgSeqOf 获取消息中最大的消息序号（不存储的消息不计入）
+func lastMsgSeqOf(messages []ReactorChannelMessage) uint32 {
+	var lastSeq uint32
+	for _, message := range messages {
+		if message.SendPacket == nil || message.SendPacket.NoPersist {
+			continue
+		}
+		if message.MessageSeq > lastSeq {
+			lastSeq = message.MessageSeq
+		}
+	}
+	return lastSeq
+}
+
+// unreadSeqsOfUser 获取需要计入指定用户未读数的消息序号（自己发的、不显示红点的、不存储的和命令消息都不计入）
+func unreadSeqsOfUser(uid string, messages []ReactorChannelMessage) []uint32 {
+	var seqs []uint32
+	for _, message := range messages {
+		if message.FromUid == uid || message.SendPacket == nil {
+			continue
+		}
+		if !message.SendPacket.RedDot || message.SendPacket.NoPersist || message.SendPacket.SyncOnce {
+			continue
+		}
+		seqs = append(seqs, message.MessageSeq)
 	}
+	return seqs
+}
 
+// mentionOfUser 统计消息中@了指定用户的数量和最后一条@消息的序号（自己发的消息不算）
+func mentionOfUser(uid string, messages []ReactorChannelMessage) (uint32, uint32) {
+	var (
+		count   uint32
+		lastSeq uint32
+	)
+	for _, message := range messages {
+		if message.FromUid == uid || !message.Mention.Contains(uid) {
+			continue
+		}
+		count++
+		if message.MessageSeq > lastSeq {
+			lastSeq = message.MessageSeq
+		}
+	}
+	return count, lastSeq
 }
 
 func (c *ConversationManager) Start() error {
@@ -115,11 +188,24 @@ func (c *ConversationManager) Start() error {
 
 	c.recoverFromFile()
 
+	c.cleanTimer = c.s.Schedule(c.s.opts.Conversation.TombstoneCleanInterval, func() {
+		if !c.cleaning.CompareAndSwap(false, true) {
+			return
+		}
+		defer c.cleaning.Store(false)
+		c.cleanExpiredTombstones()
+	})
+
 	return nil
 }
 
 func (c *ConversationManager) Stop() {
 
+	if c.cleanTimer != nil {
+		c.cleanTimer.Stop()
+		c.cleanTimer = nil
+	}
+
 	for _, w := range c.workers {
 		w.stop()
 	}
@@ -127,6 +213,28 @@ func (c *ConversationManager) Stop() {
 	c.saveToFile()
 }
 
+// cleanExpiredTombstones 清理本节点上超过保留时长的会话删除墓碑
+func (c *ConversationManager) cleanExpiredTombstones() {
+	deletedBefore := uint64(time.Now().Add(-c.s.opts.Conversation.TombstoneRetention).Unix())
+	for {
+		count, err := c.s.store.RemoveExpiredConversationTombstones(deletedBefore, 1000)
+		if err != nil {
+			c.Error("remove expired conversation tombstones failed", zap.Error(err))
+			return
+		}
+		if count < 1000 {
+			return
+		}
+	}
+}
+
+// Flush 将缓存中需要更新的最近会话立即提案到存储
+func (c *ConversationManager) Flush() {
+	for _, w := range c.workers {
+		w.propose()
+	}
+}
+
 func (c *ConversationManager) saveToFile() {
 	c.Lock()
 	defer c.Unlock()
@@ -260,13 +368,18 @@ func (c *conversationWorker) stop() {
 
 func (c *conversationWorker) loopPropose() {
 
-	tk := time.NewTicker(time.Minute * 5)
+	interval := c.s.opts.Live().ConversationSyncInterval
+	tk := time.NewTicker(interval)
 	defer tk.Stop()
 
 	for {
 		select {
 		case <-tk.C:
 			c.propose()
+			if syncInterval := c.s.opts.Live().ConversationSyncInterval; interval != syncInterval { // 同步间隔热加载后生效
+				interval = syncInterval
+				tk.Reset(interval)
+			}
 		case <-c.stopper.ShouldStop():
 			return
 		}
@@ -292,23 +405,21 @@ func (c *conversationWorker) propose() {
 			if conversation.NeedUpdate {
 				conversation.NeedUpdate = false // 提前设置为false，防止在更新的时候再次更新
 
-				var conversationType wkdb.ConversationType
-				if c.s.opts.IsCmdChannel(conversation.ChannelId) {
-					conversationType = wkdb.ConversationTypeCMD
-				} else {
-					conversationType = wkdb.ConversationTypeChat
-				}
+				conversationType := getConversationType(c.s.opts, conversation.ChannelId)
 				createdAt := time.Now()
 				updatedAt := time.Now()
 				conversations = append(conversations, wkdb.Conversation{
-					Id:           conversation.Id,
-					Uid:          cc.uid,
-					Type:         conversationType,
-					ChannelId:    conversation.ChannelId,
-					ChannelType:  conversation.ChannelType,
-					ReadToMsgSeq: uint64(conversation.ReadedMsgSeq),
-					CreatedAt:    &createdAt,
-					UpdatedAt:    &updatedAt,
+					Id:             conversation.Id,
+					Uid:            cc.uid,
+					Type:           conversationType,
+					ChannelId:      conversation.ChannelId,
+					ChannelType:    conversation.ChannelType,
+					ReadToMsgSeq:   uint64(conversation.ReadedMsgSeq),
+					UnreadCount:    conversation.UnreadCount,
+					MentionCount:   conversation.MentionCount,
+					LastMentionSeq: uint64(conversation.LastMentionSeq),
+					CreatedAt:      &createdAt,
+					UpdatedAt:      &updatedAt,
 				})
 			}
 		}
@@ -411,13 +522,16 @@ func (c *userConversation) getConversationsByType(conversationType wkdb.Conversa
 	for _, s := range c.conversations {
 		if s.ConversationType == conversationType {
 			conversations = append(conversations, wkdb.Conversation{
-				Uid:          c.uid,
-				Type:         s.ConversationType,
-				ChannelId:    s.ChannelId,
-				ChannelType:  s.ChannelType,
-				ReadToMsgSeq: uint64(s.ReadedMsgSeq),
-				CreatedAt:    &s.CreatedAt,
-				UpdatedAt:    &s.UpdatedAt,
+				Uid:            c.uid,
+				Type:           s.ConversationType,
+				ChannelId:      s.ChannelId,
+				ChannelType:    s.ChannelType,
+				ReadToMsgSeq:   uint64(s.ReadedMsgSeq),
+				UnreadCount:    s.UnreadCount,
+				MentionCount:   s.MentionCount,
+				LastMentionSeq: uint64(s.LastMentionSeq),
+				CreatedAt:      &s.CreatedAt,
+				UpdatedAt:      &s.UpdatedAt,
 			})
 		}
 	}
@@ -467,17 +581,17 @@ func (c *userConversation) updateOrAddConversation(channelId string, channelType
 	if conversation != nil {
 		if conversation.ReadedMsgSeq < readedMsgSeq {
 			conversation.ReadedMsgSeq = readedMsgSeq
+			// 自己发送了消息，说明之前的消息都已读
+			conversation.UnreadCount = 0
+			if readedMsgSeq >= conversation.LastMentionSeq { // 已读过最后一条@消息，清空@数量
+				conversation.MentionCount = 0
+			}
 			conversation.NeedUpdate = true
 		}
 		return
 	}
 
-	var conversationType wkdb.ConversationType
-	if c.s.opts.IsCmdChannel(channelId) {
-		conversationType = wkdb.ConversationTypeCMD
-	} else {
-		conversationType = wkdb.ConversationTypeChat
-	}
+	conversationType := getConversationType(c.s.opts, channelId)
 
 	c.conversations = append(c.conversations, &channelConversation{
 		ChannelId:        channelId,
@@ -490,14 +604,55 @@ func (c *userConversation) updateOrAddConversation(channelId string, channelType
 	})
 }
 
-func (c *userConversation) addConversationNotLock(conversationId uint64, channelId string, channelType uint8, readedMsgSeq uint32) *channelConversation {
+// addUnread 增加会话的未读数（已读过的消息不计入）
+func (c *userConversation) addUnread(channelId string, channelType uint8, seqs []uint32) {
+	c.Lock()
+	defer c.Unlock()
+
+	conversation := c.getConversationNotLock(channelId, channelType)
+	if conversation == nil {
+		return
+	}
+	for _, seq := range seqs {
+		if seq > conversation.ReadedMsgSeq {
+			conversation.UnreadCount++
+			conversation.NeedUpdate = true
+		}
+	}
+}
 
-	var conversationType wkdb.ConversationType
-	if c.s.opts.IsCmdChannel(channelId) {
-		conversationType = wkdb.ConversationTypeCMD
-	} else {
-		conversationType = wkdb.ConversationTypeChat
+// addMention 增加会话的@数量
+func (c *userConversation) addMention(channelId string, channelType uint8, count uint32, lastMentionSeq uint32) {
+	c.Lock()
+	defer c.Unlock()
+
+	conversation := c.getConversationNotLock(channelId, channelType)
+	if conversation == nil || lastMentionSeq <= conversation.ReadedMsgSeq {
+		return
+	}
+	conversation.MentionCount += count
+	if lastMentionSeq > conversation.LastMentionSeq {
+		conversation.LastMentionSeq = lastMentionSeq
 	}
+	conversation.NeedUpdate = true
+}
+
+// updateLastMsgSeq 更新会话的最新消息序号，有新消息时会话需要重新保存（保存时会话的版本号增加，增量同步才能同步到有新消息的会话）
+func (c *userConversation) updateLastMsgSeq(channelId string, channelType uint8, lastMsgSeq uint32) {
+	c.Lock()
+	defer c.Unlock()
+
+	conversation := c.getConversationNotLock(channelId, channelType)
+	if conversation == nil || lastMsgSeq <= conversation.LastMsgSeq {
+		return
+	}
+	conversation.LastMsgSeq = lastMsgSeq
+	conversation.NeedUpdate = true
+}
+
+func (c *userConversation) addConversationNotLock(conversationId uint64, channelId string, channelType uint8, readedMsgSeq uint32) *channelConversation {
+
+	conversationType := getConversationType(c.s.opts, channelId)
 
 	cn := &channelConversation{
 		Id:               conversationId,
@@ -514,11 +669,66 @@ func (c *userConversation) addConversationNotLock(conversationId uint64, channel
 	return cn
 }
 
+// 获取频道对应的会话类型
+func getConversationType(opts *Options, channelId string) wkdb.ConversationType {
+	if opts.IsCmdChannel(channelId) {
+		return wkdb.ConversationTypeCMD
+	}
+	if opts.IsThreadChannel(channelId) {
+		return wkdb.ConversationTypeThread
+	}
+	return wkdb.ConversationTypeChat
+}
+
+// mergeCacheConversations 将缓存中的会话合并到db的会话中（缓存中的数据比db中的新）
+func mergeCacheConversations(conversations []wkdb.Conversation, cacheConversations []wkdb.Conversation) []wkdb.Conversation {
+	for _, cacheConversation := range cacheConversations {
+		exist := false
+		for i, conversation := range conversations {
+			if cacheConversation.ChannelId == conversation.ChannelId && cacheConversation.ChannelType == conversation.ChannelType {
+				if cacheConversation.ReadToMsgSeq > conversation.ReadToMsgSeq {
+					conversations[i].ReadToMsgSeq = cacheConversation.ReadToMsgSeq
+				}
+				conversations[i].UnreadCount = cacheConversation.UnreadCount
+				if cacheConversation.LastMentionSeq >= conversation.LastMentionSeq {
+					conversations[i].MentionCount = cacheConversation.MentionCount
+					conversations[i].LastMentionSeq = cacheConversation.LastMentionSeq
+				}
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			conversations = append(conversations, cacheConversation)
+		}
+	}
+	return conversations
+}
+
+// unreadTotalOfConversations 统计会话的总未读数量（免打扰和系统账号的会话不计入）
+func unreadTotalOfConversations(conversations []wkdb.Conversation, systemUid string) uint64 {
+	var total uint64
+	for _, conversation := range conversations {
+		if conversation.Muted {
+			continue
+		}
+		if conversation.ChannelType == wkproto.ChannelTypePerson && conversation.ChannelId == systemUid {
+			continue
+		}
+		total += uint64(conversation.UnreadCount)
+	}
+	return total
+}
+
 type channelConversation struct {
 	Id               uint64                `json:"id"` // 会话id
 	ChannelId        string                `json:"channel_id"`
 	ChannelType      uint8                 `json:"channel_type"`
 	ReadedMsgSeq     uint32                `json:"readed_msg_seq"`
+	UnreadCount      uint32                `json:"unread_count"`     // 未读数量
+	MentionCount     uint32                `json:"mention_count"`    // 未读的@数量
+	LastMentionSeq   uint32                `json:"last_mention_seq"` // 最后一条@消息的序号
+	LastMsgSeq       uint32                `json:"last_msg_seq"`     // 最新消息的序号
 	NeedUpdate       bool                  `json:"need_update"`
 	ConversationType wkdb.ConversationType `json:"conversation_type"`
 	CreatedAt        time.Time             `json:"created_at"`
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap"
+)
+
+// 最近会话变更的cmd类型
+const conversationUpdateCMDType = "conversationUpdate"
+
+// 最近会话变更的动作
+const (
+	conversationUpdateActionUnread = "unread" // 已读位置/未读数变更
+	conversationUpdateActionDelete = "delete" // 删除会话
+	conversationUpdateActionAttrs  = "attrs"  // 会话属性变更（置顶、免打扰、草稿、扩展数据）
+)
+
+// conversationDeviceTarget 会话变更需要同步的设备
+// 会话变更会立即推送给用户其他在线的设备，离线的设备上线后通过/conversation/sync的版本号增量同步
+type conversationDeviceTarget struct {
+	DeviceId    string  `json:"device_id"`    // 发起变更的设备id，此设备的连接不再推送
+	DeviceFlags []uint8 `json:"device_flags"` // 只推送给指定类型的设备（0.app 1.web 2.pc），不传则推送给所有类型的设备
+}
+
+// match 连接是否需要推送
+func (t conversationDeviceTarget) match(conn *connContext) bool {
+	if t.DeviceId != "" && conn.deviceId == t.DeviceId {
+		return false
+	}
+	if len(t.DeviceFlags) == 0 {
+		return true
+	}
+	for _, deviceFlag := range t.DeviceFlags {
+		if deviceFlag == conn.deviceFlag.ToUint8() {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyConversationUpdate 将用户的会话变更推送给用户在线的设备
+// 会话接口都在用户所在槽的领导节点上处理，用户的连接都在领导节点上，所以直接写入用户的连接即可
+func (s *Server) notifyConversationUpdate(uid string, target conversationDeviceTarget, content map[string]interface{}) {
+	if !s.opts.Conversation.DeviceSyncOn {
+		return
+	}
+	userHandler := s.userReactor.getUser(uid)
+	if userHandler == nil { // 用户不在线，上线后通过会话同步获取
+		return
+	}
+	version, err := s.store.GetConversationVersion(uid)
+	if err != nil {
+		s.Warn("get conversation version failed", zap.Error(err), zap.String("uid", uid))
+	}
+	content["type"] = conversationUpdateCMDType
+	content["version"] = version
+	payload := []byte(wkutil.ToJSON(content))
+
+	for _, conn := range userHandler.getConns() {
+		if !target.match(conn) {
+			continue
+		}
+		recvPacket := &wkproto.RecvPacket{
+			Framer: wkproto.Framer{
+				NoPersist: true,
+				SyncOnce:  true,
+			},
+			ClientMsgNo: wkutil.GenUUID(),
+			FromUID:     s.opts.SystemUID,
+			ChannelID:   s.opts.SystemUID,
+			ChannelType: wkproto.ChannelTypePerson,
+			Timestamp:   int32(time.Now().Unix()),
+			Payload:     payload,
+		}
+
+		payloadEnc, err := encryptMessagePayload(recvPacket.Payload, conn)
+		if err != nil {
+			s.Error("加密payload失败！", zap.Error(err))
+			continue
+		}
+		recvPacket.Payload = payloadEnc
+
+		msgKey, err := makeMsgKey(recvPacket.VerityString(), conn)
+		if err != nil {
+			s.Error("生成MsgKey失败！", zap.Error(err))
+			continue
+		}
+		recvPacket.MsgKey = msgKey
+
+		data, err := s.opts.Proto.EncodeFrame(recvPacket, conn.protoVersion)
+		if err != nil {
+			s.Error("encode recvPacket failed", zap.Error(err), zap.String("uid", conn.uid))
+			continue
+		}
+		if err = conn.write(data, wkproto.RECV); err != nil {
+			s.Debug("write conversation update failed", zap.Error(err), zap.String("uid", conn.uid), zap.Int64("connId", conn.connId))
+		}
+	}
+}
+
+// conversationUnreadContent 已读位置/未读数变更的cmd内容，channelId为用户视角的频道id（个人频道为对方uid）
+func conversationUnreadContent(channelId string, channelType uint8, conversation wkdb.Conversation) map[string]interface{} {
+	return map[string]interface{}{
+		"action":          conversationUpdateActionUnread,
+		"channel_id":      channelId,
+		"channel_type":    channelType,
+		"read_to_msg_seq": conversation.ReadToMsgSeq,
+		"unread":          conversation.UnreadCount,
+		"mention_count":   conversation.MentionCount,
+	}
+}
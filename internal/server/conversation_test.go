@@ -39,3 +39,149 @@ func TestConversationGet(t *testing.T) {
 	assert.Equal(t, uint64(0), conversations2[0].ReadToMsgSeq)
 
 }
+
+func TestConversationMention(t *testing.T) {
+	messages := []ReactorChannelMessage{
+		{
+			FromUid:    "u1",
+			MessageSeq: 1,
+			Mention:    &MessageMention{Uids: []string{"u2"}},
+		},
+		{
+			FromUid:    "u1",
+			MessageSeq: 2,
+			Mention:    &MessageMention{All: 1},
+		},
+	}
+
+	count, lastSeq := mentionOfUser("u1", messages) // 自己发的@不计数
+	assert.Equal(t, uint32(0), count)
+	assert.Equal(t, uint32(0), lastSeq)
+
+	count, lastSeq = mentionOfUser("u2", messages)
+	assert.Equal(t, uint32(2), count)
+	assert.Equal(t, uint32(2), lastSeq)
+
+	count, lastSeq = mentionOfUser("u3", messages)
+	assert.Equal(t, uint32(1), count)
+	assert.Equal(t, uint32(2), lastSeq)
+
+	uc := newUserConversation("u2", &Server{opts: NewOptions()})
+	uc.addConversationIfNotExist(0, "g1", 2, 0)
+	uc.addMention("g1", 2, 2, 2)
+
+	conversations := uc.getConversationsByType(wkdb.ConversationTypeChat)
+	assert.Equal(t, 1, len(conversations))
+	assert.Equal(t, uint32(2), conversations[0].MentionCount)
+	assert.Equal(t, uint64(2), conversations[0].LastMentionSeq)
+
+	// 已读过最后一条@消息后，@数量清空
+	uc.updateOrAddConversation("g1", 2, 3)
+	conversations = uc.getConversationsByType(wkdb.ConversationTypeChat)
+	assert.Equal(t, uint32(0), conversations[0].MentionCount)
+
+	// 已读之前的@不再计数
+	uc.addMention("g1", 2, 1, 3)
+	conversations = uc.getConversationsByType(wkdb.ConversationTypeChat)
+	assert.Equal(t, uint32(0), conversations[0].MentionCount)
+}
+
+func TestConversationUnread(t *testing.T) {
+	messages := []ReactorChannelMessage{
+		{
+			FromUid:    "u1",
+			MessageSeq: 1,
+			SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: true}},
+		},
+		{
+			FromUid:    "u1",
+			MessageSeq: 2,
+			SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: false}}, // 不显示红点的消息不计入未读
+		},
+		{
+			FromUid:    "u1",
+			MessageSeq: 3,
+			SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: true, SyncOnce: true}}, // 命令消息不计入未读
+		},
+		{
+			FromUid:    "u1",
+			MessageSeq: 4,
+			SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: true}},
+		},
+	}
+
+	assert.Equal(t, 0, len(unreadSeqsOfUser("u1", messages))) // 自己发的消息不计入未读
+	assert.Equal(t, []uint32{1, 4}, unreadSeqsOfUser("u2", messages))
+
+	uc := newUserConversation("u2", &Server{opts: NewOptions()})
+	uc.addConversationIfNotExist(0, "g1", 2, 0)
+	uc.addUnread("g1", 2, []uint32{1, 4})
+
+	conversations := uc.getConversationsByType(wkdb.ConversationTypeChat)
+	assert.Equal(t, 1, len(conversations))
+	assert.Equal(t, uint32(2), conversations[0].UnreadCount)
+	assert.Equal(t, uint64(2), unreadTotalOfConversations(conversations, ""))
+
+	// 已读之前的消息不再计入未读
+	uc.updateOrAddConversation("g1", 2, 4)
+	uc.addUnread("g1", 2, []uint32{3, 4, 5})
+	conversations = uc.getConversationsByType(wkdb.ConversationTypeChat)
+	assert.Equal(t, uint32(1), conversations[0].UnreadCount)
+
+	// 免打扰的会话不计入总未读数
+	conversations[0].Muted = true
+	assert.Equal(t, uint64(0), unreadTotalOfConversations(conversations, ""))
+}
+
+func TestConversationLastMsgSeq(t *testing.T) {
+	messages := []ReactorChannelMessage{
+		{
+			FromUid:    "u1",
+			MessageSeq: 5,
+			SendPacket: &wkproto.SendPacket{},
+		},
+		{
+			FromUid:    "u1",
+			MessageSeq: 0,
+			SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{NoPersist: true}}, // 不存储的消息不计入
+		},
+	}
+	assert.Equal(t, uint32(5), lastMsgSeqOf(messages))
+
+	uc := newUserConversation("u2", &Server{opts: NewOptions()})
+	uc.addConversationIfNotExist(0, "g1", 2, 0)
+	uc.conversations[0].NeedUpdate = false // 模拟从数据库加载的会话
+
+	// 有新消息，会话需要重新保存（保存时版本号增加）
+	uc.updateLastMsgSeq("g1", 2, 5)
+	assert.True(t, uc.conversations[0].NeedUpdate)
+	assert.Equal(t, uint32(5), uc.conversations[0].LastMsgSeq)
+
+	// 消息序号没有变化，不需要重新保存
+	uc.conversations[0].NeedUpdate = false
+	uc.updateLastMsgSeq("g1", 2, 5)
+	assert.False(t, uc.conversations[0].NeedUpdate)
+}
+
+func TestConversationDeviceTargetMatch(t *testing.T) {
+	app := &connContext{connInfo: connInfo{deviceId: "d1", deviceFlag: wkproto.APP}}
+	web := &connContext{connInfo: connInfo{deviceId: "d2", deviceFlag: wkproto.WEB}}
+	pc := &connContext{connInfo: connInfo{deviceId: "d3", deviceFlag: wkproto.PC}}
+
+	// 不指定设备，推送给所有设备
+	target := conversationDeviceTarget{}
+	assert.True(t, target.match(app))
+	assert.True(t, target.match(web))
+	assert.True(t, target.match(pc))
+
+	// 发起变更的设备不推送
+	target = conversationDeviceTarget{DeviceId: "d1"}
+	assert.False(t, target.match(app))
+	assert.True(t, target.match(web))
+
+	// 只推送给指定类型的设备
+	target = conversationDeviceTarget{DeviceId: "d2", DeviceFlags: []uint8{uint8(wkproto.WEB), uint8(wkproto.PC)}}
+	assert.False(t, target.match(app))
+	assert.False(t, target.match(web))
+	assert.True(t, target.match(pc))
+}
@@ -0,0 +1,672 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RussellLuo/timingwheel"
+	"github.com/WuKongIM/WuKongIM/pkg/keylock"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/lni/goutils/syncutil"
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// 客服会话的分配策略
+const (
+	CustomerServiceStrategyRoundRobin = "roundRobin" // 轮询
+	CustomerServiceStrategyLeastBusy  = "leastBusy"  // 分配给当前接待会话最少的客服
+)
+
+// 客服会话变更的cmd类型
+const customerServiceCMDType = "customerService"
+
+// 客服会话变更的动作
+const (
+	customerServiceActionAssign   = "assign"
+	customerServiceActionTransfer = "transfer"
+	customerServiceActionClose    = "close"
+)
+
+var ErrCustomerServiceNoAgent = errors.New("没有可分配的客服！")
+
+// customerServiceManager 客服管理
+// 客服频道id格式为：访客uid|队列id，访客始终是频道的接收者，接待的客服是频道的订阅者。
+// 队列和队列下的会话通过队列id所在的槽复制，会话的打开、分配、转接、关闭都由队列所在槽的领导节点串行处理。
+type customerServiceManager struct {
+	s *Server
+	wklog.Log
+	queueLock *keylock.KeyLock // 按队列id串行处理会话变更
+	openC     chan string      // 需要打开会话的客服频道
+	stopper   *syncutil.Stopper
+	timer     *timingwheel.Timer
+	assigning atomic.Bool // 是否正在定时分配
+	channel   *ChannelAPI
+	user      *UserAPI
+}
+
+func newCustomerServiceManager(s *Server) *customerServiceManager {
+	return &customerServiceManager{
+		s:         s,
+		Log:       wklog.NewWKLog("customerServiceManager"),
+		openC:     make(chan string, 1024),
+		queueLock: keylock.NewKeyLock(),
+		stopper:   syncutil.NewStopper(),
+		channel:   NewChannelAPI(s),
+		user:      NewUserAPI(s),
+	}
+}
+
+func (m *customerServiceManager) start() {
+	m.queueLock.StartCleanLoop()
+	m.stopper.RunWorker(m.loop)
+	m.timer = m.s.Schedule(m.s.opts.CustomerService.AssignInterval, func() {
+		if !m.s.opts.CustomerService.On {
+			return
+		}
+		if !m.assigning.CompareAndSwap(false, true) { // 上一次还没处理完
+			return
+		}
+		defer m.assigning.Store(false)
+		m.assignAll()
+	})
+}
+
+func (m *customerServiceManager) stop() {
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	m.stopper.Stop()
+	m.queueLock.StopCleanLoop()
+}
+
+// parseCustomerServiceChannel 解析客服频道id，返回访客uid和队列id
+func parseCustomerServiceChannel(channelId string) (visitorUid string, queueId string, ok bool) {
+	visitorUid, queueId, ok = strings.Cut(channelId, "|")
+	if !ok || visitorUid == "" || queueId == "" {
+		return "", "", false
+	}
+	return visitorUid, queueId, true
+}
+
+// onVisitorMessages 客服频道的消息存储成功后，如果有访客发的消息，则在队列中打开会话
+func (m *customerServiceManager) onVisitorMessages(channelId string, messages []ReactorChannelMessage) {
+	if !m.s.opts.CustomerService.On || m.s.opts.IsCmdChannel(channelId) {
+		return
+	}
+	visitorUid, _, ok := parseCustomerServiceChannel(channelId)
+	if !ok {
+		return
+	}
+	fromVisitor := false
+	for _, msg := range messages {
+		if msg.ReasonCode == wkproto.ReasonSuccess && msg.FromUid == visitorUid {
+			fromVisitor = true
+			break
+		}
+	}
+	if !fromVisitor {
+		return
+	}
+	select {
+	case m.openC <- channelId:
+	case <-m.stopper.ShouldStop():
+	default:
+		m.Warn("customer service open queue is full, discard", zap.String("channelId", channelId))
+	}
+}
+
+func (m *customerServiceManager) loop() {
+	for {
+		select {
+		case channelId := <-m.openC:
+			m.openSessionOrForward(channelId)
+		case <-m.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+// openSessionOrForward 在队列所在槽的领导节点上打开会话
+func (m *customerServiceManager) openSessionOrForward(channelId string) {
+	_, queueId, _ := parseCustomerServiceChannel(channelId)
+	leaderInfo, err := m.s.cluster.SlotLeaderOfChannel(queueId, wkproto.ChannelTypeCustomerService)
+	if err != nil {
+		m.Error("get queue slot leader failed", zap.Error(err), zap.String("queueId", queueId))
+		return
+	}
+	if leaderInfo.Id == m.s.opts.Cluster.NodeId {
+		err = m.openSession(channelId)
+	} else {
+		err = m.s.requestCustomerServiceOpen(leaderInfo.Id, channelId)
+	}
+	if err != nil {
+		m.Error("open customer service session failed", zap.Error(err), zap.String("channelId", channelId))
+	}
+}
+
+// openSession 打开会话（会话已存在则忽略），然后尝试分配客服
+func (m *customerServiceManager) openSession(channelId string) error {
+	visitorUid, queueId, ok := parseCustomerServiceChannel(channelId)
+	if !ok {
+		return fmt.Errorf("invalid customer service channel: %s", channelId)
+	}
+	opened, err := m.openSessionLocked(queueId, channelId, visitorUid)
+	if err != nil || !opened {
+		return err
+	}
+	return m.assignWaiting(queueId)
+}
+
+func (m *customerServiceManager) openSessionLocked(queueId, channelId, visitorUid string) (bool, error) {
+	m.queueLock.Lock(queueId)
+	defer m.queueLock.Unlock(queueId)
+
+	if _, err := m.s.store.GetCustomerServiceQueue(queueId); err != nil {
+		if err == wkdb.ErrNotFound { // 没有配置队列的客服频道当普通频道处理
+			return false, nil
+		}
+		return false, err
+	}
+	sessions, err := m.s.store.GetCustomerServiceSessions(queueId)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := findCustomerServiceSession(sessions, channelId); ok {
+		return false, nil
+	}
+	session := wkdb.CustomerServiceSession{
+		QueueId:    queueId,
+		ChannelId:  channelId,
+		VisitorUid: visitorUid,
+		CreatedAt:  uint64(time.Now().UnixMilli()),
+	}
+	if err = m.s.store.AddOrUpdateCustomerServiceSessions(queueId, []wkdb.CustomerServiceSession{session}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// assignAll 为本节点负责的队列中排队的会话分配客服
+func (m *customerServiceManager) assignAll() {
+	queues, err := m.s.store.GetCustomerServiceQueues()
+	if err != nil {
+		m.Error("get customer service queues failed", zap.Error(err))
+		return
+	}
+	for _, queue := range queues {
+		isLeader, err := m.s.cluster.IsSlotLeaderOfChannel(queue.QueueId, wkproto.ChannelTypeCustomerService)
+		if err != nil {
+			m.Warn("get slot leader failed", zap.Error(err), zap.String("queueId", queue.QueueId))
+			continue
+		}
+		if !isLeader { // 由队列所在槽的领导节点负责分配
+			continue
+		}
+		if err = m.assignWaiting(queue.QueueId); err != nil {
+			m.Warn("assign customer service sessions failed", zap.Error(err), zap.String("queueId", queue.QueueId))
+		}
+	}
+}
+
+// assignWaiting 为队列中排队的会话分配客服
+// 在线客服的查询在加锁前完成，加锁后重新读取队列和会话进行分配，订阅者变更和通知在释放锁后进行
+func (m *customerServiceManager) assignWaiting(queueId string) error {
+	queue, err := m.s.store.GetCustomerServiceQueue(queueId)
+	if err != nil {
+		if err == wkdb.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	sessions, err := m.s.store.GetCustomerServiceSessions(queueId)
+	if err != nil {
+		return err
+	}
+	if !hasWaitingCustomerServiceSession(sessions) || len(queue.Agents) == 0 {
+		return nil
+	}
+	online, err := m.onlineAgents(queue.Agents)
+	if err != nil {
+		return err
+	}
+	changes, err := m.assignWaitingLocked(queueId, online)
+	m.publish(changes)
+	return err
+}
+
+func (m *customerServiceManager) assignWaitingLocked(queueId string, online map[string]bool) ([]customerServiceChange, error) {
+	m.queueLock.Lock(queueId)
+	defer m.queueLock.Unlock(queueId)
+
+	queue, err := m.s.store.GetCustomerServiceQueue(queueId)
+	if err != nil {
+		if err == wkdb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sessions, err := m.s.store.GetCustomerServiceSessions(queueId)
+	if err != nil {
+		return nil, err
+	}
+	load := newCustomerServiceAgentLoad(sessions)
+	assigned := make([]wkdb.CustomerServiceSession, 0)
+	assignedAt := uint64(time.Now().UnixMilli())
+	for _, session := range sessions {
+		if !session.Waiting() {
+			continue
+		}
+		agent := load.selectAgent(queue, online, "")
+		if agent == "" { // 没有可分配的客服，继续排队
+			break
+		}
+		load.assign(agent)
+		session.AgentUid = agent
+		session.AssignedAt = assignedAt
+		assigned = append(assigned, session)
+	}
+	if len(assigned) == 0 {
+		return nil, nil
+	}
+	if err = m.s.store.AddOrUpdateCustomerServiceSessions(queueId, assigned); err != nil {
+		return nil, err
+	}
+	changes := make([]customerServiceChange, 0, len(assigned))
+	for _, session := range assigned {
+		changes = append(changes, customerServiceChange{
+			action:  customerServiceActionAssign,
+			session: session,
+		})
+	}
+	return changes, nil
+}
+
+// transfer 转接会话，toAgent为空则按队列的分配策略选择其他客服
+func (m *customerServiceManager) transfer(channelId string, toAgent string, operator string) (wkdb.CustomerServiceSession, error) {
+	_, queueId, ok := parseCustomerServiceChannel(channelId)
+	if !ok {
+		return wkdb.CustomerServiceSession{}, errors.New("客服频道id格式有误！")
+	}
+	var online map[string]bool
+	if toAgent == "" {
+		queue, err := m.s.store.GetCustomerServiceQueue(queueId)
+		if err != nil {
+			return wkdb.CustomerServiceSession{}, err
+		}
+		online, err = m.onlineAgents(queue.Agents)
+		if err != nil {
+			return wkdb.CustomerServiceSession{}, err
+		}
+	}
+	change, err := m.transferLocked(queueId, channelId, toAgent, online, operator)
+	if err != nil {
+		return wkdb.CustomerServiceSession{}, err
+	}
+	if change.action != "" {
+		m.publish([]customerServiceChange{change})
+	}
+	return change.session, nil
+}
+
+func (m *customerServiceManager) transferLocked(queueId, channelId, toAgent string, online map[string]bool, operator string) (customerServiceChange, error) {
+	m.queueLock.Lock(queueId)
+	defer m.queueLock.Unlock(queueId)
+
+	queue, err := m.s.store.GetCustomerServiceQueue(queueId)
+	if err != nil {
+		return customerServiceChange{}, err
+	}
+	sessions, err := m.s.store.GetCustomerServiceSessions(queueId)
+	if err != nil {
+		return customerServiceChange{}, err
+	}
+	session, ok := findCustomerServiceSession(sessions, channelId)
+	if !ok {
+		return customerServiceChange{}, wkdb.ErrNotFound
+	}
+	fromAgent := session.AgentUid
+	if toAgent == "" {
+		toAgent = newCustomerServiceAgentLoad(sessions).selectAgent(queue, online, fromAgent)
+		if toAgent == "" {
+			return customerServiceChange{}, ErrCustomerServiceNoAgent
+		}
+	}
+	if toAgent == fromAgent { // 无需转接
+		return customerServiceChange{session: session}, nil
+	}
+	session.AgentUid = toAgent
+	session.AssignedAt = uint64(time.Now().UnixMilli())
+	if err = m.s.store.AddOrUpdateCustomerServiceSessions(queueId, []wkdb.CustomerServiceSession{session}); err != nil {
+		return customerServiceChange{}, err
+	}
+	return customerServiceChange{
+		action:    customerServiceActionTransfer,
+		session:   session,
+		fromAgent: fromAgent,
+		operator:  operator,
+	}, nil
+}
+
+// close 关闭会话，释放的接待名额分配给排队中的会话
+func (m *customerServiceManager) close(channelId string, operator string) error {
+	_, queueId, ok := parseCustomerServiceChannel(channelId)
+	if !ok {
+		return errors.New("客服频道id格式有误！")
+	}
+	change, err := m.closeLocked(queueId, channelId, operator)
+	if err != nil {
+		return err
+	}
+	m.publish([]customerServiceChange{change})
+	return m.assignWaiting(queueId)
+}
+
+func (m *customerServiceManager) closeLocked(queueId, channelId, operator string) (customerServiceChange, error) {
+	m.queueLock.Lock(queueId)
+	defer m.queueLock.Unlock(queueId)
+
+	sessions, err := m.s.store.GetCustomerServiceSessions(queueId)
+	if err != nil {
+		return customerServiceChange{}, err
+	}
+	session, ok := findCustomerServiceSession(sessions, channelId)
+	if !ok {
+		return customerServiceChange{}, wkdb.ErrNotFound
+	}
+	if err = m.s.store.RemoveCustomerServiceSession(queueId, channelId); err != nil {
+		return customerServiceChange{}, err
+	}
+	return customerServiceChange{
+		action:   customerServiceActionClose,
+		session:  session,
+		operator: operator,
+	}, nil
+}
+
+// saveQueue 添加或更新队列，然后尝试为排队中的会话分配客服（可能新增了客服）
+func (m *customerServiceManager) saveQueue(queue wkdb.CustomerServiceQueue) error {
+	m.queueLock.Lock(queue.QueueId)
+	err := m.s.store.AddOrUpdateCustomerServiceQueue(queue)
+	m.queueLock.Unlock(queue.QueueId)
+	if err != nil {
+		return err
+	}
+	return m.assignWaiting(queue.QueueId)
+}
+
+// deleteQueue 关闭队列下的所有会话并删除队列
+func (m *customerServiceManager) deleteQueue(queueId string, operator string) error {
+	changes, err := m.deleteQueueLocked(queueId, operator)
+	m.publish(changes)
+	return err
+}
+
+func (m *customerServiceManager) deleteQueueLocked(queueId string, operator string) ([]customerServiceChange, error) {
+	m.queueLock.Lock(queueId)
+	defer m.queueLock.Unlock(queueId)
+
+	sessions, err := m.s.store.GetCustomerServiceSessions(queueId)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]customerServiceChange, 0, len(sessions))
+	for _, session := range sessions {
+		if err = m.s.store.RemoveCustomerServiceSession(queueId, session.ChannelId); err != nil {
+			return changes, err
+		}
+		changes = append(changes, customerServiceChange{
+			action:   customerServiceActionClose,
+			session:  session,
+			operator: operator,
+		})
+	}
+	return changes, m.s.store.DeleteCustomerServiceQueue(queueId)
+}
+
+// customerServiceChange 已持久化的会话变更，释放队列锁后再变更频道订阅者和发送通知
+type customerServiceChange struct {
+	action    string
+	session   wkdb.CustomerServiceSession
+	fromAgent string // 转接前的客服
+	operator  string
+}
+
+// publish 根据会话变更更新客服频道的订阅者并通知，不能在持有队列锁时调用
+func (m *customerServiceManager) publish(changes []customerServiceChange) {
+	for _, change := range changes {
+		session := change.session
+		switch change.action {
+		case customerServiceActionAssign:
+			if err := m.updateSubscribers(session.ChannelId, []string{session.AgentUid}, nil); err != nil {
+				m.Error("add agent to customer service channel failed", zap.Error(err), zap.String("channelId", session.ChannelId), zap.String("agent", session.AgentUid))
+			}
+			m.notify(change.action, session, "", change.operator)
+		case customerServiceActionTransfer:
+			var removes []string
+			if change.fromAgent != "" {
+				removes = []string{change.fromAgent}
+			}
+			if err := m.updateSubscribers(session.ChannelId, []string{session.AgentUid}, removes); err != nil {
+				m.Error("update customer service channel subscribers failed", zap.Error(err), zap.String("channelId", session.ChannelId))
+			}
+			m.notify(change.action, session, change.fromAgent, change.operator)
+		case customerServiceActionClose:
+			// 先通知再移除客服，接待的客服也能收到关闭的通知
+			m.notify(change.action, session, "", change.operator)
+			if session.AgentUid != "" {
+				if err := m.updateSubscribers(session.ChannelId, nil, []string{session.AgentUid}); err != nil {
+					m.Error("remove agent from customer service channel failed", zap.Error(err), zap.String("channelId", session.ChannelId), zap.String("agent", session.AgentUid))
+				}
+			}
+		}
+	}
+}
+
+// onlineAgents 获取在线的客服
+func (m *customerServiceManager) onlineAgents(agents []string) (map[string]bool, error) {
+	var (
+		conns []*OnlinestatusResp
+		err   error
+	)
+	if m.s.opts.ClusterOn() {
+		conns, err = m.user.getOnlineConnsForCluster(agents)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		conns = m.user.getOnlineConns(agents)
+	}
+	online := make(map[string]bool, len(conns))
+	for _, conn := range conns {
+		if conn.Online == 1 {
+			online[conn.UID] = true
+		}
+	}
+	return online, nil
+}
+
+// updateSubscribers 在客服频道所在槽的领导节点上变更频道的订阅者
+func (m *customerServiceManager) updateSubscribers(channelId string, adds []string, removes []string) error {
+	req := customerServiceSubscribersReq{
+		ChannelId: channelId,
+		Adds:      adds,
+		Removes:   removes,
+	}
+	leaderInfo, err := m.s.cluster.SlotLeaderOfChannel(channelId, wkproto.ChannelTypeCustomerService)
+	if err != nil {
+		return err
+	}
+	if leaderInfo.Id == m.s.opts.Cluster.NodeId {
+		return m.updateSubscribersLocal(req)
+	}
+	return m.s.requestCustomerServiceSubscribers(leaderInfo.Id, req)
+}
+
+func (m *customerServiceManager) updateSubscribersLocal(req customerServiceSubscribersReq) error {
+	channelType := wkproto.ChannelTypeCustomerService
+	if len(req.Removes) > 0 {
+		if err := m.channel.removeSubscribers(req.ChannelId, channelType, req.Removes); err != nil {
+			return err
+		}
+	}
+	if len(req.Adds) == 0 {
+		return nil
+	}
+	exist, err := m.s.store.ExistChannel(req.ChannelId, channelType)
+	if err != nil {
+		return err
+	}
+	if !exist { // 如果没有频道则创建
+		if err = m.s.store.AddChannelInfo(wkdb.NewChannelInfo(req.ChannelId, channelType)); err != nil {
+			return err
+		}
+	}
+	return m.channel.addSubscriberWithReq(subscriberAddReq{
+		ChannelId:   req.ChannelId,
+		ChannelType: channelType,
+		Subscribers: req.Adds,
+	})
+}
+
+// notify 通过cmd消息通知客服频道的访客和接待的客服，并触发webhook
+func (m *customerServiceManager) notify(action string, session wkdb.CustomerServiceSession, fromAgent string, operator string) {
+	m.channel.notifyChannelCMD(session.ChannelId, wkproto.ChannelTypeCustomerService, map[string]interface{}{
+		"type":           customerServiceCMDType,
+		"action":         action,
+		"queue_id":       session.QueueId,
+		"channel_id":     session.ChannelId,
+		"channel_type":   wkproto.ChannelTypeCustomerService,
+		"visitor_uid":    session.VisitorUid,
+		"agent_uid":      session.AgentUid,
+		"from_agent_uid": fromAgent,
+		"operator":       operator,
+	})
+
+	var event string
+	switch action {
+	case customerServiceActionAssign:
+		event = EventCustomerServiceAssign
+	case customerServiceActionTransfer:
+		event = EventCustomerServiceTransfer
+	case customerServiceActionClose:
+		event = EventCustomerServiceClose
+	}
+	m.s.webhook.TriggerEvent(&Event{
+		Event: event,
+		Data: CustomerServiceNotify{
+			QueueId:      session.QueueId,
+			ChannelId:    session.ChannelId,
+			ChannelType:  wkproto.ChannelTypeCustomerService,
+			VisitorUid:   session.VisitorUid,
+			AgentUid:     session.AgentUid,
+			FromAgentUid: fromAgent,
+			Operator:     operator,
+			Timestamp:    time.Now().Unix(),
+		},
+	})
+}
+
+func hasWaitingCustomerServiceSession(sessions []wkdb.CustomerServiceSession) bool {
+	for _, session := range sessions {
+		if session.Waiting() {
+			return true
+		}
+	}
+	return false
+}
+
+func findCustomerServiceSession(sessions []wkdb.CustomerServiceSession, channelId string) (wkdb.CustomerServiceSession, bool) {
+	for _, session := range sessions {
+		if session.ChannelId == channelId {
+			return session, true
+		}
+	}
+	return wkdb.CustomerServiceSession{}, false
+}
+
+// customerServiceAgentLoad 客服的接待情况
+type customerServiceAgentLoad struct {
+	sessions  map[string]int // 客服当前接待的会话数量
+	lastAgent string         // 最近一次分配的客服
+}
+
+func newCustomerServiceAgentLoad(sessions []wkdb.CustomerServiceSession) *customerServiceAgentLoad {
+	load := &customerServiceAgentLoad{
+		sessions: make(map[string]int),
+	}
+	var lastAssignedAt uint64
+	for _, session := range sessions {
+		if session.Waiting() {
+			continue
+		}
+		load.sessions[session.AgentUid]++
+		if session.AssignedAt >= lastAssignedAt {
+			lastAssignedAt = session.AssignedAt
+			load.lastAgent = session.AgentUid
+		}
+	}
+	return load
+}
+
+func (l *customerServiceAgentLoad) assign(agent string) {
+	l.sessions[agent]++
+	l.lastAgent = agent
+}
+
+// selectAgent 按队列的分配策略从在线且未达到最大接待数量的客服中选择一个（排除exclude），没有可分配的客服返回空
+func (l *customerServiceAgentLoad) selectAgent(queue wkdb.CustomerServiceQueue, online map[string]bool, exclude string) string {
+	available := func(agent string) bool {
+		if agent == exclude || !online[agent] {
+			return false
+		}
+		return queue.MaxSessions == 0 || l.sessions[agent] < int(queue.MaxSessions)
+	}
+	if queue.Strategy == CustomerServiceStrategyLeastBusy {
+		selected := ""
+		for _, agent := range queue.Agents {
+			if available(agent) && (selected == "" || l.sessions[agent] < l.sessions[selected]) {
+				selected = agent
+			}
+		}
+		return selected
+	}
+	// 轮询：从最近一次分配的客服的下一个开始
+	start := 0
+	for i, agent := range queue.Agents {
+		if agent == l.lastAgent {
+			start = i + 1
+			break
+		}
+	}
+	for i := 0; i < len(queue.Agents); i++ {
+		agent := queue.Agents[(start+i)%len(queue.Agents)]
+		if available(agent) {
+			return agent
+		}
+	}
+	return ""
+}
+
+// CustomerServiceNotify 客服会话变更的webhook数据
+type CustomerServiceNotify struct {
+	QueueId      string `json:"queue_id"`                 // 队列id
+	ChannelId    string `json:"channel_id"`               // 客服频道id
+	ChannelType  uint8  `json:"channel_type"`             // 频道类型
+	VisitorUid   string `json:"visitor_uid"`              // 访客uid
+	AgentUid     string `json:"agent_uid"`                // 接待的客服uid
+	FromAgentUid string `json:"from_agent_uid,omitempty"` // 转接前的客服uid
+	Operator     string `json:"operator,omitempty"`       // 操作者
+	Timestamp    int64  `json:"timestamp"`                // 时间（10位时间戳）
+}
+
+type customerServiceSubscribersReq struct {
+	ChannelId string   `json:"channel_id"`
+	Adds      []string `json:"adds,omitempty"`    // 添加的订阅者
+	Removes   []string `json:"removes,omitempty"` // 移除的订阅者
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCustomerServiceChannel(t *testing.T) {
+	visitorUid, queueId, ok := parseCustomerServiceChannel("v1|q1")
+	assert.True(t, ok)
+	assert.Equal(t, "v1", visitorUid)
+	assert.Equal(t, "q1", queueId)
+
+	for _, channelId := range []string{"v1", "|q1", "v1|", ""} {
+		_, _, ok = parseCustomerServiceChannel(channelId)
+		assert.False(t, ok, channelId)
+	}
+}
+
+func TestCustomerServiceSelectAgentRoundRobin(t *testing.T) {
+	queue := wkdb.CustomerServiceQueue{
+		QueueId:     "q1",
+		Strategy:    CustomerServiceStrategyRoundRobin,
+		MaxSessions: 2,
+		Agents:      []string{"a1", "a2", "a3"},
+	}
+	online := map[string]bool{"a1": true, "a2": true, "a3": true}
+
+	// 从最近一次分配的客服的下一个开始
+	load := newCustomerServiceAgentLoad([]wkdb.CustomerServiceSession{
+		{ChannelId: "v1|q1", AgentUid: "a1", AssignedAt: 100},
+		{ChannelId: "v2|q1", AgentUid: "a2", AssignedAt: 200},
+		{ChannelId: "v3|q1"},
+	})
+	assert.Equal(t, "a3", load.selectAgent(queue, online, ""))
+	load.assign("a3")
+	assert.Equal(t, "a1", load.selectAgent(queue, online, ""))
+	load.assign("a1")
+
+	// a1达到最大接待数量，离线的客服不分配
+	online["a2"] = false
+	assert.Equal(t, "a3", load.selectAgent(queue, online, ""))
+	load.assign("a3")
+	assert.Equal(t, "", load.selectAgent(queue, online, ""))
+
+	// 排除转接前的客服
+	online["a2"] = true
+	assert.Equal(t, "", load.selectAgent(queue, online, "a2"))
+	assert.Equal(t, "a2", load.selectAgent(queue, online, ""))
+}
+
+func TestCustomerServiceSelectAgentLeastBusy(t *testing.T) {
+	queue := wkdb.CustomerServiceQueue{
+		QueueId:  "q1",
+		Strategy: CustomerServiceStrategyLeastBusy,
+		Agents:   []string{"a1", "a2", "a3"},
+	}
+	online := map[string]bool{"a1": true, "a2": true, "a3": true}
+
+	load := newCustomerServiceAgentLoad([]wkdb.CustomerServiceSession{
+		{ChannelId: "v1|q1", AgentUid: "a1", AssignedAt: 100},
+		{ChannelId: "v2|q1", AgentUid: "a1", AssignedAt: 200},
+		{ChannelId: "v3|q1", AgentUid: "a2", AssignedAt: 300},
+	})
+	assert.Equal(t, "a3", load.selectAgent(queue, online, ""))
+	load.assign("a3")
+	assert.Equal(t, "a2", load.selectAgent(queue, online, "")) // 接待数量相同时按列表顺序
+	assert.Equal(t, "a3", load.selectAgent(queue, online, "a2"))
+
+	online["a2"] = false
+	online["a3"] = false
+	assert.Equal(t, "a1", load.selectAgent(queue, online, ""))
+
+	assert.Equal(t, "", load.selectAgent(wkdb.CustomerServiceQueue{Strategy: CustomerServiceStrategyLeastBusy}, online, ""))
+	assert.Equal(t, "", load.selectAgent(wkdb.CustomerServiceQueue{}, online, ""))
+}
@@ -21,19 +21,31 @@ type IDatasource interface {
 	GetSystemUIDs() ([]string, error)
 	// 获取频道信息
 	GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error)
+	// 批量获取订阅者 返回 频道key（wkutil.ChannelToKey） -> 订阅者
+	GetSubscribersBatch(channels []wkdb.Channel) (map[string][]string, error)
+	// 批量获取频道信息
+	GetChannelInfoBatch(channels []wkdb.Channel) ([]wkdb.ChannelInfo, error)
 }
 
-// Datasource Datasource
+// Datasource 通过http请求第三方数据源
 type Datasource struct {
 	s *Server
 }
 
-// NewDatasource 创建一个数据源
+// NewDatasource 创建一个数据源，配置了grpc地址则使用grpc，开启了缓存则在外面包一层缓存
 func NewDatasource(s *Server) IDatasource {
-
-	return &Datasource{
-		s: s,
+	var ds IDatasource
+	if s.opts.DatasourceGRPCOn() {
+		ds = newGRPCDatasource(s)
+	} else {
+		ds = &Datasource{
+			s: s,
+		}
+	}
+	if s.opts.Datasource.CacheTTL > 0 && s.opts.Datasource.CacheSize > 0 {
+		ds = newCachedDatasource(ds, s.opts.Datasource.CacheSize, s.opts.Datasource.CacheTTL)
 	}
+	return ds
 }
 
 func (d *Datasource) GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error) {
@@ -52,8 +64,31 @@ func (d *Datasource) GetChannelInfo(channelID string, channelType uint8) (wkdb.C
 	channelInfo := channelInfoResp.ToChannelInfo()
 	channelInfo.ChannelId = channelID
 	channelInfo.ChannelType = channelType
-	return wkdb.EmptyChannelInfo, nil
+	return *channelInfo, nil
+
+}
 
+// GetChannelInfoBatch 批量获取频道信息
+func (d *Datasource) GetChannelInfoBatch(channels []wkdb.Channel) ([]wkdb.ChannelInfo, error) {
+	result, err := d.requestCMD("getChannelInfoBatch", map[string]interface{}{
+		"channels": channels,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resps []*channelInfoBatchResp
+	err = wkutil.ReadJSONByByte([]byte(result), &resps)
+	if err != nil {
+		return nil, err
+	}
+	channelInfos := make([]wkdb.ChannelInfo, 0, len(resps))
+	for _, resp := range resps {
+		channelInfo := resp.ToChannelInfo()
+		channelInfo.ChannelId = resp.ChannelId
+		channelInfo.ChannelType = resp.ChannelType
+		channelInfos = append(channelInfos, *channelInfo)
+	}
+	return channelInfos, nil
 }
 
 // GetSubscribers 获取频道的订阅者
@@ -74,6 +109,26 @@ func (d *Datasource) GetSubscribers(channelID string, channelType uint8) ([]stri
 	return subscribers, nil
 }
 
+// GetSubscribersBatch 批量获取频道的订阅者
+func (d *Datasource) GetSubscribersBatch(channels []wkdb.Channel) (map[string][]string, error) {
+	result, err := d.requestCMD("getSubscribersBatch", map[string]interface{}{
+		"channels": channels,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resps []*subscribersBatchResp
+	err = wkutil.ReadJSONByByte([]byte(result), &resps)
+	if err != nil {
+		return nil, err
+	}
+	subscribersMap := make(map[string][]string, len(resps))
+	for _, resp := range resps {
+		subscribersMap[wkutil.ChannelToKey(resp.ChannelId, resp.ChannelType)] = resp.Subscribers
+	}
+	return subscribersMap, nil
+}
+
 // GetBlacklist 获取频道的黑名单
 func (d *Datasource) GetBlacklist(channelID string, channelType uint8) ([]string, error) {
 
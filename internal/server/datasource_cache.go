@@ -0,0 +1,181 @@
+package server
+
+import (
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	datasourceCacheSubscribers = "subscribers:"
+	datasourceCacheBlacklist   = "blacklist:"
+	datasourceCacheWhitelist   = "whitelist:"
+	datasourceCacheChannelInfo = "channelInfo:"
+)
+
+type datasourceCacheItem struct {
+	value    interface{}
+	expireAt time.Time
+}
+
+// datasourceInvalidator 可以清除缓存的数据源
+type datasourceInvalidator interface {
+	// Invalidate 清除频道的缓存
+	Invalidate(channelId string, channelType uint8)
+	// InvalidateAll 清除所有缓存
+	InvalidateAll()
+}
+
+// cachedDatasource 带缓存的数据源，频道相关的数据按ttl缓存在lru中，系统账号由SystemUIDManager缓存
+type cachedDatasource struct {
+	ds    IDatasource
+	ttl   time.Duration
+	cache *lru.Cache[string, *datasourceCacheItem]
+	wklog.Log
+}
+
+func newCachedDatasource(ds IDatasource, size int, ttl time.Duration) *cachedDatasource {
+	cache, err := lru.New[string, *datasourceCacheItem](size)
+	if err != nil {
+		panic(err)
+	}
+	return &cachedDatasource{
+		ds:    ds,
+		ttl:   ttl,
+		cache: cache,
+		Log:   wklog.NewWKLog("cachedDatasource"),
+	}
+}
+
+func (c *cachedDatasource) GetSubscribers(channelID string, channelType uint8) ([]string, error) {
+	return c.getUids(datasourceCacheSubscribers, channelID, channelType, c.ds.GetSubscribers)
+}
+
+func (c *cachedDatasource) GetBlacklist(channelID string, channelType uint8) ([]string, error) {
+	return c.getUids(datasourceCacheBlacklist, channelID, channelType, c.ds.GetBlacklist)
+}
+
+func (c *cachedDatasource) GetWhitelist(channelID string, channelType uint8) ([]string, error) {
+	return c.getUids(datasourceCacheWhitelist, channelID, channelType, c.ds.GetWhitelist)
+}
+
+func (c *cachedDatasource) GetSystemUIDs() ([]string, error) {
+	return c.ds.GetSystemUIDs()
+}
+
+func (c *cachedDatasource) GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error) {
+	key := datasourceCacheChannelInfo + wkutil.ChannelToKey(channelID, channelType)
+	if v, ok := c.get(key); ok {
+		return v.(wkdb.ChannelInfo), nil
+	}
+	channelInfo, err := c.ds.GetChannelInfo(channelID, channelType)
+	if err != nil {
+		return channelInfo, err
+	}
+	c.set(key, channelInfo)
+	return channelInfo, nil
+}
+
+// GetSubscribersBatch 只请求缓存中没有的频道
+func (c *cachedDatasource) GetSubscribersBatch(channels []wkdb.Channel) (map[string][]string, error) {
+	subscribersMap := make(map[string][]string, len(channels))
+	misses := make([]wkdb.Channel, 0, len(channels))
+	for _, ch := range channels {
+		channelKey := wkutil.ChannelToKey(ch.ChannelId, ch.ChannelType)
+		if v, ok := c.get(datasourceCacheSubscribers + channelKey); ok {
+			subscribersMap[channelKey] = v.([]string)
+			continue
+		}
+		misses = append(misses, ch)
+	}
+	if len(misses) == 0 {
+		return subscribersMap, nil
+	}
+	results, err := c.ds.GetSubscribersBatch(misses)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range misses {
+		channelKey := wkutil.ChannelToKey(ch.ChannelId, ch.ChannelType)
+		subscribers := results[channelKey]
+		c.set(datasourceCacheSubscribers+channelKey, subscribers)
+		subscribersMap[channelKey] = subscribers
+	}
+	return subscribersMap, nil
+}
+
+// GetChannelInfoBatch 只请求缓存中没有的频道
+func (c *cachedDatasource) GetChannelInfoBatch(channels []wkdb.Channel) ([]wkdb.ChannelInfo, error) {
+	channelInfos := make([]wkdb.ChannelInfo, 0, len(channels))
+	misses := make([]wkdb.Channel, 0, len(channels))
+	for _, ch := range channels {
+		if v, ok := c.get(datasourceCacheChannelInfo + wkutil.ChannelToKey(ch.ChannelId, ch.ChannelType)); ok {
+			channelInfos = append(channelInfos, v.(wkdb.ChannelInfo))
+			continue
+		}
+		misses = append(misses, ch)
+	}
+	if len(misses) == 0 {
+		return channelInfos, nil
+	}
+	results, err := c.ds.GetChannelInfoBatch(misses)
+	if err != nil {
+		return nil, err
+	}
+	for _, channelInfo := range results {
+		c.set(datasourceCacheChannelInfo+wkutil.ChannelToKey(channelInfo.ChannelId, channelInfo.ChannelType), channelInfo)
+		channelInfos = append(channelInfos, channelInfo)
+	}
+	return channelInfos, nil
+}
+
+// Invalidate 清除频道的缓存
+func (c *cachedDatasource) Invalidate(channelId string, channelType uint8) {
+	channelKey := wkutil.ChannelToKey(channelId, channelType)
+	c.cache.Remove(datasourceCacheSubscribers + channelKey)
+	c.cache.Remove(datasourceCacheBlacklist + channelKey)
+	c.cache.Remove(datasourceCacheWhitelist + channelKey)
+	c.cache.Remove(datasourceCacheChannelInfo + channelKey)
+	c.Debug("invalidate", zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+}
+
+// InvalidateAll 清除所有缓存
+func (c *cachedDatasource) InvalidateAll() {
+	c.cache.Purge()
+}
+
+func (c *cachedDatasource) getUids(prefix string, channelID string, channelType uint8, load func(channelID string, channelType uint8) ([]string, error)) ([]string, error) {
+	key := prefix + wkutil.ChannelToKey(channelID, channelType)
+	if v, ok := c.get(key); ok {
+		return v.([]string), nil
+	}
+	uids, err := load(channelID, channelType)
+	if err != nil {
+		return nil, err
+	}
+	c.set(key, uids)
+	return uids, nil
+}
+
+func (c *cachedDatasource) get(key string) (interface{}, bool) {
+	item, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(item.expireAt) {
+		c.cache.Remove(key)
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (c *cachedDatasource) set(key string, value interface{}) {
+	c.cache.Add(key, &datasourceCacheItem{
+		value:    value,
+		expireAt: time.Now().Add(c.ttl),
+	})
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDatasource struct {
+	subscribers map[string][]string
+	disbands    map[string]bool
+	calls       int
+	batchCalls  int
+}
+
+func (t *testDatasource) GetSubscribers(channelID string, channelType uint8) ([]string, error) {
+	t.calls++
+	return t.subscribers[wkutil.ChannelToKey(channelID, channelType)], nil
+}
+
+func (t *testDatasource) GetBlacklist(channelID string, channelType uint8) ([]string, error) {
+	return nil, nil
+}
+
+func (t *testDatasource) GetWhitelist(channelID string, channelType uint8) ([]string, error) {
+	return nil, nil
+}
+
+func (t *testDatasource) GetSystemUIDs() ([]string, error) {
+	return nil, nil
+}
+
+func (t *testDatasource) GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error) {
+	return wkdb.NewChannelInfo(channelID, channelType), nil
+}
+
+func (t *testDatasource) GetSubscribersBatch(channels []wkdb.Channel) (map[string][]string, error) {
+	t.batchCalls++
+	results := make(map[string][]string)
+	for _, ch := range channels {
+		channelKey := wkutil.ChannelToKey(ch.ChannelId, ch.ChannelType)
+		results[channelKey] = t.subscribers[channelKey]
+	}
+	return results, nil
+}
+
+func (t *testDatasource) GetChannelInfoBatch(channels []wkdb.Channel) ([]wkdb.ChannelInfo, error) {
+	channelInfos := make([]wkdb.ChannelInfo, 0, len(channels))
+	for _, ch := range channels {
+		channelInfo := wkdb.NewChannelInfo(ch.ChannelId, ch.ChannelType)
+		channelInfo.Disband = t.disbands[wkutil.ChannelToKey(ch.ChannelId, ch.ChannelType)]
+		channelInfos = append(channelInfos, channelInfo)
+	}
+	return channelInfos, nil
+}
+
+func TestCachedDatasource(t *testing.T) {
+	ds := &testDatasource{
+		subscribers: map[string][]string{
+			wkutil.ChannelToKey("g1", 2): {"u1", "u2"},
+			wkutil.ChannelToKey("g2", 2): {"u3"},
+		},
+	}
+	cached := newCachedDatasource(ds, 100, time.Millisecond*100)
+
+	subscribers, err := cached.GetSubscribers("g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, subscribers)
+	_, _ = cached.GetSubscribers("g1", 2)
+	assert.Equal(t, 1, ds.calls)
+
+	// 批量获取只请求缓存中没有的频道
+	results, err := cached.GetSubscribersBatch([]wkdb.Channel{{ChannelId: "g1", ChannelType: 2}, {ChannelId: "g2", ChannelType: 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u3"}, results[wkutil.ChannelToKey("g2", 2)])
+	assert.Equal(t, 1, ds.batchCalls)
+	_, _ = cached.GetSubscribers("g2", 2)
+	assert.Equal(t, 1, ds.calls)
+
+	// 清除缓存后重新请求
+	ds.subscribers[wkutil.ChannelToKey("g1", 2)] = []string{"u1"}
+	cached.Invalidate("g1", 2)
+	subscribers, _ = cached.GetSubscribers("g1", 2)
+	assert.Equal(t, []string{"u1"}, subscribers)
+	assert.Equal(t, 2, ds.calls)
+
+	// 过期后重新请求
+	time.Sleep(time.Millisecond * 150)
+	_, _ = cached.GetSubscribers("g1", 2)
+	assert.Equal(t, 3, ds.calls)
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/grpcpool"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhook"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// grpcDatasource 通过grpc请求第三方数据源
+type grpcDatasource struct {
+	s    *Server
+	pool *grpcpool.Pool
+}
+
+func newGRPCDatasource(s *Server) *grpcDatasource {
+	pool, err := grpcpool.New(func() (*grpc.ClientConn, error) {
+		return grpc.Dial(s.opts.Datasource.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    5 * time.Minute,
+			Timeout: 2 * time.Second,
+		}))
+	}, 2, 20, time.Minute*5) // 初始化2个连接 最多20个连接
+	if err != nil {
+		panic(err)
+	}
+	return &grpcDatasource{
+		s:    s,
+		pool: pool,
+	}
+}
+
+func (d *grpcDatasource) GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error) {
+	var resp *wkhook.ChannelInfo
+	err := d.call(func(ctx context.Context, cli wkhook.DatasourceServiceClient) (err error) {
+		resp, err = cli.GetChannelInfo(ctx, &wkhook.ChannelReq{ChannelId: channelID, ChannelType: uint32(channelType)})
+		return
+	})
+	if err != nil {
+		return wkdb.EmptyChannelInfo, err
+	}
+	channelInfo := toChannelInfoFromPb(resp)
+	channelInfo.ChannelId = channelID
+	channelInfo.ChannelType = channelType
+	return channelInfo, nil
+}
+
+func (d *grpcDatasource) GetChannelInfoBatch(channels []wkdb.Channel) ([]wkdb.ChannelInfo, error) {
+	var resp *wkhook.ChannelInfoBatchResp
+	err := d.call(func(ctx context.Context, cli wkhook.DatasourceServiceClient) (err error) {
+		resp, err = cli.GetChannelInfoBatch(ctx, toChannelBatchReq(channels))
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	channelInfos := make([]wkdb.ChannelInfo, 0, len(resp.Channels))
+	for _, ch := range resp.Channels {
+		channelInfos = append(channelInfos, toChannelInfoFromPb(ch))
+	}
+	return channelInfos, nil
+}
+
+func (d *grpcDatasource) GetSubscribers(channelID string, channelType uint8) ([]string, error) {
+	return d.getUids(func(ctx context.Context, cli wkhook.DatasourceServiceClient) (*wkhook.UidsResp, error) {
+		return cli.GetSubscribers(ctx, &wkhook.ChannelReq{ChannelId: channelID, ChannelType: uint32(channelType)})
+	})
+}
+
+func (d *grpcDatasource) GetSubscribersBatch(channels []wkdb.Channel) (map[string][]string, error) {
+	var resp *wkhook.ChannelUidsBatchResp
+	err := d.call(func(ctx context.Context, cli wkhook.DatasourceServiceClient) (err error) {
+		resp, err = cli.GetSubscribersBatch(ctx, toChannelBatchReq(channels))
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	subscribersMap := make(map[string][]string, len(resp.Channels))
+	for _, ch := range resp.Channels {
+		subscribersMap[wkutil.ChannelToKey(ch.ChannelId, uint8(ch.ChannelType))] = ch.Uids
+	}
+	return subscribersMap, nil
+}
+
+func (d *grpcDatasource) GetBlacklist(channelID string, channelType uint8) ([]string, error) {
+	return d.getUids(func(ctx context.Context, cli wkhook.DatasourceServiceClient) (*wkhook.UidsResp, error) {
+		return cli.GetBlacklist(ctx, &wkhook.ChannelReq{ChannelId: channelID, ChannelType: uint32(channelType)})
+	})
+}
+
+func (d *grpcDatasource) GetWhitelist(channelID string, channelType uint8) ([]string, error) {
+	return d.getUids(func(ctx context.Context, cli wkhook.DatasourceServiceClient) (*wkhook.UidsResp, error) {
+		return cli.GetWhitelist(ctx, &wkhook.ChannelReq{ChannelId: channelID, ChannelType: uint32(channelType)})
+	})
+}
+
+func (d *grpcDatasource) GetSystemUIDs() ([]string, error) {
+	return d.getUids(func(ctx context.Context, cli wkhook.DatasourceServiceClient) (*wkhook.UidsResp, error) {
+		return cli.GetSystemUIDs(ctx, &wkhook.SystemUIDsReq{})
+	})
+}
+
+func (d *grpcDatasource) getUids(f func(ctx context.Context, cli wkhook.DatasourceServiceClient) (*wkhook.UidsResp, error)) ([]string, error) {
+	var resp *wkhook.UidsResp
+	err := d.call(func(ctx context.Context, cli wkhook.DatasourceServiceClient) (err error) {
+		resp, err = f(ctx, cli)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Uids, nil
+}
+
+func (d *grpcDatasource) call(f func(ctx context.Context, cli wkhook.DatasourceServiceClient) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+	clientConn, err := d.pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer clientConn.Close()
+
+	reqCtx, reqCancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer reqCancel()
+	return f(reqCtx, wkhook.NewDatasourceServiceClient(clientConn))
+}
+
+func toChannelBatchReq(channels []wkdb.Channel) *wkhook.ChannelBatchReq {
+	req := &wkhook.ChannelBatchReq{
+		Channels: make([]*wkhook.ChannelReq, 0, len(channels)),
+	}
+	for _, ch := range channels {
+		req.Channels = append(req.Channels, &wkhook.ChannelReq{ChannelId: ch.ChannelId, ChannelType: uint32(ch.ChannelType)})
+	}
+	return req
+}
+
+func toChannelInfoFromPb(ch *wkhook.ChannelInfo) wkdb.ChannelInfo {
+	return wkdb.ChannelInfo{
+		ChannelId:   ch.ChannelId,
+		ChannelType: uint8(ch.ChannelType),
+		Large:       ch.Large,
+		Ban:         ch.Ban,
+		Disband:     ch.Disband,
+	}
+}
@@ -57,6 +57,15 @@ func (d *deliverManager) stop() {
 	d.nodeManager.stop()
 }
 
+// pendingCount 等待投递的请求数量
+func (d *deliverManager) pendingCount() int {
+	count := 0
+	for _, deliverr := range d.deliverrs {
+		count += len(deliverr.reqC)
+	}
+	return count
+}
+
 func (d *deliverManager) deliver(req *deliverReq) {
 	d.handleDeliver(req)
 }
@@ -143,8 +152,8 @@ func (d *deliverr) handleDeliverReqs(req []*deliverReq) {
 }
 
 // 请求节点对应tag的用户集合
-func (d *deliverr) requestNodeChannelTag(nodeId uint64, req *tagReq) (*tagResp, error) {
-	timeoutCtx, cancel := context.WithTimeout(d.dm.s.ctx, time.Second*5)
+func (d *deliverr) requestNodeChannelTag(ctx context.Context, nodeId uint64, req *tagReq) (*tagResp, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 	data := req.Marshal()
 	resp, err := d.dm.s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/getNodeUidsByTag", data)
@@ -178,7 +187,11 @@ func (d *deliverr) handleDeliverReq(req *deliverReq) {
 				return
 			}
 		} else {
-			tagResp, err := d.requestNodeChannelTag(leader.Id, &tagReq{
+			var tagCtx context.Context // 请求的链路跟随第一条消息
+			if len(req.messages) > 0 {
+				tagCtx = req.messages[0].ctx
+			}
+			tagResp, err := d.requestNodeChannelTag(trace.ContextWithSpanOf(d.dm.s.ctx, tagCtx), leader.Id, &tagReq{
 				channelId:   req.channelId,
 				channelType: req.channelType,
 				tagKey:      req.tagKey,
@@ -244,7 +257,7 @@ func (d *deliverr) deliver(req *deliverReq, uids []string) {
 
 				d.Debug("deliver message to user", zap.Int64("messageId", message.MessageId), zap.String("uid", conn.uid), zap.String("deviceId", conn.deviceId), zap.Uint8("deviceFlag", uint8(conn.deviceFlag)), zap.Uint8("deviceLevel", uint8(conn.deviceLevel)), zap.Int64("connId", conn.connId), zap.String("channelId", req.channelId), zap.Uint8("channelType", req.channelType))
 
-				_, span := trace.GlobalTrace.StartSpan(message.ctx, "deliverMessage")
+				spanCtx, span := trace.GlobalTrace.StartSpan(message.ctx, "deliverMessage")
 
 				sendPacket := message.SendPacket
 
@@ -322,17 +335,22 @@ func (d *deliverr) deliver(req *deliverReq, uids []string) {
 				}
 
 				if !recvPacket.NoPersist { // 只有存储的消息才重试
+					// 等待客户端回执的链路，收到recvack或者重试结束时结束
+					_, recvackSpan := trace.GlobalTrace.StartSpan(spanCtx, "recvack")
+					recvackSpan.SetString("uid", toUid)
+					recvackSpan.SetInt64("connId", conn.connId)
 					d.dm.s.retryManager.addRetry(&retryMessage{
 						uid:            toUid,
 						connId:         conn.connId,
 						messageId:      message.MessageId,
 						recvPacketData: recvPacketData,
+						span:           recvackSpan,
 					})
 				}
 
 				// 写入包
 				// d.Info("deliverr recvPacket", zap.String("uid", conn.uid), zap.String("channelId", recvPacket.ChannelID), zap.Uint8("channelType", recvPacket.ChannelType))
-				err = conn.write(recvPacketData, wkproto.RECV)
+				err = conn.writeWithContext(spanCtx, recvPacketData, wkproto.RECV)
 				if err != nil {
 					span.RecordError(err)
 					d.Error("write recvPacket failed", zap.String("uid", conn.uid), zap.String("channelId", recvPacket.ChannelID), zap.Uint8("channelType", recvPacket.ChannelType), zap.Error(err))
@@ -365,6 +383,11 @@ func (d *deliverr) deliver(req *deliverReq, uids []string) {
 // 加密消息
 func encryptMessagePayload(payload []byte, conn *connContext) ([]byte, error) {
 	aesKey, aesIV := conn.aesKey, conn.aesIV
+	// 协商了压缩时先压缩再加密
+	payload, err := conn.compressPayload(payload)
+	if err != nil {
+		return nil, err
+	}
 	// 加密payload
 	payloadEnc, err := wkutil.AesEncryptPkcs7Base64(payload, []byte(aesKey), []byte(aesIV))
 	if err != nil {
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wknet"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap"
+)
+
+type drainState string
+
+const (
+	drainStateRunning  drainState = "running"  // 正常运行
+	drainStateDraining drainState = "draining" // 排空中
+	drainStateStopped  drainState = "stopped"  // 排空完成，服务已停止
+)
+
+// drainManager 节点排空
+// 排空依次执行：停止接收新连接、转移槽和频道领导、保存最近会话和处理重试队列、
+// 给客户端发送带重连提示的断开包，然后等待进行中的工作归零或超时后停止服务
+type drainManager struct {
+	s *Server
+	wklog.Log
+
+	mu        sync.Mutex
+	state     drainState
+	startedAt time.Time
+	timeout   time.Duration
+	targets   []*nodeAddrResp // 客户端重连的目标节点
+	doneC     chan struct{}
+}
+
+func newDrainManager(s *Server) *drainManager {
+	return &drainManager{
+		s:     s,
+		Log:   wklog.NewWKLog("drainManager"),
+		state: drainStateRunning,
+		doneC: make(chan struct{}),
+	}
+}
+
+// drain 开始排空，timeout为0时使用配置的超时时间
+func (d *drainManager) drain(timeout time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.state != drainStateRunning {
+		return errors.New("节点已经在排空中！")
+	}
+	if timeout <= 0 {
+		timeout = d.s.opts.Drain.Timeout
+	}
+	d.state = drainStateDraining
+	d.startedAt = time.Now()
+	d.timeout = timeout
+	go d.run()
+	return nil
+}
+
+// done 排空完成并停止服务后关闭
+func (d *drainManager) done() <-chan struct{} {
+	return d.doneC
+}
+
+func (d *drainManager) run() {
+	d.Info("start drain", zap.Duration("timeout", d.timeout))
+
+	// 停止接收新连接
+	d.s.engine.StopAccepting()
+
+	// 转移槽和频道领导
+	slotCount, channelCount := d.s.clusterServer.TransferAllLeaders()
+	d.Info("transfer leaders", zap.Int("slotCount", slotCount), zap.Int("channelCount", channelCount))
+
+	// 保存最近会话，立即重发等待回执的消息
+	d.s.conversationManager.Flush()
+	d.s.retryManager.flush()
+
+	// 断开客户端连接并提示重连到其他节点
+	d.disconnectAll()
+
+	// 等待进行中的工作归零
+	deadline := d.startedAt.Add(d.timeout)
+	for {
+		status := d.inFlight()
+		if status.total() == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			d.Warn("drain timeout", zap.Int("connCount", status.ConnCount), zap.Int("retryCount", status.RetryCount), zap.Int("deliverCount", status.DeliverCount), zap.Int("slotLeaderCount", status.SlotLeaderCount), zap.Int("channelLeaderCount", status.ChannelLeaderCount))
+			break
+		}
+		// 连接已断开的消息结束重试
+		d.s.retryManager.flush()
+		time.Sleep(d.s.opts.Drain.CheckInterval)
+	}
+	d.s.conversationManager.Flush()
+
+	d.Info("drain finished, stop server", zap.Duration("cost", time.Since(d.startedAt)))
+	d.s.StopNoErr()
+
+	d.mu.Lock()
+	d.state = drainStateStopped
+	d.mu.Unlock()
+	close(d.doneC)
+}
+
+// draining 是否在排空中（包括排空完成）
+func (d *drainManager) draining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state != drainStateRunning
+}
+
+// reconnectAddr 排空中时返回客户端应该重连的节点地址，没有可用节点时返回nil
+func (d *drainManager) reconnectAddr() *nodeAddrResp {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.state == drainStateRunning || len(d.targets) == 0 {
+		return nil
+	}
+	return d.targets[time.Now().UnixNano()%int64(len(d.targets))]
+}
+
+// disconnectAll 给所有客户端发送断开包，已认证的连接在断开包里带上其他节点的连接地址，客户端据此重连
+func (d *drainManager) disconnectAll() {
+	targets := d.reconnectTargets()
+	d.mu.Lock()
+	d.targets = targets
+	d.mu.Unlock()
+
+	i := 0
+	d.s.engine.Iterator(func(c wknet.Conn) bool {
+		if c.Context() == nil {
+			_ = c.Close()
+			return true
+		}
+		connCtx := c.Context().(*connContext)
+		if !connCtx.isAuth.Load() {
+			connCtx.close()
+			return true
+		}
+		packet := &wkproto.DisconnectPacket{
+			ReasonCode: wkproto.ReasonNodeNotMatch,
+		}
+		if len(targets) > 0 { // 轮流分配给其他节点
+			packet.Reason = targets[i%len(targets)].reason()
+			i++
+		}
+		_ = d.s.userReactor.writePacket(connCtx, packet)
+		d.s.timingWheel.AfterFunc(d.s.opts.Drain.DisconnectDelay, func() {
+			connCtx.close()
+		})
+		return true
+	})
+}
+
+// reconnectTargets 其他在线节点的连接地址
+func (d *drainManager) reconnectTargets() []*nodeAddrResp {
+	targets := make([]*nodeAddrResp, 0)
+	for _, node := range d.s.clusterServer.GetConfig().Nodes {
+		if node.Id == d.s.opts.Cluster.NodeId || !node.Online || !node.AllowVote {
+			continue
+		}
+		addr, err := d.s.requestNodeAddr(node.Id)
+		if err != nil {
+			d.Warn("request node addr failed", zap.Error(err), zap.Uint64("nodeId", node.Id))
+			continue
+		}
+		targets = append(targets, addr)
+	}
+	return targets
+}
+
+// inFlight 进行中的工作
+func (d *drainManager) inFlight() *drainStatusResp {
+	slotLeaderCount, channelLeaderCount := d.s.clusterServer.TransferableLeaderCount()
+	return &drainStatusResp{
+		ConnCount:          d.s.engine.ConnCount(),
+		RetryCount:         d.s.retryManager.inFlightCount(),
+		DeliverCount:       d.s.deliverManager.pendingCount(),
+		SlotLeaderCount:    slotLeaderCount,
+		ChannelLeaderCount: channelLeaderCount,
+	}
+}
+
+func (d *drainManager) status() *drainStatusResp {
+	d.mu.Lock()
+	state := d.state
+	startedAt := d.startedAt
+	timeout := d.timeout
+	d.mu.Unlock()
+
+	resp := &drainStatusResp{
+		NodeId: d.s.opts.Cluster.NodeId,
+		State:  string(state),
+	}
+	if state == drainStateRunning {
+		return resp
+	}
+	resp.StartedAt = startedAt.UnixMilli()
+	resp.Timeout = timeout.String()
+	if state == drainStateDraining {
+		inFlight := d.inFlight()
+		inFlight.NodeId = resp.NodeId
+		inFlight.State = resp.State
+		inFlight.StartedAt = resp.StartedAt
+		inFlight.Timeout = resp.Timeout
+		return inFlight
+	}
+	return resp
+}
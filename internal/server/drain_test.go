@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainReqTimeout(t *testing.T) {
+	timeout, err := drainReq{}.timeout()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), timeout)
+
+	timeout, err = drainReq{Timeout: "30s"}.timeout()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second*30, timeout)
+
+	_, err = drainReq{Timeout: "abc"}.timeout()
+	assert.Error(t, err)
+
+	_, err = drainReq{Timeout: "-1s"}.timeout()
+	assert.Error(t, err)
+}
+
+func TestDrainStatusTotal(t *testing.T) {
+	status := &drainStatusResp{}
+	assert.Equal(t, 0, status.total())
+
+	status = &drainStatusResp{ConnCount: 2, RetryCount: 1, SlotLeaderCount: 3}
+	assert.Equal(t, 6, status.total())
+}
+
+func TestNodeAddrReason(t *testing.T) {
+	addr := &nodeAddrResp{NodeId: 2, TCPAddr: "127.0.0.1:5100", WSAddr: "ws://127.0.0.1:5200"}
+
+	var result nodeAddrResp
+	err := json.Unmarshal([]byte(addr.reason()), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, *addr, result)
+}
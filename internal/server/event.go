@@ -0,0 +1,263 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap"
+)
+
+// settingEvent 临时事件在RecvPacket设置里的标记位（wkproto未使用的位），客户端据此区分临时事件和普通的不存储消息
+const settingEvent wkproto.Setting = 1 << 6
+
+// eventManager 临时事件管理（比如正在输入，音视频信令等）
+// 临时事件不存储、不更新最近会话、不重试、不触发离线webhook，只投递给当前在线的连接
+type eventManager struct {
+	s *Server
+	wklog.Log
+	rateLimiter *eventRateLimiter
+}
+
+func newEventManager(s *Server) *eventManager {
+	return &eventManager{
+		s:           s,
+		Log:         wklog.NewWKLog("eventManager"),
+		rateLimiter: newEventRateLimiter(s.opts.Event.RateLimitPerSecond),
+	}
+}
+
+// allow 发送者是否允许在频道内发送事件（在频道所在槽的领导节点上统计）
+func (e *eventManager) allow(fromUid string, channelId string, channelType uint8) bool {
+	return e.rateLimiter.allow(fmt.Sprintf("%s@%s-%d", fromUid, channelId, channelType))
+}
+
+// send 将事件投递给指定的用户（按用户所在的领导节点分组投递）
+func (e *eventManager) send(ev *ephemeralEvent, uids []string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	if !e.s.opts.ClusterOn() {
+		e.deliver(ev, uids)
+		return nil
+	}
+	nodeUidsMap := make(map[uint64][]string)
+	for _, uid := range uids {
+		leaderInfo, err := e.s.cluster.SlotLeaderOfChannel(uid, wkproto.ChannelTypePerson)
+		if err != nil {
+			e.Error("获取用户所在节点失败！", zap.Error(err), zap.String("uid", uid))
+			return err
+		}
+		nodeUidsMap[leaderInfo.Id] = append(nodeUidsMap[leaderInfo.Id], uid)
+	}
+	for nodeId, nodeUids := range nodeUidsMap {
+		if nodeId == e.s.opts.Cluster.NodeId {
+			e.deliver(ev, nodeUids)
+			continue
+		}
+		// 临时事件不重试，请求失败直接丢弃
+		go e.requestDeliver(nodeId, &ephemeralEventReq{
+			event: ev,
+			uids:  nodeUids,
+		})
+	}
+	return nil
+}
+
+func (e *eventManager) requestDeliver(nodeId uint64, req *ephemeralEventReq) {
+	data, err := req.Marshal()
+	if err != nil {
+		e.Error("ephemeralEventReq marshal failed", zap.Error(err))
+		return
+	}
+	timeout := e.s.opts.Event.MaxDelay
+	if timeout <= 0 {
+		timeout = e.s.opts.Cluster.ReqTimeout
+	}
+	timeoutCtx, cancel := context.WithTimeout(e.s.ctx, timeout)
+	defer cancel()
+	resp, err := e.s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/deliverEvent", data)
+	if err != nil {
+		e.Warn("request deliver event failed", zap.Error(err), zap.Uint64("nodeId", nodeId))
+		return
+	}
+	if resp.Status != proto.Status_OK {
+		e.Warn("request deliver event failed", zap.Uint64("nodeId", nodeId), zap.Int("status", int(resp.Status)), zap.String("err", string(resp.Body)))
+	}
+}
+
+// deliver 投递事件给本节点上的在线用户
+func (e *eventManager) deliver(ev *ephemeralEvent, uids []string) {
+	if ev.isExpired(e.s.opts.Event.MaxDelay) {
+		e.Debug("event is expired, drop it", zap.String("fromUid", ev.fromUid), zap.String("channelId", ev.channelId), zap.Uint8("channelType", ev.channelType))
+		return
+	}
+
+	for _, toUid := range uids {
+		userHandler := e.s.userReactor.getUser(toUid)
+		if userHandler == nil { // 用户不在线，直接丢弃
+			continue
+		}
+		for _, conn := range userHandler.getConns() {
+			recvPacket := newEventRecvPacket(ev, toUid)
+
+			payloadEnc, err := encryptMessagePayload(recvPacket.Payload, conn)
+			if err != nil {
+				e.Error("加密payload失败！", zap.Error(err))
+				continue
+			}
+			recvPacket.Payload = payloadEnc
+
+			msgKey, err := makeMsgKey(recvPacket.VerityString(), conn)
+			if err != nil {
+				e.Error("生成MsgKey失败！", zap.Error(err))
+				continue
+			}
+			recvPacket.MsgKey = msgKey
+
+			data, err := e.s.opts.Proto.EncodeFrame(recvPacket, conn.protoVersion)
+			if err != nil {
+				e.Error("encode recvPacket failed", zap.Error(err), zap.String("uid", conn.uid))
+				continue
+			}
+			// 临时事件写入失败不关闭连接，也不重试
+			if err = conn.write(data, wkproto.RECV); err != nil {
+				e.Debug("write event failed", zap.Error(err), zap.String("uid", conn.uid), zap.Int64("connId", conn.connId))
+			}
+		}
+	}
+}
+
+// newEventRecvPacket 生成投递给接收者的临时事件包（不存储，设置了临时事件标记位）
+func newEventRecvPacket(ev *ephemeralEvent, toUid string) *wkproto.RecvPacket {
+	recvPacket := &wkproto.RecvPacket{
+		Framer: wkproto.Framer{
+			NoPersist: true,
+		},
+		Setting:     settingEvent,
+		ClientMsgNo: ev.clientMsgNo,
+		FromUID:     ev.fromUid,
+		ChannelID:   ev.channelId,
+		ChannelType: ev.channelType,
+		Timestamp:   int32(ev.timestamp / 1000),
+		Payload:     ev.payload,
+	}
+	// 个人频道，接收者收到的channelID应该是发送者
+	if recvPacket.ChannelType == wkproto.ChannelTypePerson && recvPacket.ChannelID == toUid {
+		recvPacket.ChannelID = recvPacket.FromUID
+	}
+	return recvPacket
+}
+
+// ephemeralEvent 临时事件
+type ephemeralEvent struct {
+	fromUid     string
+	channelId   string
+	channelType uint8
+	clientMsgNo string
+	timestamp   int64 // 事件产生时间（13位时间戳）
+	payload     []byte
+}
+
+// isExpired 事件产生后超过maxDelay还没投递，则认为已过期
+func (e *ephemeralEvent) isExpired(maxDelay time.Duration) bool {
+	if maxDelay <= 0 {
+		return false
+	}
+	return time.Now().UnixMilli()-e.timestamp > maxDelay.Milliseconds()
+}
+
+type ephemeralEventReq struct {
+	event *ephemeralEvent
+	uids  []string
+}
+
+func (r *ephemeralEventReq) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteString(r.event.fromUid)
+	enc.WriteString(r.event.channelId)
+	enc.WriteUint8(r.event.channelType)
+	enc.WriteString(r.event.clientMsgNo)
+	enc.WriteInt64(r.event.timestamp)
+	enc.WriteBinary(r.event.payload)
+	enc.WriteUint32(uint32(len(r.uids)))
+	for _, uid := range r.uids {
+		enc.WriteString(uid)
+	}
+	return enc.Bytes(), nil
+}
+
+func (r *ephemeralEventReq) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	r.event = &ephemeralEvent{}
+	if r.event.fromUid, err = dec.String(); err != nil {
+		return err
+	}
+	if r.event.channelId, err = dec.String(); err != nil {
+		return err
+	}
+	if r.event.channelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	if r.event.clientMsgNo, err = dec.String(); err != nil {
+		return err
+	}
+	if r.event.timestamp, err = dec.Int64(); err != nil {
+		return err
+	}
+	if r.event.payload, err = dec.Binary(); err != nil {
+		return err
+	}
+	var count uint32
+	if count, err = dec.Uint32(); err != nil {
+		return err
+	}
+	for i := 0; i < int(count); i++ {
+		uid, err := dec.String()
+		if err != nil {
+			return err
+		}
+		r.uids = append(r.uids, uid)
+	}
+	return nil
+}
+
+// eventRateLimiter 按key（发送者@频道）限制每秒发送事件的数量
+type eventRateLimiter struct {
+	mu       sync.Mutex
+	limit    int            // 每秒最大事件数量 0表示不限制
+	second   int64          // 当前统计的秒
+	countMap map[string]int // 每个key在当前秒内已发送的事件数量
+}
+
+func newEventRateLimiter(limit int) *eventRateLimiter {
+	return &eventRateLimiter{
+		limit:    limit,
+		countMap: make(map[string]int),
+	}
+}
+
+func (l *eventRateLimiter) allow(key string) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now().Unix()
+	if now != l.second {
+		l.second = now
+		l.countMap = make(map[string]int)
+	}
+	count := l.countMap[key]
+	if count >= l.limit {
+		return false
+	}
+	l.countMap[key] = count + 1
+	return true
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEphemeralEventReqMarshal(t *testing.T) {
+	req := &ephemeralEventReq{
+		event: &ephemeralEvent{
+			fromUid:     "u1",
+			channelId:   "g1",
+			channelType: 2,
+			clientMsgNo: "no1",
+			timestamp:   time.Now().UnixMilli(),
+			payload:     []byte("typing"),
+		},
+		uids: []string{"u2", "u3"},
+	}
+	data, err := req.Marshal()
+	assert.Nil(t, err)
+
+	newReq := &ephemeralEventReq{}
+	err = newReq.Unmarshal(data)
+	assert.Nil(t, err)
+	assert.Equal(t, req.event, newReq.event)
+	assert.Equal(t, req.uids, newReq.uids)
+}
+
+func TestEphemeralEventExpired(t *testing.T) {
+	ev := &ephemeralEvent{
+		timestamp: time.Now().Add(-time.Second).UnixMilli(),
+	}
+	assert.True(t, ev.isExpired(time.Millisecond*500))
+	assert.False(t, ev.isExpired(time.Second*5))
+	assert.False(t, ev.isExpired(0))
+}
+
+func TestEventRateLimiter(t *testing.T) {
+	limiter := newEventRateLimiter(2)
+	limiter.second = time.Now().Unix()
+	assert.True(t, limiter.allow("u1"))
+	assert.True(t, limiter.allow("u1"))
+	if limiter.second == time.Now().Unix() { // 同一秒内超过限制
+		assert.False(t, limiter.allow("u1"))
+	}
+	assert.True(t, newEventRateLimiter(0).allow("u1"))
+}
+
+func TestEventRecvPacketSetting(t *testing.T) {
+	ev := &ephemeralEvent{
+		fromUid:     "u1",
+		channelId:   "u2",
+		channelType: wkproto.ChannelTypePerson,
+		clientMsgNo: "no1",
+		timestamp:   time.Now().UnixMilli(),
+		payload:     []byte("typing"),
+	}
+	recvPacket := newEventRecvPacket(ev, "u2")
+	assert.Equal(t, "u1", recvPacket.ChannelID) // 个人频道接收者收到的是发送者
+
+	// 临时事件标记位需要编码到包里
+	proto := wkproto.New()
+	data, err := proto.EncodeFrame(recvPacket, wkproto.LatestVersion)
+	assert.Nil(t, err)
+	frame, _, err := proto.DecodeFrame(data, wkproto.LatestVersion)
+	assert.Nil(t, err)
+	newRecvPacket := frame.(*wkproto.RecvPacket)
+	assert.True(t, newRecvPacket.Setting.IsSet(settingEvent))
+	assert.True(t, newRecvPacket.NoPersist)
+	assert.Equal(t, "no1", newRecvPacket.ClientMsgNo)
+}
+
+func TestEventManagerAllowPerChannel(t *testing.T) {
+	opts := NewOptions()
+	opts.Event.RateLimitPerSecond = 1
+	em := newEventManager(&Server{opts: opts})
+	em.rateLimiter.second = time.Now().Unix()
+	assert.True(t, em.allow("u1", "g1", 2))
+	assert.True(t, em.allow("u1", "g2", 2)) // 不同频道分别统计
+	if em.rateLimiter.second == time.Now().Unix() {
+		assert.False(t, em.allow("u1", "g1", 2))
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+)
+
+var mentionKey = []byte(`"mention"`)
+
+// MessageMention 消息的@信息（与客户端sdk约定放在payload的mention字段内）
+type MessageMention struct {
+	All  int      `json:"all,omitempty"`  // 是否@所有人 1.是
+	Uids []string `json:"uids,omitempty"` // @的用户
+}
+
+// IsEmpty 是否没有@任何人
+func (m *MessageMention) IsEmpty() bool {
+	return m == nil || (m.All != 1 && len(m.Uids) == 0)
+}
+
+// Contains 是否@了指定用户
+func (m *MessageMention) Contains(uid string) bool {
+	if m.IsEmpty() {
+		return false
+	}
+	if m.All == 1 {
+		return true
+	}
+	for _, u := range m.Uids {
+		if u == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter 获取uids中被@的用户
+func (m *MessageMention) Filter(uids []string) []string {
+	if m.IsEmpty() {
+		return nil
+	}
+	if m.All == 1 {
+		return uids
+	}
+	mentionUids := make([]string, 0, len(m.Uids))
+	for _, uid := range uids {
+		if m.Contains(uid) {
+			mentionUids = append(mentionUids, uid)
+		}
+	}
+	return mentionUids
+}
+
+// parseMessageMention 从payload中解析@信息，payload不是json或者没有@信息返回nil
+func parseMessageMention(payload []byte) *MessageMention {
+	if len(payload) == 0 || !bytes.Contains(payload, mentionKey) {
+		return nil
+	}
+	var content struct {
+		Mention *MessageMention `json:"mention"`
+	}
+	if err := json.Unmarshal(payload, &content); err != nil {
+		return nil
+	}
+	if content.Mention.IsEmpty() {
+		return nil
+	}
+	return content.Mention
+}
+
+// payloadWithMention 将@信息写入到payload的mention字段内
+func payloadWithMention(payload []byte, mention *MessageMention) ([]byte, error) {
+	if mention.IsEmpty() {
+		return payload, nil
+	}
+	var content map[string]interface{}
+	if err := json.Unmarshal(payload, &content); err != nil || content == nil {
+		return nil, errors.New("payload必须是json对象才能设置mention！")
+	}
+	content["mention"] = mention
+	return json.Marshal(content)
+}
+
+func encodeMessageMention(enc *wkproto.Encoder, mention *MessageMention) {
+	if mention.IsEmpty() {
+		enc.WriteUint8(0)
+		enc.WriteUint32(0)
+		return
+	}
+	enc.WriteUint8(uint8(mention.All))
+	enc.WriteUint32(uint32(len(mention.Uids)))
+	for _, uid := range mention.Uids {
+		enc.WriteString(uid)
+	}
+}
+
+func decodeMessageMention(dec *wkproto.Decoder) (*MessageMention, error) {
+	all, err := dec.Uint8()
+	if err != nil {
+		return nil, err
+	}
+	count, err := dec.Uint32()
+	if err != nil {
+		return nil, err
+	}
+	if all != 1 && count == 0 {
+		return nil, nil
+	}
+	mention := &MessageMention{All: int(all)}
+	for i := uint32(0); i < count; i++ {
+		uid, err := dec.String()
+		if err != nil {
+			return nil, err
+		}
+		mention.Uids = append(mention.Uids, uid)
+	}
+	return mention, nil
+}
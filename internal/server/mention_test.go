@@ -0,0 +1,39 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMessageMention(t *testing.T) {
+	assert.Nil(t, parseMessageMention([]byte("hello")))
+	assert.Nil(t, parseMessageMention([]byte(`{"content":"hello"}`)))
+	assert.Nil(t, parseMessageMention([]byte(`{"content":"hello","mention":{}}`)))
+
+	mention := parseMessageMention([]byte(`{"content":"@u1","mention":{"uids":["u1","u2"]}}`))
+	assert.NotNil(t, mention)
+	assert.True(t, mention.Contains("u1"))
+	assert.False(t, mention.Contains("u3"))
+	assert.Equal(t, []string{"u2"}, mention.Filter([]string{"u2", "u3"}))
+
+	mention = parseMessageMention([]byte(`{"content":"@all","mention":{"all":1}}`))
+	assert.NotNil(t, mention)
+	assert.True(t, mention.Contains("u3"))
+	assert.Equal(t, []string{"u2", "u3"}, mention.Filter([]string{"u2", "u3"}))
+}
+
+func TestPayloadWithMention(t *testing.T) {
+	payload, err := payloadWithMention([]byte(`{"content":"@u1"}`), &MessageMention{Uids: []string{"u1"}})
+	assert.NoError(t, err)
+	mention := parseMessageMention(payload)
+	assert.NotNil(t, mention)
+	assert.Equal(t, []string{"u1"}, mention.Uids)
+
+	_, err = payloadWithMention([]byte("hello"), &MessageMention{All: 1})
+	assert.Error(t, err)
+
+	payload, err = payloadWithMention([]byte("hello"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), payload)
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap"
+)
+
+// messageDedupManager 消息去重管理
+// 频道领导存储消息时按(发送者, clientMsgNo)去重，客户端超时重试或业务端重复调用发送的消息不再存储和投递，
+// 发送回执返回原消息的id和seq。去重直接查询频道的消息日志（clientMsgNo索引），消息日志在频道的所有副本上都有，
+// 频道领导切换后依然有效，也不需要额外的提案
+type messageDedupManager struct {
+	s *Server
+	wklog.Log
+
+	mu      sync.Mutex
+	waiters map[string][]chan *wkproto.SendackPacket // 等待发送回执的请求（/message/send），key为 发送者@clientMsgNo
+}
+
+func newMessageDedupManager(s *Server) *messageDedupManager {
+	return &messageDedupManager{
+		s:       s,
+		Log:     wklog.NewWKLog("messageDedupManager"),
+		waiters: make(map[string][]chan *wkproto.SendackPacket),
+	}
+}
+
+// markDuplicates 标记重复的消息，返回同一批次内重复的消息下标和它第一次出现的下标
+// 已经存储过的消息直接取原消息的id和seq，同一批次内重复的消息需要等第一条消息存储后再取（见fillBatchDuplicates）
+func (m *messageDedupManager) markDuplicates(channelId string, channelType uint8, messages []ReactorChannelMessage) map[int]int {
+	if !m.s.opts.MessageDedup.On {
+		return nil
+	}
+	var (
+		batchDuplicates map[int]int
+		firsts          map[string]int
+		expireAt        = time.Now().Add(-m.s.opts.MessageDedup.Window).Unix()
+	)
+	for i, msg := range messages {
+		if !needDedup(msg) {
+			continue
+		}
+		dedupKey := messageDedupKey(msg.FromUid, msg.SendPacket.ClientMsgNo)
+		if first, ok := firsts[dedupKey]; ok {
+			if batchDuplicates == nil {
+				batchDuplicates = make(map[int]int)
+			}
+			messages[i].DuplicateOf = messages[first].MessageId
+			batchDuplicates[i] = first
+			continue
+		}
+		originMsg, err := m.s.store.LoadMsgByClientMsgNo(channelId, channelType, msg.FromUid, msg.SendPacket.ClientMsgNo)
+		if err != nil && err != wkdb.ErrNotFound {
+			m.Warn("load msg by clientMsgNo failed", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType), zap.String("clientMsgNo", msg.SendPacket.ClientMsgNo))
+		}
+		if err == nil && int64(originMsg.Timestamp) >= expireAt {
+			m.Debug("duplicate message", zap.String("fromUid", msg.FromUid), zap.String("clientMsgNo", msg.SendPacket.ClientMsgNo), zap.Int64("originMessageId", originMsg.MessageID))
+			messages[i].DuplicateOf = originMsg.MessageID
+			messages[i].MessageSeq = uint32(originMsg.MessageSeq)
+			continue
+		}
+		if firsts == nil {
+			firsts = make(map[string]int)
+		}
+		firsts[dedupKey] = i
+	}
+	return batchDuplicates
+}
+
+// fillBatchDuplicates 消息存储成功后给同一批次内重复的消息赋值原消息的seq
+func (m *messageDedupManager) fillBatchDuplicates(messages []ReactorChannelMessage, batchDuplicates map[int]int) {
+	for i, first := range batchDuplicates {
+		messages[i].MessageSeq = messages[first].MessageSeq
+	}
+}
+
+// waitSendack 等待消息的发送回执，需要在提案消息之前调用，返回的cancel在等待结束后必须调用
+func (m *messageDedupManager) waitSendack(fromUid string, clientMsgNo string) (<-chan *wkproto.SendackPacket, func()) {
+	key := messageDedupKey(fromUid, clientMsgNo)
+	ch := make(chan *wkproto.SendackPacket, 1)
+	m.mu.Lock()
+	m.waiters[key] = append(m.waiters[key], ch)
+	m.mu.Unlock()
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		waiters := m.waiters[key]
+		for i, waiter := range waiters {
+			if waiter == ch {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(waiters) == 0 {
+			delete(m.waiters, key)
+		} else {
+			m.waiters[key] = waiters
+		}
+	}
+}
+
+// notifySendack 通知等待发送回执的请求，没有等待的请求返回false
+func (m *messageDedupManager) notifySendack(fromUid string, sendack *wkproto.SendackPacket) bool {
+	key := messageDedupKey(fromUid, sendack.ClientMsgNo)
+	m.mu.Lock()
+	waiters := m.waiters[key]
+	delete(m.waiters, key)
+	m.mu.Unlock()
+	for _, waiter := range waiters {
+		waiter <- sendack
+	}
+	return len(waiters) > 0
+}
+
+func messageDedupKey(fromUid string, clientMsgNo string) string {
+	return fromUid + "@" + clientMsgNo
+}
+
+// needDedup 消息是否需要去重，只有需要存储并且带了clientMsgNo的消息才去重（不存储的消息没有seq，重复了也无法返回原消息）
+func needDedup(msg ReactorChannelMessage) bool {
+	if msg.ReasonCode != wkproto.ReasonSuccess || msg.IsEncrypt {
+		return false
+	}
+	if msg.SendPacket == nil || msg.SendPacket.NoPersist || msg.SendPacket.ClientMsgNo == "" {
+		return false
+	}
+	return true
+}
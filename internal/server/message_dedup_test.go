@@ -0,0 +1,117 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedDedup(t *testing.T) {
+	msg := ReactorChannelMessage{
+		FromUid:    "u1",
+		ReasonCode: wkproto.ReasonSuccess,
+		SendPacket: &wkproto.SendPacket{ClientMsgNo: "c1"},
+	}
+	assert.True(t, needDedup(msg))
+
+	// 没有clientMsgNo的消息不去重
+	msg.SendPacket = &wkproto.SendPacket{}
+	assert.False(t, needDedup(msg))
+
+	// 不存储的消息不去重
+	msg.SendPacket = &wkproto.SendPacket{Framer: wkproto.Framer{NoPersist: true}, ClientMsgNo: "c1"}
+	assert.False(t, needDedup(msg))
+
+	// 没有发送权限的消息不去重
+	msg.SendPacket = &wkproto.SendPacket{ClientMsgNo: "c1"}
+	msg.ReasonCode = wkproto.ReasonNotAllowSend
+	assert.False(t, needDedup(msg))
+}
+
+func TestMessageDedupMarkDuplicates(t *testing.T) {
+	s := NewTestServer(t)
+	err := s.store.Open()
+	assert.NoError(t, err)
+	defer s.store.Close()
+
+	messages := []ReactorChannelMessage{
+		{FromUid: "u1", MessageId: 1, ReasonCode: wkproto.ReasonSuccess, SendPacket: &wkproto.SendPacket{ClientMsgNo: "c1"}},
+		{FromUid: "u2", MessageId: 2, ReasonCode: wkproto.ReasonSuccess, SendPacket: &wkproto.SendPacket{ClientMsgNo: "c1"}},
+		{FromUid: "u1", MessageId: 3, ReasonCode: wkproto.ReasonSuccess, SendPacket: &wkproto.SendPacket{ClientMsgNo: "c1"}},
+		{FromUid: "u1", MessageId: 4, ReasonCode: wkproto.ReasonSuccess, SendPacket: &wkproto.SendPacket{}},
+	}
+	batchDuplicates := s.messageDedupManager.markDuplicates("g1", wkproto.ChannelTypeGroup, messages)
+
+	// 同一批次内u1重复发送了c1
+	assert.Equal(t, map[int]int{2: 0}, batchDuplicates)
+	assert.Equal(t, int64(0), messages[0].DuplicateOf)
+	assert.Equal(t, int64(0), messages[1].DuplicateOf)
+	assert.Equal(t, int64(1), messages[2].DuplicateOf)
+	assert.Equal(t, int64(0), messages[3].DuplicateOf)
+
+	// 同一批次内重复的消息存储后取第一条消息的seq
+	messages[0].MessageSeq = 10
+	s.messageDedupManager.fillBatchDuplicates(messages, batchDuplicates)
+	assert.Equal(t, uint32(10), messages[2].MessageSeq)
+
+	// 关闭去重后不再标记
+	s.opts.MessageDedup.On = false
+	assert.Nil(t, s.messageDedupManager.markDuplicates("g1", wkproto.ChannelTypeGroup, messages))
+}
+
+func TestMessageDedupMarkStoredDuplicates(t *testing.T) {
+	s := NewTestServer(t)
+	err := s.store.Open()
+	assert.NoError(t, err)
+	defer s.store.Close()
+
+	now := int32(time.Now().Unix())
+	err = s.store.DB().AppendMessages("g1", wkproto.ChannelTypeGroup, []wkdb.Message{
+		{RecvPacket: wkproto.RecvPacket{MessageID: 100, MessageSeq: 1, ClientMsgNo: "c1", FromUID: "u1", ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup, Timestamp: now}},
+		{RecvPacket: wkproto.RecvPacket{MessageID: 101, MessageSeq: 2, ClientMsgNo: "c2", FromUID: "u1", ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup, Timestamp: now - int32(s.opts.MessageDedup.Window.Seconds()) - 10}},
+	})
+	assert.NoError(t, err)
+
+	messages := []ReactorChannelMessage{
+		{FromUid: "u1", MessageId: 1, ReasonCode: wkproto.ReasonSuccess, SendPacket: &wkproto.SendPacket{ClientMsgNo: "c1"}},
+		{FromUid: "u1", MessageId: 2, ReasonCode: wkproto.ReasonSuccess, SendPacket: &wkproto.SendPacket{ClientMsgNo: "c2"}},
+		{FromUid: "u2", MessageId: 3, ReasonCode: wkproto.ReasonSuccess, SendPacket: &wkproto.SendPacket{ClientMsgNo: "c1"}},
+	}
+	batchDuplicates := s.messageDedupManager.markDuplicates("g1", wkproto.ChannelTypeGroup, messages)
+	assert.Nil(t, batchDuplicates)
+
+	// 已存储的消息返回原消息的id和seq
+	assert.Equal(t, int64(100), messages[0].DuplicateOf)
+	assert.Equal(t, uint32(1), messages[0].MessageSeq)
+	// 超过去重窗口的消息不再去重
+	assert.Equal(t, int64(0), messages[1].DuplicateOf)
+	// 其他发送者相同clientMsgNo的消息不是重复消息
+	assert.Equal(t, int64(0), messages[2].DuplicateOf)
+}
+
+func TestMessageDedupWaitSendack(t *testing.T) {
+	m := newMessageDedupManager(NewTestServer(t))
+
+	sendackC, cancel := m.waitSendack("u1", "c1")
+	defer cancel()
+
+	// 其他clientMsgNo的回执不影响等待
+	assert.False(t, m.notifySendack("u1", &wkproto.SendackPacket{ClientMsgNo: "c2"}))
+
+	assert.True(t, m.notifySendack("u1", &wkproto.SendackPacket{ClientMsgNo: "c1", MessageID: 100, MessageSeq: 1}))
+	sendack := <-sendackC
+	assert.Equal(t, int64(100), sendack.MessageID)
+	assert.Equal(t, uint32(1), sendack.MessageSeq)
+
+	// 已经通知过的等待不再收到回执
+	assert.False(t, m.notifySendack("u1", &wkproto.SendackPacket{ClientMsgNo: "c1"}))
+
+	// 取消后不再等待
+	_, cancel2 := m.waitSendack("u1", "c3")
+	cancel2()
+	assert.False(t, m.notifySendack("u1", &wkproto.SendackPacket{ClientMsgNo: "c3"}))
+	assert.Equal(t, 0, len(m.waiters))
+}
@@ -2,15 +2,20 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterstore"
 	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap/zapcore"
 )
 
 var defaultProtoVersion uint8 = 4
@@ -31,6 +36,9 @@ type ReactorChannelMessage struct {
 	IsSystem     bool // 是否是系统发送的消息
 	ReasonCode   wkproto.ReasonCode
 	Index        uint64
+	Mention      *MessageMention // 消息的@信息（存储时从payload中解析）
+	DuplicateOf  int64           // 不为0表示是重复发送的消息，值为原消息的id（存储时去重，不再存储和投递）
+	AckRequired  bool            // 系统账号发送的消息默认不回执，/message/send 需要等待发送结果时要求回执
 }
 
 func (r *ReactorChannelMessage) Marshal() ([]byte, error) {
@@ -62,6 +70,9 @@ func (r *ReactorChannelMessage) Marshal() ([]byte, error) {
 	}
 	enc.WriteBinary(packetData)
 
+	encodeMessageMention(enc, r.Mention)
+	enc.WriteUint8(wkutil.BoolToUint8(r.AckRequired))
+
 	return enc.Bytes(), nil
 }
 
@@ -134,6 +145,24 @@ func (r *ReactorChannelMessage) Unmarshal(data []byte) error {
 		r.SendPacket = packet.(*wkproto.SendPacket)
 	}
 
+	// 兼容旧版本数据
+	if dec.Len() == 0 {
+		return nil
+	}
+	if r.Mention, err = decodeMessageMention(dec); err != nil {
+		return err
+	}
+
+	// 兼容旧版本数据
+	if dec.Len() == 0 {
+		return nil
+	}
+	var ackRequired uint8
+	if ackRequired, err = dec.Uint8(); err != nil {
+		return err
+	}
+	r.AckRequired = wkutil.Uint8ToBool(ackRequired)
+
 	return nil
 }
 
@@ -241,6 +270,11 @@ func (rs ReactorChannelMessageSet) Marshal() ([]byte, error) {
 		enc.WriteBinary(packetData)
 	}
 
+	// 消息的@信息放在最后，兼容旧版本数据
+	for _, r := range rs {
+		encodeMessageMention(enc, r.Mention)
+	}
+
 	return enc.Bytes(), nil
 }
 
@@ -304,16 +338,27 @@ func (rs *ReactorChannelMessageSet) Unmarshal(data []byte) error {
 
 		*rs = append(*rs, r)
 	}
+
+	// 兼容旧版本数据
+	if dec.Len() == 0 {
+		return nil
+	}
+	for i := range *rs {
+		if (*rs)[i].Mention, err = decodeMessageMention(dec); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 var EmptyReactorUserMessage = ReactorUserMessage{}
 
 type ReactorUserMessage struct {
-	FromNodeId uint64        // 源节点Id
-	ConnId     int64         // 连接id
-	DeviceId   string        // 设备ID
-	InPacket   wkproto.Frame // 输入的包
+	ctx        context.Context // 链路上下文（不参与编码）
+	FromNodeId uint64          // 源节点Id
+	ConnId     int64           // 连接id
+	DeviceId   string          // 设备ID
+	InPacket   wkproto.Frame   // 输入的包
 	FrameType  wkproto.FrameType
 	OutBytes   []byte // 需要输出的字节
 	Index      uint64 // 消息下标
@@ -479,6 +524,8 @@ type MessageOfflineNotify struct {
 	Compress        string   `json:"compress,omitempty"`         // 压缩ToUIDs 如果为空 表示不压缩 为gzip则采用gzip压缩
 	CompresssToUIDs []byte   `json:"compress_to_uids,omitempty"` // 已压缩的to_uids
 	SourceID        int64    `json:"source_id,omitempty"`        // 来源节点ID
+	MentionAll      int      `json:"mention_all,omitempty"`      // 是否@所有人（离线用户都被@）
+	MentionUIDs     []string `json:"mention_uids,omitempty"`     // 被@的离线用户（推送服务可以优先推送）
 }
 
 // MessageHeader Message header
@@ -496,9 +543,204 @@ type ChannelInfoResp struct {
 
 func (c ChannelInfoResp) ToChannelInfo() *wkdb.ChannelInfo {
 	return &wkdb.ChannelInfo{
-		Large: c.Large == 1,
-		Ban:   c.Ban == 1,
+		Large:   c.Large == 1,
+		Ban:     c.Ban == 1,
+		Disband: c.Disband == 1,
+	}
+}
+
+// channelInfoBatchResp 数据源批量获取频道信息的返回
+type channelInfoBatchResp struct {
+	ChannelId   string `json:"channel_id"`
+	ChannelType uint8  `json:"channel_type"`
+	ChannelInfoResp
+}
+
+// subscribersBatchResp 数据源批量获取订阅者的返回
+type subscribersBatchResp struct {
+	ChannelId   string   `json:"channel_id"`
+	ChannelType uint8    `json:"channel_type"`
+	Subscribers []string `json:"subscribers"`
+}
+
+// datasourceInvalidateReq 清除数据源缓存请求
+type datasourceInvalidateReq struct {
+	ChannelId   string `json:"channel_id"`   // 频道id
+	ChannelType uint8  `json:"channel_type"` // 频道类型
+	All         bool   `json:"all"`          // 是否清除所有缓存
+}
+
+func (d datasourceInvalidateReq) Check() error {
+	if d.All {
+		return nil
+	}
+	if strings.TrimSpace(d.ChannelId) == "" {
+		return errors.New("频道ID不能为空！")
+	}
+	if d.ChannelType == 0 {
+		return errors.New("频道类型不能为0！")
+	}
+	return nil
+}
+
+// cdcPullReq 拉取变更事件请求
+type cdcPullReq struct {
+	Consumer   string            `json:"consumer"`   // 消费者名称
+	Categories []string          `json:"categories"` // 订阅的事件分类（message、conversation、channel、user），为空表示全部
+	SlotIds    []uint32          `json:"slot_ids"`   // 拉取的槽，为空表示所有槽
+	Cursors    map[uint32]uint64 `json:"cursors"`    // 各个槽已读取到的偏移量，没有的槽从消费者已确认的偏移量开始读取
+	Limit      int               `json:"limit"`      // 每个槽最多返回的事件数量
+}
+
+func (r cdcPullReq) Check() error {
+	if strings.TrimSpace(r.Consumer) == "" {
+		return errors.New("消费者名称不能为空！")
+	}
+	for _, category := range r.Categories {
+		switch clusterstore.CDCCategory(category) {
+		case clusterstore.CDCCategoryMessage, clusterstore.CDCCategoryConversation, clusterstore.CDCCategoryChannel, clusterstore.CDCCategoryUser:
+		default:
+			return fmt.Errorf("不支持的事件分类[%s]！", category)
+		}
+	}
+	return nil
+}
+
+// hasCategory 是否订阅了指定分类的事件
+func (r cdcPullReq) hasCategory(category clusterstore.CDCCategory) bool {
+	if len(r.Categories) == 0 {
+		return true
+	}
+	for _, c := range r.Categories {
+		if clusterstore.CDCCategory(c) == category {
+			return true
+		}
+	}
+	return false
+}
+
+// channelLastSeqReq 获取频道最新消息序号请求（节点之间）
+type channelLastSeqReq struct {
+	ChannelId   string `json:"channel_id"`
+	ChannelType uint8  `json:"channel_type"`
+}
+
+type channelLastSeqResp struct {
+	MessageSeq uint64 `json:"message_seq"`
+}
+
+// cdcMessagesReq 读取变更事件引用的消息请求（节点之间）
+type cdcMessagesReq struct {
+	ChannelId       string `json:"channel_id"`
+	ChannelType     uint8  `json:"channel_type"`
+	StartMessageSeq uint64 `json:"start_message_seq"` // 开始消息序号（包含）
+	EndMessageSeq   uint64 `json:"end_message_seq"`   // 结束消息序号（包含）
+}
+
+// cdcPullResp 拉取变更事件返回
+type cdcPullResp struct {
+	Events  []*cdcEventResp   `json:"events"`  // 变更事件（同一个槽内按偏移量升序）
+	Cursors map[uint32]uint64 `json:"cursors"` // 各个槽读取到的偏移量（包含未订阅分类的事件），下次拉取时传入
+}
+
+// cdcEventResp 变更事件
+type cdcEventResp struct {
+	SlotId    uint32          `json:"slot_id"`   // 槽id
+	Offset    uint64          `json:"offset"`    // 偏移量，消费完成后通过 /cdc/commit 确认
+	Category  string          `json:"category"`  // 事件分类
+	Type      string          `json:"type"`      // 事件类型 例如 message.append、subscriber.add
+	Timestamp int64           `json:"timestamp"` // 事件产生时间（毫秒）
+	Data      json.RawMessage `json:"data"`      // 事件数据
+}
+
+// cdcCommitReq 确认消费偏移量请求
+type cdcCommitReq struct {
+	Consumer string            `json:"consumer"` // 消费者名称
+	Offsets  map[uint32]uint64 `json:"offsets"`  // 各个槽已消费完成的偏移量
+}
+
+func (r cdcCommitReq) Check() error {
+	if strings.TrimSpace(r.Consumer) == "" {
+		return errors.New("消费者名称不能为空！")
+	}
+	if len(r.Offsets) == 0 {
+		return errors.New("偏移量不能为空！")
 	}
+	return nil
+}
+
+// nodeAddrResp 节点对外的连接地址，节点排空时作为重连提示放到断开包的Reason里
+type nodeAddrResp struct {
+	NodeId  uint64 `json:"node_id"`
+	TCPAddr string `json:"tcp_addr"`
+	WSAddr  string `json:"ws_addr"`
+	WSSAddr string `json:"wss_addr"`
+}
+
+func (n *nodeAddrResp) reason() string {
+	data, _ := json.Marshal(n)
+	return string(data)
+}
+
+// nodeLoadResp 节点的负载，用于连接路由
+type nodeLoadResp struct {
+	nodeAddrResp
+	Zone      string `json:"zone"`       // 节点所在可用区
+	ConnCount int    `json:"conn_count"` // 连接数量
+	Draining  bool   `json:"draining"`   // 是否在排空中
+}
+
+// slowConsumerReason 慢消费者断开时放到断开包的Reason里，提示客户端重连后同步离线消息
+type slowConsumerReason struct {
+	Reason string `json:"reason"` // 断开原因 slow_consumer
+	Sync   bool   `json:"sync"`   // 是否需要同步离线消息
+}
+
+type drainReq struct {
+	NodeId  uint64 `json:"node_id"` // 排空的节点，为0时排空当前节点
+	Timeout string `json:"timeout"` // 等待进行中的工作完成的最长时间（例如 30s、2m），为空时使用配置的超时时间
+}
+
+func (d drainReq) timeout() (time.Duration, error) {
+	if strings.TrimSpace(d.Timeout) == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(d.Timeout)
+	if err != nil || timeout < 0 {
+		return 0, errors.New("超时时间格式有误！")
+	}
+	return timeout, nil
+}
+
+type drainStatusResp struct {
+	NodeId             uint64 `json:"node_id"`
+	State              string `json:"state"`                          // 状态 running:正常运行 draining:排空中 stopped:已停止
+	StartedAt          int64  `json:"started_at,omitempty"`           // 开始排空的时间（毫秒）
+	Timeout            string `json:"timeout,omitempty"`              // 排空超时时间
+	ConnCount          int    `json:"conn_count,omitempty"`           // 未断开的连接数量
+	RetryCount         int    `json:"retry_count,omitempty"`          // 等待回执的消息数量
+	DeliverCount       int    `json:"deliver_count,omitempty"`        // 等待投递的请求数量
+	SlotLeaderCount    int    `json:"slot_leader_count,omitempty"`    // 未转移的槽领导数量
+	ChannelLeaderCount int    `json:"channel_leader_count,omitempty"` // 未转移的频道领导数量
+}
+
+func (d *drainStatusResp) total() int {
+	return d.ConnCount + d.RetryCount + d.DeliverCount + d.SlotLeaderCount + d.ChannelLeaderCount
+}
+
+type configReloadResult struct {
+	NodeId      uint64   `json:"node_id"`
+	ChangedKeys []string `json:"changed_keys"` // 发生变化的配置名
+	Applied     bool     `json:"applied"`      // 是否已应用
+	Err         string   `json:"err,omitempty"`
+}
+
+type configViewResp struct {
+	NodeId         uint64                 `json:"node_id"`
+	ConfigFile     string                 `json:"config_file"`     // 配置文件
+	ReloadableKeys []string               `json:"reloadable_keys"` // 可以热加载的配置名
+	Config         map[string]interface{} `json:"config"`          // 生效中的配置（敏感信息已隐藏）
+	Err            string                 `json:"err,omitempty"`
 }
 
 type ForwardSendackPacket struct {
@@ -619,6 +861,7 @@ type clearConversationUnreadReq struct {
 	ChannelID   string `json:"channel_id"`
 	ChannelType uint8  `json:"channel_type"`
 	MessageSeq  uint32 `json:"message_seq"` // messageSeq 只有超大群才会传 因为超大群最近会话服务器不会维护，需要客户端传递messageSeq进行主动维护
+	conversationDeviceTarget
 }
 
 func (req clearConversationUnreadReq) Check() error {
@@ -631,10 +874,51 @@ func (req clearConversationUnreadReq) Check() error {
 	return nil
 }
 
+type conversationSetAttrsReq struct {
+	UID         string  `json:"uid"`
+	ChannelID   string  `json:"channel_id"`
+	ChannelType uint8   `json:"channel_type"`
+	Pinned      *int    `json:"pinned"` // 是否置顶 1.置顶 0.取消置顶 (不传则不修改)
+	Muted       *int    `json:"muted"`  // 是否免打扰 1.免打扰 0.取消免打扰 (不传则不修改)
+	Draft       *string `json:"draft"`  // 草稿 (不传则不修改)
+	Extra       *string `json:"extra"`  // 自定义扩展数据 (不传则不修改)
+	conversationDeviceTarget
+}
+
+func (req conversationSetAttrsReq) Check() error {
+	if req.UID == "" {
+		return errors.New("uid cannot be empty")
+	}
+	if req.ChannelID == "" || req.ChannelType == 0 {
+		return errors.New("channel_id or channel_type cannot be empty")
+	}
+	if req.Pinned == nil && req.Muted == nil && req.Draft == nil && req.Extra == nil {
+		return errors.New("no attrs to set")
+	}
+	return nil
+}
+
+// apply 将请求中的属性合并到已有属性上
+func (req conversationSetAttrsReq) apply(attrs *wkdb.ConversationAttrs) {
+	if req.Pinned != nil {
+		attrs.Pinned = *req.Pinned == 1
+	}
+	if req.Muted != nil {
+		attrs.Muted = *req.Muted == 1
+	}
+	if req.Draft != nil {
+		attrs.Draft = *req.Draft
+	}
+	if req.Extra != nil {
+		attrs.Extra = *req.Extra
+	}
+}
+
 type deleteChannelReq struct {
 	UID         string `json:"uid"`
 	ChannelID   string `json:"channel_id"`
 	ChannelType uint8  `json:"channel_type"`
+	conversationDeviceTarget
 }
 
 func (req deleteChannelReq) Check() error {
@@ -656,7 +940,14 @@ type syncUserConversationResp struct {
 	LastClientMsgNo string         `json:"last_client_msg_no"` // 最后一次消息客户端编号
 	OffsetMsgSeq    int64          `json:"offset_msg_seq"`     // 偏移位的消息seq
 	ReadedToMsgSeq  uint32         `json:"readed_to_msg_seq"`  // 已读至的消息seq
-	Version         int64          `json:"version"`            // 数据版本
+	Version         int64          `json:"version"`            // 会话版本号（用户级的变更计数，不是时间戳），客户端取返回的最大值作为下次同步的version
+	Pinned          int            `json:"pinned"`             // 是否置顶
+	Muted           int            `json:"muted"`              // 是否免打扰
+	Draft           string         `json:"draft"`              // 草稿
+	Extra           string         `json:"extra"`              // 自定义扩展数据
+	Deleted         int            `json:"deleted"`            // 会话是否已被删除（增量同步时返回）
+	MentionCount    int            `json:"mention_count"`      // 未读的@我的消息数量
+	LastMentionSeq  uint32         `json:"last_mention_seq"`   // 最后一条@我的消息seq
 	Recents         []*MessageResp `json:"recents"`            // 最近N条消息
 }
 
@@ -670,11 +961,39 @@ func newSyncUserConversationResp(conversation wkdb.Conversation) *syncUserConver
 			realChannelId = from
 		}
 	}
-	return &syncUserConversationResp{
+	resp := &syncUserConversationResp{
 		ChannelId:      realChannelId,
 		ChannelType:    conversation.ChannelType,
 		Unread:         int(conversation.UnreadCount),
 		ReadedToMsgSeq: uint32(conversation.ReadToMsgSeq),
+		Version:        int64(conversation.Version),
+		Pinned:         wkutil.BoolToInt(conversation.Pinned),
+		Muted:          wkutil.BoolToInt(conversation.Muted),
+		Draft:          conversation.Draft,
+		Extra:          conversation.Extra,
+	}
+	if conversation.LastMentionSeq > conversation.ReadToMsgSeq { // 已读过的@不再提示
+		resp.MentionCount = int(conversation.MentionCount)
+		resp.LastMentionSeq = uint32(conversation.LastMentionSeq)
+	}
+	return resp
+}
+
+func newSyncUserConversationRespWithTombstone(tombstone wkdb.ConversationTombstone) *syncUserConversationResp {
+	realChannelId := tombstone.ChannelId
+	if tombstone.ChannelType == wkproto.ChannelTypePerson {
+		from, to := GetFromUIDAndToUIDWith(tombstone.ChannelId)
+		if from == tombstone.Uid {
+			realChannelId = to
+		} else {
+			realChannelId = from
+		}
+	}
+	return &syncUserConversationResp{
+		ChannelId:   realChannelId,
+		ChannelType: tombstone.ChannelType,
+		Version:     int64(tombstone.Version),
+		Deleted:     1,
 	}
 }
 
@@ -891,15 +1210,19 @@ func (c ChannelInfoReq) ToChannelInfo() wkdb.ChannelInfo {
 
 // MessageSendReq 消息发送请求
 type MessageSendReq struct {
-	Header      MessageHeader `json:"header"`        // 消息头
-	ClientMsgNo string        `json:"client_msg_no"` // 客户端消息编号（相同编号，客户端只会显示一条）
-	StreamNo    string        `json:"stream_no"`     // 消息流编号
-	FromUID     string        `json:"from_uid"`      // 发送者UID
-	ChannelID   string        `json:"channel_id"`    // 频道ID
-	ChannelType uint8         `json:"channel_type"`  // 频道类型
-	Expire      uint32        `json:"expire"`        // 消息过期时间
-	Subscribers []string      `json:"subscribers"`   // 订阅者 如果此字段有值，表示消息只发给指定的订阅者
-	Payload     []byte        `json:"payload"`       // 消息内容
+	Header      MessageHeader   `json:"header"`        // 消息头
+	ClientMsgNo string          `json:"client_msg_no"` // 客户端消息编号（相同编号，客户端只会显示一条）
+	StreamNo    string          `json:"stream_no"`     // 消息流编号
+	FromUID     string          `json:"from_uid"`      // 发送者UID
+	ChannelID   string          `json:"channel_id"`    // 频道ID
+	ChannelType uint8           `json:"channel_type"`  // 频道类型
+	Expire      uint32          `json:"expire"`        // 消息过期时间
+	Subscribers []string        `json:"subscribers"`   // 订阅者 如果此字段有值，表示消息只发给指定的订阅者
+	Payload     []byte          `json:"payload"`       // 消息内容
+	SendAt      int64           `json:"send_at"`       // 定时发送时间（10位时间戳），大于当前时间则为定时消息
+	Mention     *MessageMention `json:"mention"`       // @信息，会写入到payload的mention字段内（payload需要是json）
+
+	ctx context.Context // 链路上下文（来自http请求时为http请求的链路）
 }
 
 // Check 检查输入
@@ -934,3 +1257,102 @@ func (a *allowSendReq) Marshal() ([]byte, error) {
 	enc.WriteString(a.To)
 	return enc.Bytes(), nil
 }
+
+// logLevelReq 设置日志级别请求
+type logLevelReq struct {
+	Level  string `json:"level"`  // 日志级别 debug, info, warn, error
+	Module string `json:"module"` // 模块，为空时设置全局日志级别
+	Remove bool   `json:"remove"` // 是否移除模块的日志级别（模块将使用全局日志级别）
+}
+
+func (l *logLevelReq) check() error {
+	if l.Remove {
+		if strings.TrimSpace(l.Module) == "" {
+			return errors.New("module不能为空！")
+		}
+		return nil
+	}
+	_, err := l.level()
+	return err
+}
+
+func (l *logLevelReq) level() (zapcore.Level, error) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return level, err
+	}
+	return level, nil
+}
+
+// apply 在当前节点生效
+func (l *logLevelReq) apply() error {
+	if l.Remove {
+		wklog.RemoveModuleLevel(l.Module)
+		return nil
+	}
+	level, err := l.level()
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(l.Module) == "" {
+		wklog.SetLevel(level)
+	} else {
+		wklog.SetModuleLevel(l.Module, level)
+	}
+	return nil
+}
+
+type logLevelResult struct {
+	NodeId uint64 `json:"node_id"`
+	Err    string `json:"err,omitempty"`
+}
+
+type logLevelResp struct {
+	NodeId  uint64            `json:"node_id"`
+	Level   string            `json:"level"`   // 全局日志级别
+	Modules map[string]string `json:"modules"` // 模块的日志级别
+}
+
+func newLogLevelResp(nodeId uint64) *logLevelResp {
+	moduleLevels := wklog.ModuleLevels()
+	modules := make(map[string]string, len(moduleLevels))
+	for module, level := range moduleLevels {
+		modules[module] = level.String()
+	}
+	return &logLevelResp{
+		NodeId:  nodeId,
+		Level:   wklog.GetLevel().String(),
+		Modules: modules,
+	}
+}
+
+// logQueryReq 查询日志请求
+type logQueryReq struct {
+	File    string `json:"file"`    // 日志文件 info, warn, error, panic
+	Module  string `json:"module"`  // 模块
+	Keyword string `json:"keyword"` // 关键字
+	Start   int64  `json:"start"`   // 开始时间（单位秒）
+	End     int64  `json:"end"`     // 结束时间（单位秒）
+	Limit   int    `json:"limit"`   // 最多返回的日志数量
+}
+
+func (l *logQueryReq) toLogQuery() wklog.LogQuery {
+	q := wklog.LogQuery{
+		File:    l.File,
+		Module:  l.Module,
+		Keyword: l.Keyword,
+		Limit:   l.Limit,
+	}
+	if l.Start > 0 {
+		q.Start = time.Unix(l.Start, 0)
+	}
+	if l.End > 0 {
+		q.End = time.Unix(l.End, 0)
+	}
+	return q
+}
+
+// metricsHistoryReq 获取内置监控数据请求
+type metricsHistoryReq struct {
+	Start int64 `json:"start"` // 开始时间（单位秒）
+}
@@ -1,13 +1,18 @@
 package server
 
 import (
+	"context"
 	"testing"
 
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	wkproto "github.com/WuKongIM/WuKongIMGoProto"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestChannelMessagesSetMarshal(t *testing.T) {
+	if trace.GlobalTrace == nil {
+		trace.SetGlobalTrace(trace.New(context.Background(), trace.NewOptions()))
+	}
 	channelMessages := ChannelMessagesSet{}
 	channelMessages = append(channelMessages, &ChannelMessages{
 		ChannelId:   "test",
@@ -15,6 +20,7 @@ func TestChannelMessagesSetMarshal(t *testing.T) {
 		TagKey:      "test",
 		Messages: ReactorChannelMessageSet{
 			ReactorChannelMessage{
+				ctx:        context.Background(),
 				MessageId:  1,
 				FromConnId: 1,
 				FromUid:    "test",
@@ -23,6 +29,7 @@ func TestChannelMessagesSetMarshal(t *testing.T) {
 					ChannelType: 1,
 					Payload:     []byte("testtesttesttesttesttesttesttesttesttesttesttest"),
 				},
+				Mention: &MessageMention{Uids: []string{"u1", "u2"}},
 			},
 		},
 	})
@@ -33,4 +40,32 @@ func TestChannelMessagesSetMarshal(t *testing.T) {
 	err = channelMessages.Unmarshal(data)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(channelMessages))
+	assert.Equal(t, []string{"u1", "u2"}, channelMessages[0].Messages[0].Mention.Uids)
+}
+
+func TestChannelFowardReqMarshal(t *testing.T) {
+	if trace.GlobalTrace == nil {
+		trace.SetGlobalTrace(trace.New(context.Background(), trace.NewOptions()))
+	}
+	req := ChannelFowardReq{
+		ChannelId:   "test",
+		ChannelType: 1,
+		Messages: []ReactorChannelMessage{
+			{
+				ctx:         context.Background(),
+				FromUid:     "test",
+				SendPacket:  &wkproto.SendPacket{ChannelID: "test", ChannelType: 1, ClientMsgNo: "c1", Payload: []byte("test")},
+				AckRequired: true,
+			},
+		},
+	}
+	data, err := req.Marshal()
+	assert.Nil(t, err)
+
+	req = ChannelFowardReq{}
+	err = req.Unmarshal(data)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(req.Messages))
+	assert.Equal(t, "c1", req.Messages[0].SendPacket.ClientMsgNo)
+	assert.True(t, req.Messages[0].AckRequired)
 }
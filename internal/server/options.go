@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/auth"
@@ -41,6 +42,21 @@ const (
 	TestMode = "test"
 )
 
+// SlowConsumerPolicy 连接发送缓冲区超出限制时的处理策略
+type SlowConsumerPolicy string
+
+const (
+	// 优先丢弃不存储的消息，丢弃后仍然超出限制则断开连接
+	SlowConsumerPolicyDrop SlowConsumerPolicy = "drop"
+	// 合并不存储的消息，每个频道只保留最新的一条，合并后仍然超出限制则断开连接
+	SlowConsumerPolicyCoalesce SlowConsumerPolicy = "coalesce"
+	// 直接断开连接
+	SlowConsumerPolicyDisconnect SlowConsumerPolicy = "disconnect"
+)
+
+// CompressionAlgorithmDeflate tcp连接消息内容的压缩算法
+const CompressionAlgorithmDeflate = "deflate"
+
 type Role string
 
 const (
@@ -65,9 +81,15 @@ type Options struct {
 	}
 
 	Logger struct {
-		Dir     string // 日志存储目录
-		Level   zapcore.Level
-		LineNum bool // 是否显示代码行数
+		Dir          string // 日志存储目录
+		Level        zapcore.Level
+		LineNum      bool                     // 是否显示代码行数
+		Format       string                   // 日志格式 json或console
+		MaxSize      int                      // 单个日志文件的最大大小（单位MB）
+		MaxAge       int                      // 日志文件保留的最大天数
+		MaxBackups   int                      // 保留的历史日志文件的最大数量
+		Compress     bool                     // 历史日志文件是否压缩
+		ModuleLevels map[string]zapcore.Level // 模块的日志级别
 	}
 	Manager struct {
 		On   bool   // 是否开启监控
@@ -92,6 +114,8 @@ type Options struct {
 		CreateIfNoExist           bool   // 如果频道不存在是否创建
 		SubscriberCompressOfCount int    // 订订阅者数组多大开始压缩（离线推送的时候订阅者数组太大 可以设置此参数进行压缩 默认为0 表示不压缩 ）
 		CmdSuffix                 string // cmd频道后缀
+		ThreadSeparator           string // 子区频道分隔符 子区频道id格式为：父频道id + 分隔符 + 根消息序号
+		PinMaxCount               int    // 每个频道最多置顶的消息数量
 	}
 	TmpChannel struct { // 临时频道配置
 		Suffix     string // 临时频道的后缀
@@ -105,26 +129,93 @@ type Options struct {
 		MsgNotifyEventRetryMaxCount int           // 消息通知事件消息推送失败最大重试次数 默认为5次，超过将丢弃
 	}
 	Datasource struct { // 数据源配置，不填写则使用自身数据存储逻辑，如果填写则使用第三方数据源，数据格式请查看文档
-		Addr          string // 数据源地址
-		ChannelInfoOn bool   // 是否开启频道信息获取
+		Addr          string        // 数据源地址
+		GRPCAddr      string        // 数据源的grpc地址 如果此地址有值 则不会再调用Addr配置的地址,格式为 ip:port
+		ChannelInfoOn bool          // 是否开启频道信息获取
+		CacheSize     int           // 数据源缓存的最大数量
+		CacheTTL      time.Duration // 数据源缓存的过期时间，为0则不缓存
 	}
 	Conversation struct {
-		On                 bool          // 是否开启最近会话
-		CacheExpire        time.Duration // 最近会话缓存过期时间 (这个是热数据缓存时间，并非最近会话数据的缓存时间)
-		SyncInterval       time.Duration // 最近会话同步间隔
-		SyncOnce           int           //  当多少最近会话数量发送变化就保存一次
-		UserMaxCount       int           // 每个用户最大最近会话数量 默认为500
-		BytesPerSave       uint64        // 每次保存的最近会话数据大小 如果为0 则表示不限制
-		SavePoolSize       int           // 保存最近会话协程池大小
-		WorkerCount        int           // 处理最近会话工作者数量
-		WorkerScanInterval time.Duration // 处理最近会话扫描间隔
-
+		On                     bool          // 是否开启最近会话
+		CacheExpire            time.Duration // 最近会话缓存过期时间 (这个是热数据缓存时间，并非最近会话数据的缓存时间)
+		SyncInterval           time.Duration // 最近会话同步间隔
+		SyncOnce               int           //  当多少最近会话数量发送变化就保存一次
+		UserMaxCount           int           // 每个用户最大最近会话数量 默认为500
+		BytesPerSave           uint64        // 每次保存的最近会话数据大小 如果为0 则表示不限制
+		SavePoolSize           int           // 保存最近会话协程池大小
+		WorkerCount            int           // 处理最近会话工作者数量
+		WorkerScanInterval     time.Duration // 处理最近会话扫描间隔
+		DeviceSyncOn           bool          // 已读、未读、删除、属性等会话变更是否立即推送给用户其他在线的设备
+		TombstoneRetention     time.Duration // 最近会话删除墓碑的保留时长，超过后被清理，版本号早于被清理墓碑的客户端需要全量同步
+		TombstoneCleanInterval time.Duration // 清理过期最近会话删除墓碑的间隔
+	}
+	Event struct { // 临时事件配置（正在输入、音视频信令等）
+		MaxDelay           time.Duration // 事件产生后超过此时间还未投递则丢弃 0表示不丢弃
+		RateLimitPerSecond int           // 每个发送者在每个频道每秒最多发送的事件数量（在频道所在槽的领导节点统计） 0表示不限制
+	}
+	ScheduledMessage struct { // 定时消息配置
+		ScanInterval  time.Duration // 扫描到期定时消息的间隔
+		FireBatchSize int           // 每次取出到期定时消息的数量
+		MaxFailCount  int           // 最大发送失败次数，达到后定时消息进入死信不再发送
+	}
+	Presence struct { // 在线状态订阅配置
+		On          bool // 是否开启在线状态订阅通知
+		WorkerCount int  // 处理在线状态事件的工作者数量
+	}
+	CustomerService struct { // 客服配置（客服频道id格式为：访客uid|队列id）
+		On             bool          // 是否开启客服会话分配
+		AssignInterval time.Duration // 定时为排队中的会话分配客服的间隔（客服上线后可以及时接待）
+	}
+	MessageDedup struct { // 消息去重配置（同一个发送者在频道内相同clientMsgNo的消息只存储一次）
+		On          bool          // 是否开启消息去重
+		Window      time.Duration // 去重窗口，超过此时长的clientMsgNo不再去重
+		WaitTimeout time.Duration // /message/send 带clientMsgNo时等待发送结果的超时时间（重复发送需要返回原消息的id和seq）
+	}
+	CDC struct { // 变更数据捕获配置（/cdc/stream 按槽消费消息、最近会话、频道、用户的变更）
+		On                bool          // 是否开启变更数据捕获
+		Retention         time.Duration // 变更事件的保留时长
+		CleanInterval     time.Duration // 清理过期变更事件的间隔
+		PullLimit         int           // 每个槽每次最多拉取的事件数量
+		PollInterval      time.Duration // 流式推送时没有新事件的轮询间隔
+		HeartbeatInterval time.Duration // 流式推送的心跳间隔
+	}
+	Drain struct { // 节点排空配置（wk drain 或 /manager/drain 下线节点）
+		Timeout         time.Duration // 等待进行中的工作完成的最长时间，超时后直接停止
+		CheckInterval   time.Duration // 检查进行中的工作的间隔
+		DisconnectDelay time.Duration // 发送断开包后延迟多久关闭连接
+	}
+	SlowConsumer struct { // 慢消费者配置（客户端接收速度跟不上时连接的发送缓冲区会堆积）
+		MaxOutboundBytes   int                // 每个连接发送缓冲区待发送的最大字节数，0为不限制
+		MaxOutboundPackets int                // 每个连接发送缓冲区待发送的最大包数量（一次写入算一个包），0为不限制
+		Policy             SlowConsumerPolicy // 超出限制时的处理策略
+		DisconnectDelay    time.Duration      // 发送断开包后延迟多久关闭连接
+	}
+	Compression struct { // 压缩配置
+		WS struct { // ws/wss的permessage-deflate压缩，客户端在握手时请求压缩才会生效
+			On              bool // 是否开启
+			Level           int  // 压缩级别 1-9
+			Threshold       int  // 消息达到多少字节才压缩
+			ContextTakeover bool // 是否允许消息间复用压缩上下文（压缩率更高，每个连接每个方向多占用32KB内存）
+		}
+		TCP struct { // tcp连接的消息内容压缩，客户端在CONNECT中声明支持压缩才会生效
+			On        bool   // 是否开启
+			Algorithm string // 压缩算法，目前支持deflate
+			Level     int    // 压缩级别 1-9
+			Threshold int    // 消息内容达到多少字节才压缩
+		}
+	}
+	Route struct { // 连接路由配置（/route 和 /route/batch）
+		LoadCacheTTL       time.Duration // 节点负载的缓存时间
+		ZoneAffinity       bool          // 优先路由到客户端所在可用区的节点
+		SlotLeaderAffinity bool          // 优先路由到用户所在槽的领导节点，减少消息转发
 	}
 	ManagerToken   string // 管理者的token
 	ManagerUID     string // 管理者的uid
 	SystemUID      string // 系统账号的uid，主要用来发消息
 	ManagerTokenOn bool   // 管理者的token是否开启
 
+	reloadable atomic.Pointer[ReloadableOptions] // 运行时生效的可热加载配置，热加载时整体替换
+
 	Proto wkproto.Protocol // 悟空IM protocol
 
 	Version string
@@ -178,14 +269,32 @@ type Options struct {
 		SlotReactorSubCount    int // 槽reactor sub的数量
 
 		PongMaxTick int // 节点超过多少tick没有回应心跳就认为是掉线
+
+		Zone string // 节点所在可用区，配置后槽和频道的副本会尽量分散在不同的可用区
+		Rack string // 节点所在机架，同一个可用区内副本会尽量分散在不同的机架
+
+		ChannelRepairInterval    time.Duration // 频道副本修复间隔，槽领导定期为副本不足的频道补充副本，为0表示不修复
+		ChannelRepairMaxPerRound int           // 每轮最多修复的频道数量
+		ChannelRepairDeadTimeout time.Duration // 副本节点离线超过这个时间则认为副本永久失效，会从频道副本中移除
+
+		ChannelLeaderBalanceInterval    time.Duration // 频道领导均衡间隔，为0表示不均衡
+		ChannelLeaderBalanceThreshold   float64       // 节点领导的频道数量超过平均值的比例达到这个值才开始转移领导
+		ChannelLeaderBalanceMaxPerRound int           // 每轮最多转移领导的频道数量
+		ChannelLeaderBalanceDryRun      bool          // 只输出均衡计划，不执行领导转移
+
+		FollowerRead bool // 是否开启追随者读，开启后消息同步和历史消息查询可以由频道的追随者副本处理
 	}
 
 	Trace struct {
 		Endpoint         string // 例如：127.0.0.1:4318
 		ServiceName      string
 		ServiceHostName  string
-		PrometheusApiUrl string  // prometheus api url
+		PrometheusApiUrl string  // prometheus api url（不配置时管理后台使用内置监控数据）
 		SampleRate       float64 // 消息链路采样率 0 ~ 1
+
+		MetricsHistoryOn        bool          // 是否开启内置监控历史
+		MetricsHistoryInterval  time.Duration // 内置监控采样间隔
+		MetricsHistoryRetention time.Duration // 内置监控保留时长
 	}
 
 	Reactor struct {
@@ -259,13 +368,23 @@ func NewOptions(op ...Option) *Options {
 		WhitelistOffOfPerson: true,
 		DeadlockCheck:        false,
 		Logger: struct {
-			Dir     string
-			Level   zapcore.Level
-			LineNum bool
+			Dir          string
+			Level        zapcore.Level
+			LineNum      bool
+			Format       string
+			MaxSize      int
+			MaxAge       int
+			MaxBackups   int
+			Compress     bool
+			ModuleLevels map[string]zapcore.Level
 		}{
-			Dir:     "",
-			Level:   zapcore.InfoLevel,
-			LineNum: false,
+			Dir:        "",
+			Level:      zapcore.InfoLevel,
+			LineNum:    false,
+			Format:     wklog.FormatJSON,
+			MaxSize:    500,
+			MaxAge:     28,
+			MaxBackups: 3,
 		},
 		HTTPAddr:            "0.0.0.0:5001",
 		Addr:                "tcp://0.0.0.0:5100",
@@ -285,40 +404,171 @@ func NewOptions(op ...Option) *Options {
 			CreateIfNoExist           bool
 			SubscriberCompressOfCount int
 			CmdSuffix                 string
+			ThreadSeparator           string
+			PinMaxCount               int
 		}{
 			CacheCount:                1000,
 			CreateIfNoExist:           true,
 			SubscriberCompressOfCount: 0,
 			CmdSuffix:                 "____cmd",
+			ThreadSeparator:           "____thread_",
+			PinMaxCount:               50,
 		},
 		Datasource: struct {
 			Addr          string
+			GRPCAddr      string
 			ChannelInfoOn bool
+			CacheSize     int
+			CacheTTL      time.Duration
 		}{
 			Addr:          "",
 			ChannelInfoOn: false,
+			CacheSize:     10000,
+			CacheTTL:      time.Minute * 5,
 		},
 		TokenAuthOn: false,
 		Conversation: struct {
-			On                 bool
-			CacheExpire        time.Duration
-			SyncInterval       time.Duration
-			SyncOnce           int
-			UserMaxCount       int
-			BytesPerSave       uint64
-			SavePoolSize       int
-			WorkerCount        int
-			WorkerScanInterval time.Duration
+			On                     bool
+			CacheExpire            time.Duration
+			SyncInterval           time.Duration
+			SyncOnce               int
+			UserMaxCount           int
+			BytesPerSave           uint64
+			SavePoolSize           int
+			WorkerCount            int
+			WorkerScanInterval     time.Duration
+			DeviceSyncOn           bool
+			TombstoneRetention     time.Duration
+			TombstoneCleanInterval time.Duration
+		}{
+			On:                     true,
+			CacheExpire:            time.Hour * 24 * 1, // 1天过期
+			UserMaxCount:           1000,
+			SyncInterval:           time.Minute * 5,
+			SyncOnce:               100,
+			BytesPerSave:           1024 * 1024 * 5,
+			SavePoolSize:           100,
+			WorkerCount:            10,
+			WorkerScanInterval:     time.Minute * 5,
+			DeviceSyncOn:           true,
+			TombstoneRetention:     time.Hour * 24 * 30,
+			TombstoneCleanInterval: time.Hour,
+		},
+		Event: struct {
+			MaxDelay           time.Duration
+			RateLimitPerSecond int
+		}{
+			MaxDelay:           time.Millisecond * 3000,
+			RateLimitPerSecond: 20,
+		},
+		ScheduledMessage: struct {
+			ScanInterval  time.Duration
+			FireBatchSize int
+			MaxFailCount  int
 		}{
-			On:                 true,
-			CacheExpire:        time.Hour * 24 * 1, // 1天过期
-			UserMaxCount:       1000,
-			SyncInterval:       time.Minute * 5,
-			SyncOnce:           100,
-			BytesPerSave:       1024 * 1024 * 5,
-			SavePoolSize:       100,
-			WorkerCount:        10,
-			WorkerScanInterval: time.Minute * 5,
+			ScanInterval:  time.Second,
+			FireBatchSize: 100,
+			MaxFailCount:  10,
+		},
+		Presence: struct {
+			On          bool
+			WorkerCount int
+		}{
+			On:          true,
+			WorkerCount: 4,
+		},
+		CustomerService: struct {
+			On             bool
+			AssignInterval time.Duration
+		}{
+			On:             true,
+			AssignInterval: time.Second * 5,
+		},
+		MessageDedup: struct {
+			On          bool
+			Window      time.Duration
+			WaitTimeout time.Duration
+		}{
+			On:          true,
+			Window:      time.Hour,
+			WaitTimeout: time.Second * 5,
+		},
+		CDC: struct {
+			On                bool
+			Retention         time.Duration
+			CleanInterval     time.Duration
+			PullLimit         int
+			PollInterval      time.Duration
+			HeartbeatInterval time.Duration
+		}{
+			On:                false,
+			Retention:         time.Hour * 72,
+			CleanInterval:     time.Minute * 5,
+			PullLimit:         100,
+			PollInterval:      time.Millisecond * 500,
+			HeartbeatInterval: time.Second * 15,
+		},
+		Drain: struct {
+			Timeout         time.Duration
+			CheckInterval   time.Duration
+			DisconnectDelay time.Duration
+		}{
+			Timeout:         time.Minute * 2,
+			CheckInterval:   time.Second,
+			DisconnectDelay: time.Second * 2,
+		},
+		SlowConsumer: struct {
+			MaxOutboundBytes   int
+			MaxOutboundPackets int
+			Policy             SlowConsumerPolicy
+			DisconnectDelay    time.Duration
+		}{
+			MaxOutboundBytes: 1024 * 1024 * 16,
+			Policy:           SlowConsumerPolicyDrop,
+			DisconnectDelay:  time.Second * 2,
+		},
+		Compression: struct {
+			WS struct {
+				On              bool
+				Level           int
+				Threshold       int
+				ContextTakeover bool
+			}
+			TCP struct {
+				On        bool
+				Algorithm string
+				Level     int
+				Threshold int
+			}
+		}{
+			WS: struct {
+				On              bool
+				Level           int
+				Threshold       int
+				ContextTakeover bool
+			}{
+				On:        true,
+				Level:     1,
+				Threshold: 256,
+			},
+			TCP: struct {
+				On        bool
+				Algorithm string
+				Level     int
+				Threshold int
+			}{
+				Algorithm: CompressionAlgorithmDeflate,
+				Level:     1,
+				Threshold: 256,
+			},
+		},
+		Route: struct {
+			LoadCacheTTL       time.Duration
+			ZoneAffinity       bool
+			SlotLeaderAffinity bool
+		}{
+			LoadCacheTTL: time.Second * 2,
+			ZoneAffinity: true,
 		},
 		DeliveryMsgPoolSize: 10240,
 		EventPoolSize:       1024,
@@ -359,23 +609,33 @@ func NewOptions(op ...Option) *Options {
 			Addr: "0.0.0.0:5172",
 		},
 		Cluster: struct {
-			NodeId                 uint64
-			Addr                   string
-			ServerAddr             string
-			APIUrl                 string
-			ReqTimeout             time.Duration
-			Role                   Role
-			Seed                   string
-			SlotReplicaCount       int
-			ChannelReplicaCount    int
-			SlotCount              int
-			InitNodes              []*Node
-			TickInterval           time.Duration
-			HeartbeatIntervalTick  int
-			ElectionIntervalTick   int
-			ChannelReactorSubCount int
-			SlotReactorSubCount    int
-			PongMaxTick            int
+			NodeId                          uint64
+			Addr                            string
+			ServerAddr                      string
+			APIUrl                          string
+			ReqTimeout                      time.Duration
+			Role                            Role
+			Seed                            string
+			SlotReplicaCount                int
+			ChannelReplicaCount             int
+			SlotCount                       int
+			InitNodes                       []*Node
+			TickInterval                    time.Duration
+			HeartbeatIntervalTick           int
+			ElectionIntervalTick            int
+			ChannelReactorSubCount          int
+			SlotReactorSubCount             int
+			PongMaxTick                     int
+			Zone                            string
+			Rack                            string
+			ChannelRepairInterval           time.Duration
+			ChannelRepairMaxPerRound        int
+			ChannelRepairDeadTimeout        time.Duration
+			ChannelLeaderBalanceInterval    time.Duration
+			ChannelLeaderBalanceThreshold   float64
+			ChannelLeaderBalanceMaxPerRound int
+			ChannelLeaderBalanceDryRun      bool
+			FollowerRead                    bool
 		}{
 			NodeId:                 1001,
 			Addr:                   "tcp://0.0.0.0:11110",
@@ -391,6 +651,14 @@ func NewOptions(op ...Option) *Options {
 			ChannelReactorSubCount: 64,
 			SlotReactorSubCount:    64,
 			PongMaxTick:            30,
+
+			ChannelRepairInterval:    time.Minute,
+			ChannelRepairMaxPerRound: 50,
+			ChannelRepairDeadTimeout: time.Minute * 30,
+
+			ChannelLeaderBalanceInterval:    time.Minute * 5,
+			ChannelLeaderBalanceThreshold:   0.2,
+			ChannelLeaderBalanceMaxPerRound: 10,
 		},
 		Trace: struct {
 			Endpoint         string
@@ -398,12 +666,20 @@ func NewOptions(op ...Option) *Options {
 			ServiceHostName  string
 			PrometheusApiUrl string
 			SampleRate       float64
+
+			MetricsHistoryOn        bool
+			MetricsHistoryInterval  time.Duration
+			MetricsHistoryRetention time.Duration
 		}{
 			Endpoint:         "",
 			ServiceName:      "wukongim",
 			ServiceHostName:  "imnode",
-			PrometheusApiUrl: "http://127.0.0.1:9090",
+			PrometheusApiUrl: "",
 			SampleRate:       1,
+
+			MetricsHistoryOn:        true,
+			MetricsHistoryInterval:  time.Second * 10,
+			MetricsHistoryRetention: time.Hour,
 		},
 		Reactor: struct {
 			ChannelSubCount             int
@@ -531,6 +807,7 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.Channel.CacheCount = o.getInt("channel.cacheCount", o.Channel.CacheCount)
 	o.Channel.CreateIfNoExist = o.getBool("channel.createIfNoExist", o.Channel.CreateIfNoExist)
 	o.Channel.SubscriberCompressOfCount = o.getInt("channel.subscriberCompressOfCount", o.Channel.SubscriberCompressOfCount)
+	o.Channel.PinMaxCount = o.getInt("channel.pinMaxCount", o.Channel.PinMaxCount)
 
 	o.ConnIdleTime = o.getDuration("connIdleTime", o.ConnIdleTime)
 
@@ -557,7 +834,10 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.TmpChannel.Suffix = o.getString("tmpChannel.suffix", o.TmpChannel.Suffix)
 
 	o.Datasource.Addr = o.getString("datasource.addr", o.Datasource.Addr)
+	o.Datasource.GRPCAddr = o.getString("datasource.grpcAddr", o.Datasource.GRPCAddr)
 	o.Datasource.ChannelInfoOn = o.getBool("datasource.channelInfoOn", o.Datasource.ChannelInfoOn)
+	o.Datasource.CacheSize = o.getInt("datasource.cacheSize", o.Datasource.CacheSize)
+	o.Datasource.CacheTTL = o.getDuration("datasource.cacheTTL", o.Datasource.CacheTTL)
 
 	o.WhitelistOffOfPerson = o.getBool("whitelistOffOfPerson", o.WhitelistOffOfPerson)
 
@@ -575,6 +855,55 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.Conversation.SavePoolSize = o.getInt("conversation.savePoolSize", o.Conversation.SavePoolSize)
 	o.Conversation.WorkerCount = o.getInt("conversation.workerNum", o.Conversation.WorkerCount)
 	o.Conversation.WorkerScanInterval = o.getDuration("conversation.workerScanInterval", o.Conversation.WorkerScanInterval)
+	o.Conversation.DeviceSyncOn = o.getBool("conversation.deviceSyncOn", o.Conversation.DeviceSyncOn)
+	o.Conversation.TombstoneRetention = o.getDuration("conversation.tombstoneRetention", o.Conversation.TombstoneRetention)
+	o.Conversation.TombstoneCleanInterval = o.getDuration("conversation.tombstoneCleanInterval", o.Conversation.TombstoneCleanInterval)
+
+	o.Event.MaxDelay = o.getDuration("event.maxDelay", o.Event.MaxDelay)
+	o.Event.RateLimitPerSecond = o.getInt("event.rateLimitPerSecond", o.Event.RateLimitPerSecond)
+
+	o.ScheduledMessage.ScanInterval = o.getDuration("scheduledMessage.scanInterval", o.ScheduledMessage.ScanInterval)
+	o.ScheduledMessage.FireBatchSize = o.getInt("scheduledMessage.fireBatchSize", o.ScheduledMessage.FireBatchSize)
+	o.ScheduledMessage.MaxFailCount = o.getInt("scheduledMessage.maxFailCount", o.ScheduledMessage.MaxFailCount)
+
+	o.Presence.On = o.getBool("presence.on", o.Presence.On)
+	o.Presence.WorkerCount = o.getInt("presence.workerCount", o.Presence.WorkerCount)
+
+	o.CustomerService.On = o.getBool("customerService.on", o.CustomerService.On)
+	o.CustomerService.AssignInterval = o.getDuration("customerService.assignInterval", o.CustomerService.AssignInterval)
+
+	o.MessageDedup.On = o.getBool("messageDedup.on", o.MessageDedup.On)
+	o.MessageDedup.Window = o.getDuration("messageDedup.window", o.MessageDedup.Window)
+	o.MessageDedup.WaitTimeout = o.getDuration("messageDedup.waitTimeout", o.MessageDedup.WaitTimeout)
+
+	o.CDC.On = o.getBool("cdc.on", o.CDC.On)
+	o.CDC.Retention = o.getDuration("cdc.retention", o.CDC.Retention)
+	o.CDC.CleanInterval = o.getDuration("cdc.cleanInterval", o.CDC.CleanInterval)
+	o.CDC.PullLimit = o.getInt("cdc.pullLimit", o.CDC.PullLimit)
+	o.CDC.PollInterval = o.getDuration("cdc.pollInterval", o.CDC.PollInterval)
+	o.CDC.HeartbeatInterval = o.getDuration("cdc.heartbeatInterval", o.CDC.HeartbeatInterval)
+
+	o.Drain.Timeout = o.getDuration("drain.timeout", o.Drain.Timeout)
+	o.Drain.CheckInterval = o.getDuration("drain.checkInterval", o.Drain.CheckInterval)
+	o.Drain.DisconnectDelay = o.getDuration("drain.disconnectDelay", o.Drain.DisconnectDelay)
+
+	o.SlowConsumer.MaxOutboundBytes = o.getInt("slowConsumer.maxOutboundBytes", o.SlowConsumer.MaxOutboundBytes)
+	o.SlowConsumer.MaxOutboundPackets = o.getInt("slowConsumer.maxOutboundPackets", o.SlowConsumer.MaxOutboundPackets)
+	o.SlowConsumer.Policy = SlowConsumerPolicy(o.getString("slowConsumer.policy", string(o.SlowConsumer.Policy)))
+	o.SlowConsumer.DisconnectDelay = o.getDuration("slowConsumer.disconnectDelay", o.SlowConsumer.DisconnectDelay)
+
+	o.Compression.WS.On = o.getBool("compression.ws.on", o.Compression.WS.On)
+	o.Compression.WS.Level = o.getInt("compression.ws.level", o.Compression.WS.Level)
+	o.Compression.WS.Threshold = o.getInt("compression.ws.threshold", o.Compression.WS.Threshold)
+	o.Compression.WS.ContextTakeover = o.getBool("compression.ws.contextTakeover", o.Compression.WS.ContextTakeover)
+	o.Compression.TCP.On = o.getBool("compression.tcp.on", o.Compression.TCP.On)
+	o.Compression.TCP.Algorithm = o.getString("compression.tcp.algorithm", o.Compression.TCP.Algorithm)
+	o.Compression.TCP.Level = o.getInt("compression.tcp.level", o.Compression.TCP.Level)
+	o.Compression.TCP.Threshold = o.getInt("compression.tcp.threshold", o.Compression.TCP.Threshold)
+
+	o.Route.LoadCacheTTL = o.getDuration("route.loadCacheTTL", o.Route.LoadCacheTTL)
+	o.Route.ZoneAffinity = o.getBool("route.zoneAffinity", o.Route.ZoneAffinity)
+	o.Route.SlotLeaderAffinity = o.getBool("route.slotLeaderAffinity", o.Route.SlotLeaderAffinity)
 
 	if o.WSSConfig.CertFile != "" && o.WSSConfig.KeyFile != "" {
 		certificate, err := tls.LoadX509KeyPair(o.WSSConfig.CertFile, o.WSSConfig.KeyFile)
@@ -654,11 +983,21 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.Cluster.ChannelReplicaCount = o.getInt("cluster.channelReplicaCount", o.Cluster.ChannelReplicaCount)
 	o.Cluster.ServerAddr = o.getString("cluster.serverAddr", o.Cluster.ServerAddr)
 	o.Cluster.PongMaxTick = o.getInt("cluster.pongMaxTick", o.Cluster.PongMaxTick)
+	o.Cluster.Zone = o.getString("cluster.zone", o.Cluster.Zone)
+	o.Cluster.Rack = o.getString("cluster.rack", o.Cluster.Rack)
+	o.Cluster.ChannelRepairInterval = o.getDuration("cluster.channelRepairInterval", o.Cluster.ChannelRepairInterval)
+	o.Cluster.ChannelRepairMaxPerRound = o.getInt("cluster.channelRepairMaxPerRound", o.Cluster.ChannelRepairMaxPerRound)
+	o.Cluster.ChannelRepairDeadTimeout = o.getDuration("cluster.channelRepairDeadTimeout", o.Cluster.ChannelRepairDeadTimeout)
+	o.Cluster.ChannelLeaderBalanceInterval = o.getDuration("cluster.channelLeaderBalanceInterval", o.Cluster.ChannelLeaderBalanceInterval)
+	o.Cluster.ChannelLeaderBalanceThreshold = o.getFloat64("cluster.channelLeaderBalanceThreshold", o.Cluster.ChannelLeaderBalanceThreshold)
+	o.Cluster.ChannelLeaderBalanceMaxPerRound = o.getInt("cluster.channelLeaderBalanceMaxPerRound", o.Cluster.ChannelLeaderBalanceMaxPerRound)
+	o.Cluster.ChannelLeaderBalanceDryRun = o.getBool("cluster.channelLeaderBalanceDryRun", o.Cluster.ChannelLeaderBalanceDryRun)
+	o.Cluster.FollowerRead = o.getBool("cluster.followerRead", o.Cluster.FollowerRead)
 
 	o.Cluster.ReqTimeout = o.getDuration("cluster.reqTimeout", o.Cluster.ReqTimeout)
 	o.Cluster.Seed = o.getString("cluster.seed", o.Cluster.Seed)
 	o.Cluster.SlotCount = o.getInt("cluster.slotCount", o.Cluster.SlotCount)
-	nodes := o.getStringSlice("cluster.initNodes") // 格式为： nodeID@addr 例如 1@localhost:11110
+	nodes := o.getStringSlice("cluster.initNodes") // 格式为： nodeID@addr[@zone[/rack]] 例如 1@localhost:11110 或 1@localhost:11110@zone-a/rack-1
 	if len(nodes) > 0 {
 		for _, nodeStr := range nodes {
 			if !strings.Contains(nodeStr, "@") {
@@ -676,9 +1015,16 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 				addr = fmt.Sprintf("%s:%s", addr, defaultPort)
 			}
 
+			var zone, rack string
+			if len(nodeStrs) > 2 { // 节点所在的可用区和机架
+				zone, rack, _ = strings.Cut(nodeStrs[2], "/")
+			}
+
 			o.Cluster.InitNodes = append(o.Cluster.InitNodes, &Node{
 				Id:         nodeID,
 				ServerAddr: addr,
+				Zone:       zone,
+				Rack:       rack,
 			})
 		}
 	}
@@ -695,6 +1041,9 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.Trace.ServiceHostName = o.getString("trace.serviceHostName", fmt.Sprintf("%s[%d]", o.Trace.ServiceName, o.Cluster.NodeId))
 	o.Trace.PrometheusApiUrl = o.getString("trace.prometheusApiUrl", o.Trace.PrometheusApiUrl)
 	o.Trace.SampleRate = o.getFloat64("trace.sampleRate", o.Trace.SampleRate)
+	o.Trace.MetricsHistoryOn = o.getBool("trace.metricsHistoryOn", o.Trace.MetricsHistoryOn)
+	o.Trace.MetricsHistoryInterval = o.getDuration("trace.metricsHistoryInterval", o.Trace.MetricsHistoryInterval)
+	o.Trace.MetricsHistoryRetention = o.getDuration("trace.metricsHistoryRetention", o.Trace.MetricsHistoryRetention)
 
 	// =================== deliver ===================
 	o.Deliver.DeliverrCount = o.getInt("deliver.deliverrCount", o.Deliver.DeliverrCount)
@@ -874,6 +1223,20 @@ func (o *Options) Check() error {
 	if o.Cluster.NodeId == 0 {
 		return errors.New("cluster.nodeId must be set")
 	}
+	switch o.SlowConsumer.Policy {
+	case SlowConsumerPolicyDrop, SlowConsumerPolicyCoalesce, SlowConsumerPolicyDisconnect:
+	default:
+		return fmt.Errorf("slowConsumer.policy must be one of drop, coalesce, disconnect: %s", o.SlowConsumer.Policy)
+	}
+	if o.Compression.WS.Level < 1 || o.Compression.WS.Level > 9 {
+		return fmt.Errorf("compression.ws.level must be between 1 and 9: %d", o.Compression.WS.Level)
+	}
+	if o.Compression.TCP.Algorithm != CompressionAlgorithmDeflate {
+		return fmt.Errorf("compression.tcp.algorithm must be deflate: %s", o.Compression.TCP.Algorithm)
+	}
+	if o.Compression.TCP.Level < 1 || o.Compression.TCP.Level > 9 {
+		return fmt.Errorf("compression.tcp.level must be between 1 and 9: %d", o.Compression.TCP.Level)
+	}
 
 	return nil
 }
@@ -883,10 +1246,28 @@ func (o *Options) ClusterOn() bool {
 }
 
 func (o *Options) configureLog(vp *viper.Viper) {
+	o.Logger.Level, o.Logger.ModuleLevels = logLevelsOfViper(vp, o.Mode)
+	o.Logger.Dir = vp.GetString("logger.dir")
+	if strings.TrimSpace(o.Logger.Dir) == "" {
+		o.Logger.Dir = "logs"
+	}
+	if !strings.HasPrefix(strings.TrimSpace(o.Logger.Dir), "/") {
+		o.Logger.Dir = filepath.Join(o.RootDir, o.Logger.Dir)
+	}
+	o.Logger.LineNum = o.getBool("logger.lineNum", o.Logger.LineNum)
+	o.Logger.Format = o.getString("logger.format", o.Logger.Format)
+	o.Logger.MaxSize = o.getInt("logger.maxSize", o.Logger.MaxSize)
+	o.Logger.MaxAge = o.getInt("logger.maxAge", o.Logger.MaxAge)
+	o.Logger.MaxBackups = o.getInt("logger.maxBackups", o.Logger.MaxBackups)
+	o.Logger.Compress = o.getBool("logger.compress", o.Logger.Compress)
+}
+
+// logLevelsOfViper 读取全局和模块的日志级别
+func logLevelsOfViper(vp *viper.Viper, mode Mode) (zapcore.Level, map[string]zapcore.Level) {
 	logLevel := vp.GetInt("logger.level")
 	// level
 	if logLevel == 0 { // 没有设置
-		if o.Mode == DebugMode {
+		if mode == DebugMode {
 			logLevel = int(zapcore.DebugLevel)
 		} else {
 			logLevel = int(zapcore.InfoLevel)
@@ -894,15 +1275,21 @@ func (o *Options) configureLog(vp *viper.Viper) {
 	} else {
 		logLevel = logLevel - 2
 	}
-	o.Logger.Level = zapcore.Level(logLevel)
-	o.Logger.Dir = vp.GetString("logger.dir")
-	if strings.TrimSpace(o.Logger.Dir) == "" {
-		o.Logger.Dir = "logs"
-	}
-	if !strings.HasPrefix(strings.TrimSpace(o.Logger.Dir), "/") {
-		o.Logger.Dir = filepath.Join(o.RootDir, o.Logger.Dir)
+
+	// 模块的日志级别 例如 logger.modules.cluster: 1
+	var moduleLevels map[string]zapcore.Level
+	modules := vp.GetStringMap("logger.modules")
+	if len(modules) > 0 {
+		moduleLevels = make(map[string]zapcore.Level, len(modules))
+		for module := range modules {
+			level := vp.GetInt("logger.modules." + module)
+			if level <= 0 {
+				continue
+			}
+			moduleLevels[module] = zapcore.Level(level - 2)
+		}
 	}
-	o.Logger.LineNum = o.getBool("logger.lineNum", o.Logger.LineNum)
+	return zapcore.Level(logLevel), moduleLevels
 }
 
 // IsTmpChannel 是否是临时频道
@@ -988,17 +1375,22 @@ func (o *Options) getDuration(key string, defaultValue time.Duration) time.Durat
 
 // WebhookOn WebhookOn
 func (o *Options) WebhookOn() bool {
-	return strings.TrimSpace(o.Webhook.HTTPAddr) != "" || o.WebhookGRPCOn()
+	return o.Live().WebhookOn()
 }
 
 // WebhookGRPCOn 是否配置了webhook grpc地址
 func (o *Options) WebhookGRPCOn() bool {
-	return strings.TrimSpace(o.Webhook.GRPCAddr) != ""
+	return o.Live().WebhookGRPCOn()
 }
 
 // HasDatasource 是否有配置数据源
 func (o *Options) HasDatasource() bool {
-	return strings.TrimSpace(o.Datasource.Addr) != ""
+	return strings.TrimSpace(o.Datasource.Addr) != "" || o.DatasourceGRPCOn()
+}
+
+// DatasourceGRPCOn 是否配置了数据源grpc地址
+func (o *Options) DatasourceGRPCOn() bool {
+	return strings.TrimSpace(o.Datasource.GRPCAddr) != ""
 }
 
 // 获取客服频道的访客id
@@ -1037,6 +1429,30 @@ func (o *Options) CmdChannelConvertOrginalChannel(channelId string) string {
 
 }
 
+// IsThreadChannel 是否是子区频道
+func (o *Options) IsThreadChannel(channelId string) bool {
+	_, _, ok := o.ThreadChannelConvertParentChannel(channelId)
+	return ok
+}
+
+// ParentConvertThreadChannel 将父频道和根消息序号转换为子区频道
+func (o *Options) ParentConvertThreadChannel(channelId string, rootMessageSeq uint64) string {
+	return fmt.Sprintf("%s%s%d", channelId, o.Channel.ThreadSeparator, rootMessageSeq)
+}
+
+// ThreadChannelConvertParentChannel 将子区频道转换为父频道和根消息序号
+func (o *Options) ThreadChannelConvertParentChannel(channelId string) (string, uint64, bool) {
+	index := strings.LastIndex(channelId, o.Channel.ThreadSeparator)
+	if index <= 0 {
+		return channelId, 0, false
+	}
+	rootMessageSeq, err := strconv.ParseUint(channelId[index+len(o.Channel.ThreadSeparator):], 10, 64)
+	if err != nil || rootMessageSeq == 0 {
+		return channelId, 0, false
+	}
+	return channelId[:index], rootMessageSeq, true
+}
+
 // 获取内网地址
 func getIntranetIP() string {
 	intranetIPs, err := wkutil.GetIntranetIP()
@@ -1077,6 +1493,8 @@ func GetExternalIP() (string, error) {
 type Node struct {
 	Id         uint64
 	ServerAddr string
+	Zone       string // 节点所在可用区
+	Rack       string // 节点所在机架
 }
 
 type Option func(opts *Options)
@@ -1280,6 +1698,24 @@ func WithDatasourceAddr(addr string) Option {
 	}
 }
 
+func WithDatasourceGRPCAddr(grpcAddr string) Option {
+	return func(opts *Options) {
+		opts.Datasource.GRPCAddr = grpcAddr
+	}
+}
+
+func WithDatasourceCacheSize(cacheSize int) Option {
+	return func(opts *Options) {
+		opts.Datasource.CacheSize = cacheSize
+	}
+}
+
+func WithDatasourceCacheTTL(cacheTTL time.Duration) Option {
+	return func(opts *Options) {
+		opts.Datasource.CacheTTL = cacheTTL
+	}
+}
+
 func WithDatasourceChannelInfoOn(channelInfoOn bool) Option {
 	return func(opts *Options) {
 		opts.Datasource.ChannelInfoOn = channelInfoOn
@@ -1509,6 +1945,66 @@ func WithClusterPongMaxTick(pongMaxTick int) Option {
 	}
 }
 
+func WithClusterZone(zone string) Option {
+	return func(opts *Options) {
+		opts.Cluster.Zone = zone
+	}
+}
+
+func WithClusterRack(rack string) Option {
+	return func(opts *Options) {
+		opts.Cluster.Rack = rack
+	}
+}
+
+func WithClusterChannelRepairInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.Cluster.ChannelRepairInterval = interval
+	}
+}
+
+func WithClusterChannelRepairMaxPerRound(max int) Option {
+	return func(opts *Options) {
+		opts.Cluster.ChannelRepairMaxPerRound = max
+	}
+}
+
+func WithClusterChannelRepairDeadTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.Cluster.ChannelRepairDeadTimeout = timeout
+	}
+}
+
+func WithClusterChannelLeaderBalanceInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.Cluster.ChannelLeaderBalanceInterval = interval
+	}
+}
+
+func WithClusterChannelLeaderBalanceThreshold(threshold float64) Option {
+	return func(opts *Options) {
+		opts.Cluster.ChannelLeaderBalanceThreshold = threshold
+	}
+}
+
+func WithClusterChannelLeaderBalanceMaxPerRound(max int) Option {
+	return func(opts *Options) {
+		opts.Cluster.ChannelLeaderBalanceMaxPerRound = max
+	}
+}
+
+func WithClusterChannelLeaderBalanceDryRun(dryRun bool) Option {
+	return func(opts *Options) {
+		opts.Cluster.ChannelLeaderBalanceDryRun = dryRun
+	}
+}
+
+func WithClusterFollowerRead(followerRead bool) Option {
+	return func(opts *Options) {
+		opts.Cluster.FollowerRead = followerRead
+	}
+}
+
 func WithTraceEndpoint(endpoint string) Option {
 	return func(opts *Options) {
 		opts.Trace.Endpoint = endpoint
@@ -1533,6 +2029,24 @@ func WithTracePrometheusApiUrl(prometheusApiUrl string) Option {
 	}
 }
 
+func WithTraceMetricsHistoryOn(on bool) Option {
+	return func(opts *Options) {
+		opts.Trace.MetricsHistoryOn = on
+	}
+}
+
+func WithTraceMetricsHistoryInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.Trace.MetricsHistoryInterval = interval
+	}
+}
+
+func WithTraceMetricsHistoryRetention(retention time.Duration) Option {
+	return func(opts *Options) {
+		opts.Trace.MetricsHistoryRetention = retention
+	}
+}
+
 func WithReactorChannelSubCount(channelSubCount int) Option {
 	return func(opts *Options) {
 		opts.Reactor.ChannelSubCount = channelSubCount
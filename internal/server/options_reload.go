@@ -0,0 +1,337 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+)
+
+// ReloadableOptions 可以热加载的配置，修改配置文件后通过 SIGHUP 或 /manager/config/reload 在集群内生效
+// 不在这里的配置都是静态配置，修改后需要重启节点才能生效
+// key为配置文件里的配置名，view为展示时的配置名（与key不同时才需要）
+// 运行时通过 Options.Live() 读取，热加载时整体替换，读取到的配置不可修改
+type ReloadableOptions struct {
+	ManagerToken                       string                   `key:"managerToken"`
+	TokenAuthOn                        bool                     `key:"tokenAuthOn"`
+	WebhookHTTPAddr                    string                   `key:"webhook.httpAddr"`
+	WebhookGRPCAddr                    string                   `key:"webhook.grpcAddr"`
+	WebhookMsgNotifyEventRetryMaxCount int                      `key:"webhook.msgNotifyEventRetryMaxCount"`
+	WebhookMsgNotifyEventCountPerPush  int                      `key:"webhook.msgNotifyEventCountPerPush"`
+	MessageRetryInterval               time.Duration            `key:"messageRetry.interval"`
+	MessageRetryScanInterval           time.Duration            `key:"messageRetry.scanInterval"`
+	MessageRetryMaxCount               int                      `key:"messageRetry.maxCount"`
+	ConversationCacheExpire            time.Duration            `key:"conversation.cacheExpire"`
+	ConversationSyncInterval           time.Duration            `key:"conversation.syncInterval"`
+	ConversationUserMaxCount           int                      `key:"conversation.userMaxCount"`
+	LoggerLevel                        zapcore.Level            `key:"logger.level"`
+	LoggerModuleLevels                 map[string]zapcore.Level `key:"logger.modules" view:"logger.moduleLevels"`
+}
+
+// ReloadableOptionKeys 可以热加载的配置名
+func ReloadableOptionKeys() []string {
+	t := reflect.TypeOf(ReloadableOptions{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, t.Field(i).Tag.Get("key"))
+	}
+	return keys
+}
+
+// ManagerTokenOn 管理者的token是否开启
+func (r *ReloadableOptions) ManagerTokenOn() bool {
+	return strings.TrimSpace(r.ManagerToken) != ""
+}
+
+// WebhookOn 是否配置了webhook
+func (r *ReloadableOptions) WebhookOn() bool {
+	return strings.TrimSpace(r.WebhookHTTPAddr) != "" || r.WebhookGRPCOn()
+}
+
+// WebhookGRPCOn 是否配置了webhook grpc地址
+func (r *ReloadableOptions) WebhookGRPCOn() bool {
+	return strings.TrimSpace(r.WebhookGRPCAddr) != ""
+}
+
+// Check 检查配置是否合法
+func (r ReloadableOptions) Check() error {
+	if strings.TrimSpace(r.WebhookHTTPAddr) != "" {
+		u, err := url.Parse(r.WebhookHTTPAddr)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook.httpAddr格式有误: %s", r.WebhookHTTPAddr)
+		}
+	}
+	if strings.TrimSpace(r.WebhookGRPCAddr) != "" && !strings.Contains(r.WebhookGRPCAddr, ":") {
+		return fmt.Errorf("webhook.grpcAddr格式有误，格式为 ip:port: %s", r.WebhookGRPCAddr)
+	}
+	if r.WebhookMsgNotifyEventRetryMaxCount <= 0 {
+		return errors.New("webhook.msgNotifyEventRetryMaxCount必须大于0")
+	}
+	if r.WebhookMsgNotifyEventCountPerPush <= 0 {
+		return errors.New("webhook.msgNotifyEventCountPerPush必须大于0")
+	}
+	if r.MessageRetryInterval <= 0 {
+		return errors.New("messageRetry.interval必须大于0")
+	}
+	if r.MessageRetryScanInterval <= 0 {
+		return errors.New("messageRetry.scanInterval必须大于0")
+	}
+	if r.MessageRetryMaxCount < 0 {
+		return errors.New("messageRetry.maxCount不能小于0")
+	}
+	if r.ConversationCacheExpire <= 0 {
+		return errors.New("conversation.cacheExpire必须大于0")
+	}
+	if r.ConversationSyncInterval <= 0 {
+		return errors.New("conversation.syncInterval必须大于0")
+	}
+	if r.ConversationUserMaxCount < 0 {
+		return errors.New("conversation.userMaxCount不能小于0")
+	}
+	if r.LoggerLevel < zapcore.DebugLevel || r.LoggerLevel > zapcore.FatalLevel {
+		return fmt.Errorf("logger.level有误: %d", r.LoggerLevel+2)
+	}
+	for module, level := range r.LoggerModuleLevels {
+		if level < zapcore.DebugLevel || level > zapcore.FatalLevel {
+			return fmt.Errorf("logger.modules.%s有误: %d", module, level+2)
+		}
+	}
+	return nil
+}
+
+// ChangedKeys 与另一份配置相比发生变化的配置名
+func (r ReloadableOptions) ChangedKeys(other ReloadableOptions) []string {
+	keys := make([]string, 0)
+	v1 := reflect.ValueOf(r)
+	v2 := reflect.ValueOf(other)
+	t := v1.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f1, f2 := v1.Field(i).Interface(), v2.Field(i).Interface()
+		if t.Field(i).Type.Kind() == reflect.Map && v1.Field(i).Len() == 0 && v2.Field(i).Len() == 0 { // nil和空map视为相同
+			continue
+		}
+		if !reflect.DeepEqual(f1, f2) {
+			keys = append(keys, t.Field(i).Tag.Get("key"))
+		}
+	}
+	return keys
+}
+
+// Live 运行时生效的可热加载配置，并发读取安全，返回的配置不可修改
+// 第一次读取时使用启动时的配置
+func (o *Options) Live() *ReloadableOptions {
+	if r := o.reloadable.Load(); r != nil {
+		return r
+	}
+	r := o.staticReloadable()
+	o.reloadable.CompareAndSwap(nil, &r)
+	return o.reloadable.Load()
+}
+
+// Reloadable 当前生效的可热加载配置（副本）
+func (o *Options) Reloadable() ReloadableOptions {
+	return o.Live().clone()
+}
+
+func (r *ReloadableOptions) clone() ReloadableOptions {
+	c := *r
+	if len(r.LoggerModuleLevels) > 0 {
+		c.LoggerModuleLevels = make(map[string]zapcore.Level, len(r.LoggerModuleLevels))
+		for module, level := range r.LoggerModuleLevels {
+			c.LoggerModuleLevels[module] = level
+		}
+	}
+	return c
+}
+
+// staticReloadable 启动时配置里的可热加载配置
+func (o *Options) staticReloadable() ReloadableOptions {
+	r := ReloadableOptions{
+		ManagerToken:                       o.ManagerToken,
+		TokenAuthOn:                        o.TokenAuthOn,
+		WebhookHTTPAddr:                    o.Webhook.HTTPAddr,
+		WebhookGRPCAddr:                    o.Webhook.GRPCAddr,
+		WebhookMsgNotifyEventRetryMaxCount: o.Webhook.MsgNotifyEventRetryMaxCount,
+		WebhookMsgNotifyEventCountPerPush:  o.Webhook.MsgNotifyEventCountPerPush,
+		MessageRetryInterval:               o.MessageRetry.Interval,
+		MessageRetryScanInterval:           o.MessageRetry.ScanInterval,
+		MessageRetryMaxCount:               o.MessageRetry.MaxCount,
+		ConversationCacheExpire:            o.Conversation.CacheExpire,
+		ConversationSyncInterval:           o.Conversation.SyncInterval,
+		ConversationUserMaxCount:           o.Conversation.UserMaxCount,
+		LoggerLevel:                        o.Logger.Level,
+	}
+	if len(o.Logger.ModuleLevels) > 0 {
+		r.LoggerModuleLevels = make(map[string]zapcore.Level, len(o.Logger.ModuleLevels))
+		for module, level := range o.Logger.ModuleLevels {
+			r.LoggerModuleLevels[module] = level
+		}
+	}
+	return r
+}
+
+// LoadReloadable 从配置对象中读取可热加载的配置，配置文件里没有的配置项使用默认值
+func (o *Options) LoadReloadable(vp *viper.Viper) ReloadableOptions {
+	defaults := NewOptions()
+	defaults.vp = vp
+	defaults.Mode = o.Mode
+
+	defaults.ManagerToken = defaults.getString("managerToken", defaults.ManagerToken)
+	defaults.TokenAuthOn = defaults.getBool("tokenAuthOn", defaults.TokenAuthOn)
+	defaults.Webhook.HTTPAddr = defaults.getString("webhook.httpAddr", defaults.Webhook.HTTPAddr)
+	defaults.Webhook.GRPCAddr = defaults.getString("webhook.grpcAddr", defaults.Webhook.GRPCAddr)
+	defaults.Webhook.MsgNotifyEventRetryMaxCount = defaults.getInt("webhook.msgNotifyEventRetryMaxCount", defaults.Webhook.MsgNotifyEventRetryMaxCount)
+	defaults.Webhook.MsgNotifyEventCountPerPush = defaults.getInt("webhook.msgNotifyEventCountPerPush", defaults.Webhook.MsgNotifyEventCountPerPush)
+	defaults.MessageRetry.Interval = defaults.getDuration("messageRetry.interval", defaults.MessageRetry.Interval)
+	defaults.MessageRetry.ScanInterval = defaults.getDuration("messageRetry.scanInterval", defaults.MessageRetry.ScanInterval)
+	defaults.MessageRetry.MaxCount = defaults.getInt("messageRetry.maxCount", defaults.MessageRetry.MaxCount)
+	defaults.Conversation.CacheExpire = defaults.getDuration("conversation.cacheExpire", defaults.Conversation.CacheExpire)
+	defaults.Conversation.SyncInterval = defaults.getDuration("conversation.syncInterval", defaults.Conversation.SyncInterval)
+	defaults.Conversation.UserMaxCount = defaults.getInt("conversation.userMaxCount", defaults.Conversation.UserMaxCount)
+	defaults.Logger.Level, defaults.Logger.ModuleLevels = logLevelsOfViper(vp, o.Mode)
+
+	return defaults.staticReloadable()
+}
+
+// ApplyReloadable 应用可热加载的配置，返回发生变化的配置名
+// 只替换 Live() 的配置，不修改启动时的配置字段，避免与并发读取产生数据竞争
+func (o *Options) ApplyReloadable(r ReloadableOptions) []string {
+	changedKeys := o.Reloadable().ChangedKeys(r)
+	if len(changedKeys) == 0 {
+		return changedKeys
+	}
+	live := r.clone()
+	o.reloadable.Store(&live)
+	return changedKeys
+}
+
+// ReadConfigFile 重新读取启动时使用的配置文件（环境变量同样生效）
+func (o *Options) ReadConfigFile() (*viper.Viper, error) {
+	configFile := o.ConfigFileUsed()
+	if strings.TrimSpace(configFile) == "" {
+		return nil, errors.New("启动时没有指定配置文件")
+	}
+	vp := viper.New()
+	vp.SetConfigFile(configFile)
+	if err := vp.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	vp.SetEnvPrefix("wk")
+	vp.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	vp.AutomaticEnv()
+	return vp, nil
+}
+
+// secretFieldNames 配置展示时需要隐藏的字段（字段名包含这些词的字符串字段）
+var secretFieldNames = []string{"Token", "Secret", "Password"}
+
+// View 生效中的配置，用于展示，敏感信息会被隐藏
+func (o *Options) View() map[string]interface{} {
+	view := optionsView(reflect.ValueOf(o).Elem())
+
+	// 可热加载的配置以运行时生效的为准
+	live := o.Live()
+	v := reflect.ValueOf(live).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("view")
+		if key == "" {
+			key = field.Tag.Get("key")
+		}
+		value, ok := optionValueView(field.Name, v.Field(i))
+		if !ok {
+			continue
+		}
+		parent := view
+		names := strings.Split(key, ".")
+		for _, name := range names[:len(names)-1] {
+			child, ok := parent[name].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				parent[name] = child
+			}
+			parent = child
+		}
+		parent[names[len(names)-1]] = value
+	}
+	view["managerTokenOn"] = live.ManagerTokenOn()
+	return view
+}
+
+func optionsView(v reflect.Value) map[string]interface{} {
+	t := v.Type()
+	view := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if value, ok := optionValueView(field.Name, v.Field(i)); ok {
+			view[lowerFirst(field.Name)] = value
+		}
+	}
+	return view
+}
+
+func optionValueView(name string, v reflect.Value) (interface{}, bool) {
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String(), true
+	case zapcore.Level:
+		return value.String(), true
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return optionsView(v), true
+	case reflect.String:
+		for _, secret := range secretFieldNames {
+			if strings.Contains(name, secret) && v.String() != "" {
+				return "******", true
+			}
+		}
+		return v.String(), true
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return v.Interface(), true
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if item, ok := optionValueView(name, v.Index(i)); ok {
+				items = append(items, item)
+			}
+		}
+		return items, true
+	case reflect.Map:
+		items := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if item, ok := optionValueView(name, iter.Value()); ok {
+				items[fmt.Sprintf("%v", iter.Key().Interface())] = item
+			}
+		}
+		return items, true
+	}
+	return nil, false // 函数、指针等不展示
+}
+
+// lowerFirst 字段名转换为配置名的格式，例如 HTTPAddr 转换为 httpAddr
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) { // 下一个单词的首字母
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestReloadableOptionsCheck(t *testing.T) {
+	opts := NewOptions()
+	assert.NoError(t, opts.Reloadable().Check())
+
+	r := opts.Reloadable()
+	r.WebhookHTTPAddr = "127.0.0.1:8080"
+	assert.Error(t, r.Check())
+
+	r = opts.Reloadable()
+	r.WebhookGRPCAddr = "127.0.0.1"
+	assert.Error(t, r.Check())
+
+	r = opts.Reloadable()
+	r.MessageRetryScanInterval = 0
+	assert.Error(t, r.Check())
+
+	r = opts.Reloadable()
+	r.LoggerModuleLevels = map[string]zapcore.Level{"cluster": zapcore.Level(10)}
+	assert.Error(t, r.Check())
+}
+
+func TestReloadableOptionsChangedKeys(t *testing.T) {
+	opts := NewOptions()
+	r1 := opts.Reloadable()
+	r2 := opts.Reloadable()
+	assert.Empty(t, r1.ChangedKeys(r2))
+
+	r2.LoggerModuleLevels = map[string]zapcore.Level{}
+	assert.Empty(t, r1.ChangedKeys(r2))
+
+	r2.ManagerToken = "token"
+	r2.MessageRetryInterval = time.Second
+	r2.LoggerModuleLevels = map[string]zapcore.Level{"cluster": zapcore.DebugLevel}
+	assert.Equal(t, []string{"managerToken", "messageRetry.interval", "logger.modules"}, r1.ChangedKeys(r2))
+}
+
+func TestOptionsReloadFromConfigFile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "wk.yaml")
+	err := os.WriteFile(configFile, []byte("managerToken: \"abc\"\nmessageRetry:\n  scanInterval: 2s\nlogger:\n  modules:\n    cluster: 1\n"), 0o600)
+	assert.NoError(t, err)
+
+	opts := NewOptions()
+	opts.vp = viper.New()
+	opts.vp.SetConfigFile(configFile)
+
+	vp, err := opts.ReadConfigFile()
+	assert.NoError(t, err)
+	r := opts.LoadReloadable(vp)
+	assert.NoError(t, r.Check())
+
+	changedKeys := opts.ApplyReloadable(r)
+	assert.Equal(t, []string{"managerToken", "messageRetry.scanInterval", "logger.modules"}, changedKeys)
+	assert.Equal(t, "abc", opts.Live().ManagerToken)
+	assert.True(t, opts.Live().ManagerTokenOn())
+	assert.Equal(t, time.Second*2, opts.Live().MessageRetryScanInterval)
+	assert.Equal(t, zapcore.DebugLevel, opts.Live().LoggerModuleLevels["cluster"])
+	// 启动时的配置不会被修改
+	assert.Equal(t, "", opts.ManagerToken)
+
+	// 再次应用没有变化
+	assert.Empty(t, opts.ApplyReloadable(opts.LoadReloadable(vp)))
+}
+
+func TestOptionsView(t *testing.T) {
+	opts := NewOptions()
+	opts.ManagerToken = "secret"
+	opts.MessageRetry.Interval = time.Second * 30
+
+	view := opts.View()
+	assert.Equal(t, "******", view["managerToken"])
+	assert.Equal(t, opts.ManagerUID, view["managerUID"])
+	assert.Equal(t, "30s", view["messageRetry"].(map[string]interface{})["interval"])
+
+	_, err := json.Marshal(view)
+	assert.NoError(t, err)
+
+	// 热加载后展示生效中的配置
+	r := opts.Reloadable()
+	r.MessageRetryInterval = time.Minute
+	r.LoggerModuleLevels = map[string]zapcore.Level{"cluster": zapcore.DebugLevel}
+	opts.ApplyReloadable(r)
+	view = opts.View()
+	assert.Equal(t, "1m0s", view["messageRetry"].(map[string]interface{})["interval"])
+	assert.Equal(t, "debug", view["logger"].(map[string]interface{})["moduleLevels"].(map[string]interface{})["cluster"])
+	assert.Equal(t, true, view["managerTokenOn"])
+}
+
+func TestLowerFirst(t *testing.T) {
+	assert.Equal(t, "httpAddr", lowerFirst("HTTPAddr"))
+	assert.Equal(t, "managerToken", lowerFirst("ManagerToken"))
+	assert.Equal(t, "id", lowerFirst("ID"))
+	assert.Equal(t, "grpcAddr", lowerFirst("GRPCAddr"))
+}
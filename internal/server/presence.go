@@ -0,0 +1,178 @@
+package server
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/lni/goutils/syncutil"
+	"go.uber.org/zap"
+)
+
+// 在线状态变更的cmd类型
+const presenceCMDType = "presence"
+
+// presenceManager 在线状态管理，负责记录用户最后上下线时间并通知在线状态订阅者
+// 注意：只有用户的领导节点才会触发上下线事件（用户领导节点就是用户所在槽的领导节点，所以订阅者数据在本地）
+// 同一个用户的事件始终由同一个worker处理，保证上下线事件的顺序
+type presenceManager struct {
+	s *Server
+	wklog.Log
+	eventCs []chan *presenceEvent // 每个worker一个事件队列
+	stopper *syncutil.Stopper
+	message *MessageAPI
+}
+
+func newPresenceManager(s *Server) *presenceManager {
+	workerCount := s.opts.Presence.WorkerCount
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+	eventCs := make([]chan *presenceEvent, workerCount)
+	for i := 0; i < workerCount; i++ {
+		eventCs[i] = make(chan *presenceEvent, 1024)
+	}
+	return &presenceManager{
+		s:       s,
+		Log:     wklog.NewWKLog("presenceManager"),
+		eventCs: eventCs,
+		stopper: syncutil.NewStopper(),
+		message: NewMessageAPI(s),
+	}
+}
+
+func (p *presenceManager) start() {
+	for _, eventC := range p.eventCs {
+		eventC := eventC
+		p.stopper.RunWorker(func() {
+			p.loop(eventC)
+		})
+	}
+}
+
+func (p *presenceManager) stop() {
+	p.stopper.Stop()
+}
+
+// 用户上线（用户的第一个连接）
+func (p *presenceManager) online(uid string, deviceFlag wkproto.DeviceFlag) {
+	p.addEvent(&presenceEvent{
+		uid:        uid,
+		online:     true,
+		deviceFlag: deviceFlag,
+		at:         uint64(time.Now().Unix()),
+	})
+}
+
+// 用户离线（用户的所有连接都已关闭）
+func (p *presenceManager) offline(uid string) {
+	p.addEvent(&presenceEvent{
+		uid:    uid,
+		online: false,
+		at:     uint64(time.Now().Unix()),
+	})
+}
+
+func (p *presenceManager) addEvent(event *presenceEvent) {
+	if !p.s.opts.Presence.On {
+		return
+	}
+	if event.uid == p.s.opts.SystemUID || event.uid == p.s.opts.ManagerUID {
+		return
+	}
+	select {
+	case p.eventC(event.uid) <- event:
+	case <-p.stopper.ShouldStop():
+	default:
+		p.Warn("presence event queue is full, discard event", zap.String("uid", event.uid), zap.Bool("online", event.online))
+	}
+}
+
+// eventC 用户的事件队列，按uid哈希到固定的worker
+func (p *presenceManager) eventC(uid string) chan *presenceEvent {
+	h := fnv.New32a()
+	h.Write([]byte(uid))
+	return p.eventCs[h.Sum32()%uint32(len(p.eventCs))]
+}
+
+func (p *presenceManager) loop(eventC chan *presenceEvent) {
+	for {
+		select {
+		case event := <-eventC:
+			p.handleEvent(event)
+		case <-p.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+func (p *presenceManager) handleEvent(event *presenceEvent) {
+	user, err := p.s.store.GetUser(event.uid)
+	if err != nil && err != wkdb.ErrNotFound {
+		p.Error("get user failed", zap.Error(err), zap.String("uid", event.uid))
+		return
+	}
+	if event.online {
+		user.LastOnlineAt = event.at
+	} else {
+		user.LastOfflineAt = event.at
+	}
+	// 更新最后上下线时间（只写对应的列，不覆盖隐藏在线状态等并发修改的数据）
+	err = p.s.store.UpdateUserLastSeen(event.uid, event.online, event.at)
+	if err != nil {
+		p.Error("update user last seen failed", zap.Error(err), zap.String("uid", event.uid))
+	}
+
+	if user.PresenceHidden { // 用户隐藏了在线状态，不通知订阅者
+		return
+	}
+
+	subscribers, err := p.s.store.GetPresenceSubscribers(event.uid)
+	if err != nil {
+		p.Error("get presence subscribers failed", zap.Error(err), zap.String("uid", event.uid))
+		return
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	online := 0
+	if event.online {
+		online = 1
+	}
+	payload := []byte(wkutil.ToJSON(map[string]interface{}{
+		"type":            presenceCMDType,
+		"uid":             event.uid,
+		"online":          online,
+		"device_flag":     event.deviceFlag,
+		"last_online_at":  user.LastOnlineAt,
+		"last_offline_at": user.LastOfflineAt,
+	}))
+
+	for _, subscriber := range subscribers {
+		clientMsgNo := fmt.Sprintf("%s0", wkutil.GenUUID())
+		// 在线状态通知不存储，订阅者不在线则直接丢弃
+		_, err = p.message.sendMessageToChannel(MessageSendReq{
+			Header: MessageHeader{
+				NoPersist: 1,
+				SyncOnce:  1,
+			},
+			FromUID: p.s.opts.SystemUID,
+			Payload: payload,
+		}, subscriber, wkproto.ChannelTypePerson, clientMsgNo, wkproto.StreamFlagIng)
+		if err != nil {
+			p.Warn("send presence cmd failed", zap.Error(err), zap.String("uid", event.uid), zap.String("subscriber", subscriber))
+		}
+	}
+}
+
+type presenceEvent struct {
+	uid        string
+	online     bool
+	deviceFlag wkproto.DeviceFlag
+	at         uint64 // 事件发生时间（10位时间戳）
+}
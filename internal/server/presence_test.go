@@ -0,0 +1,26 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresenceEventCByUid(t *testing.T) {
+	s := NewTestServer(t)
+	s.opts.Presence.WorkerCount = 4
+	p := newPresenceManager(s)
+	assert.Equal(t, 4, len(p.eventCs))
+
+	// 同一个用户的事件始终进入同一个队列，保证上下线的顺序
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, p.eventC("u1"), p.eventC("u1"))
+	}
+
+	p.online("u1", 0)
+	p.offline("u1")
+	eventC := p.eventC("u1")
+	assert.Equal(t, 2, len(eventC))
+	assert.True(t, (<-eventC).online)
+	assert.False(t, (<-eventC).online)
+}
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"errors"
+
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	wkproto "github.com/WuKongIM/WuKongIMGoProto"
 	"go.uber.org/zap"
@@ -39,6 +42,29 @@ func (r *retryManager) stop() {
 
 }
 
+// flush 立即处理所有等待回执的消息，连接还在的立即重发，连接已断开的结束重试
+func (r *retryManager) flush() {
+	for _, retryQueue := range r.retryQueues {
+		retryQueue.flush()
+	}
+}
+
+// inFlightCount 等待回执的消息数量
+func (r *retryManager) inFlightCount() int {
+	count := 0
+	for _, retryQueue := range r.retryQueues {
+		count += retryQueue.inFlightCount()
+	}
+	return count
+}
+
+// resetScanInterval 按新的扫描间隔重启所有重试队列的扫描
+func (r *retryManager) resetScanInterval() {
+	for _, retryQueue := range r.retryQueues {
+		retryQueue.resetScanInterval()
+	}
+}
+
 func (r *retryManager) addRetry(msg *retryMessage) {
 	index := msg.messageId % int64(len(r.retryQueues))
 	r.retryQueues[index].startInFlightTimeout(msg)
@@ -46,24 +72,32 @@ func (r *retryManager) addRetry(msg *retryMessage) {
 
 func (r *retryManager) removeRetry(connId int64, messageId int64) error {
 	index := messageId % int64(len(r.retryQueues))
-	return r.retryQueues[index].finishMessage(connId, messageId)
+	msg, err := r.retryQueues[index].finishMessage(connId, messageId)
+	if err != nil {
+		return err
+	}
+	msg.endSpan(nil) // 收到回执
+	return nil
 }
 
 func (r *retryManager) retry(msg *retryMessage) {
 	r.Debug("retry msg", zap.Int("retryCount", msg.retry), zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int64("connId", msg.connId))
 	msg.retry++
-	if msg.retry > r.s.opts.MessageRetry.MaxCount {
-		r.Debug("exceeded the maximum number of retries", zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int("messageMaxRetryCount", r.s.opts.MessageRetry.MaxCount))
+	if maxCount := r.s.opts.Live().MessageRetryMaxCount; msg.retry > maxCount {
+		r.Debug("exceeded the maximum number of retries", zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int("messageMaxRetryCount", maxCount))
+		msg.endSpan(errors.New("exceeded the maximum number of retries"))
 		return
 	}
 	userHandler := r.s.userReactor.getUser(msg.uid)
 	if userHandler == nil {
 		r.Debug("user offline, retry end", zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int64("connId", msg.connId))
+		msg.endSpan(errors.New("user offline"))
 		return
 	}
 	conn := userHandler.getConnById(msg.connId)
 	if conn == nil {
 		r.Debug("conn offline", zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int64("connId", msg.connId))
+		msg.endSpan(errors.New("conn offline"))
 		return
 	}
 	// 添加到重试队列
@@ -81,11 +115,25 @@ func (r *retryManager) retry(msg *retryMessage) {
 }
 
 type retryMessage struct {
-	recvPacketData []byte // 接受包数据
-	uid            string // 用户id
-	connId         int64  // 需要接受的连接id
-	messageId      int64  // 消息id
-	retry          int    // 重试次数
-	index          int    //在切片中的索引值
-	pri            int64  // 优先级的时间点 值越小越优先
+	recvPacketData []byte     // 接受包数据
+	uid            string     // 用户id
+	connId         int64      // 需要接受的连接id
+	messageId      int64      // 消息id
+	retry          int        // 重试次数
+	index          int        //在切片中的索引值
+	pri            int64      // 优先级的时间点 值越小越优先
+	span           trace.Span // 等待回执的链路
+}
+
+// endSpan 结束等待回执的链路（收到回执或者不再重试）
+func (r *retryMessage) endSpan(err error) {
+	if r.span == nil {
+		return
+	}
+	r.span.SetInt("retry", r.retry)
+	if err != nil {
+		r.span.RecordError(err)
+	}
+	r.span.End()
+	r.span = nil
 }
@@ -41,7 +41,7 @@ func NewRetryQueue(index int, s *Server) *RetryQueue {
 
 func (r *RetryQueue) startInFlightTimeout(msg *retryMessage) {
 	now := time.Now()
-	msg.pri = now.Add(r.s.opts.MessageRetry.Interval).UnixNano()
+	msg.pri = now.Add(r.s.opts.Live().MessageRetryInterval).UnixNano()
 	r.pushInFlightMessage(msg)
 	r.addToInFlightPQ(msg)
 
@@ -86,14 +86,14 @@ func (r *RetryQueue) getInFlightKey(connId int64, messageId int64) string {
 	b.WriteString(strconv.FormatInt(messageId, 10))
 	return b.String()
 }
-func (r *RetryQueue) finishMessage(connId int64, messageId int64) error {
+func (r *RetryQueue) finishMessage(connId int64, messageId int64) (*retryMessage, error) {
 	msg, err := r.popInFlightMessage(connId, messageId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	r.removeFromInFlightPQ(msg)
 
-	return nil
+	return msg, nil
 }
 func (r *RetryQueue) removeFromInFlightPQ(msg *retryMessage) {
 	r.inFlightMutex.Lock()
@@ -114,7 +114,7 @@ func (r *RetryQueue) processInFlightQueue(t int64) {
 		if msg == nil {
 			break
 		}
-		err := r.finishMessage(msg.connId, msg.messageId)
+		_, err := r.finishMessage(msg.connId, msg.messageId)
 		if err != nil {
 			r.Error("processInFlightQueue-finishMessage失败", zap.Error(err), zap.Int64("connId", msg.connId), zap.Int64("messageId", msg.messageId))
 			break
@@ -123,14 +123,36 @@ func (r *RetryQueue) processInFlightQueue(t int64) {
 	}
 }
 
+// flush 立即处理队列里所有的消息（重试后重新入队的消息优先级晚于本次处理的截止时间，不会被重复处理）
+func (r *RetryQueue) flush() {
+	r.processInFlightQueue(time.Now().Add(r.s.opts.Live().MessageRetryInterval).UnixNano() - 1)
+}
+
+func (r *RetryQueue) inFlightCount() int {
+	r.inFlightMutex.Lock()
+	defer r.inFlightMutex.Unlock()
+	return len(r.inFlightMessages)
+}
+
 // Start 开始运行重试
 func (r *RetryQueue) Start() {
-	r.retryTimer = r.s.Schedule(r.s.opts.MessageRetry.ScanInterval, func() {
+	r.retryTimer = r.s.Schedule(r.s.opts.Live().MessageRetryScanInterval, func() {
 		now := time.Now().UnixNano()
 		r.processInFlightQueue(now)
 	})
 }
 
+// resetScanInterval messageRetry.scanInterval热加载后按新的间隔重新扫描
+func (r *RetryQueue) resetScanInterval() {
+	if r.stopped.Load() {
+		return
+	}
+	if r.retryTimer != nil {
+		r.retryTimer.Stop()
+	}
+	r.Start()
+}
+
 func (r *RetryQueue) Stop() {
 	r.stopped.Store(true)
 	if r.retryTimer != nil {
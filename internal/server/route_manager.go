@@ -0,0 +1,161 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap"
+)
+
+// routeManager 连接路由，为用户挑选连接的节点
+// 候选节点为在线、已加入集群且没有在排空的节点，挑选的优先级：
+// 同可用区的节点（开启zoneAffinity时）> 用户所在槽的领导节点（开启slotLeaderAffinity时）> 连接数最少的节点
+type routeManager struct {
+	s *Server
+	wklog.Log
+
+	mu      sync.Mutex
+	loads   []*nodeLoadResp // 候选节点的负载
+	loadsAt time.Time       // 负载的获取时间
+}
+
+func newRouteManager(s *Server) *routeManager {
+	return &routeManager{
+		s:   s,
+		Log: wklog.NewWKLog("routeManager"),
+	}
+}
+
+// route 为用户挑选连接的节点，zone为客户端所在可用区，为空时使用当前节点的可用区
+func (r *routeManager) route(uids []string, zone string) map[uint64]*userAddrResp {
+	if zone == "" {
+		zone = r.s.opts.Cluster.Zone
+	}
+	candidates := r.candidates()
+	assigned := make(map[uint64]int)
+	results := make(map[uint64]*userAddrResp)
+	for _, uid := range uids {
+		addr := r.fallback()
+		if len(candidates) > 0 {
+			var preferNodeId uint64
+			if r.s.opts.Route.SlotLeaderAffinity {
+				leaderId, err := r.s.cluster.SlotLeaderIdOfChannel(uid, wkproto.ChannelTypePerson)
+				if err != nil {
+					r.Warn("get slot leader of user failed", zap.Error(err), zap.String("uid", uid))
+				}
+				preferNodeId = leaderId
+			}
+			load := pickRouteNode(candidates, zone, r.s.opts.Route.ZoneAffinity, preferNodeId, assigned)
+			assigned[load.NodeId]++
+			addr = &load.nodeAddrResp
+		}
+		result := results[addr.NodeId]
+		if result == nil {
+			result = &userAddrResp{
+				NodeId:  addr.NodeId,
+				TCPAddr: addr.TCPAddr,
+				WSAddr:  addr.WSAddr,
+				WSSAddr: addr.WSSAddr,
+				UIDs:    make([]string, 0),
+			}
+			results[addr.NodeId] = result
+		}
+		result.UIDs = append(result.UIDs, uid)
+	}
+	return results
+}
+
+// fallback 没有候选节点时返回当前节点，当前节点排空中时返回排空时分配的其他节点
+func (r *routeManager) fallback() *nodeAddrResp {
+	if addr := r.s.drainManager.reconnectAddr(); addr != nil {
+		return addr
+	}
+	return r.s.nodeAddr()
+}
+
+// candidates 候选节点的负载，缓存Route.LoadCacheTTL时间
+func (r *routeManager) candidates() []*nodeLoadResp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loads != nil && time.Since(r.loadsAt) < r.s.opts.Route.LoadCacheTTL {
+		return r.loads
+	}
+
+	var (
+		loadsLock sync.Mutex
+		wg        sync.WaitGroup
+	)
+	loads := make([]*nodeLoadResp, 0)
+	for _, node := range r.s.clusterServer.GetConfig().Nodes {
+		if !routeable(node) && node.Id != r.s.opts.Cluster.NodeId {
+			continue
+		}
+		if node.Id == r.s.opts.Cluster.NodeId {
+			if load := r.s.nodeLoad(); !load.Draining {
+				loads = append(loads, load)
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(nodeId uint64) {
+			defer wg.Done()
+			load, err := r.s.requestNodeLoad(nodeId)
+			if err != nil {
+				r.Warn("request node load failed", zap.Error(err), zap.Uint64("nodeId", nodeId))
+				return
+			}
+			if load.Draining {
+				return
+			}
+			loadsLock.Lock()
+			loads = append(loads, load)
+			loadsLock.Unlock()
+		}(node.Id)
+	}
+	wg.Wait()
+
+	sort.Slice(loads, func(i, j int) bool {
+		return loads[i].NodeId < loads[j].NodeId
+	})
+	r.loads = loads
+	r.loadsAt = time.Now()
+	return loads
+}
+
+// routeable 节点是否可以接收客户端连接
+func routeable(node *pb.Node) bool {
+	if !node.Online {
+		return false
+	}
+	return node.Status != pb.NodeStatus_NodeStatusWillJoin && node.Status != pb.NodeStatus_NodeStatusJoining
+}
+
+// pickRouteNode 从候选节点中挑选连接数最少的节点，assigned为本次已分配给各节点的用户数量（计入连接数）
+func pickRouteNode(candidates []*nodeLoadResp, zone string, zoneAffinity bool, preferNodeId uint64, assigned map[uint64]int) *nodeLoadResp {
+	if zoneAffinity && zone != "" {
+		zoneCandidates := make([]*nodeLoadResp, 0, len(candidates))
+		for _, candidate := range candidates {
+			if candidate.Zone == zone {
+				zoneCandidates = append(zoneCandidates, candidate)
+			}
+		}
+		if len(zoneCandidates) > 0 {
+			candidates = zoneCandidates
+		}
+	}
+
+	var picked *nodeLoadResp
+	for _, candidate := range candidates {
+		if candidate.NodeId == preferNodeId {
+			return candidate
+		}
+		if picked == nil || candidate.ConnCount+assigned[candidate.NodeId] < picked.ConnCount+assigned[picked.NodeId] {
+			picked = candidate
+		}
+	}
+	return picked
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickRouteNode(t *testing.T) {
+	candidates := []*nodeLoadResp{
+		{nodeAddrResp: nodeAddrResp{NodeId: 1}, Zone: "a", ConnCount: 10},
+		{nodeAddrResp: nodeAddrResp{NodeId: 2}, Zone: "a", ConnCount: 5},
+		{nodeAddrResp: nodeAddrResp{NodeId: 3}, Zone: "b", ConnCount: 1},
+	}
+
+	// 连接数最少
+	assert.Equal(t, uint64(3), pickRouteNode(candidates, "", true, 0, map[uint64]int{}).NodeId)
+
+	// 同可用区优先
+	assert.Equal(t, uint64(2), pickRouteNode(candidates, "a", true, 0, map[uint64]int{}).NodeId)
+	assert.Equal(t, uint64(3), pickRouteNode(candidates, "a", false, 0, map[uint64]int{}).NodeId)
+
+	// 可用区没有节点时不限制可用区
+	assert.Equal(t, uint64(3), pickRouteNode(candidates, "c", true, 0, map[uint64]int{}).NodeId)
+
+	// 槽领导节点优先，但不能跨可用区
+	assert.Equal(t, uint64(1), pickRouteNode(candidates, "a", true, 1, map[uint64]int{}).NodeId)
+	assert.Equal(t, uint64(2), pickRouteNode(candidates, "a", true, 3, map[uint64]int{}).NodeId)
+
+	// 本次已分配的用户计入连接数
+	assigned := map[uint64]int{}
+	for i := 0; i < 6; i++ {
+		assigned[pickRouteNode(candidates, "a", true, 0, assigned).NodeId]++
+	}
+	assert.Equal(t, 1, assigned[1])
+	assert.Equal(t, 5, assigned[2])
+}
+
+func TestRouteable(t *testing.T) {
+	assert.True(t, routeable(&pb.Node{Online: true, Status: pb.NodeStatus_NodeStatusJoined}))
+	assert.True(t, routeable(&pb.Node{Online: true}))
+	assert.False(t, routeable(&pb.Node{Online: false, Status: pb.NodeStatus_NodeStatusJoined}))
+	assert.False(t, routeable(&pb.Node{Online: true, Status: pb.NodeStatus_NodeStatusJoining}))
+	assert.False(t, routeable(&pb.Node{Online: true, Status: pb.NodeStatus_NodeStatusWillJoin}))
+}
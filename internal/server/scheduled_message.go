@@ -0,0 +1,152 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RussellLuo/timingwheel"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// scheduledMessageManager 定时消息管理
+// 定时消息通过目标频道所在的槽复制，由槽的领导节点负责到时发送
+type scheduledMessageManager struct {
+	s *Server
+	wklog.Log
+	timer   *timingwheel.Timer
+	firing  atomic.Bool // 是否正在处理到期的定时消息
+	stopped atomic.Bool
+	message *MessageAPI
+}
+
+func newScheduledMessageManager(s *Server) *scheduledMessageManager {
+	return &scheduledMessageManager{
+		s:       s,
+		Log:     wklog.NewWKLog("scheduledMessageManager"),
+		message: NewMessageAPI(s),
+	}
+}
+
+func (m *scheduledMessageManager) start() {
+	m.timer = m.s.Schedule(m.s.opts.ScheduledMessage.ScanInterval, func() {
+		if !m.firing.CompareAndSwap(false, true) { // 上一次还没处理完
+			return
+		}
+		defer m.firing.Store(false)
+		m.fireDueMessages()
+	})
+}
+
+func (m *scheduledMessageManager) stop() {
+	m.stopped.Store(true)
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+}
+
+// schedule 添加定时消息
+func (m *scheduledMessageManager) schedule(req MessageSendReq, clientMsgNo string) (wkdb.ScheduledMessage, error) {
+	sendAt := req.SendAt
+	req.SendAt = 0
+	req.ClientMsgNo = clientMsgNo
+	scheduledMessage := wkdb.ScheduledMessage{
+		Id:          m.s.store.NextPrimaryKey(),
+		ChannelId:   req.ChannelID,
+		ChannelType: req.ChannelType,
+		FromUid:     req.FromUID,
+		SendAt:      uint64(sendAt),
+		Data:        []byte(wkutil.ToJSON(req)),
+		CreatedAt:   uint64(time.Now().Unix()),
+	}
+	err := m.s.store.AddOrUpdateScheduledMessage(scheduledMessage)
+	if err != nil {
+		return wkdb.EmptyScheduledMessage, err
+	}
+	return scheduledMessage, nil
+}
+
+// 发送已到期的定时消息（只发送本节点是槽领导的频道的定时消息）
+// 本节点上有所有副本槽的定时消息，按(发送时间, id)分页扫描，跳过非本节点负责的和发送失败的，不影响后面的定时消息
+func (m *scheduledMessageManager) fireDueMessages() {
+	var (
+		now          = uint64(time.Now().Unix())
+		offsetSendAt uint64
+		offsetId     uint64
+	)
+	for !m.stopped.Load() {
+		messages, err := m.s.store.GetDueScheduledMessages(now, offsetSendAt, offsetId, m.s.opts.ScheduledMessage.FireBatchSize)
+		if err != nil {
+			m.Error("get due scheduled messages failed", zap.Error(err))
+			return
+		}
+		for _, scheduledMessage := range messages {
+			if m.stopped.Load() {
+				return
+			}
+			isLeader, err := m.s.cluster.IsSlotLeaderOfChannel(scheduledMessage.ChannelId, scheduledMessage.ChannelType)
+			if err != nil {
+				m.Warn("get slot leader failed", zap.Error(err), zap.String("channelId", scheduledMessage.ChannelId))
+				continue
+			}
+			if !isLeader { // 由槽的领导节点负责发送
+				continue
+			}
+			m.fire(scheduledMessage)
+		}
+		// 已经没有更多到期的消息了
+		if len(messages) < m.s.opts.ScheduledMessage.FireBatchSize {
+			return
+		}
+		lastMessage := messages[len(messages)-1]
+		offsetSendAt, offsetId = lastMessage.SendAt, lastMessage.Id
+	}
+}
+
+// fire 发送定时消息，发送成功后移除定时消息，发送失败次数过多的定时消息进入死信
+func (m *scheduledMessageManager) fire(scheduledMessage wkdb.ScheduledMessage) {
+	var req MessageSendReq
+	err := wkutil.ReadJSONByByte(scheduledMessage.Data, &req)
+	if err != nil {
+		m.Error("scheduled message data is invalid, remove it", zap.Error(err), zap.Uint64("id", scheduledMessage.Id))
+	} else {
+		_, err = m.send(req)
+		if err != nil {
+			m.Error("send scheduled message failed", zap.Error(err), zap.Uint64("id", scheduledMessage.Id), zap.String("channelId", scheduledMessage.ChannelId), zap.Uint8("channelType", scheduledMessage.ChannelType), zap.Uint32("failCount", scheduledMessage.FailCount+1))
+			m.fireFailed(scheduledMessage)
+			return
+		}
+	}
+	// 移除失败下次会重新发送，定时消息带有clientMsgNo，频道领导存储时会去重
+	err = m.s.store.RemoveScheduledMessages(scheduledMessage.ChannelId, scheduledMessage.ChannelType, []uint64{scheduledMessage.Id})
+	if err != nil {
+		m.Error("remove scheduled message failed", zap.Error(err), zap.Uint64("id", scheduledMessage.Id))
+	}
+}
+
+// fireFailed 记录定时消息的发送失败次数，达到最大次数后进入死信，不再到期发送（可以查询到，重新设置发送时间后恢复）
+func (m *scheduledMessageManager) fireFailed(scheduledMessage wkdb.ScheduledMessage) {
+	scheduledMessage.FailCount++
+	if scheduledMessage.FailCount >= uint32(m.s.opts.ScheduledMessage.MaxFailCount) {
+		scheduledMessage.Dead = true
+		m.Error("scheduled message failed too many times, move to dead letter", zap.Uint64("id", scheduledMessage.Id), zap.String("channelId", scheduledMessage.ChannelId), zap.Uint8("channelType", scheduledMessage.ChannelType), zap.Uint32("failCount", scheduledMessage.FailCount))
+	}
+	err := m.s.store.AddOrUpdateScheduledMessage(scheduledMessage)
+	if err != nil {
+		m.Error("update scheduled message fail count failed", zap.Error(err), zap.Uint64("id", scheduledMessage.Id))
+	}
+}
+
+func (m *scheduledMessageManager) send(req MessageSendReq) (int64, error) {
+	clientMsgNo := req.ClientMsgNo
+	if strings.TrimSpace(clientMsgNo) == "" {
+		clientMsgNo = fmt.Sprintf("%s0", wkutil.GenUUID())
+	}
+	return m.message.sendMessageToChannel(req, req.ChannelID, req.ChannelType, clientMsgNo, wkproto.StreamFlagIng)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterstore"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/icluster"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/replica"
+	"github.com/WuKongIM/WuKongIM/pkg/keylock"
 	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
@@ -54,12 +55,26 @@ type Server struct {
 	managerServer *ManagerServer // 管理者api服务
 
 	systemUIDManager *SystemUIDManager // 系统账号管理
+	datasource       IDatasource       // 第三方数据源
 
 	tagManager     *tagManager     // tag管理，用来管理频道订阅者的tag，用于快速查找订阅者所在节点
 	deliverManager *deliverManager // 消息投递管理
 	retryManager   *retryManager   // 消息重试管理
 
 	conversationManager *ConversationManager // 会话管理
+	presenceManager     *presenceManager     // 在线状态管理
+	eventManager        *eventManager        // 临时事件管理
+
+	scheduledMessageManager *scheduledMessageManager // 定时消息管理
+	threadManager           *threadManager           // 子区管理
+	cdcManager              *cdcManager              // 变更数据捕获管理
+	drainManager            *drainManager            // 节点排空
+	configManager           *configManager           // 配置热加载
+	routeManager            *routeManager            // 连接路由
+	customerServiceManager  *customerServiceManager  // 客服管理
+	messageDedupManager     *messageDedupManager     // 消息去重
+
+	channelLock *keylock.KeyLock // 频道的读改写操作（公告、置顶）在频道所在槽的领导上按频道串行执行
 
 	migrateTask *MigrateTask // 迁移任务
 }
@@ -95,6 +110,12 @@ func New(opts *Options) *Server {
 			trace.WithServiceName(s.opts.Trace.ServiceName),
 			trace.WithServiceHostName(s.opts.Trace.ServiceHostName),
 			trace.WithPrometheusApiUrl(s.opts.Trace.PrometheusApiUrl),
+			trace.WithSampleRate(s.opts.Trace.SampleRate),
+			trace.WithNodeId(s.opts.Cluster.NodeId),
+			trace.WithMetricsHistoryOn(s.opts.Trace.MetricsHistoryOn),
+			trace.WithMetricsHistoryInterval(s.opts.Trace.MetricsHistoryInterval),
+			trace.WithMetricsHistoryRetention(s.opts.Trace.MetricsHistoryRetention),
+			trace.WithRemoteMetricsHistory(s.requestNodesMetricsHistory),
 		))
 	trace.SetGlobalTrace(s.trace)
 
@@ -106,6 +127,7 @@ func New(opts *Options) *Server {
 	storeOpts.SlotCount = uint32(s.opts.Cluster.SlotCount)
 	storeOpts.GetSlotId = s.getSlotId
 	storeOpts.IsCmdChannel = opts.IsCmdChannel
+	storeOpts.CDCOn = s.opts.CDC.On
 	storeOpts.Db.ShardNum = s.opts.Db.ShardNum
 	storeOpts.Db.MemTableSize = s.opts.Db.MemTableSize
 	s.store = clusterstore.NewStore(storeOpts)
@@ -119,6 +141,15 @@ func New(opts *Options) *Server {
 		wknet.WithWSAddr(s.opts.WSAddr),
 		wknet.WithWSSAddr(s.opts.WSSAddr),
 		wknet.WithWSTLSConfig(s.opts.WSTLSConfig),
+		wknet.WithMaxOutboundBytes(s.opts.SlowConsumer.MaxOutboundBytes),
+		wknet.WithMaxOutboundPackets(s.opts.SlowConsumer.MaxOutboundPackets),
+		wknet.WithWSCompression(s.opts.Compression.WS.On),
+		wknet.WithWSCompressionLevel(s.opts.Compression.WS.Level),
+		wknet.WithWSCompressionThreshold(s.opts.Compression.WS.Threshold),
+		wknet.WithWSContextTakeover(s.opts.Compression.WS.ContextTakeover),
+		wknet.WithOnCompress(func(rawBytes, compressedBytes int, cost time.Duration) {
+			trace.GlobalTrace.Metrics.App().CompressAdd(int64(rawBytes), int64(compressedBytes), cost)
+		}),
 		wknet.WithOnReadBytes(func(n int) {
 			trace.GlobalTrace.Metrics.System().ExtranetIncomingAdd(int64(n))
 		}),
@@ -130,19 +161,36 @@ func New(opts *Options) *Server {
 	s.channelReactor = newChannelReactor(s, opts)     // 频道的reactor
 	s.userReactor = newUserReactor(s)                 // 用户的reactor
 	s.demoServer = NewDemoServer(s)                   // demo server
+	s.datasource = NewDatasource(s)                   // 第三方数据源
 	s.systemUIDManager = NewSystemUIDManager(s)       // 系统账号管理
 	s.apiServer = NewAPIServer(s)                     // api服务
 	s.managerServer = NewManagerServer(s)             // 管理者的api服务
 	s.retryManager = newRetryManager(s)               // 消息重试管理
 	s.conversationManager = NewConversationManager(s) // 会话管理
+	s.presenceManager = newPresenceManager(s)         // 在线状态管理
+	s.eventManager = newEventManager(s)               // 临时事件管理
 	s.migrateTask = NewMigrateTask(s)                 // 迁移任务
 
+	s.scheduledMessageManager = newScheduledMessageManager(s) // 定时消息管理
+	s.threadManager = newThreadManager(s)                     // 子区管理
+	s.cdcManager = newCDCManager(s)                           // 变更数据捕获管理
+	s.drainManager = newDrainManager(s)                       // 节点排空
+	s.configManager = newConfigManager(s)                     // 配置热加载
+	s.routeManager = newRouteManager(s)                       // 连接路由
+	s.customerServiceManager = newCustomerServiceManager(s)   // 客服管理
+	s.messageDedupManager = newMessageDedupManager(s)         // 消息去重
+	s.channelLock = keylock.NewKeyLock()
+
 	// 初始化分布式服务
 	initNodes := make(map[uint64]string)
+	initNodeLocations := make(map[uint64]cluster.NodeLocation)
 	if len(s.opts.Cluster.InitNodes) > 0 {
 		for _, node := range s.opts.Cluster.InitNodes {
 			serverAddr := strings.ReplaceAll(node.ServerAddr, "tcp://", "")
 			initNodes[node.Id] = serverAddr
+			if node.Zone != "" {
+				initNodeLocations[node.Id] = cluster.NodeLocation{Zone: node.Zone, Rack: node.Rack}
+			}
 		}
 	}
 	role := pb.NodeRole_NodeRoleReplica
@@ -156,11 +204,21 @@ func New(opts *Options) *Server {
 			cluster.WithDataDir(path.Join(opts.DataDir, "cluster")),
 			cluster.WithSlotCount(uint32(s.opts.Cluster.SlotCount)),
 			cluster.WithInitNodes(initNodes),
+			cluster.WithInitNodeLocations(initNodeLocations),
 			cluster.WithSeed(s.opts.Cluster.Seed),
 			cluster.WithRole(role),
 			cluster.WithServerAddr(s.opts.Cluster.ServerAddr),
 			cluster.WithMessageLogStorage(s.store.GetMessageShardLogStorage()),
 			cluster.WithApiServerAddr(s.opts.Cluster.APIUrl),
+			cluster.WithZone(s.opts.Cluster.Zone),
+			cluster.WithRack(s.opts.Cluster.Rack),
+			cluster.WithChannelRepairInterval(s.opts.Cluster.ChannelRepairInterval),
+			cluster.WithChannelRepairMaxPerRound(s.opts.Cluster.ChannelRepairMaxPerRound),
+			cluster.WithChannelRepairDeadReplicaTimeout(s.opts.Cluster.ChannelRepairDeadTimeout),
+			cluster.WithChannelLeaderBalanceInterval(s.opts.Cluster.ChannelLeaderBalanceInterval),
+			cluster.WithChannelLeaderBalanceThreshold(s.opts.Cluster.ChannelLeaderBalanceThreshold),
+			cluster.WithChannelLeaderBalanceMaxPerRound(s.opts.Cluster.ChannelLeaderBalanceMaxPerRound),
+			cluster.WithChannelLeaderBalanceDryRun(s.opts.Cluster.ChannelLeaderBalanceDryRun),
 			cluster.WithChannelMaxReplicaCount(s.opts.Cluster.ChannelReplicaCount),
 			cluster.WithSlotMaxReplicaCount(uint32(s.opts.Cluster.SlotReplicaCount)),
 			cluster.WithLogLevel(s.opts.Logger.Level),
@@ -308,6 +366,18 @@ func (s *Server) Start() error {
 
 	s.webhook.Start()
 
+	s.presenceManager.start()
+
+	s.scheduledMessageManager.start()
+
+	s.threadManager.start()
+
+	s.cdcManager.start()
+
+	s.customerServiceManager.start()
+
+	s.channelLock.StartCleanLoop()
+
 	// 判断是否开启迁移任务
 	if strings.TrimSpace(s.opts.OldV1Api) != "" {
 		s.migrateTask.Run()
@@ -331,6 +401,12 @@ func (s *Server) Stop() error {
 
 	s.retryManager.stop()
 	s.conversationManager.Stop()
+	s.presenceManager.stop()
+	s.scheduledMessageManager.stop()
+	s.threadManager.stop()
+	s.cdcManager.stop()
+	s.customerServiceManager.stop()
+	s.channelLock.StopCleanLoop()
 	s.cluster.Stop()
 	s.apiServer.Stop()
 
@@ -361,6 +437,25 @@ func (s *Server) Stop() error {
 	return nil
 }
 
+// Drained 节点排空完成并停止服务后关闭
+func (s *Server) Drained() <-chan struct{} {
+	return s.drainManager.done()
+}
+
+// ReloadConfig 重新读取配置文件，校验通过后在集群内所有在线节点热加载（SIGHUP触发）
+func (s *Server) ReloadConfig() error {
+	results, ok := s.configManager.reload(0)
+	if ok {
+		return nil
+	}
+	for _, result := range results {
+		if result.Err != "" {
+			return fmt.Errorf("node[%d] reload config failed: %s", result.NodeId, result.Err)
+		}
+	}
+	return errors.New("reload config failed")
+}
+
 // 等待分布式就绪
 func (s *Server) MustWaitClusterReady() {
 	s.cluster.MustWaitClusterReady()
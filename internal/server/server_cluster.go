@@ -2,9 +2,17 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	cluster "github.com/WuKongIM/WuKongIM/pkg/cluster/clusterserver"
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
@@ -55,6 +63,38 @@ func (s *Server) setClusterRoutes() {
 	s.cluster.Route("/wk/getNodeUidsByTag", s.getNodeUidsByTag)
 	// 是否允许发送消息
 	s.cluster.Route("/wk/allowSend", s.handleAllowSend)
+	// 投递临时事件（将临时事件转发给对应用户的逻辑节点）
+	s.cluster.Route("/wk/deliverEvent", s.handleDeliverEvent)
+	// 设置日志级别
+	s.cluster.Route("/wk/logLevel", s.handleLogLevel)
+	// 查询节点日志
+	s.cluster.Route("/wk/queryLogs", s.handleQueryLogs)
+	// 获取节点的内置监控数据
+	s.cluster.Route("/wk/metricsHistory", s.handleMetricsHistory)
+	// 清除数据源缓存
+	s.cluster.Route("/wk/datasourceInvalidate", s.handleDatasourceInvalidate)
+	// 拉取本节点作为槽领导的变更事件
+	s.cluster.Route("/wk/cdcPull", s.handleCDCPull)
+	s.cluster.Route("/wk/cdcMessages", s.handleCDCMessages)
+	s.cluster.Route("/wk/channelLastSeq", s.handleChannelLastSeq)
+	// 获取节点对外的连接地址
+	s.cluster.Route("/wk/nodeAddr", s.handleNodeAddr)
+	// 获取节点的负载
+	s.cluster.Route("/wk/nodeLoad", s.handleNodeLoad)
+	// 排空节点
+	s.cluster.Route("/wk/drain", s.handleDrain)
+	// 查询节点排空状态
+	s.cluster.Route("/wk/drainStatus", s.handleDrainStatus)
+	// 校验节点的配置文件
+	s.cluster.Route("/wk/configValidate", s.handleConfigValidate)
+	// 应用节点的配置文件
+	s.cluster.Route("/wk/configApply", s.handleConfigApply)
+	// 获取节点生效中的配置
+	s.cluster.Route("/wk/configView", s.handleConfigView)
+	// 打开客服会话（访客发消息后通知队列所在槽的领导节点）
+	s.cluster.Route("/wk/customerServiceOpen", s.handleCustomerServiceOpen)
+	// 变更客服频道的订阅者（分配、转接、关闭会话时）
+	s.cluster.Route("/wk/customerServiceSubscribers", s.handleCustomerServiceSubscribers)
 
 }
 
@@ -90,12 +130,15 @@ func (s *Server) handleChannelForward(c *wkserver.Context) {
 		sendPacket := reactorChannelMessage.SendPacket
 		// 提案频道消息
 		ch := s.channelReactor.loadOrCreateChannel(req.ChannelId, req.ChannelType)
-		_, err = ch.proposeSend(reactorChannelMessage.ctx, reactorChannelMessage.FromUid, reactorChannelMessage.FromDeviceId, reactorChannelMessage.FromConnId, reactorChannelMessage.FromNodeId, false, sendPacket)
-		if err != nil {
-			s.Error("handleChannelForward: proposeSend failed")
-			c.WriteErr(err)
-			return
-		}
+		ch.proposeMessage(ReactorChannelMessage{
+			ctx:          reactorChannelMessage.ctx,
+			FromConnId:   reactorChannelMessage.FromConnId,
+			FromUid:      reactorChannelMessage.FromUid,
+			FromDeviceId: reactorChannelMessage.FromDeviceId,
+			FromNodeId:   reactorChannelMessage.FromNodeId,
+			SendPacket:   sendPacket,
+			AckRequired:  reactorChannelMessage.AckRequired,
+		})
 	}
 
 	c.WriteOk()
@@ -117,6 +160,10 @@ func (s *Server) handleForwardSendack(c *wkserver.Context) {
 	}
 
 	for _, forwardSendackPacket := range forwardSendackPacketSet {
+		// /message/send 在等待发送结果
+		if s.messageDedupManager.notifySendack(forwardSendackPacket.Uid, forwardSendackPacket.Sendack) {
+			continue
+		}
 		conn := s.userReactor.getConnContext(forwardSendackPacket.Uid, forwardSendackPacket.DeviceId)
 		if conn == nil {
 			s.Error("handleForwardSendack: conn not found", zap.String("uid", forwardSendackPacket.Uid), zap.String("deviceId", forwardSendackPacket.DeviceId))
@@ -253,6 +300,7 @@ func (s *Server) handleUserAuthResult(c *wkserver.Context) {
 		connCtx.deviceLevel = authResult.DeviceLevel
 		connCtx.deviceId = authResult.DeviceId
 		connCtx.protoVersion = authResult.ProtoVersion
+		connCtx.compression = authResult.Compression
 		connCtx.isAuth.Store(true)
 		if connCtx.isRealConn {
 			connCtx.conn.SetMaxIdle(s.opts.ConnIdleTime)
@@ -265,7 +313,13 @@ func (s *Server) handleUserAuthResult(c *wkserver.Context) {
 			NodeId:        s.opts.Cluster.NodeId,
 		}
 		connack.HasServerVersion = authResult.ProtoVersion > 3 // 如果协议版本大于3，就返回serverVersion
-		_ = connCtx.writePacket(connack)
+		data, err := connCtx.encodeConnack(connack)
+		if err != nil {
+			s.Error("handleUserAuthResult: encode connack err", zap.Error(err))
+			c.WriteErr(err)
+			return
+		}
+		_ = connCtx.write(data, wkproto.CONNACK)
 	} else {
 		connCtx.isAuth.Store(false)
 		_ = connCtx.writePacket(&wkproto.ConnackPacket{
@@ -301,6 +355,18 @@ func (s *Server) handleDeliver(c *wkserver.Context) {
 	c.WriteOk()
 }
 
+func (s *Server) handleDeliverEvent(c *wkserver.Context) {
+	var req = &ephemeralEventReq{}
+	err := req.Unmarshal(c.Body())
+	if err != nil {
+		s.Error("handleDeliverEvent Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	s.eventManager.deliver(req.event, req.uids)
+	c.WriteOk()
+}
+
 func (s *Server) getNodeUidsByTag(c *wkserver.Context) {
 	req := &tagReq{}
 	err := req.Unmarshal(c.Body())
@@ -479,3 +545,686 @@ func (s *Server) handleAllowSend(c *wkserver.Context) {
 	}
 	c.WriteErrorAndStatus(errors.New("not allow send"), proto.Status(reasonCode))
 }
+
+// channelNodeForRead 获取读取频道消息的节点
+// 开启了追随者读则选择负载最低且数据不落后于minSeq的副本，否则为频道的领导节点
+func (s *Server) channelNodeForRead(channelId string, channelType uint8, minSeq uint64) (*pb.Node, error) {
+	if s.opts.Cluster.FollowerRead {
+		return s.cluster.ReplicaOfChannelForRead(channelId, channelType, minSeq)
+	}
+	return s.cluster.LeaderOfChannelForRead(channelId, channelType)
+}
+
+// channelLastMessageSeq 获取频道最新的消息序号，消息日志在频道的副本上，本节点不是频道领导时向频道领导请求
+func (s *Server) channelLastMessageSeq(channelId string, channelType uint8) (uint64, error) {
+	if s.opts.ClusterOn() {
+		leaderInfo, err := s.cluster.LeaderOfChannelForRead(channelId, channelType)
+		if errors.Is(err, cluster.ErrChannelClusterConfigNotFound) { // 频道从未初始化，没有消息
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if leaderInfo.Id != s.opts.Cluster.NodeId {
+			return s.requestChannelLastSeq(leaderInfo.Id, &channelLastSeqReq{ChannelId: channelId, ChannelType: channelType})
+		}
+	}
+	return s.store.GetLastMsgSeq(channelId, channelType)
+}
+
+func (s *Server) handleChannelLastSeq(c *wkserver.Context) {
+	req := &channelLastSeqReq{}
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		s.Error("handleChannelLastSeq Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	lastSeq, err := s.store.GetLastMsgSeq(req.ChannelId, req.ChannelType)
+	if err != nil {
+		s.Error("handleChannelLastSeq GetLastMsgSeq err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	data, err := json.Marshal(&channelLastSeqResp{MessageSeq: lastSeq})
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// requestChannelLastSeq 请求频道领导节点获取频道最新的消息序号
+func (s *Server) requestChannelLastSeq(nodeId uint64, req *channelLastSeqReq) (uint64, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return 0, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/channelLastSeq", data)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Status != proto.Status_OK {
+		return 0, fmt.Errorf("channel last seq failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	lastSeqResp := &channelLastSeqResp{}
+	if err := json.Unmarshal(resp.Body, lastSeqResp); err != nil {
+		return 0, err
+	}
+	return lastSeqResp.MessageSeq, nil
+}
+
+// readableMessageSeq 追随者读时当前节点可读取的最大消息序号（已应用的消息），0表示不限制
+func (s *Server) readableMessageSeq(channelId string, channelType uint8) uint64 {
+	if !s.opts.ClusterOn() || !s.opts.Cluster.FollowerRead {
+		return 0
+	}
+	readableSeq, err := s.cluster.ReadableMessageSeqOfChannel(channelId, channelType)
+	if err != nil {
+		s.Warn("readableMessageSeq: get readable message seq failed", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+		return 0
+	}
+	return readableSeq
+}
+
+// 以下方法读取频道消息，追随者读时先把查询范围限制在已应用的消息内再取limit条（先取再过滤会导致消息数量不足和more判断错误）
+
+// loadReadableLastMsgsWithEnd 获取最新的消息，endMessageSeq不为0时只获取大于endMessageSeq的消息
+func (s *Server) loadReadableLastMsgsWithEnd(channelId string, channelType uint8, endMessageSeq uint64, limit int) ([]wkdb.Message, error) {
+	readableSeq := s.readableMessageSeq(channelId, channelType)
+	if readableSeq == 0 {
+		if endMessageSeq == 0 {
+			return s.store.LoadLastMsgs(channelId, channelType, limit)
+		}
+		return s.store.LoadLastMsgsWithEnd(channelId, channelType, endMessageSeq, limit)
+	}
+	return s.loadReadablePrevRangeMsgsWith(channelId, channelType, readableSeq, endMessageSeq, limit, readableSeq)
+}
+
+// loadReadablePrevRangeMsgs 向下拉取消息（包含startMessageSeq，不包含endMessageSeq）
+func (s *Server) loadReadablePrevRangeMsgs(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64, limit int) ([]wkdb.Message, error) {
+	return s.loadReadablePrevRangeMsgsWith(channelId, channelType, startMessageSeq, endMessageSeq, limit, s.readableMessageSeq(channelId, channelType))
+}
+
+func (s *Server) loadReadablePrevRangeMsgsWith(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64, limit int, readableSeq uint64) ([]wkdb.Message, error) {
+	startMessageSeq, ok := readablePrevRange(startMessageSeq, endMessageSeq, readableSeq)
+	if !ok {
+		return nil, nil
+	}
+	return s.store.LoadPrevRangeMsgs(channelId, channelType, startMessageSeq, endMessageSeq, limit)
+}
+
+// loadReadableNextRangeMsgs 向上拉取消息（包含startMessageSeq，不包含endMessageSeq，endMessageSeq为0表示不限制）
+func (s *Server) loadReadableNextRangeMsgs(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64, limit int) ([]wkdb.Message, error) {
+	endMessageSeq, ok := readableNextRange(startMessageSeq, endMessageSeq, s.readableMessageSeq(channelId, channelType))
+	if !ok {
+		return nil, nil
+	}
+	return s.store.LoadNextRangeMsgs(channelId, channelType, startMessageSeq, endMessageSeq, limit)
+}
+
+// readablePrevRange 向下拉取时把开始序号限制在可读取的最大消息序号内，范围内没有可读取的消息返回false
+func readablePrevRange(startMessageSeq, endMessageSeq, readableSeq uint64) (uint64, bool) {
+	if readableSeq != 0 && startMessageSeq > readableSeq {
+		startMessageSeq = readableSeq
+	}
+	if startMessageSeq == 0 || (endMessageSeq != 0 && endMessageSeq >= startMessageSeq) {
+		return startMessageSeq, false
+	}
+	return startMessageSeq, true
+}
+
+// readableNextRange 向上拉取时把结束序号限制在可读取的最大消息序号内，范围内没有可读取的消息返回false
+func readableNextRange(startMessageSeq, endMessageSeq, readableSeq uint64) (uint64, bool) {
+	if readableSeq == 0 {
+		return endMessageSeq, true
+	}
+	if endMessageSeq == 0 || endMessageSeq > readableSeq+1 {
+		endMessageSeq = readableSeq + 1
+	}
+	if startMessageSeq >= endMessageSeq {
+		return endMessageSeq, false
+	}
+	return endMessageSeq, true
+}
+
+func (s *Server) handleLogLevel(c *wkserver.Context) {
+	req := &logLevelReq{}
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		s.Error("handleLogLevel Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	if err := req.apply(); err != nil {
+		c.WriteErr(err)
+		return
+	}
+	s.Info("log level changed", zap.String("level", req.Level), zap.String("module", req.Module), zap.Bool("remove", req.Remove))
+	c.WriteOk()
+}
+
+// requestSetLogLevel 请求指定节点设置日志级别
+func (s *Server) requestSetLogLevel(nodeId uint64, req *logLevelReq) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/logLevel", data)
+	if err != nil {
+		return err
+	}
+	if resp.Status != proto.Status_OK {
+		return fmt.Errorf("set log level failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	return nil
+}
+
+func (s *Server) handleQueryLogs(c *wkserver.Context) {
+	req := &logQueryReq{}
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		s.Error("handleQueryLogs Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	logs, err := wklog.QueryLogs(req.toLogQuery())
+	if err != nil {
+		s.Error("handleQueryLogs: query logs failed", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	data, err := json.Marshal(logs)
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// requestQueryLogs 请求查询指定节点的日志
+func (s *Server) requestQueryLogs(nodeId uint64, req *logQueryReq) ([]map[string]interface{}, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/queryLogs", data)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("query logs failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	var logs []map[string]interface{}
+	if err := json.Unmarshal(resp.Body, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (s *Server) handleMetricsHistory(c *wkserver.Context) {
+	req := &metricsHistoryReq{}
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		s.Error("handleMetricsHistory Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	data, err := json.Marshal(s.trace.Metrics.History(req.Start))
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// requestMetricsHistory 请求获取指定节点start（秒）之后的内置监控数据
+func (s *Server) requestMetricsHistory(nodeId uint64, start int64) ([]*trace.MetricsPoint, error) {
+	data, err := json.Marshal(&metricsHistoryReq{Start: start})
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/metricsHistory", data)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("request metrics history failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	var points []*trace.MetricsPoint
+	if err := json.Unmarshal(resp.Body, &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// requestNodesMetricsHistory 获取其他节点的内置监控数据，nodeId为0表示所有其他在线节点
+// 请求失败的节点只打印日志，不影响其他节点的数据
+func (s *Server) requestNodesMetricsHistory(nodeId uint64, start int64) map[uint64][]*trace.MetricsPoint {
+	nodeIds := make([]uint64, 0)
+	if nodeId != 0 {
+		if nodeId != s.opts.Cluster.NodeId {
+			nodeIds = append(nodeIds, nodeId)
+		}
+	} else if s.clusterServer != nil {
+		for _, node := range s.clusterServer.GetConfig().Nodes {
+			if node.Id == s.opts.Cluster.NodeId || !node.Online {
+				continue
+			}
+			nodeIds = append(nodeIds, node.Id)
+		}
+	}
+
+	var (
+		results = make(map[uint64][]*trace.MetricsPoint, len(nodeIds))
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+	)
+	for _, id := range nodeIds {
+		wg.Add(1)
+		go func(id uint64) {
+			defer wg.Done()
+			points, err := s.requestMetricsHistory(id, start)
+			if err != nil {
+				s.Warn("requestMetricsHistory failed", zap.Uint64("nodeId", id), zap.Error(err))
+				return
+			}
+			mu.Lock()
+			results[id] = points
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	return results
+}
+
+func (s *Server) handleDatasourceInvalidate(c *wkserver.Context) {
+	req := datasourceInvalidateReq{}
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		s.Error("handleDatasourceInvalidate Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	s.invalidateDatasource(req)
+	c.WriteOk()
+}
+
+// requestDatasourceInvalidate 请求指定节点清除数据源缓存
+func (s *Server) requestDatasourceInvalidate(nodeId uint64, req datasourceInvalidateReq) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/datasourceInvalidate", data)
+	if err != nil {
+		return err
+	}
+	if resp.Status != proto.Status_OK {
+		return fmt.Errorf("datasource invalidate failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	return nil
+}
+
+func (s *Server) handleCDCPull(c *wkserver.Context) {
+	req := &cdcPullReq{}
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		s.Error("handleCDCPull Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	resp, err := s.cdcManager.pullLocal(req)
+	if err != nil {
+		s.Error("handleCDCPull pullLocal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// requestCDCPull 请求指定节点拉取其作为领导的槽的变更事件
+func (s *Server) requestCDCPull(nodeId uint64, req *cdcPullReq) (*cdcPullResp, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/cdcPull", data)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("cdc pull failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	pullResp := &cdcPullResp{}
+	if err := json.Unmarshal(resp.Body, pullResp); err != nil {
+		return nil, err
+	}
+	return pullResp, nil
+}
+
+func (s *Server) handleCDCMessages(c *wkserver.Context) {
+	req := &cdcMessagesReq{}
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		s.Error("handleCDCMessages Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	data, err := s.cdcManager.loadLocalMessages(req.ChannelId, req.ChannelType, req.StartMessageSeq, req.EndMessageSeq)
+	if err != nil {
+		s.Error("handleCDCMessages loadLocalMessages err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// requestCDCMessages 请求指定节点读取变更事件引用的消息
+func (s *Server) requestCDCMessages(nodeId uint64, req *cdcMessagesReq) ([]byte, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/cdcMessages", data)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("cdc messages failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	return resp.Body, nil
+}
+
+func (s *Server) handleNodeAddr(c *wkserver.Context) {
+	data, err := json.Marshal(s.nodeAddr())
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// nodeAddr 本节点对外的连接地址
+func (s *Server) nodeAddr() *nodeAddrResp {
+	return &nodeAddrResp{
+		NodeId:  s.opts.Cluster.NodeId,
+		TCPAddr: s.opts.External.TCPAddr,
+		WSAddr:  s.opts.External.WSAddr,
+		WSSAddr: s.opts.External.WSSAddr,
+	}
+}
+
+// requestNodeAddr 请求指定节点对外的连接地址
+func (s *Server) requestNodeAddr(nodeId uint64) (*nodeAddrResp, error) {
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/nodeAddr", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("request node addr failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	addrResp := &nodeAddrResp{}
+	if err := json.Unmarshal(resp.Body, addrResp); err != nil {
+		return nil, err
+	}
+	return addrResp, nil
+}
+
+func (s *Server) handleNodeLoad(c *wkserver.Context) {
+	data, err := json.Marshal(s.nodeLoad())
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// nodeLoad 本节点的负载
+func (s *Server) nodeLoad() *nodeLoadResp {
+	return &nodeLoadResp{
+		nodeAddrResp: *s.nodeAddr(),
+		Zone:         s.opts.Cluster.Zone,
+		ConnCount:    s.engine.ConnCount(),
+		Draining:     s.drainManager.draining(),
+	}
+}
+
+// requestNodeLoad 请求指定节点的负载
+func (s *Server) requestNodeLoad(nodeId uint64) (*nodeLoadResp, error) {
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/nodeLoad", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("request node load failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	loadResp := &nodeLoadResp{}
+	if err := json.Unmarshal(resp.Body, loadResp); err != nil {
+		return nil, err
+	}
+	return loadResp, nil
+}
+
+func (s *Server) handleDrain(c *wkserver.Context) {
+	req := &drainReq{}
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		s.Error("handleDrain Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	timeout, err := req.timeout()
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	if err = s.drainManager.drain(timeout); err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.WriteOk()
+}
+
+// requestDrain 请求指定节点开始排空
+func (s *Server) requestDrain(nodeId uint64, req *drainReq) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/drain", data)
+	if err != nil {
+		return err
+	}
+	if resp.Status != proto.Status_OK {
+		return fmt.Errorf("drain failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	return nil
+}
+
+func (s *Server) handleDrainStatus(c *wkserver.Context) {
+	data, err := json.Marshal(s.drainManager.status())
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// requestDrainStatus 请求指定节点的排空状态
+func (s *Server) requestDrainStatus(nodeId uint64) (*drainStatusResp, error) {
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/drainStatus", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("request drain status failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	statusResp := &drainStatusResp{}
+	if err := json.Unmarshal(resp.Body, statusResp); err != nil {
+		return nil, err
+	}
+	return statusResp, nil
+}
+
+// onlineNodeIds 集群内所有在线节点（包含当前节点）
+func (s *Server) onlineNodeIds() []uint64 {
+	nodeIds := make([]uint64, 0)
+	for _, node := range s.clusterServer.GetConfig().Nodes {
+		if node.Online || node.Id == s.opts.Cluster.NodeId {
+			nodeIds = append(nodeIds, node.Id)
+		}
+	}
+	if len(nodeIds) == 0 {
+		nodeIds = append(nodeIds, s.opts.Cluster.NodeId)
+	}
+	return nodeIds
+}
+
+func (s *Server) handleConfigValidate(c *wkserver.Context) {
+	changedKeys, err := s.configManager.validate()
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	data, err := json.Marshal(changedKeys)
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+func (s *Server) handleConfigApply(c *wkserver.Context) {
+	changedKeys, err := s.configManager.apply()
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	data, err := json.Marshal(changedKeys)
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// requestConfigReload 请求指定节点校验（/wk/configValidate）或应用（/wk/configApply）配置文件，返回发生变化的配置名
+func (s *Server) requestConfigReload(nodeId uint64, path string) ([]string, error) {
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("request %s failed, status: %d err: %s", path, resp.Status, string(resp.Body))
+	}
+	changedKeys := make([]string, 0)
+	if err := json.Unmarshal(resp.Body, &changedKeys); err != nil {
+		return nil, err
+	}
+	return changedKeys, nil
+}
+
+func (s *Server) handleConfigView(c *wkserver.Context) {
+	data, err := json.Marshal(s.configManager.view())
+	if err != nil {
+		c.WriteErr(err)
+		return
+	}
+	c.Write(data)
+}
+
+// requestConfigView 请求指定节点生效中的配置
+func (s *Server) requestConfigView(nodeId uint64) (*configViewResp, error) {
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/configView", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != proto.Status_OK {
+		return nil, fmt.Errorf("request config view failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	viewResp := &configViewResp{}
+	if err := json.Unmarshal(resp.Body, viewResp); err != nil {
+		return nil, err
+	}
+	return viewResp, nil
+}
+
+func (s *Server) handleCustomerServiceOpen(c *wkserver.Context) {
+	if err := s.customerServiceManager.openSession(string(c.Body())); err != nil {
+		s.Error("handleCustomerServiceOpen err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	c.WriteOk()
+}
+
+// requestCustomerServiceOpen 请求队列所在槽的领导节点打开会话
+func (s *Server) requestCustomerServiceOpen(nodeId uint64, channelId string) error {
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/customerServiceOpen", []byte(channelId))
+	if err != nil {
+		return err
+	}
+	if resp.Status != proto.Status_OK {
+		return fmt.Errorf("customer service open failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	return nil
+}
+
+func (s *Server) handleCustomerServiceSubscribers(c *wkserver.Context) {
+	req := customerServiceSubscribersReq{}
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		s.Error("handleCustomerServiceSubscribers Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	if err := s.customerServiceManager.updateSubscribersLocal(req); err != nil {
+		s.Error("handleCustomerServiceSubscribers err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	c.WriteOk()
+}
+
+// requestCustomerServiceSubscribers 请求客服频道所在槽的领导节点变更频道的订阅者
+func (s *Server) requestCustomerServiceSubscribers(nodeId uint64, req customerServiceSubscribersReq) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	resp, err := s.cluster.RequestWithContext(timeoutCtx, nodeId, "/wk/customerServiceSubscribers", data)
+	if err != nil {
+		return err
+	}
+	if resp.Status != proto.Status_OK {
+		return fmt.Errorf("customer service subscribers failed, status: %d err: %s", resp.Status, string(resp.Body))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	cluster "github.com/WuKongIM/WuKongIM/pkg/cluster/clusterserver"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/icluster"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+// testFollowerCluster 模拟数据落后的追随者（只应用到readableSeq）
+type testFollowerCluster struct {
+	icluster.Cluster
+	readableSeq uint64
+}
+
+func (t *testFollowerCluster) ReadableMessageSeqOfChannel(channelId string, channelType uint8) (uint64, error) {
+	return t.readableSeq, nil
+}
+
+func TestReadableRange(t *testing.T) {
+	// 不限制
+	start, ok := readablePrevRange(10, 0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), start)
+	end, ok := readableNextRange(1, 0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), end)
+
+	// 开始序号超过可读取的序号
+	start, ok = readablePrevRange(10, 2, 6)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(6), start)
+	_, ok = readablePrevRange(10, 6, 6)
+	assert.False(t, ok)
+
+	// 结束序号限制在可读取的序号内
+	end, ok = readableNextRange(1, 0, 6)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(7), end)
+	end, ok = readableNextRange(1, 5, 6)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), end)
+	_, ok = readableNextRange(7, 0, 6)
+	assert.False(t, ok)
+}
+
+func TestLoadReadableMessagesOnLaggingFollower(t *testing.T) {
+	s := NewTestServer(t)
+	err := s.store.Open()
+	assert.NoError(t, err)
+	defer s.store.Close()
+
+	messages := make([]wkdb.Message, 0, 10)
+	for i := 1; i <= 10; i++ {
+		messages = append(messages, wkdb.Message{RecvPacket: wkproto.RecvPacket{MessageID: int64(i), MessageSeq: uint32(i), FromUID: "u1", ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup}})
+	}
+	err = s.store.DB().AppendMessages("g1", wkproto.ChannelTypeGroup, messages)
+	assert.NoError(t, err)
+
+	s.opts.Cluster.NodeId = 1
+	s.opts.Cluster.FollowerRead = true
+	s.cluster = &testFollowerCluster{Cluster: s.cluster, readableSeq: 6}
+
+	seqsOf := func(messages []wkdb.Message) []uint32 {
+		seqs := make([]uint32, 0, len(messages))
+		for _, m := range messages {
+			seqs = append(seqs, m.MessageSeq)
+		}
+		return seqs
+	}
+
+	// 先限制在已应用的消息内再取limit条，数量不会因为过滤而不足
+	lastMessages, err := s.loadReadableLastMsgsWithEnd("g1", wkproto.ChannelTypeGroup, 0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{4, 5, 6}, seqsOf(lastMessages))
+
+	prevMessages, err := s.loadReadablePrevRangeMsgs("g1", wkproto.ChannelTypeGroup, 10, 0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{4, 5, 6}, seqsOf(prevMessages))
+
+	nextMessages, err := s.loadReadableNextRangeMsgs("g1", wkproto.ChannelTypeGroup, 5, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{5, 6}, seqsOf(nextMessages))
+
+	// 关闭追随者读后不限制
+	s.opts.Cluster.FollowerRead = false
+	lastMessages, err = s.loadReadableLastMsgsWithEnd("g1", wkproto.ChannelTypeGroup, 0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{8, 9, 10}, seqsOf(lastMessages))
+}
+
+func TestChannelLastMessageSeq(t *testing.T) {
+	s := NewTestServer(t)
+	err := s.store.Open()
+	assert.NoError(t, err)
+	defer s.store.Close()
+
+	err = s.store.DB().AppendMessages("g1", wkproto.ChannelTypeGroup, []wkdb.Message{
+		{RecvPacket: wkproto.RecvPacket{MessageID: 1, MessageSeq: 1, FromUID: "u1", ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup}},
+		{RecvPacket: wkproto.RecvPacket{MessageID: 2, MessageSeq: 2, FromUID: "u1", ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup}},
+	})
+	assert.NoError(t, err)
+
+	// 本节点是频道领导，从本地读取
+	fakeCluster := &testCDCCluster{Cluster: s.cluster, leader: &pb.Node{Id: s.opts.Cluster.NodeId}}
+	s.cluster = fakeCluster
+	lastSeq, err := s.channelLastMessageSeq("g1", wkproto.ChannelTypeGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), lastSeq)
+
+	// 频道从未初始化
+	fakeCluster.err = cluster.ErrChannelClusterConfigNotFound
+	lastSeq, err = s.channelLastMessageSeq("g2", wkproto.ChannelTypeGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), lastSeq)
+}
@@ -42,12 +42,13 @@ func NewAPIServer(s *Server) *APIServer {
 func (s *APIServer) Start() {
 
 	s.r.Use(func(c *wkhttp.Context) { // 管理者权限判断
-		if strings.TrimSpace(s.s.opts.ManagerToken) == "" {
+		token := s.s.opts.Live().ManagerToken
+		if strings.TrimSpace(token) == "" {
 			c.Next()
 			return
 		}
 		managerToken := c.GetHeader("token")
-		if managerToken != s.s.opts.ManagerToken {
+		if managerToken != token {
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
@@ -58,6 +59,10 @@ func (s *APIServer) Start() {
 	s.r.Use(wkhttp.CORSMiddleware())
 	// 带宽流量计算中间件
 	s.r.Use(bandwidthMiddleware())
+	// 链路追踪中间件
+	if s.s.opts.TraceOn() {
+		s.r.Use(traceMiddleware())
+	}
 
 	s.setRoutes()
 	go func() {
@@ -106,10 +111,30 @@ func (s *APIServer) setRoutes() {
 	message := NewMessageAPI(s.s)
 	message.Route(s.r)
 
+	// 临时事件API
+	event := NewEventAPI(s.s)
+	event.Route(s.r)
+
+	// 子区API
+	thread := NewThreadAPI(s.s)
+	thread.Route(s.r)
+
 	// 路由api
 	routeapi := NewRouteAPI(s.s)
 	routeapi.Route(s.r)
 
+	// 数据源api
+	datasource := NewDatasourceAPI(s.s)
+	datasource.Route(s.r)
+
+	// 变更数据捕获api
+	cdc := NewCDCAPI(s.s)
+	cdc.Route(s.r)
+
+	// 客服api
+	customerService := NewCustomerServiceAPI(s.s)
+	customerService.Route(s.r)
+
 	// 分布式api
 	clusterServer, ok := s.s.cluster.(*cluster.Server)
 	if ok {
@@ -144,6 +169,18 @@ func bandwidthMiddleware() wkhttp.HandlerFunc {
 	}
 }
 
+// traceMiddleware http请求的链路（请求头携带W3C traceparent时作为其子链路），通过api发送的消息链路会挂在此链路下
+func traceMiddleware() wkhttp.HandlerFunc {
+	return func(c *wkhttp.Context) {
+		ctx := trace.ContextWithHTTPHeader(c.Request.Context(), c.Request.Header)
+		ctx, span := trace.GlobalTrace.StartSpan(ctx, c.Request.Method+" "+c.FullPath())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		span.SetInt("status", c.Writer.Status())
+		span.End()
+	}
+}
+
 type bodyLogWriter struct {
 	gin.ResponseWriter
 	size int
@@ -123,7 +123,7 @@ func (m *ManagerServer) jwtAndTokenAuthMiddleware() wkhttp.HandlerFunc {
 
 		// 管理token认证
 		token := c.GetHeader("token")
-		if strings.TrimSpace(token) != "" && token == m.s.opts.ManagerToken {
+		if strings.TrimSpace(token) != "" && token == m.s.opts.Live().ManagerToken {
 			c.Set("username", m.s.opts.ManagerUID)
 			c.Next()
 			return
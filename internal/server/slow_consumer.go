@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap"
+)
+
+// 慢消费者断开连接的原因码（客户端接收速度跟不上，属于限流的一种）
+const reasonSlowConsumer = wkproto.ReasonRateLimit
+
+// outboundFrame 待写入连接的一个包
+type outboundFrame struct {
+	data  []byte
+	frame wkproto.Frame // 解码失败时为nil
+}
+
+// handleOutboundOverflow 连接写入data后会超出发送缓冲区的限制，按配置的策略处理
+// 返回处理后可以写入的数据和其中的recv包数量，返回false表示连接已被断开
+func (c *connContext) handleOutboundOverflow(data []byte, recvFrameCount uint32) ([]byte, uint32, bool) {
+	opts := c.subReactor.r.s.opts
+	stats := c.conn.ConnStats()
+	stats.Slow()
+	trace.GlobalTrace.Metrics.App().SlowConsumerCountAdd(1)
+
+	if opts.SlowConsumer.Policy != SlowConsumerPolicyDisconnect {
+		frames := splitOutboundFrames(opts.Proto, data, c.protoVersion)
+		keptFrames := filterOutboundFrames(frames, opts.SlowConsumer.Policy)
+		if dropped := len(frames) - len(keptFrames); dropped > 0 {
+			keptData := make([]byte, 0, len(data))
+			for _, frame := range keptFrames {
+				keptData = append(keptData, frame.data...)
+			}
+			stats.Dropped(dropped, len(data)-len(keptData))
+			trace.GlobalTrace.Metrics.App().SlowConsumerDroppedCountAdd(int64(dropped))
+			c.Debug("slow consumer, drop no persist messages", zap.String("uid", c.uid), zap.String("deviceId", c.deviceId), zap.Int("dropped", dropped))
+
+			data = keptData
+			if uint32(dropped) < recvFrameCount { // 丢弃的都是recv包
+				recvFrameCount -= uint32(dropped)
+			} else {
+				recvFrameCount = 0
+			}
+		}
+		if len(data) == 0 || !c.conn.OutboundOverflow(len(data), 1) {
+			return data, recvFrameCount, true
+		}
+	}
+	c.disconnectSlowConsumer()
+	return nil, 0, false
+}
+
+// disconnectSlowConsumer 断开慢消费者，断开包的Reason里提示客户端重连后同步离线消息
+// 断开前后续写入的数据都会被丢弃，存储的消息客户端可以通过同步获取
+func (c *connContext) disconnectSlowConsumer() {
+	if !c.slowClosing.CompareAndSwap(false, true) {
+		return
+	}
+	s := c.subReactor.r.s
+	stats := c.conn.ConnStats()
+	c.Warn("slow consumer, disconnect", zap.String("uid", c.uid), zap.String("deviceId", c.deviceId), zap.Int64("connId", c.connId), zap.Int("pendingBytes", c.conn.OutboundBuffer().BoundBufferSize()), zap.Int64("pendingPackets", stats.PendingPackets.Load()), zap.Int64("droppedPackets", stats.DroppedPackets.Load()))
+	trace.GlobalTrace.Metrics.App().SlowConsumerDisconnectCountAdd(1)
+
+	// 断开包需要写入已经满了的发送缓冲区
+	c.conn.DisableOutboundLimit()
+	reason, _ := json.Marshal(&slowConsumerReason{Reason: "slow_consumer", Sync: true})
+	data, err := s.opts.Proto.EncodeFrame(&wkproto.DisconnectPacket{
+		ReasonCode: reasonSlowConsumer,
+		Reason:     string(reason),
+	}, c.protoVersion)
+	if err != nil {
+		c.Warn("encode disconnect packet failed", zap.Error(err))
+		c.close()
+		return
+	}
+	_ = c.writeConn(data)
+	s.timingWheel.AfterFunc(s.opts.SlowConsumer.DisconnectDelay, func() {
+		c.close()
+	})
+}
+
+// splitOutboundFrames 将多个包合并的数据拆分为单个的包，解码失败的数据作为一个整体保留
+func splitOutboundFrames(proto wkproto.Protocol, data []byte, version uint8) []outboundFrame {
+	frames := make([]outboundFrame, 0)
+	for len(data) > 0 {
+		frame, size, err := proto.DecodeFrame(data, version)
+		if err != nil || frame == nil || size <= 0 || size > len(data) {
+			frames = append(frames, outboundFrame{data: data})
+			break
+		}
+		frames = append(frames, outboundFrame{data: data[:size], frame: frame})
+		data = data[size:]
+	}
+	return frames
+}
+
+// filterOutboundFrames 按策略过滤不存储的消息，其他的包保持原样和顺序
+func filterOutboundFrames(frames []outboundFrame, policy SlowConsumerPolicy) []outboundFrame {
+	if policy != SlowConsumerPolicyDrop && policy != SlowConsumerPolicyCoalesce {
+		return frames
+	}
+	// 合并时每个频道只保留最新的一条不存储的消息
+	lastIndexMap := make(map[string]int)
+	if policy == SlowConsumerPolicyCoalesce {
+		for i, frame := range frames {
+			if recv := noPersistRecv(frame); recv != nil {
+				lastIndexMap[fmt.Sprintf("%d-%s", recv.ChannelType, recv.ChannelID)] = i
+			}
+		}
+	}
+	keptFrames := make([]outboundFrame, 0, len(frames))
+	for i, frame := range frames {
+		if recv := noPersistRecv(frame); recv != nil {
+			if policy == SlowConsumerPolicyDrop {
+				continue
+			}
+			if lastIndexMap[fmt.Sprintf("%d-%s", recv.ChannelType, recv.ChannelID)] != i {
+				continue
+			}
+		}
+		keptFrames = append(keptFrames, frame)
+	}
+	return keptFrames
+}
+
+func noPersistRecv(frame outboundFrame) *wkproto.RecvPacket {
+	recv, ok := frame.frame.(*wkproto.RecvPacket)
+	if !ok || !recv.NoPersist {
+		return nil
+	}
+	return recv
+}
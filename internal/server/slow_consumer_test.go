@@ -0,0 +1,67 @@
+package server
+
+import (
+	"testing"
+
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeOutboundFrames(t *testing.T, proto wkproto.Protocol, packets ...wkproto.Frame) []byte {
+	data := make([]byte, 0)
+	for _, packet := range packets {
+		frameData, err := proto.EncodeFrame(packet, wkproto.LatestVersion)
+		assert.NoError(t, err)
+		data = append(data, frameData...)
+	}
+	return data
+}
+
+func TestSplitOutboundFrames(t *testing.T) {
+	proto := wkproto.New()
+	data := encodeOutboundFrames(t, proto,
+		&wkproto.RecvPacket{MessageID: 1, ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup, Payload: []byte("hello")},
+		&wkproto.PongPacket{},
+		&wkproto.RecvPacket{Framer: wkproto.Framer{NoPersist: true}, MessageID: 2, ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup, Payload: []byte("typing")},
+	)
+
+	frames := splitOutboundFrames(proto, data, wkproto.LatestVersion)
+	assert.Equal(t, 3, len(frames))
+	assert.Equal(t, int64(1), frames[0].frame.(*wkproto.RecvPacket).MessageID)
+	assert.Equal(t, wkproto.PONG, frames[1].frame.GetFrameType())
+	assert.True(t, frames[2].frame.GetNoPersist())
+
+	joined := make([]byte, 0, len(data))
+	for _, frame := range frames {
+		joined = append(joined, frame.data...)
+	}
+	assert.Equal(t, data, joined)
+}
+
+func TestFilterOutboundFrames(t *testing.T) {
+	proto := wkproto.New()
+	data := encodeOutboundFrames(t, proto,
+		&wkproto.RecvPacket{Framer: wkproto.Framer{NoPersist: true}, MessageID: 1, ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup},
+		&wkproto.RecvPacket{MessageID: 2, ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup},
+		&wkproto.RecvPacket{Framer: wkproto.Framer{NoPersist: true}, MessageID: 3, ChannelID: "g2", ChannelType: wkproto.ChannelTypeGroup},
+		&wkproto.RecvPacket{Framer: wkproto.Framer{NoPersist: true}, MessageID: 4, ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup},
+	)
+	frames := splitOutboundFrames(proto, data, wkproto.LatestVersion)
+
+	messageIds := func(frames []outboundFrame) []int64 {
+		ids := make([]int64, 0, len(frames))
+		for _, frame := range frames {
+			ids = append(ids, frame.frame.(*wkproto.RecvPacket).MessageID)
+		}
+		return ids
+	}
+
+	// 丢弃所有不存储的消息
+	assert.Equal(t, []int64{2}, messageIds(filterOutboundFrames(frames, SlowConsumerPolicyDrop)))
+
+	// 每个频道只保留最新的一条不存储的消息
+	assert.Equal(t, []int64{2, 3, 4}, messageIds(filterOutboundFrames(frames, SlowConsumerPolicyCoalesce)))
+
+	// 断开策略不过滤
+	assert.Equal(t, []int64{1, 2, 3, 4}, messageIds(filterOutboundFrames(frames, SlowConsumerPolicyDisconnect)))
+}
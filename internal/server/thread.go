@@ -0,0 +1,96 @@
+package server
+
+import (
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/lni/goutils/syncutil"
+	"go.uber.org/zap"
+)
+
+// threadManager 子区管理
+// 子区的回复存储在子区频道内（子区频道有自己的消息序号），子区的统计数据（回复数量、最后回复时间、参与者）通过父频道所在的槽复制
+type threadManager struct {
+	s *Server
+	wklog.Log
+	replyC  chan *threadReplyEvent
+	stopper *syncutil.Stopper
+}
+
+func newThreadManager(s *Server) *threadManager {
+	return &threadManager{
+		s:       s,
+		Log:     wklog.NewWKLog("threadManager"),
+		replyC:  make(chan *threadReplyEvent, 1024),
+		stopper: syncutil.NewStopper(),
+	}
+}
+
+func (t *threadManager) start() {
+	t.stopper.RunWorker(t.loop)
+}
+
+func (t *threadManager) stop() {
+	t.stopper.Stop()
+}
+
+// addReplies 子区频道的消息存储成功后，更新子区的统计数据
+// 回复数量取自子区频道的消息序号，队列满丢弃的回复会被同一子区后面的回复修正（不阻塞频道的存储流程）
+func (t *threadManager) addReplies(threadChannelId string, channelType uint8, messages []ReactorChannelMessage) {
+	parentChannelId, rootMessageSeq, ok := t.s.opts.ThreadChannelConvertParentChannel(threadChannelId)
+	if !ok {
+		return
+	}
+	replyAt := uint64(time.Now().Unix())
+	replies := make([]wkdb.ThreadReply, 0, len(messages))
+	for _, msg := range messages {
+		if msg.ReasonCode != wkproto.ReasonSuccess || msg.DuplicateOf != 0 {
+			continue
+		}
+		if msg.SendPacket.NoPersist || msg.MessageSeq == 0 {
+			continue
+		}
+		replies = append(replies, wkdb.ThreadReply{
+			Uid:        msg.FromUid,
+			MessageSeq: uint64(msg.MessageSeq),
+			ReplyAt:    replyAt,
+		})
+	}
+	if len(replies) == 0 {
+		return
+	}
+	select {
+	case t.replyC <- &threadReplyEvent{
+		channelId:      parentChannelId,
+		channelType:    channelType,
+		rootMessageSeq: rootMessageSeq,
+		replies:        replies,
+	}:
+	case <-t.stopper.ShouldStop():
+	default:
+		t.Warn("thread reply queue is full, discard replies, reply count will be corrected by the next reply", zap.String("threadChannelId", threadChannelId), zap.Int("count", len(replies)))
+	}
+}
+
+func (t *threadManager) loop() {
+	for {
+		select {
+		case event := <-t.replyC:
+			err := t.s.store.AddThreadReplies(event.channelId, event.channelType, event.rootMessageSeq, event.replies)
+			if err != nil {
+				t.Error("add thread replies failed", zap.Error(err), zap.String("channelId", event.channelId), zap.Uint8("channelType", event.channelType), zap.Uint64("rootMessageSeq", event.rootMessageSeq))
+			}
+		case <-t.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+type threadReplyEvent struct {
+	channelId      string // 父频道id
+	channelType    uint8  // 父频道类型
+	rootMessageSeq uint64 // 根消息序号
+	replies        []wkdb.ThreadReply
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThreadChannelConvert(t *testing.T) {
+	opts := NewOptions()
+
+	threadChannelId := opts.ParentConvertThreadChannel("g1", 10)
+	assert.True(t, opts.IsThreadChannel(threadChannelId))
+
+	parentChannelId, rootMessageSeq, ok := opts.ThreadChannelConvertParentChannel(threadChannelId)
+	assert.True(t, ok)
+	assert.Equal(t, "g1", parentChannelId)
+	assert.Equal(t, uint64(10), rootMessageSeq)
+
+	assert.False(t, opts.IsThreadChannel("g1"))
+	assert.False(t, opts.IsThreadChannel(opts.Channel.ThreadSeparator+"10"))
+	assert.False(t, opts.IsThreadChannel(opts.OrginalConvertCmdChannel(threadChannelId)))
+
+	assert.Equal(t, wkdb.ConversationTypeThread, getConversationType(opts, threadChannelId))
+	assert.Equal(t, wkdb.ConversationTypeCMD, getConversationType(opts, opts.OrginalConvertCmdChannel(threadChannelId)))
+	assert.Equal(t, wkdb.ConversationTypeChat, getConversationType(opts, "g1"))
+}
+
+func TestThreadFilterByParentChannel(t *testing.T) {
+	s := NewTestServer(t)
+	ds := &testDatasource{
+		subscribers: map[string][]string{
+			wkutil.ChannelToKey("g1", 2): {"u1"},
+			wkutil.ChannelToKey("g2", 2): {"u2"},
+			wkutil.ChannelToKey("g3", 2): {"u1"},
+		},
+		disbands: map[string]bool{
+			wkutil.ChannelToKey("g3", 2): true,
+		},
+	}
+	s.opts.Datasource.Addr = "http://127.0.0.1:8080"
+	s.opts.Datasource.ChannelInfoOn = true
+	s.datasource = ds
+
+	conversations := []wkdb.Conversation{
+		{Uid: "u1", ChannelId: s.opts.ParentConvertThreadChannel("g1", 1), ChannelType: 2},
+		{Uid: "u1", ChannelId: s.opts.ParentConvertThreadChannel("g1", 2), ChannelType: 2},
+		{Uid: "u1", ChannelId: s.opts.ParentConvertThreadChannel("g2", 1), ChannelType: 2}, // 不是父频道的订阅者
+		{Uid: "u1", ChannelId: s.opts.ParentConvertThreadChannel("g3", 1), ChannelType: 2}, // 父频道已解散
+	}
+	filtered, err := NewThreadAPI(s).filterByParentChannel("u1", conversations)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(filtered))
+	assert.Equal(t, conversations[0].ChannelId, filtered[0].ChannelId)
+	assert.Equal(t, conversations[1].ChannelId, filtered[1].ChannelId)
+
+	// 所有父频道一次批量获取
+	assert.Equal(t, 1, ds.batchCalls)
+	assert.Equal(t, 0, ds.calls)
+}
+
+func TestThreadPermissionRootMessage(t *testing.T) {
+	s := NewTestServer(t)
+	err := s.store.Open()
+	assert.NoError(t, err)
+	defer s.store.Close()
+
+	err = s.store.DB().AppendMessages("g1", wkproto.ChannelTypeGroup, []wkdb.Message{
+		{RecvPacket: wkproto.RecvPacket{MessageID: 1, MessageSeq: 1, FromUID: "u1", ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup}},
+		{RecvPacket: wkproto.RecvPacket{MessageID: 2, MessageSeq: 2, FromUID: "u1", ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup}},
+	})
+	assert.NoError(t, err)
+	s.cluster = &testCDCCluster{Cluster: s.cluster, leader: &pb.Node{Id: s.opts.Cluster.NodeId}}
+
+	r := s.channelReactor
+	newThreadChannel := func(rootMessageSeq uint64) *channel {
+		threadChannelId := s.opts.ParentConvertThreadChannel("g1", rootMessageSeq)
+		return newChannel(r.reactorSub(wkutil.ChannelToKey(threadChannelId, wkproto.ChannelTypeGroup)), threadChannelId, wkproto.ChannelTypeGroup)
+	}
+
+	// 根消息超出父频道的最大消息序号
+	ch := newThreadChannel(3)
+	reasonCode, err := r.hasThreadPermission("g1", 3, wkproto.ChannelTypeGroup, "u1", ch)
+	assert.NoError(t, err)
+	assert.Equal(t, wkproto.ReasonChannelNotExist, reasonCode)
+	assert.False(t, ch.threadRootVerified.Load())
+
+	// 根消息存在，继续判断父频道的权限
+	ch = newThreadChannel(2)
+	reasonCode, err = r.hasThreadPermission("g1", 2, wkproto.ChannelTypeGroup, "u1", ch)
+	assert.NoError(t, err)
+	assert.NotEqual(t, wkproto.ReasonChannelNotExist, reasonCode)
+	assert.True(t, ch.threadRootVerified.Load())
+
+	// 子区不支持嵌套
+	reasonCode, err = r.hasThreadPermission(s.opts.ParentConvertThreadChannel("g1", 2), 1, wkproto.ChannelTypeGroup, "u1", ch)
+	assert.NoError(t, err)
+	assert.Equal(t, wkproto.ReasonNotSupportChannelType, reasonCode)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
@@ -122,14 +123,15 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 		sub.addConnContext(connCtx)
 	}
 	// -------------------- token verify --------------------
+	live := r.s.opts.Live()
 	if connectPacket.UID == r.s.opts.ManagerUID {
-		if r.s.opts.ManagerTokenOn && connectPacket.Token != r.s.opts.ManagerToken {
+		if live.ManagerTokenOn() && connectPacket.Token != live.ManagerToken {
 			r.Error("manager token verify fail", zap.String("uid", uid), zap.String("token", connectPacket.Token))
 			r.authResponseConnackAuthFail(connCtx)
 			return wkproto.ReasonAuthFail, nil
 		}
 		devceLevel = wkproto.DeviceLevelSlave // 默认都是slave设备
-	} else if r.s.opts.TokenAuthOn {
+	} else if live.TokenAuthOn {
 		if connectPacket.Token == "" {
 			r.Error("token is empty")
 			r.authResponseConnackAuthFail(connCtx)
@@ -234,6 +236,7 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 	connCtx.aesKey = aesKey
 	connCtx.deviceLevel = devceLevel
 	connCtx.protoVersion = lastVersion
+	connCtx.compression = r.s.negotiateCompression(connectPacket)
 	connCtx.isAuth.Store(true)
 
 	if connCtx.isRealConn {
@@ -246,7 +249,7 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 		hasServerVersion = true
 	}
 
-	r.Debug("Auth Success", zap.Any("conn", connCtx), zap.Uint8("protoVersion", connectPacket.Version), zap.Bool("hasServerVersion", hasServerVersion))
+	r.Debug("Auth Success", zap.Any("conn", connCtx), zap.Uint8("protoVersion", connectPacket.Version), zap.Bool("hasServerVersion", hasServerVersion), zap.String("compression", connCtx.compression.String()))
 	connack := &wkproto.ConnackPacket{
 		Salt:          aesIV,
 		ServerKey:     dhServerPublicKeyEnc,
@@ -263,7 +266,8 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 	totalOnlineCount := r.s.userReactor.getConnContextCount(uid)
 	r.s.webhook.Online(uid, connectPacket.DeviceFlag, connCtx.connId, deviceOnlineCount, totalOnlineCount)
 	if totalOnlineCount <= 1 {
-		r.s.trace.Metrics.App().OnlineUserCountAdd(1) // 统计在线用户数
+		r.s.trace.Metrics.App().OnlineUserCountAdd(1)             // 统计在线用户数
+		r.s.presenceManager.online(uid, connectPacket.DeviceFlag) // 通知在线状态订阅者
 	}
 	r.s.trace.Metrics.App().OnlineDeviceCountAdd(1) // 统计在线设备数
 
@@ -292,7 +296,12 @@ func (r *userReactor) getClientAesKeyAndIV(clientKey string, dhServerPrivKey [32
 
 func (r *userReactor) authResponse(connCtx *connContext, packet *wkproto.ConnackPacket) {
 	if connCtx.isRealConn {
-		_ = connCtx.writeDirectlyPacket(packet)
+		data, err := connCtx.encodeConnack(packet)
+		if err != nil {
+			r.Error("encode connack error", zap.String("uid", connCtx.uid), zap.Error(err))
+			return
+		}
+		_ = connCtx.writeDirectly(data, 0)
 	} else {
 		status, err := r.requestUserAuthResult(connCtx.realNodeId, &UserAuthResult{
 			ReasonCode:   packet.ReasonCode,
@@ -304,6 +313,7 @@ func (r *userReactor) authResponse(connCtx *connContext, packet *wkproto.Connack
 			AesIV:        connCtx.aesIV,
 			DeviceLevel:  connCtx.deviceLevel,
 			ProtoVersion: connCtx.protoVersion,
+			Compression:  connCtx.compression,
 		})
 		if err != nil {
 			r.Error("requestUserAuthResult error", zap.String("uid", connCtx.uid), zap.String("deviceId", connCtx.deviceId), zap.Error(err))
@@ -359,6 +369,7 @@ type UserAuthResult struct {
 	AesIV        string
 	DeviceLevel  wkproto.DeviceLevel
 	ProtoVersion uint8
+	Compression  payloadCompression // 协商的消息内容压缩方式
 }
 
 func (u *UserAuthResult) Marshal() ([]byte, error) {
@@ -373,6 +384,7 @@ func (u *UserAuthResult) Marshal() ([]byte, error) {
 	encoder.WriteString(u.AesIV)
 	encoder.WriteUint8(uint8(u.DeviceLevel))
 	encoder.WriteUint8(u.ProtoVersion)
+	encoder.WriteUint8(uint8(u.Compression))
 	return encoder.Bytes(), nil
 }
 
@@ -413,6 +425,15 @@ func (u *UserAuthResult) Unmarshal(data []byte) error {
 	if u.ProtoVersion, err = decoder.Uint8(); err != nil {
 		return err
 	}
+
+	// compression（旧版本节点没有此字段）
+	if decoder.Len() > 0 {
+		var compression uint8
+		if compression, err = decoder.Uint8(); err != nil {
+			return err
+		}
+		u.Compression = payloadCompression(compression)
+	}
 	return nil
 }
 
@@ -639,10 +660,14 @@ func (r *userReactor) handleWrite(req *writeReq) error {
 	sub := r.reactorSub(req.uid)
 
 	var connDataMap = map[string][]byte{}
-	var deviceIdConnIdMap = map[string]int64{}  // 设备id对应的连接id
-	var recvFrameCountMap = map[string]uint32{} // 设备id对应的接收帧数
+	var deviceIdConnIdMap = map[string]int64{}     // 设备id对应的连接id
+	var recvFrameCountMap = map[string]uint32{}    // 设备id对应的接收帧数
+	var traceCtxMap = map[string]context.Context{} // 设备id对应的链路（跟随第一条带链路的数据）
 	for _, msg := range req.messages {
 		deviceIdConnIdMap[msg.DeviceId] = msg.ConnId
+		if traceCtxMap[msg.DeviceId] == nil && trace.TraceIDFromContext(msg.ctx) != "" {
+			traceCtxMap[msg.DeviceId] = msg.ctx
+		}
 		if msg.FrameType == wkproto.RECV {
 			recvFrameCountMap[msg.DeviceId]++
 		}
@@ -677,7 +702,7 @@ func (r *userReactor) handleWrite(req *writeReq) error {
 				return errors.New("node not online")
 			}
 
-			status, err := r.fowardWriteReq(conn.realNodeId, &FowardWriteReq{
+			status, err := r.fowardWriteReq(trace.ContextWithSpanOf(r.s.ctx, traceCtxMap[deviceId]), conn.realNodeId, &FowardWriteReq{
 				Uid:            req.uid,
 				DeviceId:       deviceId,
 				ConnId:         conn.proxyConnId,
@@ -699,8 +724,8 @@ func (r *userReactor) handleWrite(req *writeReq) error {
 }
 
 // 转发写请求
-func (r *userReactor) fowardWriteReq(nodeId uint64, req *FowardWriteReq) (proto.Status, error) {
-	timeoutCtx, cancel := context.WithTimeout(r.s.ctx, time.Second*2)
+func (r *userReactor) fowardWriteReq(ctx context.Context, nodeId uint64, req *FowardWriteReq) (proto.Status, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second*2)
 	defer cancel()
 	data, err := req.Marshal()
 	if err != nil {
@@ -1163,6 +1188,7 @@ func (r *userReactor) processClose(req *userCloseReq) {
 
 	if req.role == userRoleLeader {
 		r.s.trace.Metrics.App().OnlineUserCountAdd(-1) //用户下线
+		r.s.presenceManager.offline(req.uid)           // 通知在线状态订阅者
 	}
 
 	conns := r.getConnsByUniqueNo(req.uid, req.uniqueNo)
@@ -31,6 +31,7 @@ type webhook struct {
 	eventPool        *ants.Pool
 	httpClient       *http.Client
 	webhookGRPCPool  *grpcpool.Pool // webhook grpc客户端
+	grpcPoolLock     sync.RWMutex
 	stoped           chan struct{}
 	onlinestatusLock sync.RWMutex
 	onlinestatusList []string
@@ -47,12 +48,7 @@ func newWebhook(s *Server) *webhook {
 		webhookGRPCPool *grpcpool.Pool
 	)
 	if s.opts.WebhookGRPCOn() {
-		webhookGRPCPool, err = grpcpool.New(func() (*grpc.ClientConn, error) {
-			return grpc.Dial(s.opts.Webhook.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithKeepaliveParams(keepalive.ClientParameters{
-				Time:    5 * time.Minute, // send pings every 5 minute if there is no activity
-				Timeout: 2 * time.Second, // wait 1 second for ping ack before considering the connection dead
-			}))
-		}, 2, 20, time.Minute*5) // 初始化2个连接 最多20个连接
+		webhookGRPCPool, err = newWebhookGRPCPool(s.opts.Live().WebhookGRPCAddr)
 		if err != nil {
 			panic(err)
 		}
@@ -83,6 +79,36 @@ func newWebhook(s *Server) *webhook {
 	}
 }
 
+func newWebhookGRPCPool(addr string) (*grpcpool.Pool, error) {
+	return grpcpool.New(func() (*grpc.ClientConn, error) {
+		return grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    5 * time.Minute, // send pings every 5 minute if there is no activity
+			Timeout: 2 * time.Second, // wait 1 second for ping ack before considering the connection dead
+		}))
+	}, 2, 20, time.Minute*5) // 初始化2个连接 最多20个连接
+}
+
+// resetGRPCPool webhook.grpcAddr热加载后重建grpc连接池
+func (w *webhook) resetGRPCPool() {
+	var (
+		pool *grpcpool.Pool
+		err  error
+	)
+	if live := w.s.opts.Live(); live.WebhookGRPCOn() {
+		pool, err = newWebhookGRPCPool(live.WebhookGRPCAddr)
+		if err != nil {
+			w.Error("create webhook grpc pool failed", zap.Error(err), zap.String("grpcAddr", live.WebhookGRPCAddr))
+		}
+	}
+	w.grpcPoolLock.Lock()
+	old := w.webhookGRPCPool
+	w.webhookGRPCPool = pool
+	w.grpcPoolLock.Unlock()
+	if old != nil {
+		old.Close()
+	}
+}
+
 func (w *webhook) Start() {
 	go w.notifyQueueLoop()
 	go w.loopOnlineStatus()
@@ -157,6 +183,19 @@ func (w *webhook) notifyOfflineMsg(msg ReactorChannelMessage, subscribers []stri
 			compresssToUIDs = buff.Bytes()
 		}
 	}
+	// 被@的离线用户，推送服务可以优先推送
+	var (
+		mentionAll  int
+		mentionUids []string
+	)
+	if !msg.Mention.IsEmpty() {
+		if msg.Mention.All == 1 {
+			mentionAll = 1
+		} else {
+			mentionUids = msg.Mention.Filter(subscribers)
+		}
+	}
+
 	// 推送离线到上层应用
 	w.TriggerEvent(&Event{
 		Event: EventMsgOffline,
@@ -184,6 +223,8 @@ func (w *webhook) notifyOfflineMsg(msg ReactorChannelMessage, subscribers []stri
 			Compress:        compress,
 			CompresssToUIDs: compresssToUIDs,
 			SourceID:        int64(w.s.opts.Cluster.NodeId),
+			MentionAll:      mentionAll,
+			MentionUIDs:     mentionUids,
 		},
 	})
 }
@@ -194,9 +235,9 @@ func (w *webhook) notifyQueueLoop() {
 	ticker := time.NewTicker(w.s.opts.Webhook.MsgNotifyEventPushInterval)
 	defer ticker.Stop()
 	errMessageIDMap := make(map[int64]int) // 记录错误的消息ID value为错误次数
-	if w.s.opts.WebhookOn() {
-		for {
-			messages, err := w.s.store.GetMessagesOfNotifyQueue(w.s.opts.Webhook.MsgNotifyEventCountPerPush)
+	for {
+		if w.s.opts.WebhookOn() { // webhook可以热加载，每次都需要判断
+			messages, err := w.s.store.GetMessagesOfNotifyQueue(w.s.opts.Live().WebhookMsgNotifyEventCountPerPush)
 			if err != nil {
 				w.Error("获取通知队列内的消息失败！", zap.Error(err))
 				time.Sleep(errorSleepTime) // 如果报错就休息下
@@ -228,7 +269,7 @@ func (w *webhook) notifyQueueLoop() {
 						errCount := errMessageIDMap[message.MessageID]
 						errCount++
 						errMessageIDMap[message.MessageID] = errCount
-						if errCount >= w.s.opts.Webhook.MsgNotifyEventRetryMaxCount {
+						if errCount >= w.s.opts.Live().WebhookMsgNotifyEventRetryMaxCount {
 							errMessageIDs = append(errMessageIDs, message.MessageID)
 						}
 					}
@@ -255,28 +296,33 @@ func (w *webhook) notifyQueueLoop() {
 				}
 				err = w.s.store.RemoveMessagesOfNotifyQueue(messageIDs)
 				if err != nil {
-					w.Warn("从通知队列里移除消息失败！", zap.Error(err), zap.Int64s("messageIDs", messageIDs), zap.String("Webhook", w.s.opts.Webhook.HTTPAddr))
+					w.Warn("从通知队列里移除消息失败！", zap.Error(err), zap.Int64s("messageIDs", messageIDs), zap.String("Webhook", w.s.opts.Live().WebhookHTTPAddr))
 					time.Sleep(errorSleepTime) // 如果报错就休息下
 					continue
 				}
 			}
+		}
 
-			select {
-			case <-ticker.C:
-			case <-w.stoped:
-				return
-			}
+		select {
+		case <-ticker.C:
+		case <-w.stoped:
+			return
 		}
 	}
 }
 
 func (w *webhook) loopOnlineStatus() {
-	if !w.s.opts.WebhookOn() {
-		return
-	}
 	opLen := 0    // 最后一次操作在线状态数组的长度
 	errCount := 0 // webhook请求失败重试次数
 	for {
+		if !w.s.opts.WebhookOn() { // webhook可以热加载，没设置webhook时丢弃在线状态
+			w.onlinestatusLock.Lock()
+			w.onlinestatusList = w.onlinestatusList[:0]
+			opLen = 0
+			w.onlinestatusLock.Unlock()
+			time.Sleep(time.Second * 2)
+			continue
+		}
 		if opLen == 0 {
 			w.onlinestatusLock.Lock()
 			opLen = len(w.onlinestatusList)
@@ -304,8 +350,8 @@ func (w *webhook) loopOnlineStatus() {
 		if err != nil {
 			errCount++
 			w.Error("请求在线状态webhook失败！", zap.Error(err))
-			if errCount >= w.s.opts.Webhook.MsgNotifyEventRetryMaxCount {
-				w.Error("请求在线状态webhook失败通知超过最大次数！", zap.Int("MsgNotifyEventRetryMaxCount", w.s.opts.Webhook.MsgNotifyEventRetryMaxCount))
+			if retryMaxCount := w.s.opts.Live().WebhookMsgNotifyEventRetryMaxCount; errCount >= retryMaxCount {
+				w.Error("请求在线状态webhook失败通知超过最大次数！", zap.Int("MsgNotifyEventRetryMaxCount", retryMaxCount))
 
 				w.onlinestatusLock.Lock()
 				w.onlinestatusList = w.onlinestatusList[opLen:]
@@ -328,19 +374,20 @@ func (w *webhook) loopOnlineStatus() {
 }
 
 func (w *webhook) sendWebhookForHttp(event string, data []byte) error {
-	eventURL := fmt.Sprintf("%s?event=%s", w.s.opts.Webhook.HTTPAddr, event)
+	httpAddr := w.s.opts.Live().WebhookHTTPAddr
+	eventURL := fmt.Sprintf("%s?event=%s", httpAddr, event)
 	startTime := time.Now().UnixNano() / 1000 / 1000
 	w.Debug("webhook开始请求", zap.String("eventURL", eventURL))
 	resp, err := w.httpClient.Post(eventURL, "application/json", bytes.NewBuffer(data))
 	w.Debug("webhook请求结束 耗时", zap.Int64("mill", time.Now().UnixNano()/1000/1000-startTime))
 	if err != nil {
-		w.Warn("调用第三方消息通知失败！", zap.String("Webhook", w.s.opts.Webhook.HTTPAddr), zap.Error(err))
+		w.Warn("调用第三方消息通知失败！", zap.String("Webhook", httpAddr), zap.Error(err))
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		w.Warn("第三方消息通知接口返回状态错误！", zap.Int("status", resp.StatusCode), zap.String("Webhook", w.s.opts.Webhook.HTTPAddr))
+		w.Warn("第三方消息通知接口返回状态错误！", zap.Int("status", resp.StatusCode), zap.String("Webhook", httpAddr))
 		return errors.New("第三方消息通知接口返回状态错误！")
 	}
 	return nil
@@ -354,7 +401,13 @@ func (w *webhook) sendWebhookForGRPC(event string, data []byte) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
 	defer cancel()
-	clientConn, err := w.webhookGRPCPool.Get(ctx)
+	w.grpcPoolLock.RLock()
+	pool := w.webhookGRPCPool
+	w.grpcPoolLock.RUnlock()
+	if pool == nil {
+		return errors.New("webhook grpc连接池不存在！")
+	}
+	clientConn, err := pool.Get(ctx)
 	if err != nil {
 		return err
 	}
@@ -389,6 +442,12 @@ const (
 	EventMsgNotify = "msg.notify"
 	// EventOnlineStatus 用户在线状态
 	EventOnlineStatus = "user.onlinestatus"
+	// EventCustomerServiceAssign 客服会话分配给客服
+	EventCustomerServiceAssign = "customerservice.assign"
+	// EventCustomerServiceTransfer 客服会话转接
+	EventCustomerServiceTransfer = "customerservice.transfer"
+	// EventCustomerServiceClose 客服会话关闭
+	EventCustomerServiceClose = "customerservice.close"
 )
 
 // Event Event
@@ -14,6 +14,22 @@ var ClusterChannel = channel{
 	Stop:    "clusterchannelStop",    // 停止频道
 }
 
+// 日志资源
+var Log = log{
+	Level: "logLevel", // 日志级别
+}
+
+// 节点资源
+var Node = node{
+	Drain: "nodeDrain", // 排空节点
+}
+
+// 配置资源
+var Config = config{
+	View:   "configView",   // 查看配置
+	Reload: "configReload", // 热加载配置
+}
+
 type slot struct {
 	Migrate Id
 }
@@ -24,4 +40,17 @@ type channel struct {
 	Stop    Id
 }
 
+type log struct {
+	Level Id
+}
+
+type node struct {
+	Drain Id
+}
+
+type config struct {
+	View   Id
+	Reload Id
+}
+
 var All Id = "*"
@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -590,6 +591,12 @@ func (c *Client) SendMessage(channel *Channel, payload []byte, opt ...SendOption
 	}
 	var err error
 	var setting wkproto.Setting
+	if opts.MentionAll || len(opts.MentionUids) > 0 {
+		payload, err = payloadWithMention(payload, opts.MentionUids, opts.MentionAll)
+		if err != nil {
+			return err
+		}
+	}
 	newPayload := payload
 	if !opts.NoEncrypt {
 		// 加密消息内容
@@ -822,3 +829,20 @@ func parseAddr(addr string) (network, address string, port int) {
 	}
 	return
 }
+
+// payloadWithMention 将@信息写入payload的mention字段
+func payloadWithMention(payload []byte, uids []string, all bool) ([]byte, error) {
+	var content map[string]interface{}
+	if err := json.Unmarshal(payload, &content); err != nil || content == nil {
+		return nil, errors.New("payload must be a json object when mention is set")
+	}
+	mention := map[string]interface{}{}
+	if all {
+		mention["all"] = 1
+	}
+	if len(uids) > 0 {
+		mention["uids"] = uids
+	}
+	content["mention"] = mention
+	return json.Marshal(content)
+}
@@ -97,6 +97,8 @@ type SendOptions struct {
 	RedDot      bool // 是否显示红点 默认true
 	NoEncrypt   bool // 是否不需要加密
 	ClientMsgNo string
+	MentionUids []string // @的用户
+	MentionAll  bool     // 是否@所有人
 }
 
 // NewSendOptions NewSendOptions
@@ -159,3 +161,12 @@ func SendOptionWithNoEncrypt(noEncrypt bool) SendOption {
 		return nil
 	}
 }
+
+// SendOptionWithMention @指定用户或所有人（payload需要是json，@信息会写入payload的mention字段）
+func SendOptionWithMention(uids []string, all bool) SendOption {
+	return func(opts *SendOptions) error {
+		opts.MentionUids = uids
+		opts.MentionAll = all
+		return nil
+	}
+}
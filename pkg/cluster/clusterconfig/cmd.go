@@ -21,6 +21,7 @@ const (
 	CMDTypeSlotMigrate                       // 槽迁移
 	CMDTypeSlotUpdate                        // 槽更新
 	CMDTypeNodeStatusChange                  // 节点状态改变
+	CMDTypeNodeLocationChange                // 节点位置（可用区/机架）改变
 
 )
 
@@ -48,6 +49,8 @@ func (c CMDType) String() string {
 		return "CMDTypeSlotUpdate"
 	case CMDTypeNodeStatusChange:
 		return "CMDTypeNodeStatusChange"
+	case CMDTypeNodeLocationChange:
+		return "CMDTypeNodeLocationChange"
 	}
 	return "CMDTypeUnknown"
 }
@@ -259,6 +262,30 @@ func DecodeNodeStatusChange(data []byte) (uint64, pb.NodeStatus, error) {
 	return nodeId, pb.NodeStatus(status), err
 }
 
+func EncodeNodeLocationChange(nodeId uint64, zone string, rack string) ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteUint64(nodeId)
+	enc.WriteString(zone)
+	enc.WriteString(rack)
+	return enc.Bytes(), nil
+}
+
+func DecodeNodeLocationChange(data []byte) (uint64, string, string, error) {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	var nodeId uint64
+	if nodeId, err = dec.Uint64(); err != nil {
+		return 0, "", "", err
+	}
+	zone, err := dec.String()
+	if err != nil {
+		return 0, "", "", err
+	}
+	rack, err := dec.String()
+	return nodeId, zone, rack, err
+}
+
 func EncodeNodeJoined(nodeId uint64, slots []*pb.Slot) ([]byte, error) {
 	enc := wkproto.NewEncoder()
 	defer enc.End()
@@ -145,6 +145,14 @@ func (n *Node) Equal(v *Node) bool {
 	if n.ClusterAddr != v.ClusterAddr {
 		return false
 	}
+
+	if n.Zone != v.Zone {
+		return false
+	}
+
+	if n.Rack != v.Rack {
+		return false
+	}
 	return true
 }
 
@@ -69,6 +69,8 @@ func (s *Server) handleCmd(cmd *CMD) error {
 		return s.handleSlotUpdate(cmd)
 	case CMDTypeNodeStatusChange: // 节点状态改变
 		return s.handleNodeStatusChange(cmd)
+	case CMDTypeNodeLocationChange: // 节点位置改变
+		return s.handleNodeLocationChange(cmd)
 	}
 	return nil
 }
@@ -180,3 +182,14 @@ func (s *Server) handleNodeStatusChange(cmd *CMD) error {
 	s.cfg.updateNodeStatus(nodeId, status)
 	return nil
 }
+
+func (s *Server) handleNodeLocationChange(cmd *CMD) error {
+	nodeId, zone, rack, err := DecodeNodeLocationChange(cmd.Data)
+	if err != nil {
+		s.Error("decode node location change err", zap.Error(err))
+		return err
+	}
+
+	s.cfg.updateNodeLocation(nodeId, zone, rack)
+	return nil
+}
@@ -64,6 +64,34 @@ func (s *Server) ProposeApiServerAddr(nodeId uint64, apiServerAddr string) error
 	return nil
 }
 
+// ProposeNodeLocation 提案节点位置（可用区/机架）变更
+func (s *Server) ProposeNodeLocation(nodeId uint64, zone string, rack string) error {
+
+	data, err := EncodeNodeLocationChange(nodeId, zone, rack)
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCMD(CMDTypeNodeLocationChange, data)
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeNodeLocation failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
 // ProposeJoin 提案节点加入
 func (s *Server) ProposeJoin(node *pb.Node) error {
 
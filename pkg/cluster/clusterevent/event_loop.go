@@ -60,6 +60,13 @@ func (s *Server) checkClusterConfig() error {
 			return err
 		}
 
+		// 检查槽副本的可用区分布
+		err = s.handleSlotZoneRepair()
+		if err != nil {
+			s.Error("handleSlotZoneRepair failed", zap.Error(err))
+			return err
+		}
+
 	}
 
 	// ================== 处理槽领导选举 ==================
@@ -109,6 +116,20 @@ func (s *Server) tick() {
 
 func (s *Server) handleClusterConfigInit() error {
 
+	cfg := s.newInitClusterConfig()
+
+	// 提案初始配置
+	err := s.cfgServer.ProposeConfigInit(cfg)
+	if err != nil {
+		s.Error("ProposeConfigInit failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// newInitClusterConfig 根据初始节点生成集群的初始配置
+// 其他初始节点的可用区和机架来自静态配置（InitNodeLocations），初始化时就能按可用区分散槽副本
+func (s *Server) newInitClusterConfig() *pb.Config {
 	cfg := &pb.Config{
 		SlotCount:           s.opts.SlotCount,
 		SlotReplicaCount:    s.opts.SlotMaxReplicaCount,
@@ -120,8 +141,16 @@ func (s *Server) handleClusterConfigInit() error {
 	var replicas []uint64
 	for nodeId, addr := range s.opts.InitNodes {
 		apiAddr := ""
+		location := s.opts.InitNodeLocations[nodeId]
+		zone, rack := location.Zone, location.Rack
 		if nodeId == s.opts.NodeId {
 			apiAddr = s.opts.ApiServerAddr
+			if s.opts.Zone != "" { // 以节点自己的配置为准
+				zone, rack = s.opts.Zone, s.opts.Rack
+			}
+		}
+		if zone == "" && s.opts.Zone != "" {
+			s.Warn("init node zone unknown, initial slot replicas may not be spread across zones", zap.Uint64("nodeId", nodeId))
 		}
 		nodes = append(nodes, &pb.Node{
 			Id:            nodeId,
@@ -132,6 +161,8 @@ func (s *Server) handleClusterConfigInit() error {
 			Role:          pb.NodeRole_NodeRoleReplica,
 			Status:        pb.NodeStatus_NodeStatusJoined,
 			CreatedAt:     time.Now().Unix(),
+			Zone:          zone,
+			Rack:          rack,
 		})
 		replicas = append(replicas, nodeId)
 	}
@@ -140,6 +171,7 @@ func (s *Server) handleClusterConfigInit() error {
 	if len(replicas) > 0 {
 		offset := 0
 		replicaCount := s.opts.SlotMaxReplicaCount
+		nodeSlotCountMap := make(map[uint64]int) // 每个节点已分配的槽数量
 		for i := uint32(0); i < s.opts.SlotCount; i++ {
 			slot := &pb.Slot{
 				Id: i,
@@ -147,11 +179,15 @@ func (s *Server) handleClusterConfigInit() error {
 			if len(replicas) <= int(replicaCount) {
 				slot.Replicas = replicas
 			} else {
-				slot.Replicas = make([]uint64, 0, replicaCount)
-				for i := uint32(0); i < replicaCount; i++ {
-					idx := (offset + int(i)) % len(replicas)
-					slot.Replicas = append(slot.Replicas, replicas[idx])
+				// 按可用区分散选择副本，候选节点按偏移轮转，保证条件相同时副本均匀分布
+				candidates := make([]*pb.Node, 0, len(nodes))
+				for j := 0; j < len(nodes); j++ {
+					candidates = append(candidates, nodes[(offset+j)%len(nodes)])
 				}
+				slot.Replicas = SelectReplicas(candidates, int(replicaCount), nil, nodeSlotCountMap)
+			}
+			for _, replicaId := range slot.Replicas {
+				nodeSlotCountMap[replicaId]++
 			}
 			offset++
 			// 随机选举一个领导者
@@ -174,14 +210,7 @@ func (s *Server) handleClusterConfigInit() error {
 			}
 		}
 	}
-
-	// 提案初始配置
-	err := s.cfgServer.ProposeConfigInit(cfg)
-	if err != nil {
-		s.Error("ProposeConfigInit failed", zap.Error(err))
-		return err
-	}
-	return nil
+	return cfg
 }
 
 // 比较本地配置和远程配置
@@ -202,6 +231,16 @@ func (s *Server) handleCompareLocalClusterConfig() error {
 		}
 	}
 
+	// 如果配置里自己节点的可用区或机架和本地配置不同，则提案配置
+	localNode := s.cfgServer.Node(s.opts.NodeId)
+	if localNode != nil && (localNode.Zone != s.opts.Zone || localNode.Rack != s.opts.Rack) {
+		err := s.cfgServer.ProposeNodeLocation(s.opts.NodeId, s.opts.Zone, s.opts.Rack)
+		if err != nil {
+			s.Error("ProposeNodeLocation failed", zap.Error(err))
+			return err
+		}
+	}
+
 	if s.IsLeader() {
 		// 节点在线状态改变
 		err := s.handleNodeOnlineStatusChange()
@@ -292,6 +331,9 @@ func (s *Server) autoBalanceSlotLeaders(cfg *pb.Config) []*pb.Slot {
 		return false
 	}
 
+	// 迁入节点按所在可用区的领导数量排序，领导少的可用区优先迁入，使槽领导在可用区之间也尽量分散
+	importNodeIds := sortNodeIdsByZoneLeaderCount(cfg.Nodes, importNodeLeaderCountMap, nodeLeaderCountMap)
+
 	var newSlots []*pb.Slot
 	for exportNodeId, exportLeaderCount := range exportNodeLeaderCountMap {
 		if exportLeaderCount == 0 {
@@ -301,7 +343,8 @@ func (s *Server) autoBalanceSlotLeaders(cfg *pb.Config) []*pb.Slot {
 		if !nodeOnline(exportNodeId) { // 节点不在线 不参与
 			continue
 		}
-		for importNodeId, importLeaderCount := range importNodeLeaderCountMap {
+		for _, importNodeId := range importNodeIds {
+			importLeaderCount := importNodeLeaderCountMap[importNodeId]
 			if importLeaderCount == 0 {
 				continue
 			}
@@ -354,6 +397,7 @@ func (s *Server) handleNodeJoining() error {
 	var migrateSlots []*pb.Slot // 迁移的槽列表
 
 	voteNodes := s.cfgServer.AllowVoteNodes()
+	nodes := s.cfgServer.Nodes()
 
 	if uint32(len(firstSlot.Replicas)) < s.cfgServer.SlotReplicaCount() { // 如果当前槽的副本数量小于配置的副本数量，则可以将新节点直接加入到学习节点中
 		for _, slot := range slots {
@@ -427,6 +471,11 @@ func (s *Server) handleNodeJoining() error {
 					continue
 				}
 
+				// 新节点替换当前节点后，副本的可用区分布不能变差
+				if !replaceKeepsZoneSpread(nodes, slot.Replicas, node.Id, joiningNode.Id) {
+					continue
+				}
+
 				// ------------------- 分配槽领导 -------------------
 				allocSlotLeader := false // 是否已经分配完槽领导
 				if fromSlotCount > 0 && fromSlotLeaderCount > 0 && slot.Leader == node.Id {
@@ -661,3 +710,52 @@ func (s *Server) handleSlotLeaderAutoBalance() error {
 	}
 	return nil
 }
+
+// handleSlotZoneRepair 将违反可用区策略的槽副本迁移到其他可用区（每次只迁移一个槽，等迁移完成后再处理下一个）
+func (s *Server) handleSlotZoneRepair() error {
+	cfg := s.cfgServer.Config()
+	if !ZoneAware(cfg.Nodes) {
+		return nil
+	}
+
+	// 有未加入的节点或者有槽正在迁移，则不进行修复
+	for _, node := range cfg.Nodes {
+		if node.Status != pb.NodeStatus_NodeStatusJoined {
+			return nil
+		}
+	}
+	nodeSlotCountMap := make(map[uint64]int) // 每个节点的槽数量
+	for _, slot := range cfg.Slots {
+		if slot.MigrateFrom != 0 || slot.MigrateTo != 0 {
+			return nil
+		}
+		if slot.Status == pb.SlotStatus_SlotStatusCandidate {
+			return nil
+		}
+		for _, replicaId := range slot.Replicas {
+			nodeSlotCountMap[replicaId]++
+		}
+	}
+
+	for _, slot := range cfg.Slots {
+		if !ViolatesZonePolicy(cfg.Nodes, slot.Replicas) {
+			continue
+		}
+		from, to, ok := zoneRepairMigrate(cfg.Nodes, slot, nodeSlotCountMap)
+		if !ok {
+			continue
+		}
+		s.Info("slot violates zone policy, migrate replica", zap.Uint32("slotId", slot.Id), zap.Uint64("from", from), zap.Uint64("to", to))
+		newSlot := slot.Clone()
+		newSlot.MigrateFrom = from
+		newSlot.MigrateTo = to
+		newSlot.Learners = append(newSlot.Learners, to)
+		err := s.ProposeSlots([]*pb.Slot{newSlot})
+		if err != nil {
+			s.Error("handleSlotZoneRepair failed,ProposeSlots failed", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+	return nil
+}
@@ -17,6 +17,9 @@ type Options struct {
 	ChannelMaxReplicaCount uint32 // 每个频道最大副本数量
 	ConfigDir              string
 	ApiServerAddr          string                       // api服务地址
+	Zone                   string                       // 节点所在可用区
+	Rack                   string                       // 节点所在机架
+	InitNodeLocations      map[uint64]NodeLocation      // 初始节点的可用区和机架，初始化槽分配时按可用区分散副本
 	OnClusterConfigChange  func(cfg *pb.Config)         // 分布式配置改变
 	OnSlotElection         func(slots []*pb.Slot) error // 槽位选举
 	Send                   func(m reactor.Message)      // 发送消息
@@ -62,6 +65,12 @@ func WithInitNodes(initNodes map[uint64]string) Option {
 	}
 
 }
+func WithInitNodeLocations(initNodeLocations map[uint64]NodeLocation) Option {
+	return func(o *Options) {
+		o.InitNodeLocations = initNodeLocations
+	}
+}
+
 func WithSlotCount(slotCount uint32) Option {
 	return func(o *Options) {
 		o.SlotCount = slotCount
@@ -105,6 +114,18 @@ func WithApiServerAddr(apiServerAddr string) Option {
 	}
 }
 
+func WithZone(zone string) Option {
+	return func(o *Options) {
+		o.Zone = zone
+	}
+}
+
+func WithRack(rack string) Option {
+	return func(o *Options) {
+		o.Rack = rack
+	}
+}
+
 func WithCluster(cluster icluster.Cluster) Option {
 	return func(o *Options) {
 		o.Cluster = cluster
@@ -152,3 +173,9 @@ func WithOnSlotElection(f func(slots []*pb.Slot) error) Option {
 		o.OnSlotElection = f
 	}
 }
+
+// NodeLocation 节点所在的可用区和机架
+type NodeLocation struct {
+	Zone string
+	Rack string
+}
@@ -0,0 +1,236 @@
+package clusterevent
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+)
+
+// ZoneAware 集群是否配置了可用区（只要有一个节点配置了可用区就按可用区放置副本）
+func ZoneAware(nodes []*pb.Node) bool {
+	for _, node := range nodes {
+		if node.Zone != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeZone 节点的可用区，未配置可用区的节点视为一个独立的可用区
+func nodeZone(node *pb.Node) string {
+	if node.Zone == "" {
+		return fmt.Sprintf("node-%d", node.Id)
+	}
+	return node.Zone
+}
+
+// nodeRack 节点的机架（同一个可用区内区分），未配置机架的节点视为一个独立的机架
+func nodeRack(node *pb.Node) string {
+	if node.Rack == "" {
+		return fmt.Sprintf("%s/node-%d", nodeZone(node), node.Id)
+	}
+	return fmt.Sprintf("%s/%s", nodeZone(node), node.Rack)
+}
+
+func findNode(nodes []*pb.Node, nodeId uint64) *pb.Node {
+	for _, node := range nodes {
+		if node.Id == nodeId {
+			return node
+		}
+	}
+	return nil
+}
+
+// ZoneReplicaCount 统计副本在每个可用区的数量
+func ZoneReplicaCount(nodes []*pb.Node, replicas []uint64) map[string]int {
+	zoneCountMap := make(map[string]int)
+	for _, replicaId := range replicas {
+		node := findNode(nodes, replicaId)
+		if node == nil {
+			continue
+		}
+		zoneCountMap[nodeZone(node)]++
+	}
+	return zoneCountMap
+}
+
+// maxZoneReplicaCount 副本在单个可用区内的最大数量
+func maxZoneReplicaCount(nodes []*pb.Node, replicas []uint64) int {
+	max := 0
+	for _, count := range ZoneReplicaCount(nodes, replicas) {
+		if count > max {
+			max = count
+		}
+	}
+	return max
+}
+
+// ViolatesZonePolicy 副本放置是否违反可用区策略（任意一个可用区宕机后剩余副本数量达不到法定数量）
+func ViolatesZonePolicy(nodes []*pb.Node, replicas []uint64) bool {
+	if len(replicas) <= 1 || !ZoneAware(nodes) {
+		return false
+	}
+	quorum := len(replicas)/2 + 1
+	return len(replicas)-maxZoneReplicaCount(nodes, replicas) < quorum
+}
+
+// SelectReplicas 按可用区和机架分散的原则选择副本
+// candidates 为候选节点（顺序决定了条件相同时的优先级），fixed 为必须包含的副本，load 为节点当前的负载（例如槽数量）
+func SelectReplicas(candidates []*pb.Node, replicaCount int, fixed []uint64, load map[uint64]int) []uint64 {
+	replicas := make([]uint64, 0, replicaCount)
+	zoneCountMap := make(map[string]int)
+	rackCountMap := make(map[string]int)
+
+	var choose = func(node *pb.Node) {
+		replicas = append(replicas, node.Id)
+		zoneCountMap[nodeZone(node)]++
+		rackCountMap[nodeRack(node)]++
+	}
+
+	for _, nodeId := range fixed {
+		if len(replicas) >= replicaCount {
+			break
+		}
+		node := findNode(candidates, nodeId)
+		if node == nil {
+			replicas = append(replicas, nodeId)
+			continue
+		}
+		choose(node)
+	}
+
+	for len(replicas) < replicaCount {
+		var best *pb.Node
+		for _, node := range candidates {
+			if wkutil.ArrayContainsUint64(replicas, node.Id) {
+				continue
+			}
+			if best == nil || lessPlacement(node, best, zoneCountMap, rackCountMap, load) {
+				best = node
+			}
+		}
+		if best == nil { // 没有更多的候选节点
+			break
+		}
+		choose(best)
+	}
+	return replicas
+}
+
+// lessPlacement 节点a是否比节点b更适合放置副本（可用区副本少的优先，其次机架副本少的优先，再次负载低的优先）
+func lessPlacement(a, b *pb.Node, zoneCountMap, rackCountMap map[string]int, load map[uint64]int) bool {
+	if zoneCountMap[nodeZone(a)] != zoneCountMap[nodeZone(b)] {
+		return zoneCountMap[nodeZone(a)] < zoneCountMap[nodeZone(b)]
+	}
+	if rackCountMap[nodeRack(a)] != rackCountMap[nodeRack(b)] {
+		return rackCountMap[nodeRack(a)] < rackCountMap[nodeRack(b)]
+	}
+	return load[a.Id] < load[b.Id]
+}
+
+// replaceKeepsZoneSpread 将副本from替换为to后，可用区分布是否没有变差
+func replaceKeepsZoneSpread(nodes []*pb.Node, replicas []uint64, from, to uint64) bool {
+	if !ZoneAware(nodes) {
+		return true
+	}
+	return maxZoneReplicaCount(nodes, replaceReplica(replicas, from, to)) <= maxZoneReplicaCount(nodes, replicas)
+}
+
+// zoneRepairMigrate 为违反可用区策略的槽找到一个迁移方案（从副本最多的可用区迁出一个副本到副本最少的可用区）
+func zoneRepairMigrate(nodes []*pb.Node, slot *pb.Slot, nodeSlotCountMap map[uint64]int) (from uint64, to uint64, ok bool) {
+	zoneCountMap := ZoneReplicaCount(nodes, slot.Replicas)
+
+	// 迁出：副本最多的可用区内的节点，尽量不迁移领导
+	var fromNode *pb.Node
+	for _, replicaId := range slot.Replicas {
+		node := findNode(nodes, replicaId)
+		if node == nil {
+			continue
+		}
+		if fromNode == nil {
+			fromNode = node
+			continue
+		}
+		if zoneCountMap[nodeZone(node)] > zoneCountMap[nodeZone(fromNode)] ||
+			(zoneCountMap[nodeZone(node)] == zoneCountMap[nodeZone(fromNode)] && fromNode.Id == slot.Leader) {
+			fromNode = node
+		}
+	}
+	if fromNode == nil {
+		return 0, 0, false
+	}
+
+	// 迁入：不在副本内的在线投票节点，副本最少的可用区优先，其次槽数量少的优先
+	candidates := make([]*pb.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if !node.AllowVote || !node.Online || node.Status != pb.NodeStatus_NodeStatusJoined {
+			continue
+		}
+		if wkutil.ArrayContainsUint64(slot.Replicas, node.Id) || wkutil.ArrayContainsUint64(slot.Learners, node.Id) {
+			continue
+		}
+		candidates = append(candidates, node)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		zi, zj := zoneCountMap[nodeZone(candidates[i])], zoneCountMap[nodeZone(candidates[j])]
+		if zi != zj {
+			return zi < zj
+		}
+		if nodeSlotCountMap[candidates[i].Id] != nodeSlotCountMap[candidates[j].Id] {
+			return nodeSlotCountMap[candidates[i].Id] < nodeSlotCountMap[candidates[j].Id]
+		}
+		return candidates[i].Id < candidates[j].Id
+	})
+	for _, candidate := range candidates {
+		if maxZoneReplicaCount(nodes, replaceReplica(slot.Replicas, fromNode.Id, candidate.Id)) < maxZoneReplicaCount(nodes, slot.Replicas) {
+			return fromNode.Id, candidate.Id, true
+		}
+	}
+	return 0, 0, false
+}
+
+func replaceReplica(replicas []uint64, from, to uint64) []uint64 {
+	newReplicas := make([]uint64, 0, len(replicas))
+	for _, replicaId := range replicas {
+		if replicaId == from {
+			newReplicas = append(newReplicas, to)
+			continue
+		}
+		newReplicas = append(newReplicas, replicaId)
+	}
+	return newReplicas
+}
+
+// sortNodeIdsByZoneLeaderCount 将节点按所在可用区的领导数量从少到多排序
+func sortNodeIdsByZoneLeaderCount(nodes []*pb.Node, nodeIdMap map[uint64]uint32, nodeLeaderCountMap map[uint64]uint32) []uint64 {
+	zoneLeaderCountMap := make(map[string]uint32)
+	for nodeId, leaderCount := range nodeLeaderCountMap {
+		node := findNode(nodes, nodeId)
+		if node == nil {
+			continue
+		}
+		zoneLeaderCountMap[nodeZone(node)] += leaderCount
+	}
+	var zoneLeaderCount = func(nodeId uint64) uint32 {
+		node := findNode(nodes, nodeId)
+		if node == nil {
+			return 0
+		}
+		return zoneLeaderCountMap[nodeZone(node)]
+	}
+
+	nodeIds := make([]uint64, 0, len(nodeIdMap))
+	for nodeId := range nodeIdMap {
+		nodeIds = append(nodeIds, nodeId)
+	}
+	sort.Slice(nodeIds, func(i, j int) bool {
+		ci, cj := zoneLeaderCount(nodeIds[i]), zoneLeaderCount(nodeIds[j])
+		if ci != cj {
+			return ci < cj
+		}
+		return nodeIds[i] < nodeIds[j]
+	})
+	return nodeIds
+}
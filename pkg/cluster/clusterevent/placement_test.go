@@ -0,0 +1,87 @@
+package clusterevent
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newZoneNodes() []*pb.Node {
+	return []*pb.Node{
+		{Id: 1, Zone: "a", Online: true, AllowVote: true, Status: pb.NodeStatus_NodeStatusJoined},
+		{Id: 2, Zone: "a", Online: true, AllowVote: true, Status: pb.NodeStatus_NodeStatusJoined},
+		{Id: 3, Zone: "b", Online: true, AllowVote: true, Status: pb.NodeStatus_NodeStatusJoined},
+		{Id: 4, Zone: "b", Online: true, AllowVote: true, Status: pb.NodeStatus_NodeStatusJoined},
+		{Id: 5, Zone: "c", Online: true, AllowVote: true, Status: pb.NodeStatus_NodeStatusJoined},
+	}
+}
+
+func TestSelectReplicas(t *testing.T) {
+	nodes := newZoneNodes()
+
+	replicas := SelectReplicas(nodes, 3, []uint64{1}, nil)
+	assert.Equal(t, 3, len(replicas))
+	assert.Equal(t, uint64(1), replicas[0])
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1}, ZoneReplicaCount(nodes, replicas))
+	assert.False(t, ViolatesZonePolicy(nodes, replicas))
+
+	// 负载低的节点优先
+	replicas = SelectReplicas(nodes, 3, nil, map[uint64]int{1: 10, 3: 10})
+	assert.ElementsMatch(t, []uint64{2, 4, 5}, replicas)
+}
+
+func TestViolatesZonePolicy(t *testing.T) {
+	nodes := newZoneNodes()
+	assert.True(t, ViolatesZonePolicy(nodes, []uint64{1, 2, 3}))
+	assert.False(t, ViolatesZonePolicy(nodes, []uint64{1, 3, 5}))
+
+	// 没有配置可用区不检查
+	nodes = []*pb.Node{{Id: 1}, {Id: 2}, {Id: 3}}
+	assert.False(t, ViolatesZonePolicy(nodes, []uint64{1, 2, 3}))
+}
+
+func TestZoneRepairMigrate(t *testing.T) {
+	nodes := newZoneNodes()
+	slot := &pb.Slot{Id: 1, Leader: 1, Replicas: []uint64{1, 2, 3}}
+
+	from, to, ok := zoneRepairMigrate(nodes, slot, nil)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), from) // 尽量不迁移领导
+	assert.Equal(t, uint64(5), to)
+	assert.False(t, ViolatesZonePolicy(nodes, replaceReplica(slot.Replicas, from, to)))
+
+	assert.False(t, replaceKeepsZoneSpread(nodes, []uint64{1, 3, 5}, 5, 2))
+	assert.True(t, replaceKeepsZoneSpread(nodes, []uint64{1, 3, 5}, 3, 4))
+}
+
+func TestNewInitClusterConfigZones(t *testing.T) {
+	initNodes := map[uint64]string{}
+	locations := map[uint64]NodeLocation{}
+	zones := []string{"a", "b", "c"}
+	for i := uint64(1); i <= 6; i++ {
+		initNodes[i] = "127.0.0.1:11110"
+		if i != 1 { // 本节点的可用区来自自己的配置
+			locations[i] = NodeLocation{Zone: zones[(i-1)%3]}
+		}
+	}
+	s := &Server{
+		opts: NewOptions(
+			WithNodeId(1),
+			WithZone("a"),
+			WithInitNodes(initNodes),
+			WithInitNodeLocations(locations),
+		),
+		Log: wklog.NewWKLog("test"),
+	}
+	cfg := s.newInitClusterConfig()
+	for _, node := range cfg.Nodes {
+		assert.Equal(t, zones[(node.Id-1)%3], node.Zone)
+	}
+	assert.Equal(t, int(s.opts.SlotCount), len(cfg.Slots))
+	for _, slot := range cfg.Slots {
+		assert.Equal(t, 3, len(slot.Replicas))
+		assert.Equal(t, 3, len(ZoneReplicaCount(cfg.Nodes, slot.Replicas)), "slot %d replicas %v", slot.Id, slot.Replicas)
+	}
+}
@@ -0,0 +1,353 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"go.uber.org/zap"
+)
+
+// channelLeaderStat 本节点领导的频道的统计信息
+type channelLeaderStat struct {
+	channelId   string
+	channelType uint8
+	replicas    []uint64
+	msgRate     float64 // 消息速率（条/秒）
+	movable     bool    // 是否可以转移领导（迁移中或者最近变更过配置的频道不转移）
+}
+
+// channelLeaderBalancer 频道领导均衡
+// 每个节点定期统计自己领导的活跃频道数量和频道的消息速率，当自己领导的频道数量超过集群平均值一定比例后，
+// 将消息最繁忙的频道的领导转移给领导数量低于平均值的追随者（通过FollowerToLeader流程完成转移）
+type channelLeaderBalancer struct {
+	s *Server
+	wklog.Log
+
+	mu              sync.Mutex
+	lastLogIndexMap map[string]uint64  // 上一次统计时频道的最新日志下标
+	msgRateMap      map[string]float64 // 频道的消息速率
+	lastSampleTime  time.Time          // 上一次统计时间
+}
+
+func newChannelLeaderBalancer(s *Server) *channelLeaderBalancer {
+	return &channelLeaderBalancer{
+		s:               s,
+		Log:             wklog.NewWKLog(fmt.Sprintf("channelLeaderBalancer[%d]", s.opts.NodeId)),
+		lastLogIndexMap: make(map[string]uint64),
+		msgRateMap:      make(map[string]float64),
+	}
+}
+
+func (b *channelLeaderBalancer) loop() {
+	if b.s.opts.ChannelLeaderBalanceInterval <= 0 { // 关闭了频道领导均衡
+		return
+	}
+	tk := time.NewTicker(b.s.opts.ChannelLeaderBalanceInterval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			b.sample()
+			b.balance()
+		case <-b.s.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+// sample 统计频道的消息速率
+func (b *channelLeaderBalancer) sample() {
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elapsed := now.Sub(b.lastSampleTime).Seconds()
+	lastLogIndexMap := make(map[string]uint64, len(b.lastLogIndexMap))
+	msgRateMap := make(map[string]float64, len(b.msgRateMap))
+	b.s.channelManager.channelReactor.IteratorHandler(func(h reactor.IHandler) bool {
+		ch := h.(*channel)
+		if !ch.isLeader() {
+			return true
+		}
+		lastIndex, _ := ch.LastLogIndexAndTerm()
+		lastLogIndexMap[ch.key] = lastIndex
+		if prevIndex, ok := b.lastLogIndexMap[ch.key]; ok && lastIndex > prevIndex && elapsed > 0 {
+			msgRateMap[ch.key] = float64(lastIndex-prevIndex) / elapsed
+		}
+		return true
+	})
+	b.lastLogIndexMap = lastLogIndexMap
+	b.msgRateMap = msgRateMap
+	b.lastSampleTime = now
+}
+
+// balance 执行一轮均衡
+func (b *channelLeaderBalancer) balance() {
+	resp, err := b.plan()
+	if err != nil {
+		b.Warn("plan channel leader balance failed", zap.Error(err))
+		return
+	}
+	if len(resp.Transfers) == 0 {
+		return
+	}
+	if b.s.opts.ChannelLeaderBalanceDryRun {
+		for _, transfer := range resp.Transfers {
+			b.Info("dry run: transfer channel leader", zap.String("channelId", transfer.ChannelId), zap.Uint8("channelType", transfer.ChannelType), zap.Uint64("from", transfer.From), zap.Uint64("to", transfer.To), zap.Float64("msgRate", transfer.MsgRate))
+		}
+		return
+	}
+	for _, transfer := range resp.Transfers {
+		cfg, err := b.s.latestChannelClusterConfig(transfer.ChannelId, transfer.ChannelType)
+		if err != nil {
+			b.Warn("get channel cluster config failed", zap.Error(err), zap.String("channelId", transfer.ChannelId), zap.Uint8("channelType", transfer.ChannelType))
+			continue
+		}
+		if cfg.LeaderId != transfer.From { // 领导已经变更
+			continue
+		}
+		b.Info("transfer channel leader", zap.String("channelId", transfer.ChannelId), zap.Uint8("channelType", transfer.ChannelType), zap.Uint64("from", transfer.From), zap.Uint64("to", transfer.To), zap.Float64("msgRate", transfer.MsgRate))
+		err = b.s.transferChannelLeader(cfg, transfer.To)
+		if err != nil {
+			b.Warn("transfer channel leader failed", zap.Error(err), zap.String("channelId", transfer.ChannelId), zap.Uint8("channelType", transfer.ChannelType))
+		}
+	}
+}
+
+// plan 计算均衡计划（不执行）
+func (b *channelLeaderBalancer) plan() (*ChannelLeaderBalanceResp, error) {
+	channels := b.localLeaderChannels()
+	nodeLeaderCounts, err := b.nodeLeaderCounts(len(channels))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ChannelLeaderBalanceResp{
+		NodeId:           b.s.opts.NodeId,
+		DryRun:           wkutil.BoolToInt(b.s.opts.ChannelLeaderBalanceDryRun),
+		AvgLeaderCount:   avgLeaderCount(nodeLeaderCounts),
+		NodeLeaderCounts: nodeLeaderCounts,
+		Transfers:        planChannelLeaderTransfers(b.s.opts.NodeId, nodeLeaderCounts, channels, b.s.opts.ChannelLeaderBalanceThreshold, b.s.opts.ChannelLeaderBalanceMaxPerRound),
+	}
+	if resp.Transfers == nil {
+		resp.Transfers = make([]*ChannelLeaderTransfer, 0)
+	}
+	return resp, nil
+}
+
+// localLeaderChannels 本节点领导的活跃频道
+func (b *channelLeaderBalancer) localLeaderChannels() []*channelLeaderStat {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	channels := make([]*channelLeaderStat, 0)
+	b.s.channelManager.channelReactor.IteratorHandler(func(h reactor.IHandler) bool {
+		ch := h.(*channel)
+		if !ch.isLeader() {
+			return true
+		}
+		cfg := ch.cfg
+		movable := cfg.MigrateFrom == 0 && cfg.MigrateTo == 0 && time.Since(time.Unix(0, int64(cfg.ConfVersion))) > b.s.opts.ChannelLeaderBalanceCooldown
+		channels = append(channels, &channelLeaderStat{
+			channelId:   ch.channelId,
+			channelType: ch.channelType,
+			replicas:    cfg.Replicas,
+			msgRate:     b.msgRateMap[ch.key],
+			movable:     movable,
+		})
+		return true
+	})
+	return channels
+}
+
+// nodeLeaderCounts 获取每个在线节点领导的活跃频道数量
+func (b *channelLeaderBalancer) nodeLeaderCounts(localCount int) (map[uint64]int, error) {
+	nodes := b.s.clusterEventServer.AllowVoteAndJoinedOnlineNodes()
+	nodeLeaderCounts := make(map[uint64]int, len(nodes))
+	for _, node := range nodes {
+		if node.Id == b.s.opts.NodeId {
+			nodeLeaderCounts[node.Id] = localCount
+			continue
+		}
+		n := b.s.nodeManager.node(node.Id)
+		if n == nil {
+			continue
+		}
+		timeoutCtx, cancel := context.WithTimeout(b.s.cancelCtx, b.s.opts.ReqTimeout)
+		count, err := n.requestChannelLeaderCount(timeoutCtx)
+		cancel()
+		if err != nil { // 获取失败的节点不参与均衡
+			b.Warn("request channel leader count failed", zap.Error(err), zap.Uint64("nodeId", node.Id))
+			continue
+		}
+		nodeLeaderCounts[node.Id] = int(count)
+	}
+	if _, ok := nodeLeaderCounts[b.s.opts.NodeId]; !ok {
+		return nil, errors.New("current node is not allowed to be channel leader")
+	}
+	return nodeLeaderCounts, nil
+}
+
+// channelLeaderCount 本节点领导的活跃频道数量
+func (s *Server) channelLeaderCount() int {
+	count := 0
+	s.channelManager.channelReactor.IteratorHandler(func(h reactor.IHandler) bool {
+		if h.(*channel).isLeader() {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+func avgLeaderCount(nodeLeaderCounts map[uint64]int) float64 {
+	if len(nodeLeaderCounts) == 0 {
+		return 0
+	}
+	total := 0
+	for _, count := range nodeLeaderCounts {
+		total += count
+	}
+	return float64(total) / float64(len(nodeLeaderCounts))
+}
+
+// planChannelLeaderTransfers 计算频道领导转移计划
+// 只有当本节点的领导数量超过平均值的(1+threshold)倍时才开始转移，转移到平均值后停止（滞后区间避免频道领导来回转移）
+// 消息速率高的频道优先转移，目标节点为领导数量最少且接收后不超过平均值的追随者
+func planChannelLeaderTransfers(nodeId uint64, nodeLeaderCounts map[uint64]int, channels []*channelLeaderStat, threshold float64, maxCount int) []*ChannelLeaderTransfer {
+	if len(nodeLeaderCounts) <= 1 || maxCount <= 0 {
+		return nil
+	}
+	avg := avgLeaderCount(nodeLeaderCounts)
+	localCount := nodeLeaderCounts[nodeId]
+	if float64(localCount) <= avg*(1+threshold) || float64(localCount)-avg < 1 {
+		return nil
+	}
+
+	counts := make(map[uint64]int, len(nodeLeaderCounts))
+	for id, count := range nodeLeaderCounts {
+		counts[id] = count
+	}
+
+	sortedChannels := make([]*channelLeaderStat, 0, len(channels))
+	for _, ch := range channels {
+		if ch.movable {
+			sortedChannels = append(sortedChannels, ch)
+		}
+	}
+	sort.SliceStable(sortedChannels, func(i, j int) bool {
+		return sortedChannels[i].msgRate > sortedChannels[j].msgRate
+	})
+
+	var transfers []*ChannelLeaderTransfer
+	for _, ch := range sortedChannels {
+		if len(transfers) >= maxCount || float64(counts[nodeId]-1) < avg {
+			break
+		}
+		var to uint64
+		for _, replicaId := range ch.replicas {
+			if replicaId == nodeId {
+				continue
+			}
+			count, ok := counts[replicaId]
+			if !ok { // 节点不可用
+				continue
+			}
+			if float64(count+1) > avg {
+				continue
+			}
+			if to == 0 || count < counts[to] || (count == counts[to] && replicaId < to) {
+				to = replicaId
+			}
+		}
+		if to == 0 {
+			continue
+		}
+		counts[nodeId]--
+		counts[to]++
+		transfers = append(transfers, &ChannelLeaderTransfer{
+			ChannelId:   ch.channelId,
+			ChannelType: ch.channelType,
+			From:        nodeId,
+			To:          to,
+			MsgRate:     ch.msgRate,
+		})
+	}
+	return transfers
+}
+
+// latestChannelClusterConfig 从槽领导获取频道最新的分布式配置
+func (s *Server) latestChannelClusterConfig(channelId string, channelType uint8) (wkdb.ChannelClusterConfig, error) {
+	slotId := s.getSlotId(channelId)
+	slot := s.clusterEventServer.Slot(slotId)
+	if slot == nil {
+		return wkdb.EmptyChannelClusterConfig, ErrSlotNotExist
+	}
+	if slot.Leader == s.opts.NodeId {
+		return s.getChannelClusterConfig(channelId, channelType)
+	}
+	return s.requestChannelClusterConfigFromSlotLeader(channelId, channelType)
+}
+
+// checkChannelLeaderTransfer 检查频道领导是否可以转移给指定节点
+func (s *Server) checkChannelLeaderTransfer(cfg wkdb.ChannelClusterConfig, to uint64) error {
+	if to == 0 {
+		return errors.New("to node id is 0")
+	}
+	if cfg.LeaderId == to {
+		return errors.New("the node is already the leader")
+	}
+	if cfg.MigrateFrom != 0 || cfg.MigrateTo != 0 {
+		return errors.New("migrate is in progress")
+	}
+	if !wkutil.ArrayContainsUint64(cfg.Replicas, to) {
+		return errors.New("the node is not in replicas")
+	}
+	if !s.NodeIsOnline(to) {
+		return errors.New("the node is offline")
+	}
+	return nil
+}
+
+// transferChannelLeader 将频道领导转移给指定的追随者
+// 迁移的源节点为当前领导，目标节点为追随者，追随者追上领导的日志后通过FollowerToLeader流程成为新的领导
+func (s *Server) transferChannelLeader(cfg wkdb.ChannelClusterConfig, to uint64) error {
+	err := s.checkChannelLeaderTransfer(cfg, to)
+	if err != nil {
+		return err
+	}
+	newCfg := cfg.Clone()
+	newCfg.MigrateFrom = cfg.LeaderId
+	newCfg.MigrateTo = to
+	newCfg.ConfVersion = uint64(time.Now().UnixNano())
+
+	timeoutCtx, cancel := context.WithTimeout(s.cancelCtx, s.opts.ReqTimeout)
+	defer cancel()
+	err = s.opts.ChannelClusterStorage.Propose(timeoutCtx, newCfg)
+	if err != nil {
+		return err
+	}
+	s.clusterCfgCache.Add(wkutil.ChannelToKey(newCfg.ChannelId, newCfg.ChannelType), newCfg)
+
+	// 通知领导和目标节点（这里就算发送失败也没问题，因为频道领导会间隔比对自己与槽领导的配置）
+	for _, nodeId := range []uint64{newCfg.LeaderId, to} {
+		if nodeId == s.opts.NodeId {
+			s.UpdateChannelClusterConfig(newCfg)
+			continue
+		}
+		err = s.SendChannelClusterConfigUpdate(newCfg.ChannelId, newCfg.ChannelType, nodeId)
+		if err != nil {
+			s.Warn("transferChannelLeader: sendChannelClusterConfigUpdate failed", zap.Error(err), zap.Uint64("nodeId", nodeId), zap.String("channelId", newCfg.ChannelId), zap.Uint8("channelType", newCfg.ChannelType))
+		}
+	}
+	return nil
+}
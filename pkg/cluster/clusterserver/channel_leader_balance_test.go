@@ -0,0 +1,41 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanChannelLeaderTransfers(t *testing.T) {
+	channels := []*channelLeaderStat{
+		{channelId: "idle", channelType: 2, replicas: []uint64{1, 2, 3}, msgRate: 0, movable: true},
+		{channelId: "hot", channelType: 2, replicas: []uint64{1, 2, 3}, msgRate: 100, movable: true},
+		{channelId: "migrating", channelType: 2, replicas: []uint64{1, 2, 3}, msgRate: 1000, movable: false},
+		{channelId: "warm", channelType: 2, replicas: []uint64{1, 3}, msgRate: 10, movable: true},
+	}
+
+	// 超过平均值但在滞后区间内，不转移
+	transfers := planChannelLeaderTransfers(1, map[uint64]int{1: 11, 2: 10, 3: 9}, channels, 0.2, 10)
+	assert.Equal(t, 0, len(transfers))
+
+	// 繁忙的频道优先转移，转移到领导最少的节点
+	transfers = planChannelLeaderTransfers(1, map[uint64]int{1: 6, 2: 1, 3: 2}, channels, 0.2, 10)
+	assert.Equal(t, 3, len(transfers))
+	assert.Equal(t, "hot", transfers[0].ChannelId)
+	assert.Equal(t, uint64(2), transfers[0].To)
+	assert.Equal(t, "warm", transfers[1].ChannelId)
+	assert.Equal(t, uint64(3), transfers[1].To)
+	assert.Equal(t, "idle", transfers[2].ChannelId)
+	assert.Equal(t, uint64(2), transfers[2].To)
+
+	// 每轮最多转移的数量
+	transfers = planChannelLeaderTransfers(1, map[uint64]int{1: 6, 2: 1, 3: 2}, channels, 0.2, 1)
+	assert.Equal(t, 1, len(transfers))
+
+	// 离线的节点不作为目标
+	transfers = planChannelLeaderTransfers(1, map[uint64]int{1: 6, 3: 0}, channels, 0.2, 10)
+	assert.Equal(t, 3, len(transfers))
+	for _, transfer := range transfers {
+		assert.Equal(t, uint64(3), transfer.To)
+	}
+}
@@ -80,7 +80,18 @@ func (c *channelManager) getWithHandleKey(handleKey string) reactor.IHandler {
 }
 
 func (c *channelManager) proposeAndWait(ctx context.Context, channelId string, channelType uint8, logs []replica.Log) ([]reactor.ProposeResult, error) {
-	return c.channelReactor.ProposeAndWait(ctx, wkutil.ChannelToKey(channelId, channelType), logs)
+	// 提案并等待副本复制提交
+	_, span := trace.GlobalTrace.StartSpan(ctx, "replicateChannelMessages")
+	span.SetString("channelId", channelId)
+	span.SetUint8("channelType", channelType)
+	span.SetInt("logCount", len(logs))
+	defer span.End()
+
+	results, err := c.channelReactor.ProposeAndWait(ctx, wkutil.ChannelToKey(channelId, channelType), logs)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return results, err
 }
 
 func (c *channelManager) addMessage(m reactor.Message) {
@@ -0,0 +1,253 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterevent"
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"go.uber.org/zap"
+)
+
+// channelRepair 频道副本修复
+// 槽领导定期扫描自己负责的槽内的频道：
+// 1. 移除永久失效（长时间离线或已不在集群内）的追随者副本
+// 2. 为副本数量不足的频道添加学习者，学习者追上日志后通过LearnerToFollower流程转为追随者
+// 3. 取消长时间未完成的修复（例如学习者节点离线）
+type channelRepair struct {
+	s *Server
+	wklog.Log
+}
+
+func newChannelRepair(s *Server) *channelRepair {
+	return &channelRepair{
+		s:   s,
+		Log: wklog.NewWKLog(fmt.Sprintf("channelRepair[%d]", s.opts.NodeId)),
+	}
+}
+
+func (c *channelRepair) loop() {
+	if c.s.opts.ChannelRepairInterval <= 0 { // 关闭了频道副本修复
+		return
+	}
+	tk := time.NewTicker(c.s.opts.ChannelRepairInterval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			c.repair()
+		case <-c.s.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+// repair 执行一轮修复，每轮最多修复ChannelRepairMaxPerRound个频道
+func (c *channelRepair) repair() {
+	remaining := c.s.opts.ChannelRepairMaxPerRound
+	underReplicatedCount := 0
+	for _, slot := range c.s.clusterEventServer.Slots() {
+		if slot.Leader != c.s.opts.NodeId {
+			continue
+		}
+		cfgs, err := c.s.opts.ChannelClusterStorage.GetWithSlotId(slot.Id)
+		if err != nil {
+			c.Error("get channel cluster configs failed", zap.Error(err), zap.Uint32("slotId", slot.Id))
+			continue
+		}
+		for _, cfg := range cfgs {
+			if len(cfg.Replicas) < c.replicaMaxCount(cfg) {
+				underReplicatedCount++
+			}
+			if remaining <= 0 { // 本轮修复数量已达上限，只统计不修复
+				continue
+			}
+			repaired, err := c.repairChannel(cfg)
+			if err != nil {
+				c.Warn("repair channel failed", zap.Error(err), zap.String("channelId", cfg.ChannelId), zap.Uint8("channelType", cfg.ChannelType))
+				continue
+			}
+			if repaired {
+				remaining--
+			}
+		}
+	}
+	trace.GlobalTrace.Metrics.Cluster().ChannelUnderReplicatedCountSet(int64(underReplicatedCount))
+}
+
+// channelRepairNodes 修复时需要的节点信息（由clusterevent.Server提供）
+type channelRepairNodes interface {
+	Node(nodeId uint64) *pb.Node
+	NodeOnline(nodeId uint64) bool
+	AllowVoteAndJoinedOnlineNodes() []*pb.Node
+}
+
+// channelRepairPlan 单个频道的修复计划
+type channelRepairPlan struct {
+	cfg          wkdb.ChannelClusterConfig // 修复后的配置
+	notifyIds    []uint64                  // 需要通知更新配置的节点
+	deadReplicas []uint64                  // 移除的永久失效的副本
+	learnerId    uint64                    // 添加的学习者
+}
+
+// repairChannel 修复单个频道，返回是否提案了新的配置
+func (c *channelRepair) repairChannel(cfg wkdb.ChannelClusterConfig) (bool, error) {
+	plan := c.plan(cfg, c.s.clusterEventServer, time.Now())
+	if plan == nil {
+		return false, nil
+	}
+	err := c.proposeAndNotify(plan.cfg, plan.notifyIds...)
+	if err != nil {
+		return false, err
+	}
+	if len(plan.deadReplicas) > 0 {
+		trace.GlobalTrace.Metrics.Cluster().ChannelRepairReplicaRemoveCountAdd(int64(len(plan.deadReplicas)))
+	}
+	if plan.learnerId != 0 {
+		trace.GlobalTrace.Metrics.Cluster().ChannelRepairLearnerAddCountAdd(1)
+	}
+	return true, nil
+}
+
+// plan 生成单个频道的修复计划，不需要修复返回nil
+func (c *channelRepair) plan(cfg wkdb.ChannelClusterConfig, nodes channelRepairNodes, now time.Time) *channelRepairPlan {
+	if cfg.LeaderId == 0 || !nodes.NodeOnline(cfg.LeaderId) { // 领导不可用的交给频道选举处理
+		return nil
+	}
+
+	// 迁移中的频道，只处理超时的修复
+	if cfg.MigrateFrom != 0 || cfg.MigrateTo != 0 {
+		if !c.isRepairing(cfg) || now.Sub(time.Unix(0, int64(cfg.ConfVersion))) < c.s.opts.ChannelRepairLearnerTimeout {
+			return nil
+		}
+		c.Info("repair timeout, remove learner", zap.String("channelId", cfg.ChannelId), zap.Uint8("channelType", cfg.ChannelType), zap.Uint64("learnerId", cfg.MigrateTo))
+		newCfg := cfg.Clone()
+		newCfg.Learners = wkutil.RemoveUint64(newCfg.Learners, cfg.MigrateTo)
+		newCfg.MigrateFrom = 0
+		newCfg.MigrateTo = 0
+		newCfg.ConfVersion = uint64(now.UnixNano())
+		return &channelRepairPlan{cfg: newCfg, notifyIds: []uint64{newCfg.LeaderId, cfg.MigrateTo}}
+	}
+
+	// 移除永久失效的追随者副本
+	deadReplicas := c.deadReplicas(cfg, nodes, now)
+	if len(deadReplicas) > 0 {
+		c.Info("remove dead replicas", zap.String("channelId", cfg.ChannelId), zap.Uint8("channelType", cfg.ChannelType), zap.Uint64s("deadReplicas", deadReplicas))
+		newCfg := cfg.Clone()
+		for _, replicaId := range deadReplicas {
+			newCfg.Replicas = wkutil.RemoveUint64(newCfg.Replicas, replicaId)
+		}
+		newCfg.ConfVersion = uint64(now.UnixNano())
+		return &channelRepairPlan{cfg: newCfg, notifyIds: []uint64{newCfg.LeaderId}, deadReplicas: deadReplicas}
+	}
+
+	// 副本数量不足，添加学习者
+	if len(cfg.Replicas) >= c.replicaMaxCount(cfg) {
+		return nil
+	}
+	learnerId := c.selectLearner(cfg, nodes)
+	if learnerId == 0 { // 没有可用的节点
+		return nil
+	}
+	c.Info("add learner", zap.String("channelId", cfg.ChannelId), zap.Uint8("channelType", cfg.ChannelType), zap.Uint64("learnerId", learnerId))
+	newCfg := cfg.Clone()
+	newCfg.Learners = append(newCfg.Learners, learnerId)
+	// 迁移的源节点和目标节点都为学习者，表示新增副本，学习者追上日志后通过LearnerToFollower流程转为追随者
+	newCfg.MigrateFrom = learnerId
+	newCfg.MigrateTo = learnerId
+	newCfg.ConfVersion = uint64(now.UnixNano())
+	return &channelRepairPlan{cfg: newCfg, notifyIds: []uint64{newCfg.LeaderId, learnerId}, learnerId: learnerId}
+}
+
+// isRepairing 是否是修复添加的学习者（迁移的源节点和目标节点相同）
+func (c *channelRepair) isRepairing(cfg wkdb.ChannelClusterConfig) bool {
+	return cfg.MigrateFrom != 0 && cfg.MigrateFrom == cfg.MigrateTo
+}
+
+func (c *channelRepair) replicaMaxCount(cfg wkdb.ChannelClusterConfig) int {
+	if cfg.ReplicaMaxCount > 0 {
+		return int(cfg.ReplicaMaxCount)
+	}
+	return c.s.opts.ChannelMaxReplicaCount
+}
+
+// deadReplicas 获取永久失效的追随者副本
+func (c *channelRepair) deadReplicas(cfg wkdb.ChannelClusterConfig, nodes channelRepairNodes, now time.Time) []uint64 {
+	var deadReplicas []uint64
+	for _, replicaId := range cfg.Replicas {
+		if replicaId == cfg.LeaderId {
+			continue
+		}
+		if c.isDeadNode(nodes.Node(replicaId), now) {
+			deadReplicas = append(deadReplicas, replicaId)
+		}
+	}
+	return deadReplicas
+}
+
+// isDeadNode 节点是否永久失效（已不在集群内或离线时间超过ChannelRepairDeadReplicaTimeout）
+func (c *channelRepair) isDeadNode(node *pb.Node, now time.Time) bool {
+	if node == nil {
+		return true
+	}
+	if node.Online || node.LastOffline == 0 || c.s.opts.ChannelRepairDeadReplicaTimeout <= 0 {
+		return false
+	}
+	return now.Sub(time.Unix(node.LastOffline, 0)) > c.s.opts.ChannelRepairDeadReplicaTimeout
+}
+
+// selectLearner 按可用区分散的原则选择一个健康的节点作为学习者
+func (c *channelRepair) selectLearner(cfg wkdb.ChannelClusterConfig, nodes channelRepairNodes) uint64 {
+	candidates := make([]*pb.Node, 0)
+	hasNewNode := false
+	for _, node := range nodes.AllowVoteAndJoinedOnlineNodes() {
+		if wkutil.ArrayContainsUint64(cfg.Learners, node.Id) {
+			continue
+		}
+		if !wkutil.ArrayContainsUint64(cfg.Replicas, node.Id) {
+			hasNewNode = true
+		}
+		candidates = append(candidates, node)
+	}
+	if !hasNewNode {
+		return 0
+	}
+	replicas := clusterevent.SelectReplicas(candidates, len(cfg.Replicas)+1, cfg.Replicas, nil)
+	if len(replicas) <= len(cfg.Replicas) {
+		return 0
+	}
+	return replicas[len(replicas)-1]
+}
+
+// proposeAndNotify 提案频道的分布式配置，并通知相关节点更新配置
+// 通知失败不影响修复，因为频道领导会间隔比对自己与槽领导的配置
+func (c *channelRepair) proposeAndNotify(cfg wkdb.ChannelClusterConfig, nodeIds ...uint64) error {
+	timeoutCtx, cancel := context.WithTimeout(c.s.cancelCtx, c.s.opts.ReqTimeout)
+	defer cancel()
+
+	err := c.s.opts.ChannelClusterStorage.Propose(timeoutCtx, cfg)
+	if err != nil {
+		return err
+	}
+	c.s.clusterCfgCache.Add(wkutil.ChannelToKey(cfg.ChannelId, cfg.ChannelType), cfg)
+
+	for _, nodeId := range nodeIds {
+		if nodeId == c.s.opts.NodeId {
+			c.s.UpdateChannelClusterConfig(cfg)
+			continue
+		}
+		if !c.s.NodeIsOnline(nodeId) {
+			continue
+		}
+		err = c.s.SendChannelClusterConfigUpdate(cfg.ChannelId, cfg.ChannelType, nodeId)
+		if err != nil {
+			c.Warn("send channel cluster config update failed", zap.Error(err), zap.Uint64("nodeId", nodeId), zap.String("channelId", cfg.ChannelId), zap.Uint8("channelType", cfg.ChannelType))
+		}
+	}
+	return nil
+}
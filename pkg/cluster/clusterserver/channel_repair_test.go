@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRepairNodes struct {
+	nodes []*pb.Node
+}
+
+func (t *testRepairNodes) Node(nodeId uint64) *pb.Node {
+	for _, node := range t.nodes {
+		if node.Id == nodeId {
+			return node
+		}
+	}
+	return nil
+}
+
+func (t *testRepairNodes) NodeOnline(nodeId uint64) bool {
+	node := t.Node(nodeId)
+	return node != nil && node.Online
+}
+
+func (t *testRepairNodes) AllowVoteAndJoinedOnlineNodes() []*pb.Node {
+	nodes := make([]*pb.Node, 0, len(t.nodes))
+	for _, node := range t.nodes {
+		if node.AllowVote && node.Status == pb.NodeStatus_NodeStatusJoined && node.Online {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func newTestRepairNode(id uint64, zone string) *pb.Node {
+	return &pb.Node{Id: id, Zone: zone, Online: true, AllowVote: true, Status: pb.NodeStatus_NodeStatusJoined}
+}
+
+func newTestChannelRepair() *channelRepair {
+	return newChannelRepair(&Server{opts: NewOptions()})
+}
+
+func TestChannelRepairAddLearner(t *testing.T) {
+	c := newTestChannelRepair()
+	now := time.Now()
+	nodes := &testRepairNodes{nodes: []*pb.Node{
+		newTestRepairNode(1, "a"),
+		newTestRepairNode(2, "b"),
+		newTestRepairNode(3, "c"),
+	}}
+	cfg := wkdb.ChannelClusterConfig{ChannelId: "g1", ChannelType: 2, LeaderId: 1, Replicas: []uint64{1, 2}}
+
+	plan := c.plan(cfg, nodes, now)
+	assert.NotNil(t, plan)
+	assert.Equal(t, uint64(3), plan.learnerId)
+	assert.Equal(t, []uint64{3}, plan.cfg.Learners)
+	// 迁移的源节点和目标节点都为学习者，表示修复新增的副本
+	assert.Equal(t, uint64(3), plan.cfg.MigrateFrom)
+	assert.Equal(t, uint64(3), plan.cfg.MigrateTo)
+	assert.True(t, c.isRepairing(plan.cfg))
+	assert.Equal(t, []uint64{1, 3}, plan.notifyIds)
+	assert.Equal(t, []uint64{1, 2}, plan.cfg.Replicas)
+
+	// 副本数量足够不需要修复
+	cfg.Replicas = []uint64{1, 2, 3}
+	assert.Nil(t, c.plan(cfg, nodes, now))
+
+	// 领导离线交给频道选举处理
+	cfg.Replicas = []uint64{1, 2}
+	nodes.nodes[0].Online = false
+	assert.Nil(t, c.plan(cfg, nodes, now))
+}
+
+func TestChannelRepairRemoveDeadReplica(t *testing.T) {
+	c := newTestChannelRepair()
+	now := time.Now()
+	nodes := &testRepairNodes{nodes: []*pb.Node{
+		newTestRepairNode(1, "a"),
+		newTestRepairNode(2, "b"),
+		newTestRepairNode(3, "c"),
+	}}
+	// 节点2刚离线，还不算永久失效
+	nodes.nodes[1].Online = false
+	nodes.nodes[1].LastOffline = now.Add(-time.Minute).Unix()
+	cfg := wkdb.ChannelClusterConfig{ChannelId: "g1", ChannelType: 2, LeaderId: 1, Replicas: []uint64{1, 2, 3}}
+	assert.Nil(t, c.plan(cfg, nodes, now))
+
+	// 离线超过永久失效时间
+	nodes.nodes[1].LastOffline = now.Add(-c.s.opts.ChannelRepairDeadReplicaTimeout - time.Minute).Unix()
+	plan := c.plan(cfg, nodes, now)
+	assert.NotNil(t, plan)
+	assert.Equal(t, []uint64{2}, plan.deadReplicas)
+	assert.Equal(t, []uint64{1, 3}, plan.cfg.Replicas)
+	assert.Equal(t, uint64(0), plan.learnerId)
+	assert.Equal(t, []uint64{1}, plan.notifyIds)
+
+	// 已不在集群内的节点也是永久失效
+	nodes.nodes = nodes.nodes[:1]
+	plan = c.plan(wkdb.ChannelClusterConfig{ChannelId: "g1", ChannelType: 2, LeaderId: 1, Replicas: []uint64{1, 4}}, nodes, now)
+	assert.NotNil(t, plan)
+	assert.Equal(t, []uint64{4}, plan.deadReplicas)
+}
+
+func TestChannelRepairLearnerTimeout(t *testing.T) {
+	c := newTestChannelRepair()
+	now := time.Now()
+	nodes := &testRepairNodes{nodes: []*pb.Node{
+		newTestRepairNode(1, "a"),
+		newTestRepairNode(2, "b"),
+		newTestRepairNode(3, "c"),
+	}}
+	cfg := wkdb.ChannelClusterConfig{
+		ChannelId:   "g1",
+		ChannelType: 2,
+		LeaderId:    1,
+		Replicas:    []uint64{1, 2},
+		Learners:    []uint64{3},
+		MigrateFrom: 3,
+		MigrateTo:   3,
+		ConfVersion: uint64(now.Add(-time.Minute).UnixNano()),
+	}
+	// 修复进行中，未超时
+	assert.Nil(t, c.plan(cfg, nodes, now))
+
+	// 学习者超时未转为追随者，取消修复
+	cfg.ConfVersion = uint64(now.Add(-c.s.opts.ChannelRepairLearnerTimeout - time.Minute).UnixNano())
+	plan := c.plan(cfg, nodes, now)
+	assert.NotNil(t, plan)
+	assert.Equal(t, 0, len(plan.cfg.Learners))
+	assert.Equal(t, uint64(0), plan.cfg.MigrateFrom)
+	assert.Equal(t, uint64(0), plan.cfg.MigrateTo)
+	assert.Equal(t, []uint64{1, 3}, plan.notifyIds)
+
+	// 普通的迁移不由修复处理
+	cfg.MigrateFrom = 2
+	assert.Nil(t, c.plan(cfg, nodes, now))
+}
+
+func TestChannelRepairSelectLearnerZoneSpread(t *testing.T) {
+	c := newTestChannelRepair()
+	nodes := &testRepairNodes{nodes: []*pb.Node{
+		newTestRepairNode(1, "a"),
+		newTestRepairNode(2, "a"),
+		newTestRepairNode(3, "a"),
+		newTestRepairNode(4, "b"),
+	}}
+	// 已有副本都在可用区a，优先选择其他可用区的节点
+	cfg := wkdb.ChannelClusterConfig{ChannelId: "g1", ChannelType: 2, LeaderId: 1, Replicas: []uint64{1, 2}}
+	assert.Equal(t, uint64(4), c.selectLearner(cfg, nodes))
+
+	// 离线的节点不作为学习者
+	nodes.nodes[3].Online = false
+	assert.Equal(t, uint64(3), c.selectLearner(cfg, nodes))
+
+	// 已经是学习者的节点不再选择
+	cfg.Learners = []uint64{3}
+	assert.Equal(t, uint64(0), c.selectLearner(cfg, nodes))
+}
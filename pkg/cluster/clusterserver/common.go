@@ -174,6 +174,7 @@ func (s *Server) getLocalNodeInfo() *NodeConfig {
 	nodeCfg.Uptime = myUptime(time.Since(s.uptime))
 	nodeCfg.AppVersion = s.opts.AppVersion
 	nodeCfg.ConfigVersion = cfg.Version
+	nodeCfg.ChannelLeaderCount = s.channelLeaderCount()
 	return nodeCfg
 }
 
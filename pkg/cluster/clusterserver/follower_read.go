@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"go.uber.org/zap"
+)
+
+// ReplicaOfChannelForRead 获取读取频道消息的副本节点（追随者读）
+// 当前节点是副本并且已应用的消息序号不小于minSeq时返回当前节点，当前节点数据落后时返回领导节点，
+// 当前节点不是副本时返回负载最低的在线副本（被选中的副本收到请求后会再次判断数据是否落后）
+func (s *Server) ReplicaOfChannelForRead(channelId string, channelType uint8, minSeq uint64) (*pb.Node, error) {
+	cfg, err := s.loadOnlyChannelClusterConfig(channelId, channelType)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LeaderId == 0 {
+		return nil, ErrNotLeader
+	}
+	leaderNode := s.clusterEventServer.Node(cfg.LeaderId)
+	if leaderNode == nil {
+		return nil, ErrNodeNotExist
+	}
+	node := s.clusterEventServer.Node(s.replicaIdForRead(cfg, minSeq, s.NodeIsOnline))
+	if node == nil {
+		return leaderNode, nil
+	}
+	return node, nil
+}
+
+// replicaIdForRead 选择读取频道消息的副本节点id（规则见ReplicaOfChannelForRead）
+func (s *Server) replicaIdForRead(cfg wkdb.ChannelClusterConfig, minSeq uint64, nodeOnline func(nodeId uint64) bool) uint64 {
+	if cfg.LeaderId == s.opts.NodeId {
+		return cfg.LeaderId
+	}
+
+	if wkutil.ArrayContainsUint64(cfg.Replicas, s.opts.NodeId) {
+		appliedIndex, err := s.opts.MessageLogStorage.AppliedIndex(wkutil.ChannelToKey(cfg.ChannelId, cfg.ChannelType))
+		if err != nil {
+			s.Warn("replicaIdForRead: get applied index failed", zap.Error(err), zap.String("channelId", cfg.ChannelId), zap.Uint8("channelType", cfg.ChannelType))
+			return cfg.LeaderId
+		}
+		if appliedIndex < minSeq { // 数据落后，从领导读取
+			return cfg.LeaderId
+		}
+		return s.opts.NodeId
+	}
+
+	// 追随者优先，领导放在最后
+	candidates := make([]uint64, 0, len(cfg.Replicas))
+	for _, replicaId := range cfg.Replicas {
+		if replicaId != cfg.LeaderId && nodeOnline(replicaId) {
+			candidates = append(candidates, replicaId)
+		}
+	}
+	candidates = append(candidates, cfg.LeaderId)
+	return s.readLoad.leastLoaded(candidates)
+}
+
+// ReadableMessageSeqOfChannel 当前节点作为频道追随者时可读取的最大消息序号（已应用的日志下标）
+// 当前节点是频道领导或者不是频道副本时返回0，表示不限制
+func (s *Server) ReadableMessageSeqOfChannel(channelId string, channelType uint8) (uint64, error) {
+	cfg, err := s.loadOnlyChannelClusterConfig(channelId, channelType)
+	if err != nil {
+		return 0, err
+	}
+	if cfg.LeaderId == s.opts.NodeId || !wkutil.ArrayContainsUint64(cfg.Replicas, s.opts.NodeId) {
+		return 0, nil
+	}
+	return s.opts.MessageLogStorage.AppliedIndex(wkutil.ChannelToKey(channelId, channelType))
+}
+
+// readLoadCounter 统计当前节点分配给每个副本节点的读请求数量，用于追随者读时选择负载最低的副本
+// 统计按时间窗口进行，每个窗口结束后数量减半，避免历史数据影响太久
+type readLoadCounter struct {
+	mu          sync.Mutex
+	counts      map[uint64]int64
+	window      time.Duration
+	windowStart time.Time
+}
+
+func newReadLoadCounter(window time.Duration) *readLoadCounter {
+	return &readLoadCounter{
+		counts:      make(map[uint64]int64),
+		window:      window,
+		windowStart: time.Now(),
+	}
+}
+
+// leastLoaded 从候选节点中选择负载最低的节点（负载相同时按候选顺序），并增加其负载
+func (r *readLoadCounter) leastLoaded(nodeIds []uint64) uint64 {
+	if len(nodeIds) == 0 {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.windowStart) > r.window {
+		for nodeId, count := range r.counts {
+			if count/2 == 0 {
+				delete(r.counts, nodeId)
+				continue
+			}
+			r.counts[nodeId] = count / 2
+		}
+		r.windowStart = time.Now()
+	}
+
+	selected := nodeIds[0]
+	for _, nodeId := range nodeIds[1:] {
+		if r.counts[nodeId] < r.counts[selected] {
+			selected = nodeId
+		}
+	}
+	r.counts[selected]++
+	return selected
+}
@@ -0,0 +1,71 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAppliedIndexStorage struct {
+	IShardLogStorage
+	appliedIndex uint64
+}
+
+func (t *testAppliedIndexStorage) AppliedIndex(shardNo string) (uint64, error) {
+	return t.appliedIndex, nil
+}
+
+func newTestFollowerReadServer(nodeId uint64, appliedIndex uint64) *Server {
+	opts := NewOptions(WithNodeId(nodeId))
+	opts.MessageLogStorage = &testAppliedIndexStorage{appliedIndex: appliedIndex}
+	return &Server{opts: opts, readLoad: newReadLoadCounter(time.Minute)}
+}
+
+func TestReplicaIdForRead(t *testing.T) {
+	cfg := wkdb.ChannelClusterConfig{ChannelId: "g1", ChannelType: 2, LeaderId: 1, Replicas: []uint64{1, 2, 3}}
+	allOnline := func(nodeId uint64) bool { return true }
+
+	// 当前节点是领导
+	s := newTestFollowerReadServer(1, 0)
+	assert.Equal(t, uint64(1), s.replicaIdForRead(cfg, 10, allOnline))
+
+	// 当前节点是追随者并且数据没有落后，从当前节点读取
+	s = newTestFollowerReadServer(2, 10)
+	assert.Equal(t, uint64(2), s.replicaIdForRead(cfg, 10, allOnline))
+
+	// 当前节点是追随者但数据落后，从领导读取
+	s = newTestFollowerReadServer(2, 5)
+	assert.Equal(t, uint64(1), s.replicaIdForRead(cfg, 10, allOnline))
+
+	// 当前节点不是副本，追随者优先，按负载轮流选择
+	s = newTestFollowerReadServer(4, 0)
+	assert.Equal(t, uint64(2), s.replicaIdForRead(cfg, 10, allOnline))
+	assert.Equal(t, uint64(3), s.replicaIdForRead(cfg, 10, allOnline))
+	assert.Equal(t, uint64(1), s.replicaIdForRead(cfg, 10, allOnline))
+
+	// 离线的追随者不选择
+	s = newTestFollowerReadServer(4, 0)
+	online := func(nodeId uint64) bool { return nodeId != 2 }
+	assert.Equal(t, uint64(3), s.replicaIdForRead(cfg, 10, online))
+	assert.Equal(t, uint64(1), s.replicaIdForRead(cfg, 10, online))
+}
+
+func TestReadLoadCounterLeastLoaded(t *testing.T) {
+	r := newReadLoadCounter(time.Minute)
+	assert.Equal(t, uint64(0), r.leastLoaded(nil))
+
+	// 负载相同时按候选顺序
+	assert.Equal(t, uint64(2), r.leastLoaded([]uint64{2, 3}))
+	assert.Equal(t, uint64(3), r.leastLoaded([]uint64{2, 3}))
+	assert.Equal(t, uint64(2), r.leastLoaded([]uint64{2, 3}))
+	assert.Equal(t, int64(2), r.counts[2])
+	assert.Equal(t, int64(1), r.counts[3])
+
+	// 窗口结束后负载减半（2的负载减为1，3的负载减为0）
+	r.windowStart = time.Now().Add(-time.Minute * 2)
+	assert.Equal(t, uint64(3), r.leastLoaded([]uint64{2, 3}))
+	assert.Equal(t, int64(1), r.counts[2])
+	assert.Equal(t, int64(1), r.counts[3])
+}
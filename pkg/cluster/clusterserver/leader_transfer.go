@@ -0,0 +1,99 @@
+package cluster
+
+import (
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"go.uber.org/zap"
+)
+
+// TransferableLeaderCount 本节点当前领导的、有其他在线副本可以接任的槽数量和活跃频道数量
+func (s *Server) TransferableLeaderCount() (slotCount int, channelCount int) {
+	for _, slot := range s.clusterEventServer.Slots() {
+		if slot.Leader == s.opts.NodeId && s.hasOtherOnlineReplica(slot.Replicas) {
+			slotCount++
+		}
+	}
+	s.channelManager.channelReactor.IteratorHandler(func(h reactor.IHandler) bool {
+		ch := h.(*channel)
+		if ch.isLeader() && s.hasOtherOnlineReplica(ch.cfg.Replicas) {
+			channelCount++
+		}
+		return true
+	})
+	return slotCount, channelCount
+}
+
+func (s *Server) hasOtherOnlineReplica(replicas []uint64) bool {
+	for _, replicaId := range replicas {
+		if replicaId != s.opts.NodeId && s.NodeIsOnline(replicaId) {
+			return true
+		}
+	}
+	return false
+}
+
+// TransferAllLeaders 将本节点领导的槽和活跃频道转移给其他在线的副本（节点下线前调用）
+// 目标节点优先选择本轮已分配最少的副本，避免领导全部集中到同一个节点，返回发起转移的槽数量和频道数量
+func (s *Server) TransferAllLeaders() (slotCount int, channelCount int) {
+	assigned := make(map[uint64]int)
+	pickTarget := func(replicas []uint64) uint64 {
+		var to uint64
+		for _, replicaId := range replicas {
+			if replicaId == s.opts.NodeId || !s.NodeIsOnline(replicaId) {
+				continue
+			}
+			if to == 0 || assigned[replicaId] < assigned[to] {
+				to = replicaId
+			}
+		}
+		if to != 0 {
+			assigned[to]++
+		}
+		return to
+	}
+
+	// 槽领导
+	for _, slot := range s.clusterEventServer.Slots() {
+		if slot.Leader != s.opts.NodeId || slot.MigrateFrom != 0 || slot.MigrateTo != 0 {
+			continue
+		}
+		to := pickTarget(slot.Replicas)
+		if to == 0 {
+			s.Warn("transfer slot leader: no online replica", zap.Uint32("slotId", slot.Id))
+			continue
+		}
+		err := s.clusterEventServer.ProposeMigrateSlot(slot.Id, s.opts.NodeId, to)
+		if err != nil {
+			s.Warn("transfer slot leader failed", zap.Error(err), zap.Uint32("slotId", slot.Id), zap.Uint64("to", to))
+			continue
+		}
+		slotCount++
+	}
+
+	// 频道领导
+	cfgs := make([]wkdb.ChannelClusterConfig, 0)
+	s.channelManager.channelReactor.IteratorHandler(func(h reactor.IHandler) bool {
+		ch := h.(*channel)
+		if ch.isLeader() {
+			cfgs = append(cfgs, ch.cfg)
+		}
+		return true
+	})
+	for _, cfg := range cfgs {
+		if cfg.MigrateFrom != 0 || cfg.MigrateTo != 0 {
+			continue
+		}
+		to := pickTarget(cfg.Replicas)
+		if to == 0 {
+			s.Warn("transfer channel leader: no online replica", zap.String("channelId", cfg.ChannelId), zap.Uint8("channelType", cfg.ChannelType))
+			continue
+		}
+		err := s.transferChannelLeader(cfg, to)
+		if err != nil {
+			s.Warn("transfer channel leader failed", zap.Error(err), zap.String("channelId", cfg.ChannelId), zap.Uint8("channelType", cfg.ChannelType), zap.Uint64("to", to))
+			continue
+		}
+		channelCount++
+	}
+	return slotCount, channelCount
+}
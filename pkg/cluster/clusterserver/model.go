@@ -649,6 +649,8 @@ type ClusterJoinReq struct {
 	NodeId     uint64
 	ServerAddr string
 	Role       pb.NodeRole
+	Zone       string // 节点所在可用区
+	Rack       string // 节点所在机架
 }
 
 func (c *ClusterJoinReq) Marshal() ([]byte, error) {
@@ -657,6 +659,8 @@ func (c *ClusterJoinReq) Marshal() ([]byte, error) {
 	enc.WriteUint64(c.NodeId)
 	enc.WriteString(c.ServerAddr)
 	enc.WriteUint32(uint32(c.Role))
+	enc.WriteString(c.Zone)
+	enc.WriteString(c.Rack)
 	return enc.Bytes(), nil
 
 }
@@ -675,6 +679,15 @@ func (c *ClusterJoinReq) Unmarshal(data []byte) error {
 		return err
 	}
 	c.Role = pb.NodeRole(role)
+	if dec.Len() == 0 { // 兼容旧版本
+		return nil
+	}
+	if c.Zone, err = dec.String(); err != nil {
+		return err
+	}
+	if c.Rack, err = dec.String(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1070,27 +1083,30 @@ func newMessageResp(m wkdb.Message) *messageResp {
 }
 
 type NodeConfig struct {
-	Id              uint64         `json:"id"`                          // 节点ID
-	IsLeader        int            `json:"is_leader,omitempty"`         // 是否是leader
-	Role            pb.NodeRole    `json:"role"`                        // 节点角色
-	ClusterAddr     string         `json:"cluster_addr"`                // 集群地址
-	ApiServerAddr   string         `json:"api_server_addr,omitempty"`   // API服务地址
-	Online          int            `json:"online,omitempty"`            // 是否在线
-	OfflineCount    int            `json:"offline_count,omitempty"`     // 下线次数
-	LastOffline     string         `json:"last_offline,omitempty"`      // 最后一次下线时间
-	AllowVote       int            `json:"allow_vote"`                  // 是否允许投票
-	SlotCount       int            `json:"slot_count,omitempty"`        // 槽位数量
-	Term            uint32         `json:"term,omitempty"`              // 任期
-	SlotLeaderCount int            `json:"slot_leader_count,omitempty"` // 槽位领导者数量
-	ExportCount     int            `json:"export_count,omitempty"`      // 迁出槽位数量
-	Exports         []*SlotMigrate `json:"exports,omitempty"`           // 迁移槽位
-	ImportCount     int            `json:"import_count,omitempty"`      // 迁入槽位数量
-	Imports         []*SlotMigrate `json:"imports,omitempty"`           // 迁入槽位
-	Uptime          string         `json:"uptime,omitempty"`            // 运行时间
-	AppVersion      string         `json:"app_version,omitempty"`       // 应用版本
-	ConfigVersion   uint64         `json:"config_version,omitempty"`    // 配置版本
-	Status          pb.NodeStatus  `json:"status,omitempty"`            // 状态
-	StatusFormat    string         `json:"status_format,omitempty"`     // 状态格式化
+	Id                 uint64         `json:"id"`                             // 节点ID
+	IsLeader           int            `json:"is_leader,omitempty"`            // 是否是leader
+	Role               pb.NodeRole    `json:"role"`                           // 节点角色
+	ClusterAddr        string         `json:"cluster_addr"`                   // 集群地址
+	ApiServerAddr      string         `json:"api_server_addr,omitempty"`      // API服务地址
+	Online             int            `json:"online,omitempty"`               // 是否在线
+	OfflineCount       int            `json:"offline_count,omitempty"`        // 下线次数
+	LastOffline        string         `json:"last_offline,omitempty"`         // 最后一次下线时间
+	AllowVote          int            `json:"allow_vote"`                     // 是否允许投票
+	SlotCount          int            `json:"slot_count,omitempty"`           // 槽位数量
+	Term               uint32         `json:"term,omitempty"`                 // 任期
+	SlotLeaderCount    int            `json:"slot_leader_count,omitempty"`    // 槽位领导者数量
+	ExportCount        int            `json:"export_count,omitempty"`         // 迁出槽位数量
+	Exports            []*SlotMigrate `json:"exports,omitempty"`              // 迁移槽位
+	ImportCount        int            `json:"import_count,omitempty"`         // 迁入槽位数量
+	Imports            []*SlotMigrate `json:"imports,omitempty"`              // 迁入槽位
+	Uptime             string         `json:"uptime,omitempty"`               // 运行时间
+	AppVersion         string         `json:"app_version,omitempty"`          // 应用版本
+	ConfigVersion      uint64         `json:"config_version,omitempty"`       // 配置版本
+	Status             pb.NodeStatus  `json:"status,omitempty"`               // 状态
+	StatusFormat       string         `json:"status_format,omitempty"`        // 状态格式化
+	Zone               string         `json:"zone,omitempty"`                 // 可用区
+	Rack               string         `json:"rack,omitempty"`                 // 机架
+	ChannelLeaderCount int            `json:"channel_leader_count,omitempty"` // 领导的活跃频道数量
 }
 
 func NewNodeConfigFromNode(n *pb.Node) *NodeConfig {
@@ -1119,6 +1135,8 @@ func NewNodeConfigFromNode(n *pb.Node) *NodeConfig {
 		AllowVote:     wkutil.BoolToInt(n.AllowVote),
 		Status:        n.Status,
 		StatusFormat:  status,
+		Zone:          n.Zone,
+		Rack:          n.Rack,
 	}
 }
 
@@ -1468,3 +1486,37 @@ type LogRespTotal struct {
 	More    int        `json:"more"`    // 是否有更多
 	Logs    []*LogResp `json:"logs"`    // 日志信息
 }
+
+// PlacementViolation 违反可用区放置策略的槽或频道
+type PlacementViolation struct {
+	SlotId      uint32         `json:"slot_id"`                // 槽id（频道则为频道所属的槽）
+	ChannelId   string         `json:"channel_id,omitempty"`   // 频道id
+	ChannelType uint8          `json:"channel_type,omitempty"` // 频道类型
+	Replicas    []uint64       `json:"replicas"`               // 副本节点
+	Zones       map[string]int `json:"zones"`                  // 每个可用区的副本数量
+}
+
+type PlacementViolationResp struct {
+	Zones    []string              `json:"zones"`    // 集群的可用区
+	Slots    []*PlacementViolation `json:"slots"`    // 违反策略的槽
+	Channels []*PlacementViolation `json:"channels"` // 违反策略的频道（当前节点为槽领导的频道）
+}
+
+// ChannelLeaderTransfer 频道领导转移
+type ChannelLeaderTransfer struct {
+	ChannelId   string  `json:"channel_id"`   // 频道id
+	ChannelType uint8   `json:"channel_type"` // 频道类型
+	From        uint64  `json:"from"`         // 原领导节点
+	To          uint64  `json:"to"`           // 新领导节点
+	MsgRate     float64 `json:"msg_rate"`     // 频道消息速率（条/秒）
+	DryRun      int     `json:"dry_run,omitempty"`
+}
+
+// ChannelLeaderBalanceResp 频道领导均衡计划
+type ChannelLeaderBalanceResp struct {
+	NodeId           uint64                   `json:"node_id"`            // 节点id
+	DryRun           int                      `json:"dry_run"`            // 是否只输出计划不执行
+	AvgLeaderCount   float64                  `json:"avg_leader_count"`   // 每个节点平均领导的频道数量
+	NodeLeaderCounts map[uint64]int           `json:"node_leader_counts"` // 每个节点领导的活跃频道数量
+	Transfers        []*ChannelLeaderTransfer `json:"transfers"`          // 领导转移计划
+}
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"time"
@@ -231,6 +232,20 @@ func (n *node) requestSlotLogInfo(ctx context.Context, req *SlotLogInfoReq) (*Sl
 
 }
 
+func (n *node) requestChannelLeaderCount(ctx context.Context) (uint32, error) {
+	resp, err := n.client.RequestWithContext(ctx, "/node/channelLeaderCount", nil)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Status != proto.Status_OK {
+		return 0, fmt.Errorf("requestChannelLeaderCount is failed, status:%d", resp.Status)
+	}
+	if len(resp.Body) < 4 {
+		return 0, fmt.Errorf("requestChannelLeaderCount: invalid response")
+	}
+	return binary.BigEndian.Uint32(resp.Body), nil
+}
+
 func (n *node) requestSlotPropose(ctx context.Context, req *SlotProposeReq) (*SlotProposeResp, error) {
 	data, err := req.Marshal()
 	if err != nil {
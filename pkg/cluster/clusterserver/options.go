@@ -6,21 +6,29 @@ import (
 
 	"github.com/WuKongIM/WuKongIM/pkg/auth"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterevent"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/replica"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"go.uber.org/zap/zapcore"
 )
 
+// NodeLocation 节点所在的可用区和机架
+type NodeLocation = clusterevent.NodeLocation
+
 type Options struct {
 	NodeId        uint64
 	Role          pb.NodeRole // 节点角色
 	Addr          string      // 分布式监听地址
 	ServerAddr    string      // 分布式可访问地址
 	ApiServerAddr string      // api服务地址
+	Zone          string      // 节点所在可用区
+	Rack          string      // 节点所在机架
 	AppVersion    string      // 当前应用版本
 	// InitNodes 集群初始节点，key为节点id，value为节点内网通信地址
 	InitNodes map[uint64]string
+	// InitNodeLocations 集群初始节点的可用区和机架，key为节点id
+	InitNodeLocations map[uint64]NodeLocation
 	// SlotCount 槽位数量
 	SlotCount uint32
 	// SlotMaxReplicaCount 每个槽位最大副本数量
@@ -78,6 +86,26 @@ type Options struct {
 	// LearnerMinLogGap  学习者最小日志差距（ 当日志差距小于这个值时，可以认为已学习达到要求）
 	LearnerMinLogGap uint64
 
+	// ChannelRepairInterval 频道副本修复的间隔（槽领导定期为副本不足的频道补充副本），为0表示不修复
+	ChannelRepairInterval time.Duration
+	// ChannelRepairMaxPerRound 每轮最多修复的频道数量
+	ChannelRepairMaxPerRound int
+	// ChannelRepairDeadReplicaTimeout 副本所在节点离线超过这个时间则认为副本永久失效，会从频道副本中移除
+	ChannelRepairDeadReplicaTimeout time.Duration
+	// ChannelRepairLearnerTimeout 修复添加的学习者超过这个时间还没转为追随者，则取消本次修复
+	ChannelRepairLearnerTimeout time.Duration
+
+	// ChannelLeaderBalanceInterval 频道领导均衡的间隔，为0表示不均衡
+	ChannelLeaderBalanceInterval time.Duration
+	// ChannelLeaderBalanceThreshold 节点领导的频道数量超过平均值的比例达到这个值才开始转移领导（例如0.2表示超过平均值20%）
+	ChannelLeaderBalanceThreshold float64
+	// ChannelLeaderBalanceMaxPerRound 每轮最多转移领导的频道数量
+	ChannelLeaderBalanceMaxPerRound int
+	// ChannelLeaderBalanceCooldown 频道配置变更后，在这个时间内不转移领导
+	ChannelLeaderBalanceCooldown time.Duration
+	// ChannelLeaderBalanceDryRun 只输出均衡计划，不执行领导转移
+	ChannelLeaderBalanceDryRun bool
+
 	DB wkdb.DB
 
 	SlotDbShardNum int // 槽位数据库分片数量
@@ -118,6 +146,16 @@ func NewOptions(opt ...Option) *Options {
 		LearnerMinLogGap:           100,
 		PageSize:                   20,
 
+		ChannelRepairInterval:           time.Minute,
+		ChannelRepairMaxPerRound:        50,
+		ChannelRepairDeadReplicaTimeout: 30 * time.Minute,
+		ChannelRepairLearnerTimeout:     10 * time.Minute,
+
+		ChannelLeaderBalanceInterval:    5 * time.Minute,
+		ChannelLeaderBalanceThreshold:   0.2,
+		ChannelLeaderBalanceMaxPerRound: 10,
+		ChannelLeaderBalanceCooldown:    10 * time.Minute,
+
 		TickInterval:          150 * time.Millisecond,
 		HeartbeatIntervalTick: 1,
 		ElectionIntervalTick:  10,
@@ -305,6 +343,24 @@ func WithApiServerAddr(addr string) Option {
 	}
 }
 
+func WithInitNodeLocations(initNodeLocations map[uint64]NodeLocation) Option {
+	return func(o *Options) {
+		o.InitNodeLocations = initNodeLocations
+	}
+}
+
+func WithZone(zone string) Option {
+	return func(o *Options) {
+		o.Zone = zone
+	}
+}
+
+func WithRack(rack string) Option {
+	return func(o *Options) {
+		o.Rack = rack
+	}
+}
+
 func WithLogLevel(level zapcore.Level) Option {
 	return func(o *Options) {
 		o.LogLevel = level
@@ -378,6 +434,60 @@ func WithLearnerMinLogGap(gap uint64) Option {
 	}
 }
 
+func WithChannelRepairInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.ChannelRepairInterval = interval
+	}
+}
+
+func WithChannelRepairMaxPerRound(max int) Option {
+	return func(o *Options) {
+		o.ChannelRepairMaxPerRound = max
+	}
+}
+
+func WithChannelRepairDeadReplicaTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.ChannelRepairDeadReplicaTimeout = timeout
+	}
+}
+
+func WithChannelRepairLearnerTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.ChannelRepairLearnerTimeout = timeout
+	}
+}
+
+func WithChannelLeaderBalanceInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.ChannelLeaderBalanceInterval = interval
+	}
+}
+
+func WithChannelLeaderBalanceThreshold(threshold float64) Option {
+	return func(o *Options) {
+		o.ChannelLeaderBalanceThreshold = threshold
+	}
+}
+
+func WithChannelLeaderBalanceMaxPerRound(max int) Option {
+	return func(o *Options) {
+		o.ChannelLeaderBalanceMaxPerRound = max
+	}
+}
+
+func WithChannelLeaderBalanceCooldown(cooldown time.Duration) Option {
+	return func(o *Options) {
+		o.ChannelLeaderBalanceCooldown = cooldown
+	}
+}
+
+func WithChannelLeaderBalanceDryRun(dryRun bool) Option {
+	return func(o *Options) {
+		o.ChannelLeaderBalanceDryRun = dryRun
+	}
+}
+
 func WithPongMaxTick(tick int) Option {
 	return func(o *Options) {
 		o.PongMaxTick = tick
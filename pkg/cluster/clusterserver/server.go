@@ -60,6 +60,10 @@ type Server struct {
 	stopper *syncutil.Stopper
 
 	clusterCfgCache *lru.Cache[string, wkdb.ChannelClusterConfig]
+
+	channelRepair         *channelRepair         // 频道副本修复
+	channelLeaderBalancer *channelLeaderBalancer // 频道领导均衡
+	readLoad              *readLoadCounter       // 追随者读的副本负载统计
 }
 
 func New(opts *Options) *Server {
@@ -80,6 +84,9 @@ func New(opts *Options) *Server {
 
 	s.slotManager = newSlotManager(s)
 	s.channelManager = newChannelManager(s)
+	s.channelRepair = newChannelRepair(s)
+	s.channelLeaderBalancer = newChannelLeaderBalancer(s)
+	s.readLoad = newReadLoadCounter(time.Second * 10)
 
 	if opts.SlotLogStorage == nil {
 		s.slotStorage = NewPebbleShardLogStorage(path.Join(opts.DataDir, "logdb"), uint32(opts.SlotDbShardNum))
@@ -115,6 +122,7 @@ func New(opts *Options) *Server {
 	s.clusterEventServer = clusterevent.New(clusterevent.NewOptions(
 		clusterevent.WithNodeId(opts.NodeId),
 		clusterevent.WithInitNodes(initNodes),
+		clusterevent.WithInitNodeLocations(opts.InitNodeLocations),
 		clusterevent.WithSeed(s.opts.Seed),
 		clusterevent.WithSlotCount(opts.SlotCount),
 		clusterevent.WithSlotMaxReplicaCount(opts.SlotMaxReplicaCount),
@@ -124,6 +132,8 @@ func New(opts *Options) *Server {
 		clusterevent.WithSend(s.onSend),
 		clusterevent.WithConfigDir(cfgDir),
 		clusterevent.WithApiServerAddr(opts.ApiServerAddr),
+		clusterevent.WithZone(opts.Zone),
+		clusterevent.WithRack(opts.Rack),
 		clusterevent.WithCluster(s),
 		clusterevent.WithElectionIntervalTick(opts.ElectionIntervalTick),
 		clusterevent.WithHeartbeatIntervalTick(opts.HeartbeatIntervalTick),
@@ -233,6 +243,11 @@ func (s *Server) Start() error {
 		s.stopper.RunWorker(s.joinLoop)
 	}
 
+	// 频道副本修复
+	s.stopper.RunWorker(s.channelRepair.loop)
+	// 频道领导均衡
+	s.stopper.RunWorker(s.channelLeaderBalancer.loop)
+
 	return nil
 }
 
@@ -521,6 +536,8 @@ func (s *Server) joinLoop() {
 		NodeId:     s.opts.NodeId,
 		ServerAddr: s.opts.ServerAddr,
 		Role:       s.opts.Role,
+		Zone:       s.opts.Zone,
+		Rack:       s.opts.Rack,
 	}
 	for {
 		select {
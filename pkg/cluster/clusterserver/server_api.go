@@ -14,6 +14,7 @@ import (
 
 	"github.com/WuKongIM/WuKongIM/pkg/auth"
 	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterevent"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/replica"
 	"github.com/WuKongIM/WuKongIM/pkg/network"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
@@ -32,30 +33,35 @@ func (s *Server) ServerAPI(route *wkhttp.WKHttp, prefix string) {
 	route.GET(s.formatPath("/nodes/:id/channels"), s.nodeChannelsGet) // 获取节点的所有频道信息
 
 	// route.GET(s.formatPath("/channels/:channel_id/:channel_type/config"), s.channelClusterConfigGet) // 获取频道分布式配置
-	route.GET(s.formatPath("/slots"), s.slotsGet)                                                      // 获取指定的槽信息
-	route.GET(s.formatPath("/allslot"), s.allSlotsGet)                                                 // 获取所有槽信息
-	route.GET(s.formatPath("/slots/:id/config"), s.slotClusterConfigGet)                               // 槽分布式配置
-	route.GET(s.formatPath("/slots/:id/channels"), s.slotChannelsGet)                                  // 获取某个槽的所有频道信息
-	route.POST(s.formatPath("/slots/:id/migrate"), s.slotMigrate)                                      // 迁移槽
-	route.GET(s.formatPath("/info"), s.clusterInfoGet)                                                 // 获取集群信息
-	route.GET(s.formatPath("/messages"), s.messageSearch)                                              // 搜索消息
-	route.GET(s.formatPath("/channels"), s.channelSearch)                                              // 频道搜索
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/subscribers"), s.subscribersGet)       // 获取频道的订阅者列表
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/denylist"), s.denylistGet)             // 获取黑名单列表
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/allowlist"), s.allowlistGet)           // 获取白名单列表
-	route.GET(s.formatPath("/users"), s.userSearch)                                                    // 用户搜索
-	route.GET(s.formatPath("/devices"), s.deviceSearch)                                                // 设备搜索
-	route.GET(s.formatPath("/conversations"), s.conversationSearch)                                    // 搜索最近会话消息
-	route.POST(s.formatPath("/channels/:channel_id/:channel_type/migrate"), s.channelMigrate)          // 迁移频道
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/config"), s.channelClusterConfig)      // 获取频道的分布式配置
-	route.POST(s.formatPath("/channels/:channel_id/:channel_type/start"), s.channelStart)              // 开始频道
-	route.POST(s.formatPath("/channels/:channel_id/:channel_type/stop"), s.channelStop)                // 停止频道
-	route.POST(s.formatPath("/channel/status"), s.channelStatus)                                       // 获取频道状态
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/replicas"), s.channelReplicas)         // 获取频道副本信息
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/localReplica"), s.channelLocalReplica) // 获取频道在本节点的副本信息
+	route.GET(s.formatPath("/slots"), s.slotsGet)                                                            // 获取指定的槽信息
+	route.GET(s.formatPath("/allslot"), s.allSlotsGet)                                                       // 获取所有槽信息
+	route.GET(s.formatPath("/slots/:id/config"), s.slotClusterConfigGet)                                     // 槽分布式配置
+	route.GET(s.formatPath("/slots/:id/channels"), s.slotChannelsGet)                                        // 获取某个槽的所有频道信息
+	route.POST(s.formatPath("/slots/:id/migrate"), s.slotMigrate)                                            // 迁移槽
+	route.GET(s.formatPath("/info"), s.clusterInfoGet)                                                       // 获取集群信息
+	route.GET(s.formatPath("/messages"), s.messageSearch)                                                    // 搜索消息
+	route.GET(s.formatPath("/channels"), s.channelSearch)                                                    // 频道搜索
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/subscribers"), s.subscribersGet)             // 获取频道的订阅者列表
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/denylist"), s.denylistGet)                   // 获取黑名单列表
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/allowlist"), s.allowlistGet)                 // 获取白名单列表
+	route.GET(s.formatPath("/users"), s.userSearch)                                                          // 用户搜索
+	route.GET(s.formatPath("/devices"), s.deviceSearch)                                                      // 设备搜索
+	route.GET(s.formatPath("/conversations"), s.conversationSearch)                                          // 搜索最近会话消息
+	route.POST(s.formatPath("/channels/:channel_id/:channel_type/migrate"), s.channelMigrate)                // 迁移频道
+	route.POST(s.formatPath("/channels/:channel_id/:channel_type/transfer_leader"), s.channelTransferLeader) // 转移频道领导
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/config"), s.channelClusterConfig)            // 获取频道的分布式配置
+	route.POST(s.formatPath("/channels/:channel_id/:channel_type/start"), s.channelStart)                    // 开始频道
+	route.POST(s.formatPath("/channels/:channel_id/:channel_type/stop"), s.channelStop)                      // 停止频道
+	route.POST(s.formatPath("/channel/status"), s.channelStatus)                                             // 获取频道状态
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/replicas"), s.channelReplicas)               // 获取频道副本信息
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/localReplica"), s.channelLocalReplica)       // 获取频道在本节点的副本信息
 
 	route.GET(s.formatPath("/logs"), s.clusterLogs) // 获取节点日志
 
+	route.GET(s.formatPath("/placement/violations"), s.placementViolationsGet) // 获取违反可用区放置策略的槽和频道
+
+	route.GET(s.formatPath("/channelLeaderBalance"), s.channelLeaderBalanceGet) // 获取节点的频道领导均衡计划（不执行）
+
 }
 
 func (s *Server) nodesGet(c *wkhttp.Context) {
@@ -2036,7 +2042,16 @@ func (s *Server) channelReplicas(c *wkhttp.Context) {
 	for _, replicaId := range replicaIds {
 		replicaId := replicaId
 
-		if !s.NodeIsOnline(replicaId) {
+		if !s.NodeIsOnline(replicaId) { // 离线的副本不请求，只展示状态
+			replicas = append(replicas, &channelReplicaDetailResp{
+				channelReplicaResp: channelReplicaResp{
+					ReplicaId: replicaId,
+				},
+				Role:       s.getReplicaRole(channelClusterConfig, replicaId),
+				RoleFormat: s.getReplicaRoleFormat(channelClusterConfig, replicaId),
+				Dead:       wkutil.BoolToInt(s.channelRepair.isDeadNode(s.clusterEventServer.Node(replicaId), time.Now())),
+				Repairing:  wkutil.BoolToInt(s.channelRepair.isRepairing(channelClusterConfig) && channelClusterConfig.MigrateTo == replicaId),
+			})
 			continue
 		}
 
@@ -2063,6 +2078,8 @@ func (s *Server) channelReplicas(c *wkhttp.Context) {
 				Role:               s.getReplicaRole(channelClusterConfig, replicaId),
 				RoleFormat:         s.getReplicaRoleFormat(channelClusterConfig, replicaId),
 				LastMsgTimeFormat:  lastMsgTimeFormat,
+				Online:             1,
+				Repairing:          wkutil.BoolToInt(s.channelRepair.isRepairing(channelClusterConfig) && channelClusterConfig.MigrateTo == replicaId),
 			})
 			continue
 
@@ -2084,6 +2101,8 @@ func (s *Server) channelReplicas(c *wkhttp.Context) {
 				Role:               s.getReplicaRole(channelClusterConfig, replicaId),
 				RoleFormat:         s.getReplicaRoleFormat(channelClusterConfig, replicaId),
 				LastMsgTimeFormat:  lastMsgTimeFormat,
+				Online:             1,
+				Repairing:          wkutil.BoolToInt(s.channelRepair.isRepairing(channelClusterConfig) && channelClusterConfig.MigrateTo == replicaId),
 			})
 			return nil
 		})
@@ -2193,6 +2212,9 @@ type channelReplicaDetailResp struct {
 	Role              int    `json:"role"`                 // 角色
 	RoleFormat        string `json:"role_format"`          // 角色格式化
 	LastMsgTimeFormat string `json:"last_msg_time_format"` // 最新消息时间格式化
+	Online            int    `json:"online"`               // 副本节点是否在线
+	Dead              int    `json:"dead,omitempty"`       // 副本是否已永久失效（等待修复移除）
+	Repairing         int    `json:"repairing,omitempty"`  // 是否是修复中的学习者
 }
 
 func (s *Server) clusterLogs(c *wkhttp.Context) {
@@ -2317,3 +2339,158 @@ func (s *Server) clusterLogs(c *wkhttp.Context) {
 		Logs:    resps,
 	})
 }
+
+// placementViolationsGet 获取违反可用区放置策略（任意一个可用区宕机会失去法定副本数）的槽和频道
+// 频道只统计node_id节点（默认当前节点）为槽领导的频道
+func (s *Server) placementViolationsGet(c *wkhttp.Context) {
+	nodeId := wkutil.ParseUint64(c.Query("node_id"))
+	limit := wkutil.ParseInt(c.Query("limit"))
+	if limit <= 0 {
+		limit = s.opts.PageSize
+	}
+	if nodeId == 0 {
+		nodeId = s.opts.NodeId
+	}
+
+	if nodeId != s.opts.NodeId {
+		node := s.clusterEventServer.Node(nodeId)
+		if node == nil {
+			s.Error("node not found", zap.Uint64("nodeId", nodeId))
+			c.ResponseError(errors.New("node not found"))
+			return
+		}
+		c.Forward(fmt.Sprintf("%s%s", node.ApiServerAddr, c.Request.URL.Path))
+		return
+	}
+
+	cfg := s.clusterEventServer.Config()
+
+	resp := &PlacementViolationResp{
+		Zones:    make([]string, 0),
+		Slots:    make([]*PlacementViolation, 0),
+		Channels: make([]*PlacementViolation, 0),
+	}
+	for _, node := range cfg.Nodes {
+		if node.Zone != "" && !wkutil.ArrayContains(resp.Zones, node.Zone) {
+			resp.Zones = append(resp.Zones, node.Zone)
+		}
+	}
+	sort.Strings(resp.Zones)
+
+	for _, slot := range cfg.Slots {
+		if !clusterevent.ViolatesZonePolicy(cfg.Nodes, slot.Replicas) {
+			continue
+		}
+		resp.Slots = append(resp.Slots, &PlacementViolation{
+			SlotId:   slot.Id,
+			Replicas: slot.Replicas,
+			Zones:    clusterevent.ZoneReplicaCount(cfg.Nodes, slot.Replicas),
+		})
+	}
+
+	channelClusterConfigs, err := s.opts.DB.SearchChannelClusterConfig(wkdb.ChannelClusterConfigSearchReq{
+		Limit: limit,
+	}, func(channelCfg wkdb.ChannelClusterConfig) bool {
+		slot := s.clusterEventServer.Slot(s.getSlotId(channelCfg.ChannelId))
+		if slot == nil || slot.Leader != s.opts.NodeId {
+			return false
+		}
+		return clusterevent.ViolatesZonePolicy(cfg.Nodes, channelCfg.Replicas)
+	})
+	if err != nil {
+		s.Error("SearchChannelClusterConfig error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	for _, channelCfg := range channelClusterConfigs {
+		resp.Channels = append(resp.Channels, &PlacementViolation{
+			SlotId:      s.getSlotId(channelCfg.ChannelId),
+			ChannelId:   channelCfg.ChannelId,
+			ChannelType: channelCfg.ChannelType,
+			Replicas:    channelCfg.Replicas,
+			Zones:       clusterevent.ZoneReplicaCount(cfg.Nodes, channelCfg.Replicas),
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) channelTransferLeader(c *wkhttp.Context) {
+	var req struct {
+		To     uint64 `json:"to"`      // 新领导节点
+		DryRun int    `json:"dry_run"` // 只检查不执行
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		s.Error("BindJSON error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+
+	channelId := c.Param("channel_id")
+	channelType := wkutil.ParseUint8(c.Param("channel_type"))
+
+	// 获取频道所属槽领导的id
+	nodeId, err := s.SlotLeaderIdOfChannel(channelId, channelType)
+	if err != nil {
+		s.Error("channelTransferLeader: LeaderIdOfChannel error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if nodeId != s.opts.NodeId {
+		c.ForwardWithBody(fmt.Sprintf("%s%s", s.clusterEventServer.Node(nodeId).ApiServerAddr, c.Request.URL.Path), bodyBytes)
+		return
+	}
+
+	clusterConfig, err := s.getChannelClusterConfig(channelId, channelType)
+	if err != nil {
+		s.Error("channelTransferLeader: getChannelClusterConfig error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+
+	transfer := &ChannelLeaderTransfer{
+		ChannelId:   channelId,
+		ChannelType: channelType,
+		From:        clusterConfig.LeaderId,
+		To:          req.To,
+		DryRun:      req.DryRun,
+	}
+	if req.DryRun == 1 {
+		err = s.checkChannelLeaderTransfer(clusterConfig, req.To)
+	} else {
+		err = s.transferChannelLeader(clusterConfig, req.To)
+	}
+	if err != nil {
+		s.Error("channelTransferLeader: transfer failed", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType), zap.Uint64("to", req.To))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (s *Server) channelLeaderBalanceGet(c *wkhttp.Context) {
+	nodeId := wkutil.ParseUint64(c.Query("node_id"))
+	if nodeId == 0 {
+		nodeId = s.opts.NodeId
+	}
+
+	if nodeId != s.opts.NodeId {
+		node := s.clusterEventServer.Node(nodeId)
+		if node == nil {
+			s.Error("node not found", zap.Uint64("nodeId", nodeId))
+			c.ResponseError(errors.New("node not found"))
+			return
+		}
+		c.Forward(fmt.Sprintf("%s%s", node.ApiServerAddr, c.Request.URL.Path))
+		return
+	}
+
+	resp, err := s.channelLeaderBalancer.plan()
+	if err != nil {
+		s.Error("channelLeaderBalanceGet: plan failed", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -7,9 +7,11 @@ import (
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterevent"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/icluster"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/replica"
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
@@ -143,9 +145,16 @@ func (s *Server) ProposeChannelMessages(ctx context.Context, channelId string, c
 		return nil, ErrStopped
 	}
 
+	ctx, span := trace.GlobalTrace.StartSpan(ctx, "proposeChannelMessages")
+	span.SetString("channelId", channelId)
+	span.SetUint8("channelType", channelType)
+	span.SetInt("logCount", len(logs))
+	defer span.End()
+
 	// 加载或创建频道
 	ch, err := s.loadOrCreateChannel(ctx, channelId, channelType)
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
@@ -153,14 +162,17 @@ func (s *Server) ProposeChannelMessages(ctx context.Context, channelId string, c
 		results []reactor.ProposeResult
 	)
 	if !ch.isLeader() { // 如果当前节点不是频道的领导者，向频道的领导者发送提案请求
-		resp, err := s.requestChannelProposeMessage(ch.leaderId(), channelId, channelType, logs)
+		span.SetUint64("leaderId", ch.leaderId())
+		resp, err := s.requestChannelProposeMessage(ctx, ch.leaderId(), channelId, channelType, logs)
 		if err != nil {
+			span.RecordError(err)
 			return nil, err
 		}
 		results = resp.ProposeResults
 	} else { // 如果当前节点是频道的领导者，直接提案
 		results, err = s.channelManager.proposeAndWait(ctx, channelId, channelType, logs)
 		if err != nil {
+			span.RecordError(err)
 			return nil, err
 		}
 	}
@@ -507,26 +519,15 @@ func (s *Server) createChannelClusterConfig(channelId string, channelType uint8)
 		CreatedAt:       &createdAt,
 		UpdatedAt:       &updatedAt,
 	}
-	replicaIds := make([]uint64, 0, s.opts.ChannelMaxReplicaCount)
-	replicaIds = append(replicaIds, s.opts.NodeId) // 默认当前节点是领导，所以加入到副本列表中
-
-	// 随机选择副本
+	// 随机打乱候选节点，防止每次都是相同的节点成为副本
 	newAllowVoteNodes := make([]*pb.Node, 0, len(allowVoteNodes))
 	newAllowVoteNodes = append(newAllowVoteNodes, allowVoteNodes...)
 	rand.Shuffle(len(newAllowVoteNodes), func(i, j int) {
 		newAllowVoteNodes[i], newAllowVoteNodes[j] = newAllowVoteNodes[j], newAllowVoteNodes[i]
 	})
 
-	for _, allowVoteNode := range newAllowVoteNodes {
-		if allowVoteNode.Id == s.opts.NodeId {
-			continue
-		}
-		if len(replicaIds) >= int(s.opts.ChannelMaxReplicaCount) {
-			break
-		}
-		replicaIds = append(replicaIds, allowVoteNode.Id)
-
-	}
+	// 默认当前节点是领导，所以加入到副本列表中，其他副本按可用区分散选择
+	replicaIds := clusterevent.SelectReplicas(newAllowVoteNodes, int(s.opts.ChannelMaxReplicaCount), []uint64{s.opts.NodeId}, nil)
 	clusterConfig.Replicas = replicaIds
 	return clusterConfig, nil
 }
@@ -703,13 +704,13 @@ func (s *Server) loadOnlyChannelClusterConfig(channelId string, channelType uint
 	return clusterConfig, nil
 }
 
-func (s *Server) requestChannelProposeMessage(to uint64, channelId string, channelType uint8, logs []replica.Log) (*ChannelProposeResp, error) {
+func (s *Server) requestChannelProposeMessage(ctx context.Context, to uint64, channelId string, channelType uint8, logs []replica.Log) (*ChannelProposeResp, error) {
 	node := s.nodeManager.node(to)
 	if node == nil {
 		s.Error("node is not found", zap.Uint64("nodeID", to))
 		return nil, ErrNodeNotFound
 	}
-	timeoutCtx, cancel := context.WithTimeout(s.cancelCtx, s.opts.ReqTimeout)
+	timeoutCtx, cancel := context.WithTimeout(trace.ContextWithSpanOf(s.cancelCtx, ctx), s.opts.ReqTimeout)
 	resp, err := node.requestChannelProposeMessage(timeoutCtx, &ChannelProposeReq{
 		ChannelId:   channelId,
 		ChannelType: channelType,
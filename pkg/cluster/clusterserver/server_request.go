@@ -9,6 +9,7 @@ import (
 
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	"go.uber.org/zap"
@@ -34,6 +35,9 @@ func (s *Server) setRoutes() {
 
 	// 获取槽日志信息
 	s.netServer.Route("/slot/logInfo", s.handleSlotLogInfo)
+
+	// 获取节点领导的活跃频道数量
+	s.netServer.Route("/node/channelLeaderCount", s.handleChannelLeaderCount)
 }
 
 func (s *Server) handleChannelLastLogInfo(c *wkserver.Context) {
@@ -181,7 +185,7 @@ func (s *Server) handleProposeMessage(c *wkserver.Context) {
 		c.WriteErr(ErrOldChannelClusterConfig)
 		return
 	}
-	results, err := s.channelManager.proposeAndWait(s.cancelCtx, req.ChannelId, req.ChannelType, req.Logs)
+	results, err := s.channelManager.proposeAndWait(trace.ContextWithSpanOf(s.cancelCtx, c.Context()), req.ChannelId, req.ChannelType, req.Logs)
 	if err != nil {
 		s.Error("proposeAndWait failed", zap.Error(err))
 		c.WriteErr(err)
@@ -293,6 +297,8 @@ func (s *Server) handleClusterJoin(c *wkserver.Context) {
 		AllowVote:   allowVote,
 		CreatedAt:   time.Now().Unix(),
 		Status:      pb.NodeStatus_NodeStatusWillJoin,
+		Zone:        req.Zone,
+		Rack:        req.Rack,
 	})
 	if err != nil {
 		s.Error("proposeJoin failed", zap.Error(err))
@@ -416,3 +422,9 @@ func (s *Server) handleSlotLogInfo(c *wkserver.Context) {
 	}
 	c.Write(data)
 }
+
+func (s *Server) handleChannelLeaderCount(c *wkserver.Context) {
+	resultBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(resultBytes, uint32(s.channelLeaderCount()))
+	c.Write(resultBytes)
+}
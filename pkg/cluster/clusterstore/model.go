@@ -78,6 +78,50 @@ const (
 
 	// 批量更新最近会话
 	CMDBatchUpdateConversation
+
+	// 添加在线状态订阅者
+	CMDAddPresenceSubscribers
+	// 移除在线状态订阅者
+	CMDRemovePresenceSubscribers
+
+	// 添加或更新定时消息
+	CMDAddOrUpdateScheduledMessage
+	// 移除定时消息
+	CMDRemoveScheduledMessages
+
+	// 添加子区回复
+	CMDAddThreadReplies
+
+	// 添加或更新频道置顶消息
+	CMDAddOrUpdateChannelPin
+	// 移除频道置顶消息
+	CMDRemoveChannelPins
+	// 设置频道公告
+	CMDSetChannelAnnouncement
+
+	// 设置最近会话的用户属性
+	CMDSetConversationAttrs
+
+	// 追加变更事件的消息（CDC），只记录频道和消息序号范围，消息内容从频道日志读取
+	CMDAppendCDCMessages
+	// 设置变更事件消费者的偏移量
+	CMDSetCDCConsumerOffset
+	// 移除变更事件消费者
+	CMDRemoveCDCConsumer
+
+	// 添加或更新客服队列
+	CMDAddOrUpdateCustomerServiceQueue
+	// 删除客服队列
+	CMDDeleteCustomerServiceQueue
+	// 添加或更新客服会话
+	CMDAddOrUpdateCustomerServiceSessions
+	// 移除客服会话
+	CMDRemoveCustomerServiceSession
+
+	// 更新用户最后一次上线/离线时间（只写对应的列）
+	CMDUpdateUserLastSeen
+	// 更新用户是否隐藏在线状态（只写对应的列）
+	CMDUpdateUserPresenceHidden
 )
 
 func (c CMDType) Uint16() uint16 {
@@ -150,6 +194,42 @@ func (c CMDType) String() string {
 		return "CMDBatchUpdateConversation"
 	case CMDDeleteConversations:
 		return "CMDDeleteConversations"
+	case CMDAddPresenceSubscribers:
+		return "CMDAddPresenceSubscribers"
+	case CMDRemovePresenceSubscribers:
+		return "CMDRemovePresenceSubscribers"
+	case CMDAddOrUpdateScheduledMessage:
+		return "CMDAddOrUpdateScheduledMessage"
+	case CMDRemoveScheduledMessages:
+		return "CMDRemoveScheduledMessages"
+	case CMDAddThreadReplies:
+		return "CMDAddThreadReplies"
+	case CMDAddOrUpdateChannelPin:
+		return "CMDAddOrUpdateChannelPin"
+	case CMDRemoveChannelPins:
+		return "CMDRemoveChannelPins"
+	case CMDSetChannelAnnouncement:
+		return "CMDSetChannelAnnouncement"
+	case CMDSetConversationAttrs:
+		return "CMDSetConversationAttrs"
+	case CMDAppendCDCMessages:
+		return "CMDAppendCDCMessages"
+	case CMDSetCDCConsumerOffset:
+		return "CMDSetCDCConsumerOffset"
+	case CMDRemoveCDCConsumer:
+		return "CMDRemoveCDCConsumer"
+	case CMDAddOrUpdateCustomerServiceQueue:
+		return "CMDAddOrUpdateCustomerServiceQueue"
+	case CMDDeleteCustomerServiceQueue:
+		return "CMDDeleteCustomerServiceQueue"
+	case CMDAddOrUpdateCustomerServiceSessions:
+		return "CMDAddOrUpdateCustomerServiceSessions"
+	case CMDRemoveCustomerServiceSession:
+		return "CMDRemoveCustomerServiceSession"
+	case CMDUpdateUserLastSeen:
+		return "CMDUpdateUserLastSeen"
+	case CMDUpdateUserPresenceHidden:
+		return "CMDUpdateUserPresenceHidden"
 	default:
 		return fmt.Sprintf("CMDUnknown[%d]", c)
 	}
@@ -434,6 +514,164 @@ func (c *CMD) CMDContent() (string, error) {
 		}
 		return wkutil.ToJSON(channelClusterConfig), nil
 
+	case CMDAddPresenceSubscribers, CMDRemovePresenceSubscribers:
+		uid, subscribers, err := c.DecodeCMDPresenceSubscribers()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"uid":         uid,
+			"subscribers": subscribers,
+		}), nil
+
+	case CMDAddOrUpdateScheduledMessage:
+		m, err := c.DecodeCMDScheduledMessage()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(m), nil
+
+	case CMDRemoveScheduledMessages:
+		channelId, channelType, ids, err := c.DecodeCMDScheduledMessageIds()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"channelId":   channelId,
+			"channelType": channelType,
+			"ids":         ids,
+		}), nil
+
+	case CMDAddThreadReplies:
+		channelId, channelType, rootMessageSeq, replies, err := c.DecodeCMDThreadReplies()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"channelId":      channelId,
+			"channelType":    channelType,
+			"rootMessageSeq": rootMessageSeq,
+			"replies":        replies,
+		}), nil
+
+	case CMDAddOrUpdateChannelPin:
+		pin, err := c.DecodeCMDChannelPin()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(pin), nil
+
+	case CMDRemoveChannelPins:
+		channelId, channelType, messageSeqs, err := c.DecodeCMDRemoveChannelPins()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"channelId":   channelId,
+			"channelType": channelType,
+			"messageSeqs": messageSeqs,
+		}), nil
+
+	case CMDSetChannelAnnouncement:
+		announcement, err := c.DecodeCMDChannelAnnouncement()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(announcement), nil
+
+	case CMDSetConversationAttrs:
+		uid, attrs, err := c.DecodeCMDSetConversationAttrs()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"uid":   uid,
+			"attrs": attrs,
+		}), nil
+
+	case CMDAppendCDCMessages:
+		channelId, channelType, startMessageSeq, endMessageSeq, err := c.DecodeCMDAppendCDCMessages()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"channelId":       channelId,
+			"channelType":     channelType,
+			"startMessageSeq": startMessageSeq,
+			"endMessageSeq":   endMessageSeq,
+		}), nil
+
+	case CMDSetCDCConsumerOffset:
+		consumer, slotId, offset, err := c.DecodeCMDSetCDCConsumerOffset()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"consumer": consumer,
+			"slotId":   slotId,
+			"offset":   offset,
+		}), nil
+
+	case CMDRemoveCDCConsumer:
+		consumer, slotId, err := c.DecodeCMDRemoveCDCConsumer()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"consumer": consumer,
+			"slotId":   slotId,
+		}), nil
+
+	case CMDAddOrUpdateCustomerServiceQueue:
+		queue, err := c.DecodeCMDCustomerServiceQueue()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(queue), nil
+
+	case CMDDeleteCustomerServiceQueue:
+		return wkutil.ToJSON(map[string]interface{}{
+			"queueId": string(c.Data),
+		}), nil
+
+	case CMDAddOrUpdateCustomerServiceSessions:
+		sessions, err := c.DecodeCMDCustomerServiceSessions()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(sessions), nil
+
+	case CMDRemoveCustomerServiceSession:
+		queueId, channelId, err := c.DecodeCMDRemoveCustomerServiceSession()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"queueId":   queueId,
+			"channelId": channelId,
+		}), nil
+
+	case CMDUpdateUserLastSeen:
+		uid, online, at, err := c.DecodeCMDUserLastSeen()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"uid":    uid,
+			"online": online,
+			"at":     at,
+		}), nil
+
+	case CMDUpdateUserPresenceHidden:
+		uid, hidden, err := c.DecodeCMDUserPresenceHidden()
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"uid":    uid,
+			"hidden": hidden,
+		}), nil
+
 	}
 
 	return "", nil
@@ -559,6 +797,9 @@ func EncodeCMDUser(u wkdb.User) []byte {
 	} else {
 		enc.WriteUint64(0)
 	}
+	enc.WriteUint64(u.LastOnlineAt)
+	enc.WriteUint64(u.LastOfflineAt)
+	enc.WriteUint8(wkutil.BoolToUint8(u.PresenceHidden))
 	return enc.Bytes()
 }
 
@@ -589,6 +830,22 @@ func (c *CMD) DecodeCMDUser() (u wkdb.User, err error) {
 		u.UpdatedAt = &ct
 	}
 
+	// 兼容旧版本的数据（旧版本没有在线状态相关字段）
+	if decoder.Len() == 0 {
+		return
+	}
+	if u.LastOnlineAt, err = decoder.Uint64(); err != nil {
+		return
+	}
+	if u.LastOfflineAt, err = decoder.Uint64(); err != nil {
+		return
+	}
+	var presenceHidden uint8
+	if presenceHidden, err = decoder.Uint8(); err != nil {
+		return
+	}
+	u.PresenceHidden = wkutil.Uint8ToBool(presenceHidden)
+
 	return
 }
 
@@ -1207,4 +1464,390 @@ func (c *CMD) DecodeCMDSystemUIDs() (uids []string, err error) {
 	return
 }
 
+func EncodeCMDPresenceSubscribers(uid string, subscribers []string) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(uid)
+	encoder.WriteUint32(uint32(len(subscribers)))
+	for _, subscriber := range subscribers {
+		encoder.WriteString(subscriber)
+	}
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDPresenceSubscribers() (uid string, subscribers []string, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if uid, err = decoder.String(); err != nil {
+		return
+	}
+	var count uint32
+	if count, err = decoder.Uint32(); err != nil {
+		return
+	}
+	for i := uint32(0); i < count; i++ {
+		var subscriber string
+		if subscriber, err = decoder.String(); err != nil {
+			return
+		}
+		subscribers = append(subscribers, subscriber)
+	}
+	return
+}
+
+func EncodeCMDScheduledMessage(m wkdb.ScheduledMessage) ([]byte, error) {
+	return m.Marshal()
+}
+
+func (c *CMD) DecodeCMDScheduledMessage() (wkdb.ScheduledMessage, error) {
+	var m wkdb.ScheduledMessage
+	err := m.Unmarshal(c.Data)
+	return m, err
+}
+
+func EncodeCMDScheduledMessageIds(channelId string, channelType uint8, ids []uint64) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteUint32(uint32(len(ids)))
+	for _, id := range ids {
+		encoder.WriteUint64(id)
+	}
+	encoder.WriteString(channelId)
+	encoder.WriteUint8(channelType)
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDScheduledMessageIds() (channelId string, channelType uint8, ids []uint64, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	var count uint32
+	if count, err = decoder.Uint32(); err != nil {
+		return
+	}
+	for i := uint32(0); i < count; i++ {
+		var id uint64
+		if id, err = decoder.Uint64(); err != nil {
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	// 兼容旧版本数据（旧版本没有频道信息）
+	if decoder.Len() == 0 {
+		return
+	}
+	if channelId, err = decoder.String(); err != nil {
+		return
+	}
+	channelType, err = decoder.Uint8()
+	return
+}
+
+func EncodeCMDThreadReplies(channelId string, channelType uint8, rootMessageSeq uint64, replies []wkdb.ThreadReply) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(channelId)
+	encoder.WriteUint8(channelType)
+	encoder.WriteUint64(rootMessageSeq)
+	encoder.WriteUint32(uint32(len(replies)))
+	for _, reply := range replies {
+		encoder.WriteString(reply.Uid)
+		encoder.WriteUint64(reply.MessageSeq)
+		encoder.WriteUint64(reply.ReplyAt)
+	}
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDThreadReplies() (channelId string, channelType uint8, rootMessageSeq uint64, replies []wkdb.ThreadReply, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if channelId, err = decoder.String(); err != nil {
+		return
+	}
+	if channelType, err = decoder.Uint8(); err != nil {
+		return
+	}
+	if rootMessageSeq, err = decoder.Uint64(); err != nil {
+		return
+	}
+	var count uint32
+	if count, err = decoder.Uint32(); err != nil {
+		return
+	}
+	for i := uint32(0); i < count; i++ {
+		var reply wkdb.ThreadReply
+		if reply.Uid, err = decoder.String(); err != nil {
+			return
+		}
+		if reply.MessageSeq, err = decoder.Uint64(); err != nil {
+			return
+		}
+		if reply.ReplyAt, err = decoder.Uint64(); err != nil {
+			return
+		}
+		replies = append(replies, reply)
+	}
+	return
+}
+
+func EncodeCMDChannelPin(pin wkdb.ChannelPin) ([]byte, error) {
+	return pin.Marshal()
+}
+
+func (c *CMD) DecodeCMDChannelPin() (wkdb.ChannelPin, error) {
+	var pin wkdb.ChannelPin
+	err := pin.Unmarshal(c.Data)
+	return pin, err
+}
+
+func EncodeCMDRemoveChannelPins(channelId string, channelType uint8, messageSeqs []uint64) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(channelId)
+	encoder.WriteUint8(channelType)
+	encoder.WriteUint32(uint32(len(messageSeqs)))
+	for _, messageSeq := range messageSeqs {
+		encoder.WriteUint64(messageSeq)
+	}
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDRemoveChannelPins() (channelId string, channelType uint8, messageSeqs []uint64, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if channelId, err = decoder.String(); err != nil {
+		return
+	}
+	if channelType, err = decoder.Uint8(); err != nil {
+		return
+	}
+	var count uint32
+	if count, err = decoder.Uint32(); err != nil {
+		return
+	}
+	for i := uint32(0); i < count; i++ {
+		var messageSeq uint64
+		if messageSeq, err = decoder.Uint64(); err != nil {
+			return
+		}
+		messageSeqs = append(messageSeqs, messageSeq)
+	}
+	return
+}
+
+func EncodeCMDChannelAnnouncement(announcement wkdb.ChannelAnnouncement) ([]byte, error) {
+	return announcement.Marshal()
+}
+
+func (c *CMD) DecodeCMDChannelAnnouncement() (wkdb.ChannelAnnouncement, error) {
+	var announcement wkdb.ChannelAnnouncement
+	err := announcement.Unmarshal(c.Data)
+	return announcement, err
+}
+
+func EncodeCMDSetConversationAttrs(uid string, attrs []wkdb.ConversationAttrs) ([]byte, error) {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(uid)
+	encoder.WriteUint32(uint32(len(attrs)))
+	for _, attr := range attrs {
+		data, err := attr.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		encoder.WriteBinary(data)
+	}
+	return encoder.Bytes(), nil
+}
+
+func (c *CMD) DecodeCMDSetConversationAttrs() (uid string, attrs []wkdb.ConversationAttrs, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if uid, err = decoder.String(); err != nil {
+		return
+	}
+	var count uint32
+	if count, err = decoder.Uint32(); err != nil {
+		return
+	}
+	for i := uint32(0); i < count; i++ {
+		var data []byte
+		if data, err = decoder.Binary(); err != nil {
+			return
+		}
+		var attr wkdb.ConversationAttrs
+		if err = attr.Unmarshal(data); err != nil {
+			return
+		}
+		attrs = append(attrs, attr)
+	}
+	return
+}
+
+func EncodeCMDAppendCDCMessages(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(channelId)
+	encoder.WriteUint8(channelType)
+	encoder.WriteUint64(startMessageSeq)
+	encoder.WriteUint64(endMessageSeq)
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDAppendCDCMessages() (channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if channelId, err = decoder.String(); err != nil {
+		return
+	}
+	if channelType, err = decoder.Uint8(); err != nil {
+		return
+	}
+	if startMessageSeq, err = decoder.Uint64(); err != nil {
+		return
+	}
+	if endMessageSeq, err = decoder.Uint64(); err != nil {
+		return
+	}
+	return
+}
+
+func EncodeCMDSetCDCConsumerOffset(consumer string, slotId uint32, offset uint64) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(consumer)
+	encoder.WriteUint32(slotId)
+	encoder.WriteUint64(offset)
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDSetCDCConsumerOffset() (consumer string, slotId uint32, offset uint64, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if consumer, err = decoder.String(); err != nil {
+		return
+	}
+	if slotId, err = decoder.Uint32(); err != nil {
+		return
+	}
+	offset, err = decoder.Uint64()
+	return
+}
+
+func EncodeCMDRemoveCDCConsumer(consumer string, slotId uint32) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(consumer)
+	encoder.WriteUint32(slotId)
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDRemoveCDCConsumer() (consumer string, slotId uint32, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if consumer, err = decoder.String(); err != nil {
+		return
+	}
+	slotId, err = decoder.Uint32()
+	return
+}
+
+func EncodeCMDCustomerServiceQueue(queue wkdb.CustomerServiceQueue) ([]byte, error) {
+	return queue.Marshal()
+}
+
+func (c *CMD) DecodeCMDCustomerServiceQueue() (wkdb.CustomerServiceQueue, error) {
+	var queue wkdb.CustomerServiceQueue
+	err := queue.Unmarshal(c.Data)
+	return queue, err
+}
+
+func EncodeCMDCustomerServiceSessions(sessions []wkdb.CustomerServiceSession) ([]byte, error) {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteUint32(uint32(len(sessions)))
+	for _, session := range sessions {
+		data, err := session.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		encoder.WriteBinary(data)
+	}
+	return encoder.Bytes(), nil
+}
+
+func (c *CMD) DecodeCMDCustomerServiceSessions() ([]wkdb.CustomerServiceSession, error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	count, err := decoder.Uint32()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]wkdb.CustomerServiceSession, 0, count)
+	for i := uint32(0); i < count; i++ {
+		data, err := decoder.Binary()
+		if err != nil {
+			return nil, err
+		}
+		var session wkdb.CustomerServiceSession
+		if err = session.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func EncodeCMDRemoveCustomerServiceSession(queueId string, channelId string) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(queueId)
+	encoder.WriteString(channelId)
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDRemoveCustomerServiceSession() (queueId string, channelId string, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if queueId, err = decoder.String(); err != nil {
+		return
+	}
+	channelId, err = decoder.String()
+	return
+}
+
+func EncodeCMDUserLastSeen(uid string, online bool, at uint64) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(uid)
+	encoder.WriteUint8(wkutil.BoolToUint8(online))
+	encoder.WriteUint64(at)
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDUserLastSeen() (uid string, online bool, at uint64, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if uid, err = decoder.String(); err != nil {
+		return
+	}
+	var onlineI uint8
+	if onlineI, err = decoder.Uint8(); err != nil {
+		return
+	}
+	online = wkutil.Uint8ToBool(onlineI)
+	at, err = decoder.Uint64()
+	return
+}
+
+func EncodeCMDUserPresenceHidden(uid string, hidden bool) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(uid)
+	encoder.WriteUint8(wkutil.BoolToUint8(hidden))
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDUserPresenceHidden() (uid string, hidden bool, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if uid, err = decoder.String(); err != nil {
+		return
+	}
+	var hiddenI uint8
+	if hiddenI, err = decoder.Uint8(); err != nil {
+		return
+	}
+	hidden = wkutil.Uint8ToBool(hiddenI)
+	return
+}
+
 var ErrStoreStopped = fmt.Errorf("store stopped")
@@ -14,6 +14,8 @@ type Options struct {
 
 	IsCmdChannel func(string) bool // 是否是cmd频道
 
+	CDCOn bool // 是否记录变更事件（CDC）
+
 	Db struct {
 		ShardNum     int // 分片数量
 		MemTableSize int // MemTable大小
@@ -74,6 +76,12 @@ func WithGetSlotId(f func(uid string) uint32) Option {
 	}
 }
 
+func WithCDCOn(on bool) Option {
+	return func(o *Options) {
+		o.CDCOn = on
+	}
+}
+
 func WithDbShardNum(num int) Option {
 	return func(o *Options) {
 		o.Db.ShardNum = num
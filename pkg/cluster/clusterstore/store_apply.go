@@ -47,6 +47,23 @@ func (s *Store) onMetaApply(slotId uint32, log replica.Log) error {
 		s.Error("exec cmd err", zap.Error(err), zap.String("cmdType", cmd.CmdType.String()), zap.Uint32("slotId", slotId), zap.Uint64("index", log.Index), zap.ByteString("data", log.Data))
 		return err
 	}
+
+	// 记录变更事件，偏移量为槽日志的下标，所以每个副本上的事件都是一致的
+	if s.opts.CDCOn && cmd.CmdType.CDCCategory() != CDCCategoryNone {
+		err = s.wdb.AppendCDCEvents([]wkdb.CDCEvent{
+			{
+				SlotId:    slotId,
+				Offset:    log.Index,
+				Type:      cmd.CmdType.Uint16(),
+				Data:      cmd.Data,
+				CreatedAt: time.Now().UnixNano(),
+			},
+		})
+		if err != nil {
+			s.Error("append cdc event err", zap.Error(err), zap.String("cmdType", cmd.CmdType.String()), zap.Uint32("slotId", slotId), zap.Uint64("index", log.Index))
+			return err
+		}
+	}
 	return nil
 }
 
@@ -102,6 +119,40 @@ func (s *Store) execCMD(cmd *CMD) error {
 		return s.handleSystemUIDsAdd(cmd)
 	case CMDSystemUIDsRemove: // 移除系统UID
 		return s.handleSystemUIDsRemove(cmd)
+	case CMDAddPresenceSubscribers: // 添加在线状态订阅者
+		return s.handleAddPresenceSubscribers(cmd)
+	case CMDRemovePresenceSubscribers: // 移除在线状态订阅者
+		return s.handleRemovePresenceSubscribers(cmd)
+	case CMDAddOrUpdateScheduledMessage: // 添加或更新定时消息
+		return s.handleAddOrUpdateScheduledMessage(cmd)
+	case CMDRemoveScheduledMessages: // 移除定时消息
+		return s.handleRemoveScheduledMessages(cmd)
+	case CMDAddThreadReplies: // 添加子区回复
+		return s.handleAddThreadReplies(cmd)
+	case CMDAddOrUpdateChannelPin: // 添加或更新频道置顶消息
+		return s.handleAddOrUpdateChannelPin(cmd)
+	case CMDRemoveChannelPins: // 移除频道置顶消息
+		return s.handleRemoveChannelPins(cmd)
+	case CMDSetChannelAnnouncement: // 设置频道公告
+		return s.handleSetChannelAnnouncement(cmd)
+	case CMDSetConversationAttrs: // 设置最近会话的用户属性
+		return s.handleSetConversationAttrs(cmd)
+	case CMDSetCDCConsumerOffset: // 设置变更事件消费者的偏移量
+		return s.handleSetCDCConsumerOffset(cmd)
+	case CMDRemoveCDCConsumer: // 移除变更事件消费者
+		return s.handleRemoveCDCConsumer(cmd)
+	case CMDAddOrUpdateCustomerServiceQueue: // 添加或更新客服队列
+		return s.handleAddOrUpdateCustomerServiceQueue(cmd)
+	case CMDDeleteCustomerServiceQueue: // 删除客服队列
+		return s.handleDeleteCustomerServiceQueue(cmd)
+	case CMDAddOrUpdateCustomerServiceSessions: // 添加或更新客服会话
+		return s.handleAddOrUpdateCustomerServiceSessions(cmd)
+	case CMDRemoveCustomerServiceSession: // 移除客服会话
+		return s.handleRemoveCustomerServiceSession(cmd)
+	case CMDUpdateUserLastSeen: // 更新用户最后一次上线/离线时间
+		return s.handleUpdateUserLastSeen(cmd)
+	case CMDUpdateUserPresenceHidden: // 更新用户是否隐藏在线状态
+		return s.handleUpdateUserPresenceHidden(cmd)
 
 	}
 	return nil
@@ -327,3 +378,135 @@ func (s *Store) handleSystemUIDsRemove(cmd *CMD) error {
 	}
 	return s.wdb.RemoveSystemUids(uids)
 }
+
+func (s *Store) handleAddPresenceSubscribers(cmd *CMD) error {
+	uid, subscribers, err := cmd.DecodeCMDPresenceSubscribers()
+	if err != nil {
+		return err
+	}
+	return s.wdb.AddPresenceSubscribers(uid, subscribers)
+}
+
+func (s *Store) handleRemovePresenceSubscribers(cmd *CMD) error {
+	uid, subscribers, err := cmd.DecodeCMDPresenceSubscribers()
+	if err != nil {
+		return err
+	}
+	return s.wdb.RemovePresenceSubscribers(uid, subscribers)
+}
+
+func (s *Store) handleAddOrUpdateScheduledMessage(cmd *CMD) error {
+	m, err := cmd.DecodeCMDScheduledMessage()
+	if err != nil {
+		return err
+	}
+	return s.wdb.AddOrUpdateScheduledMessage(m)
+}
+
+func (s *Store) handleRemoveScheduledMessages(cmd *CMD) error {
+	channelId, channelType, ids, err := cmd.DecodeCMDScheduledMessageIds()
+	if err != nil {
+		return err
+	}
+	return s.wdb.RemoveScheduledMessages(channelId, channelType, ids)
+}
+
+func (s *Store) handleAddThreadReplies(cmd *CMD) error {
+	channelId, channelType, rootMessageSeq, replies, err := cmd.DecodeCMDThreadReplies()
+	if err != nil {
+		return err
+	}
+	return s.wdb.AddThreadReplies(channelId, channelType, rootMessageSeq, replies)
+}
+
+func (s *Store) handleAddOrUpdateChannelPin(cmd *CMD) error {
+	pin, err := cmd.DecodeCMDChannelPin()
+	if err != nil {
+		return err
+	}
+	return s.wdb.AddOrUpdateChannelPin(pin)
+}
+
+func (s *Store) handleRemoveChannelPins(cmd *CMD) error {
+	channelId, channelType, messageSeqs, err := cmd.DecodeCMDRemoveChannelPins()
+	if err != nil {
+		return err
+	}
+	return s.wdb.RemoveChannelPins(channelId, channelType, messageSeqs)
+}
+
+func (s *Store) handleSetChannelAnnouncement(cmd *CMD) error {
+	announcement, err := cmd.DecodeCMDChannelAnnouncement()
+	if err != nil {
+		return err
+	}
+	return s.wdb.SetChannelAnnouncement(announcement)
+}
+
+func (s *Store) handleSetConversationAttrs(cmd *CMD) error {
+	uid, attrs, err := cmd.DecodeCMDSetConversationAttrs()
+	if err != nil {
+		return err
+	}
+	return s.wdb.SetConversationAttrs(uid, attrs)
+}
+
+func (s *Store) handleSetCDCConsumerOffset(cmd *CMD) error {
+	consumer, slotId, offset, err := cmd.DecodeCMDSetCDCConsumerOffset()
+	if err != nil {
+		return err
+	}
+	return s.wdb.SetCDCConsumerOffset(consumer, slotId, offset)
+}
+
+func (s *Store) handleRemoveCDCConsumer(cmd *CMD) error {
+	consumer, slotId, err := cmd.DecodeCMDRemoveCDCConsumer()
+	if err != nil {
+		return err
+	}
+	return s.wdb.RemoveCDCConsumerOffset(consumer, slotId)
+}
+
+func (s *Store) handleAddOrUpdateCustomerServiceQueue(cmd *CMD) error {
+	queue, err := cmd.DecodeCMDCustomerServiceQueue()
+	if err != nil {
+		return err
+	}
+	return s.wdb.AddOrUpdateCustomerServiceQueue(queue)
+}
+
+func (s *Store) handleDeleteCustomerServiceQueue(cmd *CMD) error {
+	return s.wdb.DeleteCustomerServiceQueue(string(cmd.Data))
+}
+
+func (s *Store) handleAddOrUpdateCustomerServiceSessions(cmd *CMD) error {
+	sessions, err := cmd.DecodeCMDCustomerServiceSessions()
+	if err != nil {
+		return err
+	}
+	return s.wdb.AddOrUpdateCustomerServiceSessions(sessions)
+}
+
+func (s *Store) handleRemoveCustomerServiceSession(cmd *CMD) error {
+	queueId, channelId, err := cmd.DecodeCMDRemoveCustomerServiceSession()
+	if err != nil {
+		return err
+	}
+	return s.wdb.RemoveCustomerServiceSession(queueId, channelId)
+}
+
+func (s *Store) handleUpdateUserLastSeen(cmd *CMD) error {
+	uid, online, at, err := cmd.DecodeCMDUserLastSeen()
+	if err != nil {
+		return err
+	}
+	return s.wdb.UpdateUserLastSeen(uid, online, at)
+}
+
+func (s *Store) handleUpdateUserPresenceHidden(cmd *CMD) error {
+	uid, hidden, err := cmd.DecodeCMDUserPresenceHidden()
+	if err != nil {
+		return err
+	}
+	return s.wdb.UpdateUserPresenceHidden(uid, hidden)
+}
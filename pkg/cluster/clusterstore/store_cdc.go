@@ -0,0 +1,138 @@
+package clusterstore
+
+import (
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+)
+
+// CDCCategory 变更事件的分类，消费者按分类订阅
+type CDCCategory string
+
+const (
+	CDCCategoryNone         CDCCategory = ""
+	CDCCategoryMessage      CDCCategory = "message"      // 消息追加
+	CDCCategoryConversation CDCCategory = "conversation" // 最近会话变化
+	CDCCategoryChannel      CDCCategory = "channel"      // 频道和订阅者变化
+	CDCCategoryUser         CDCCategory = "user"         // 用户和设备变化
+)
+
+// CDCCategory 命令对应的变更事件分类，不产生变更事件的命令返回CDCCategoryNone
+func (c CMDType) CDCCategory() CDCCategory {
+	switch c {
+	case CMDAppendCDCMessages:
+		return CDCCategoryMessage
+	case CMDAddOrUpdateConversations, CMDDeleteConversation, CMDDeleteConversations, CMDBatchUpdateConversation, CMDSetConversationAttrs:
+		return CDCCategoryConversation
+	case CMDAddChannelInfo, CMDUpdateChannelInfo, CMDDeleteChannel,
+		CMDAddSubscribers, CMDRemoveSubscribers, CMDRemoveAllSubscriber,
+		CMDAddDenylist, CMDRemoveDenylist, CMDRemoveAllDenylist,
+		CMDAddAllowlist, CMDRemoveAllowlist, CMDRemoveAllAllowlist:
+		return CDCCategoryChannel
+	case CMDAddUser, CMDUpdateUser, CMDAddDevice, CMDUpdateDevice:
+		return CDCCategoryUser
+	}
+	return CDCCategoryNone
+}
+
+// CDCEventType 命令对应的变更事件类型名
+func (c CMDType) CDCEventType() string {
+	switch c {
+	case CMDAppendCDCMessages:
+		return "message.append"
+	case CMDAddOrUpdateConversations:
+		return "conversation.update"
+	case CMDDeleteConversation:
+		return "conversation.delete"
+	case CMDDeleteConversations:
+		return "conversation.batch_delete"
+	case CMDBatchUpdateConversation:
+		return "conversation.read"
+	case CMDSetConversationAttrs:
+		return "conversation.attrs"
+	case CMDAddChannelInfo:
+		return "channel.add"
+	case CMDUpdateChannelInfo:
+		return "channel.update"
+	case CMDDeleteChannel:
+		return "channel.delete"
+	case CMDAddSubscribers:
+		return "subscriber.add"
+	case CMDRemoveSubscribers:
+		return "subscriber.remove"
+	case CMDRemoveAllSubscriber:
+		return "subscriber.remove_all"
+	case CMDAddDenylist:
+		return "denylist.add"
+	case CMDRemoveDenylist:
+		return "denylist.remove"
+	case CMDRemoveAllDenylist:
+		return "denylist.remove_all"
+	case CMDAddAllowlist:
+		return "allowlist.add"
+	case CMDRemoveAllowlist:
+		return "allowlist.remove"
+	case CMDRemoveAllAllowlist:
+		return "allowlist.remove_all"
+	case CMDAddUser:
+		return "user.add"
+	case CMDUpdateUser:
+		return "user.update"
+	case CMDAddDevice:
+		return "device.add"
+	case CMDUpdateDevice:
+		return "device.update"
+	}
+	return ""
+}
+
+// AppendCDCMessages 将频道存储成功的消息作为变更事件提案到频道所属的槽
+// 只记录频道和消息序号范围（包含startMessageSeq和endMessageSeq），消息内容在拉取时从频道日志读取
+func (s *Store) AppendCDCMessages(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64) error {
+	if startMessageSeq == 0 || endMessageSeq < startMessageSeq {
+		return nil
+	}
+	data := EncodeCMDAppendCDCMessages(channelId, channelType, startMessageSeq, endMessageSeq)
+	cmd := NewCMD(CMDAppendCDCMessages, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(channelId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// SetCDCConsumerOffset 确认消费者在槽上已消费到的偏移量（偏移量存储在对应的槽上）
+func (s *Store) SetCDCConsumerOffset(consumer string, slotId uint32, offset uint64) error {
+	data := EncodeCMDSetCDCConsumerOffset(consumer, slotId, offset)
+	cmd := NewCMD(CMDSetCDCConsumerOffset, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// RemoveCDCConsumer 移除消费者在槽上的偏移量
+func (s *Store) RemoveCDCConsumer(consumer string, slotId uint32) error {
+	data := EncodeCMDRemoveCDCConsumer(consumer, slotId)
+	cmd := NewCMD(CMDRemoveCDCConsumer, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+func (s *Store) GetCDCConsumerOffset(consumer string, slotId uint32) (uint64, error) {
+	return s.wdb.GetCDCConsumerOffset(consumer, slotId)
+}
+
+func (s *Store) GetCDCEvents(slotId uint32, startOffset uint64, limit int) ([]wkdb.CDCEvent, error) {
+	return s.wdb.GetCDCEvents(slotId, startOffset, limit)
+}
+
+func (s *Store) RemoveExpiredCDCEvents(slotId uint32, expireAt int64, limit int) (int, error) {
+	return s.wdb.RemoveExpiredCDCEvents(slotId, expireAt, limit)
+}
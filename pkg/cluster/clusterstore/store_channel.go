@@ -225,6 +225,64 @@ func (s *Store) HasAllowlist(channelId string, channelType uint8) (bool, error)
 	return s.wdb.HasAllowlist(channelId, channelType)
 }
 
+// AddOrUpdateChannelPin 添加或更新频道置顶消息
+func (s *Store) AddOrUpdateChannelPin(pin wkdb.ChannelPin) error {
+	data, err := EncodeCMDChannelPin(pin)
+	if err != nil {
+		return err
+	}
+	cmd := NewCMD(CMDAddOrUpdateChannelPin, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(pin.ChannelId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// RemoveChannelPins 移除频道置顶消息
+func (s *Store) RemoveChannelPins(channelId string, channelType uint8, messageSeqs []uint64) error {
+	if len(messageSeqs) == 0 {
+		return nil
+	}
+	data := EncodeCMDRemoveChannelPins(channelId, channelType, messageSeqs)
+	cmd := NewCMD(CMDRemoveChannelPins, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(channelId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// GetChannelPins 获取频道置顶消息
+func (s *Store) GetChannelPins(channelId string, channelType uint8) ([]wkdb.ChannelPin, error) {
+	return s.wdb.GetChannelPins(channelId, channelType)
+}
+
+// SetChannelAnnouncement 设置频道公告
+func (s *Store) SetChannelAnnouncement(announcement wkdb.ChannelAnnouncement) error {
+	data, err := EncodeCMDChannelAnnouncement(announcement)
+	if err != nil {
+		return err
+	}
+	cmd := NewCMD(CMDSetChannelAnnouncement, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(announcement.ChannelId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// GetChannelAnnouncement 获取频道公告
+func (s *Store) GetChannelAnnouncement(channelId string, channelType uint8) (wkdb.ChannelAnnouncement, error) {
+	return s.wdb.GetChannelAnnouncement(channelId, channelType)
+}
+
 // func (s *Store) DeleteChannelClusterConfig(channelID string, channelType uint8) error {
 // 	cmd := NewCMD(CMDChannelClusterConfigDelete, nil)
 // 	cmdData, err := cmd.Marshal()
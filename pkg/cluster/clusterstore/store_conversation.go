@@ -51,6 +51,46 @@ func (s *Store) DeleteConversations(uid string, channels []wkdb.Channel) error {
 	return err
 }
 
+// SetConversationAttrs 设置最近会话的用户属性
+func (s *Store) SetConversationAttrs(uid string, attrs []wkdb.ConversationAttrs) error {
+	if len(attrs) == 0 {
+		return nil
+	}
+	data, err := EncodeCMDSetConversationAttrs(uid, attrs)
+	if err != nil {
+		return err
+	}
+	cmd := NewCMD(CMDSetConversationAttrs, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(uid)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+func (s *Store) GetConversationVersion(uid string) (uint64, error) {
+	return s.wdb.GetConversationVersion(uid)
+}
+
+func (s *Store) GetConversationsByVersion(uid string, tp wkdb.ConversationType, version uint64, limit int) ([]wkdb.Conversation, error) {
+	return s.wdb.GetConversationsByVersion(uid, tp, version, limit)
+}
+
+func (s *Store) GetConversationTombstones(uid string, version uint64) ([]wkdb.ConversationTombstone, error) {
+	return s.wdb.GetConversationTombstones(uid, version)
+}
+
+// RemoveExpiredConversationTombstones 清理本节点上过期的会话删除墓碑（各副本按删除时间独立清理，不需要提案）
+func (s *Store) RemoveExpiredConversationTombstones(deletedBefore uint64, limit int) (int, error) {
+	return s.wdb.RemoveExpiredConversationTombstones(deletedBefore, limit)
+}
+
+func (s *Store) GetConversationPrunedVersion(uid string) (uint64, error) {
+	return s.wdb.GetConversationPrunedVersion(uid)
+}
+
 func (s *Store) GetConversations(uid string) ([]wkdb.Conversation, error) {
 	return s.wdb.GetConversations(uid)
 }
@@ -0,0 +1,80 @@
+package clusterstore
+
+import "github.com/WuKongIM/WuKongIM/pkg/wkdb"
+
+// 客服队列和队列下的会话都存储在队列id所在的槽上
+
+// AddOrUpdateCustomerServiceQueue 添加或更新客服队列
+func (s *Store) AddOrUpdateCustomerServiceQueue(queue wkdb.CustomerServiceQueue) error {
+	data, err := EncodeCMDCustomerServiceQueue(queue)
+	if err != nil {
+		return err
+	}
+	cmd := NewCMD(CMDAddOrUpdateCustomerServiceQueue, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(queue.QueueId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// DeleteCustomerServiceQueue 删除客服队列和队列下的所有会话
+func (s *Store) DeleteCustomerServiceQueue(queueId string) error {
+	cmd := NewCMD(CMDDeleteCustomerServiceQueue, []byte(queueId))
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(queueId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// GetCustomerServiceQueue 获取客服队列
+func (s *Store) GetCustomerServiceQueue(queueId string) (wkdb.CustomerServiceQueue, error) {
+	return s.wdb.GetCustomerServiceQueue(queueId)
+}
+
+// GetCustomerServiceQueues 获取本节点存储的所有客服队列
+func (s *Store) GetCustomerServiceQueues() ([]wkdb.CustomerServiceQueue, error) {
+	return s.wdb.GetCustomerServiceQueues()
+}
+
+// AddOrUpdateCustomerServiceSessions 添加或更新客服队列下的会话
+func (s *Store) AddOrUpdateCustomerServiceSessions(queueId string, sessions []wkdb.CustomerServiceSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	data, err := EncodeCMDCustomerServiceSessions(sessions)
+	if err != nil {
+		return err
+	}
+	cmd := NewCMD(CMDAddOrUpdateCustomerServiceSessions, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(queueId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// RemoveCustomerServiceSession 移除客服会话
+func (s *Store) RemoveCustomerServiceSession(queueId string, channelId string) error {
+	data := EncodeCMDRemoveCustomerServiceSession(queueId, channelId)
+	cmd := NewCMD(CMDRemoveCustomerServiceSession, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(queueId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// GetCustomerServiceSessions 获取客服队列下的所有会话
+func (s *Store) GetCustomerServiceSessions(queueId string) ([]wkdb.CustomerServiceSession, error) {
+	return s.wdb.GetCustomerServiceSessions(queueId)
+}
@@ -46,6 +46,11 @@ func (s *Store) LoadMsg(channelID string, channelType uint8, seq uint64) (wkdb.M
 	return s.wdb.LoadMsg(channelID, channelType, seq)
 }
 
+// LoadMsgByClientMsgNo 加载发送者在频道内指定clientMsgNo的最新一条消息（消息日志在频道的所有副本上都有）
+func (s *Store) LoadMsgByClientMsgNo(channelID string, channelType uint8, fromUid string, clientMsgNo string) (wkdb.Message, error) {
+	return s.wdb.LoadMsgByClientMsgNo(channelID, channelType, fromUid, clientMsgNo)
+}
+
 func (s *Store) LoadLastMsgs(channelID string, channelType uint8, limit int) ([]wkdb.Message, error) {
 	return s.wdb.LoadLastMsgs(channelID, channelType, limit)
 }
@@ -368,3 +373,79 @@ func IsUserOwnChannel(channelId string, channelType uint8) bool {
 	}
 	return false
 }
+
+// AddOrUpdateScheduledMessage 添加或更新定时消息（通过目标频道所在的槽复制）
+func (s *Store) AddOrUpdateScheduledMessage(m wkdb.ScheduledMessage) error {
+	data, err := EncodeCMDScheduledMessage(m)
+	if err != nil {
+		return err
+	}
+	cmd := NewCMD(CMDAddOrUpdateScheduledMessage, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		s.Error("marshal cmd failed", zap.Error(err))
+		return err
+	}
+	slotId := s.opts.GetSlotId(m.ChannelId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// RemoveScheduledMessages 移除频道的定时消息（不属于此频道的id会被忽略）
+func (s *Store) RemoveScheduledMessages(channelId string, channelType uint8, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	data := EncodeCMDScheduledMessageIds(channelId, channelType, ids)
+	cmd := NewCMD(CMDRemoveScheduledMessages, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		s.Error("marshal cmd failed", zap.Error(err))
+		return err
+	}
+	slotId := s.opts.GetSlotId(channelId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// GetScheduledMessage 获取定时消息（需要在频道所在槽的节点上调用）
+func (s *Store) GetScheduledMessage(id uint64) (wkdb.ScheduledMessage, error) {
+	return s.wdb.GetScheduledMessage(id)
+}
+
+// GetDueScheduledMessages 获取本节点上已到发送时间的定时消息，从(offsetSendAt, offsetId)之后开始取
+func (s *Store) GetDueScheduledMessages(sendAt uint64, offsetSendAt uint64, offsetId uint64, limit int) ([]wkdb.ScheduledMessage, error) {
+	return s.wdb.GetDueScheduledMessages(sendAt, offsetSendAt, offsetId, limit)
+}
+
+// GetScheduledMessages 获取频道的定时消息（需要在频道所在槽的节点上调用）
+func (s *Store) GetScheduledMessages(channelId string, channelType uint8, limit int) ([]wkdb.ScheduledMessage, error) {
+	return s.wdb.GetScheduledMessages(channelId, channelType, limit)
+}
+
+// AddThreadReplies 添加子区的回复（通过父频道所在的槽复制）
+func (s *Store) AddThreadReplies(channelId string, channelType uint8, rootMessageSeq uint64, replies []wkdb.ThreadReply) error {
+	if len(replies) == 0 {
+		return nil
+	}
+	data := EncodeCMDThreadReplies(channelId, channelType, rootMessageSeq, replies)
+	cmd := NewCMD(CMDAddThreadReplies, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		s.Error("marshal cmd failed", zap.Error(err))
+		return err
+	}
+	slotId := s.opts.GetSlotId(channelId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// GetThread 获取子区（需要在父频道所在槽的节点上调用）
+func (s *Store) GetThread(channelId string, channelType uint8, rootMessageSeq uint64) (wkdb.Thread, error) {
+	return s.wdb.GetThread(channelId, channelType, rootMessageSeq)
+}
+
+// GetThreads 获取频道的子区（需要在父频道所在槽的节点上调用）
+func (s *Store) GetThreads(channelId string, channelType uint8, limit int) ([]wkdb.Thread, error) {
+	return s.wdb.GetThreads(channelId, channelType, limit)
+}
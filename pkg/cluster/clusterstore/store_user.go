@@ -67,6 +67,73 @@ func (s *Store) GetDevice(uid string, deviceFlag wkproto.DeviceFlag) (wkdb.Devic
 	return s.wdb.GetDevice(uid, uint64(deviceFlag))
 }
 
+// UpdateUserLastSeen 更新用户最后一次上线（online为true）或离线时间，只写对应的列，不会覆盖用户的其他数据
+func (s *Store) UpdateUserLastSeen(uid string, online bool, at uint64) error {
+	data := EncodeCMDUserLastSeen(uid, online, at)
+	cmd := NewCMD(CMDUpdateUserLastSeen, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		s.Error("marshal cmd failed", zap.Error(err))
+		return err
+	}
+	slotId := s.opts.GetSlotId(uid)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// UpdateUserPresenceHidden 更新用户是否对订阅者隐藏在线状态，只写对应的列，不会覆盖用户的其他数据
+func (s *Store) UpdateUserPresenceHidden(uid string, hidden bool) error {
+	data := EncodeCMDUserPresenceHidden(uid, hidden)
+	cmd := NewCMD(CMDUpdateUserPresenceHidden, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		s.Error("marshal cmd failed", zap.Error(err))
+		return err
+	}
+	slotId := s.opts.GetSlotId(uid)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// AddPresenceSubscribers 添加uid的在线状态订阅者
+func (s *Store) AddPresenceSubscribers(uid string, subscribers []string) error {
+	if len(subscribers) == 0 {
+		return nil
+	}
+	data := EncodeCMDPresenceSubscribers(uid, subscribers)
+	cmd := NewCMD(CMDAddPresenceSubscribers, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		s.Error("marshal cmd failed", zap.Error(err))
+		return err
+	}
+	slotId := s.opts.GetSlotId(uid)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// RemovePresenceSubscribers 移除uid的在线状态订阅者
+func (s *Store) RemovePresenceSubscribers(uid string, subscribers []string) error {
+	if len(subscribers) == 0 {
+		return nil
+	}
+	data := EncodeCMDPresenceSubscribers(uid, subscribers)
+	cmd := NewCMD(CMDRemovePresenceSubscribers, data)
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		s.Error("marshal cmd failed", zap.Error(err))
+		return err
+	}
+	slotId := s.opts.GetSlotId(uid)
+	_, err = s.opts.Cluster.ProposeDataToSlot(s.ctx, slotId, cmdData)
+	return err
+}
+
+// GetPresenceSubscribers 获取订阅了uid在线状态的用户（需要在uid所在槽的节点上调用）
+func (s *Store) GetPresenceSubscribers(uid string) ([]string, error) {
+	return s.wdb.GetPresenceSubscribers(uid)
+}
+
 func (s *Store) NextPrimaryKey() uint64 {
 	return s.wdb.NextPrimaryKey()
 }
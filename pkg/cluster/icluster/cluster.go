@@ -20,6 +20,10 @@ type Cluster interface {
 	LeaderOfChannel(ctx context.Context, channelId string, channelType uint8) (nodeInfo *pb.Node, err error)
 	// SlotLeaderIdOfChannel 获取channel的leader节点信息(不激活频道)
 	LeaderOfChannelForRead(channelId string, channelType uint8) (nodeInfo *pb.Node, err error)
+	// ReplicaOfChannelForRead 获取读取频道消息的副本节点（追随者读），副本数据落后于minSeq时返回领导节点
+	ReplicaOfChannelForRead(channelId string, channelType uint8, minSeq uint64) (nodeInfo *pb.Node, err error)
+	// ReadableMessageSeqOfChannel 当前节点作为频道追随者时可读取的最大消息序号（0表示不限制）
+	ReadableMessageSeqOfChannel(channelId string, channelType uint8) (seq uint64, err error)
 	// SlotLeaderIdOfChannel 获取频道所属槽的领导
 	SlotLeaderIdOfChannel(channelId string, channelType uint8) (nodeId uint64, err error)
 	// SlotLeaderOfChannel 获取频道所属槽的领导
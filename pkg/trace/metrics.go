@@ -1,6 +1,10 @@
 package trace
 
-import "github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+import (
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+)
 
 type ClusterKind int
 
@@ -26,6 +30,12 @@ type IMetrics interface {
 	DB() IDBMetrics
 
 	Route(r *wkhttp.WKHttp)
+
+	// History 当前节点内置监控中start（秒）之后的采样点
+	History(start int64) []*MetricsPoint
+
+	Start()
+	Stop()
 }
 
 // SystemMetrics 系统监控
@@ -209,6 +219,16 @@ type IAppMetrics interface {
 	ConnackPacketBytesAdd(v int64)
 	// ConnackPacketCountAdd 连接应答包数量
 	ConnackPacketCountAdd(v int64)
+
+	// SlowConsumerCountAdd 连接发送缓冲区超出限制的次数
+	SlowConsumerCountAdd(v int64)
+	// SlowConsumerDroppedCountAdd 慢消费者被丢弃的包数量
+	SlowConsumerDroppedCountAdd(v int64)
+	// SlowConsumerDisconnectCountAdd 慢消费者被断开的连接数量
+	SlowConsumerDisconnectCountAdd(v int64)
+
+	// CompressAdd 压缩统计，rawBytes为压缩前的字节数，compressedBytes为压缩后的字节数，cost为压缩耗时
+	CompressAdd(rawBytes, compressedBytes int64, cost time.Duration)
 }
 
 // IClusterMetrics 分布式监控
@@ -339,6 +359,13 @@ type IClusterMetrics interface {
 	// ChannelActiveCountAdd 频道激活数量
 	ChannelActiveCountAdd(v int64)
 
+	// ChannelRepairLearnerAddCountAdd 频道副本修复添加学习者的次数
+	ChannelRepairLearnerAddCountAdd(v int64)
+	// ChannelRepairReplicaRemoveCountAdd 频道副本修复移除失效副本的次数
+	ChannelRepairReplicaRemoveCountAdd(v int64)
+	// ChannelUnderReplicatedCountSet 副本数量不足的频道数量（最近一轮修复扫描的结果）
+	ChannelUnderReplicatedCountSet(v int64)
+
 	// ChannelElectionCountAdd 频道选举次数
 	ChannelElectionCountAdd(v int64)
 	// ChannelElectionSuccessCountAdd 频道选举成功次数
@@ -2,6 +2,7 @@ package trace
 
 import (
 	"context"
+	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"go.opentelemetry.io/otel/metric"
@@ -33,6 +34,15 @@ type appMetrics struct {
 	connPacketCount    atomic.Int64
 	connackPacketBytes atomic.Int64
 	connackPacketCount atomic.Int64
+
+	slowConsumerCount           atomic.Int64
+	slowConsumerDroppedCount    atomic.Int64
+	slowConsumerDisconnectCount atomic.Int64
+
+	compressCount      atomic.Int64
+	compressRawBytes   atomic.Int64
+	compressBytes      atomic.Int64
+	compressCostMicros atomic.Int64
 }
 
 func newAppMetrics(opts *Options) *appMetrics {
@@ -61,6 +71,13 @@ func newAppMetrics(opts *Options) *appMetrics {
 	connPacketCount := NewInt64ObservableCounter("app_conn_packet_count")
 	connackPacketBytes := NewInt64ObservableCounter("app_connack_packet_bytes")
 	connackPacketCount := NewInt64ObservableCounter("app_connack_packet_count")
+	slowConsumerCount := NewInt64ObservableCounter("app_slow_consumer_count")
+	slowConsumerDroppedCount := NewInt64ObservableCounter("app_slow_consumer_dropped_count")
+	slowConsumerDisconnectCount := NewInt64ObservableCounter("app_slow_consumer_disconnect_count")
+	compressCount := NewInt64ObservableCounter("app_compress_count")
+	compressRawBytes := NewInt64ObservableCounter("app_compress_raw_bytes")
+	compressBytes := NewInt64ObservableCounter("app_compress_bytes")
+	compressCostMicros := NewInt64ObservableCounter("app_compress_cost_micros")
 
 	RegisterCallback(func(ctx context.Context, obs metric.Observer) error {
 		obs.ObserveInt64(connCount, a.connCount.Load())
@@ -82,8 +99,15 @@ func newAppMetrics(opts *Options) *appMetrics {
 		obs.ObserveInt64(connPacketCount, a.connPacketCount.Load())
 		obs.ObserveInt64(connackPacketBytes, a.connackPacketBytes.Load())
 		obs.ObserveInt64(connackPacketCount, a.connackPacketCount.Load())
+		obs.ObserveInt64(slowConsumerCount, a.slowConsumerCount.Load())
+		obs.ObserveInt64(slowConsumerDroppedCount, a.slowConsumerDroppedCount.Load())
+		obs.ObserveInt64(slowConsumerDisconnectCount, a.slowConsumerDisconnectCount.Load())
+		obs.ObserveInt64(compressCount, a.compressCount.Load())
+		obs.ObserveInt64(compressRawBytes, a.compressRawBytes.Load())
+		obs.ObserveInt64(compressBytes, a.compressBytes.Load())
+		obs.ObserveInt64(compressCostMicros, a.compressCostMicros.Load())
 		return nil
-	}, connCount, onlineUserCount, onlineDeviceCount, pingBytes, pingCount, pongBytes, pongCount, sendPacketBytes, sendPacketCount, sendackPacketBytes, sendackPacketCount, recvPacketBytes, recvPacketCount, recvackPacketBytes, recvackPacketCount, connPacketBytes, connPacketCount, connackPacketBytes, connackPacketCount)
+	}, connCount, onlineUserCount, onlineDeviceCount, pingBytes, pingCount, pongBytes, pongCount, sendPacketBytes, sendPacketCount, sendackPacketBytes, sendackPacketCount, recvPacketBytes, recvPacketCount, recvackPacketBytes, recvackPacketCount, connPacketBytes, connPacketCount, connackPacketBytes, connackPacketCount, slowConsumerCount, slowConsumerDroppedCount, slowConsumerDisconnectCount, compressCount, compressRawBytes, compressBytes, compressCostMicros)
 	var err error
 	a.messageLatency, err = meter.Int64Histogram("app_message_latency", metric.WithDescription("The latency of message processing in the app layer"), metric.WithUnit("ms"))
 	if err != nil {
@@ -170,3 +194,45 @@ func (a *appMetrics) ConnackPacketBytesAdd(v int64) {
 func (a *appMetrics) ConnackPacketCountAdd(v int64) {
 	a.connackPacketCount.Add(v)
 }
+
+func (a *appMetrics) collectHistory(values map[string]historySample) {
+	values["app_conn_count_total"] = historyGauge(a.connCount.Load())
+	values["app_online_user_count_total"] = historyGauge(a.onlineUserCount.Load())
+	values["app_online_device_count_total"] = historyGauge(a.onlineDeviceCount.Load())
+
+	values["app_send_packet_count_total"] = historyRate(a.sendPacketCount.Load())
+	values["app_send_packet_bytes_total"] = historyRate(a.sendPacketBytes.Load())
+	values["app_sendack_packet_count_total"] = historyRate(a.sendackPacketCount.Load())
+	values["app_sendack_packet_bytes_total"] = historyRate(a.sendackPacketBytes.Load())
+	values["app_recv_packet_count_total"] = historyRate(a.recvPacketCount.Load())
+	values["app_recv_packet_bytes_total"] = historyRate(a.recvPacketBytes.Load())
+	values["app_recvack_packet_count_total"] = historyRate(a.recvackPacketCount.Load())
+	values["app_recvack_packet_bytes_total"] = historyRate(a.recvackPacketBytes.Load())
+	values["app_conn_packet_count_total"] = historyRate(a.connPacketCount.Load())
+	values["app_conn_packet_bytes_total"] = historyRate(a.connPacketBytes.Load())
+	values["app_connack_packet_count_total"] = historyRate(a.connackPacketCount.Load())
+	values["app_connack_packet_bytes_total"] = historyRate(a.connackPacketBytes.Load())
+	values["app_ping_count_total"] = historyRate(a.pingCount.Load())
+	values["app_ping_bytes_total"] = historyRate(a.pingBytes.Load())
+	values["app_pong_count_total"] = historyRate(a.pongCount.Load())
+	values["app_pong_bytes_total"] = historyRate(a.pongBytes.Load())
+}
+
+func (a *appMetrics) SlowConsumerCountAdd(v int64) {
+	a.slowConsumerCount.Add(v)
+}
+
+func (a *appMetrics) SlowConsumerDroppedCountAdd(v int64) {
+	a.slowConsumerDroppedCount.Add(v)
+}
+
+func (a *appMetrics) SlowConsumerDisconnectCountAdd(v int64) {
+	a.slowConsumerDisconnectCount.Add(v)
+}
+
+func (a *appMetrics) CompressAdd(rawBytes, compressedBytes int64, cost time.Duration) {
+	a.compressCount.Add(1)
+	a.compressRawBytes.Add(rawBytes)
+	a.compressBytes.Add(compressedBytes)
+	a.compressCostMicros.Add(cost.Microseconds())
+}
@@ -34,6 +34,12 @@ type clusterMetrics struct {
 
 	// channel
 	channelActiveCount metric.Int64UpDownCounter
+	channelActive      atomic.Int64 // 激活的频道数量（内置监控使用）
+
+	// channel repair
+	channelRepairLearnerAddCount    atomic.Int64
+	channelRepairReplicaRemoveCount atomic.Int64
+	channelUnderReplicatedCount     atomic.Int64
 
 	// channel log
 	channelLogIncomingBytes atomic.Int64
@@ -150,6 +156,17 @@ func newClusterMetrics(opts *Options) IClusterMetrics {
 		return nil
 	}, channelLogIncomingBytes, channelLogIncomingCount, channelLogOutgoingBytes, channelLogOutgoingCount)
 
+	// channel repair
+	channelRepairLearnerAddCount := NewInt64ObservableCounter("cluster_channel_repair_learner_add_count")
+	channelRepairReplicaRemoveCount := NewInt64ObservableCounter("cluster_channel_repair_replica_remove_count")
+	channelUnderReplicatedCount := NewInt64ObservableGauge("cluster_channel_under_replicated_count")
+	RegisterCallback(func(ctx context.Context, obs metric.Observer) error {
+		obs.ObserveInt64(channelRepairLearnerAddCount, c.channelRepairLearnerAddCount.Load())
+		obs.ObserveInt64(channelRepairReplicaRemoveCount, c.channelRepairReplicaRemoveCount.Load())
+		obs.ObserveInt64(channelUnderReplicatedCount, c.channelUnderReplicatedCount.Load())
+		return nil
+	}, channelRepairLearnerAddCount, channelRepairReplicaRemoveCount, channelUnderReplicatedCount)
+
 	// msg sync
 	msgSyncIncomingBytes := NewInt64ObservableCounter("cluster_msg_sync_incoming_bytes")
 	msgSyncOutgoingBytes := NewInt64ObservableCounter("cluster_msg_sync_outgoing_bytes")
@@ -482,6 +499,19 @@ func (c *clusterMetrics) ForwardConnPongCountAdd(v int64) {
 
 func (c *clusterMetrics) ChannelActiveCountAdd(v int64) {
 	c.channelActiveCount.Add(c.ctx, v)
+	c.channelActive.Add(v)
+}
+
+func (c *clusterMetrics) ChannelRepairLearnerAddCountAdd(v int64) {
+	c.channelRepairLearnerAddCount.Add(v)
+}
+
+func (c *clusterMetrics) ChannelRepairReplicaRemoveCountAdd(v int64) {
+	c.channelRepairReplicaRemoveCount.Add(v)
+}
+
+func (c *clusterMetrics) ChannelUnderReplicatedCountSet(v int64) {
+	c.channelUnderReplicatedCount.Store(v)
 }
 
 func (c *clusterMetrics) ChannelElectionCountAdd(v int64) {
@@ -530,3 +560,37 @@ func (c *clusterMetrics) ProposeFailedCountAdd(kind ClusterKind, v int64) {
 	case ClusterKindSlot:
 	}
 }
+
+func (c *clusterMetrics) collectHistory(values map[string]historySample) {
+	values["cluster_msg_incoming_count_total"] = historyRate(c.messageIncomingCount.Load())
+	values["cluster_msg_outgoing_count_total"] = historyRate(c.messageOutgoingCount.Load())
+	values["cluster_msg_incoming_bytes_total"] = historyRate(c.messageIncomingBytes.Load())
+	values["cluster_msg_outgoing_bytes_total"] = historyRate(c.messageOutgoingBytes.Load())
+
+	values["cluster_channel_msg_incoming_count_total"] = historyRate(c.channelMsgIncomingCount.Load())
+	values["cluster_channel_msg_outgoing_count_total"] = historyRate(c.channelMsgOutgoingCount.Load())
+	values["cluster_channel_msg_incoming_bytes_total"] = historyRate(c.channelMsgIncomingBytes.Load())
+	values["cluster_channel_msg_outgoing_bytes_total"] = historyRate(c.channelMsgOutgoingBytes.Load())
+
+	values["cluster_sendpacket_incoming_count_total"] = historyRate(c.sendPacketIncomingCount.Load())
+	values["cluster_sendpacket_incoming_bytes_total"] = historyRate(c.sendPacketIncomingBytes.Load())
+	values["cluster_sendpacket_outgoing_count_total"] = historyRate(c.sendPacketOutgoingCount.Load())
+	values["cluster_sendpacket_outgoing_bytes_total"] = historyRate(c.sendPacketOutgoingBytes.Load())
+
+	values["cluster_msg_sync_incoming_count_total"] = historyRate(c.msgSyncIncomingCount.Load())
+	values["cluster_msg_sync_outgoing_count_total"] = historyRate(c.msgSyncOutgoingCount.Load())
+	values["cluster_msg_sync_incoming_bytes_total"] = historyRate(c.msgSyncIncomingBytes.Load())
+	values["cluster_msg_sync_outgoing_bytes_total"] = historyRate(c.msgSyncOutgoingBytes.Load())
+
+	values["cluster_msg_ping_incoming_count_total"] = historyRate(c.clusterPingIncomingCount.Load())
+	values["cluster_msg_ping_outgoing_count_total"] = historyRate(c.clusterPingOutgoingCount.Load())
+	values["cluster_msg_ping_incoming_bytes_total"] = historyRate(c.clusterPingIncomingBytes.Load())
+	values["cluster_msg_ping_outgoing_bytes_total"] = historyRate(c.clusterPingOutgoingBytes.Load())
+
+	values["cluster_channel_active_count"] = historyGauge(c.channelActive.Load())
+
+	values["cluster_channel_propose_count_total"] = historyRate(c.channelProposeCount.Load())
+	values["cluster_channel_propose_failed_count_total"] = historyRate(c.channelProposeFailedCount.Load())
+	values["cluster_channel_propose_latency_over_500ms_total"] = historyRate(c.channelProposeLatencyOver500ms.Load())
+	values["cluster_channel_propose_latency_under_500ms_total"] = historyRate(c.channelProposeLatencyUnder500ms.Load())
+}
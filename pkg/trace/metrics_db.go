@@ -436,3 +436,15 @@ func (m *dbMetrics) LevelTablesMovedSet(shardId uint32, v int64) {
 func (m *dbMetrics) MessageAppendBatchCountAdd(v int64) {
 	m.messageAppendBatchCount.Add(v)
 }
+
+func (m *dbMetrics) collectHistory(values map[string]historySample) {
+	values["db_compact_estimated_debt"] = historyGauge(m.compactEstimatedDebt.Load())
+	values["db_compact_num_in_progress"] = historyGauge(m.compactNumInProgress.Load())
+	values["db_flush_bytes"] = historyGauge(m.flushBytes.Load())
+	values["db_memtable_size"] = historyGauge(m.memTableSize.Load())
+	values["db_table_cache_size"] = historyGauge(m.tableCacheSize.Load())
+	values["db_wal_size"] = historyGauge(m.walSize.Load())
+	values["db_disk_space_usage"] = historyGauge(m.diskSpaceUsage.Load())
+	values["db_wal_bytes_written"] = historyRate(m.walBytesWritten.Load())
+	values["db_message_append_batch_count_total"] = historyRate(m.messageAppendBatchCount.Load())
+}
@@ -24,11 +24,12 @@ type metrics struct {
 	system  ISystemMetrics
 	db      IDBMetrics
 	opts    *Options
+	history *metricsHistory // 内置监控历史（未开启时为nil）
 	wklog.Log
 }
 
 func newMetrics(opts *Options) *metrics {
-	return &metrics{
+	m := &metrics{
 		cluster: newClusterMetrics(opts),
 		app:     newAppMetrics(opts),
 		system:  newSystemMetrics(opts),
@@ -36,6 +37,22 @@ func newMetrics(opts *Options) *metrics {
 		opts:    opts,
 		Log:     wklog.NewWKLog("Metrics"),
 	}
+	if opts.MetricsHistoryOn {
+		m.history = newMetricsHistory(opts.MetricsHistoryInterval, opts.MetricsHistoryRetention, m.collectHistory)
+	}
+	return m
+}
+
+func (d *metrics) Start() {
+	if d.history != nil {
+		d.history.start()
+	}
+}
+
+func (d *metrics) Stop() {
+	if d.history != nil {
+		d.history.stop()
+	}
 }
 
 // System 系统监控
@@ -63,6 +80,7 @@ func (d *metrics) Route(r *wkhttp.WKHttp) {
 	r.GET("/metrics/app", d.appMetrics)         // 获取应用监控数据
 	r.GET("/metrics/cluster", d.clusterMetrics) // 获取集群监控数据
 	r.GET("/metrics/system", d.systemMetrics)   // 获取系统监控数据
+	r.GET("/metrics/db", d.dbMetrics)           // 获取数据库监控数据
 }
 
 func (d *metrics) appMetrics(c *wkhttp.Context) {
@@ -87,6 +105,11 @@ func (d *metrics) appMetrics(c *wkhttp.Context) {
 		filterId = strconv.FormatUint(nodeId, 10)
 	}
 
+	if !d.usePrometheus(latestTime) { // 没有外部prometheus时使用内置监控数据
+		c.JSON(http.StatusOK, d.appMetricsFromHistory(nodeId, latestTime.Unix()))
+		return
+	}
+
 	rg := v1.Range{
 		Start: latestTime,
 		End:   time.Now(),
@@ -156,6 +179,11 @@ func (d *metrics) clusterMetrics(c *wkhttp.Context) {
 		latestTime = time.Now().Add(-time.Second * time.Duration(latest))
 	}
 
+	if !d.usePrometheus(latestTime) {
+		c.JSON(http.StatusOK, d.clusterMetricsFromHistory(queryNodeId(c), latestTime.Unix()))
+		return
+	}
+
 	rg := v1.Range{
 		Start: latestTime,
 		End:   time.Now(),
@@ -227,6 +255,11 @@ func (d *metrics) systemMetrics(c *wkhttp.Context) {
 		latestTime = time.Now().Add(-time.Second * time.Duration(latest))
 	}
 
+	if !d.usePrometheus(latestTime) {
+		c.JSON(http.StatusOK, d.systemMetricsFromHistory(queryNodeId(c), latestTime.Unix()))
+		return
+	}
+
 	rg := v1.Range{
 		Start: latestTime,
 		End:   time.Now(),
@@ -262,6 +295,47 @@ func (d *metrics) systemMetrics(c *wkhttp.Context) {
 	c.JSON(http.StatusOK, resps)
 }
 
+func (d *metrics) dbMetrics(c *wkhttp.Context) {
+	latestStr := c.Query("latest")
+	var latest int64
+	if latestStr != "" {
+		latest, _ = strconv.ParseInt(latestStr, 10, 64)
+	}
+	var latestTime time.Time
+	if latest == 0 {
+		latestTime = time.Now().Add(-time.Minute * 5)
+	} else {
+		latestTime = time.Now().Add(-time.Second * time.Duration(latest))
+	}
+
+	if !d.usePrometheus(latestTime) {
+		c.JSON(http.StatusOK, d.dbMetricsFromHistory(queryNodeId(c), latestTime.Unix()))
+		return
+	}
+
+	rg := v1.Range{
+		Start: latestTime,
+		End:   time.Now(),
+		Step:  time.Second * 10,
+	}
+
+	var resps = make([]*dbMetricsResp, 0)
+	d.requestAndFillDBMetrics("db_compact_estimated_debt", rg, false, &resps)
+	d.requestAndFillDBMetrics("db_compact_num_in_progress", rg, false, &resps)
+	d.requestAndFillDBMetrics("db_flush_bytes", rg, false, &resps)
+	d.requestAndFillDBMetrics("db_memtable_size", rg, false, &resps)
+	d.requestAndFillDBMetrics("db_table_cache_size", rg, false, &resps)
+	d.requestAndFillDBMetrics("db_wal_size", rg, false, &resps)
+	d.requestAndFillDBMetrics("db_disk_space_usage", rg, false, &resps)
+	d.requestAndFillDBMetrics("db_wal_bytes_written", rg, true, &resps)
+	d.requestAndFillDBMetrics("db_message_append_batch_count_total", rg, true, &resps)
+
+	sort.Slice(resps, func(i, j int) bool {
+		return resps[i].Timestamp < resps[j].Timestamp
+	})
+	c.JSON(http.StatusOK, resps)
+}
+
 func (d *metrics) requestAndFillAppMetrics(label string, filterId string, rg v1.Range, rate bool, resps *[]*appMetricsResp) {
 	where := getLabelByFilterId(label, filterId)
 	if rate {
@@ -333,6 +407,23 @@ func (d *metrics) requestAndFillSystemMetrics(label string, rg v1.Range, rate bo
 	}
 }
 
+func (d *metrics) requestAndFillDBMetrics(label string, rg v1.Range, rate bool, resps *[]*dbMetricsResp) {
+	query := `rate(` + label + `[1m])`
+	if !rate {
+		query = label
+	}
+	countValue, err := d.opts.requestPrometheus(query, rg)
+	if err != nil {
+		d.Warn("request failed", zap.String("label", label), zap.Error(err))
+	}
+	if countValue != nil {
+		valueMatrix, ok := countValue.(model.Matrix)
+		if ok && len(valueMatrix) > 0 {
+			d.fillDBMetricsRespByMatrix(resps, label, valueMatrix)
+		}
+	}
+}
+
 func (d *metrics) getAppMetricsRespBySamplePair(label string, pair model.SamplePair) (*appMetricsResp, error) {
 	resp := &appMetricsResp{
 		ConnCount: int64(pair.Value),
@@ -441,6 +532,32 @@ func (d *metrics) fillSystemMetricsRespByMatrix(resps *[]*systemMetricsResp, lab
 	}
 }
 
+func (d *metrics) fillDBMetricsRespByMatrix(resps *[]*dbMetricsResp, label string, matrix model.Matrix) {
+
+	for _, v := range matrix {
+		nodeId := v.Metric["id"]
+		if nodeId == "" {
+			continue
+		}
+		for _, pair := range v.Values {
+			var newResp *dbMetricsResp
+			for _, resp := range *resps {
+				if resp.Timestamp == pair.Timestamp.Time().Unix() {
+					newResp = resp
+					break
+				}
+			}
+			if newResp == nil {
+				newResp = &dbMetricsResp{
+					Timestamp: pair.Timestamp.Time().Unix(),
+				}
+				*resps = append(*resps, newResp)
+			}
+			d.fillDBMetricsRespBySamplePair(newResp, label, string(nodeId), pair)
+		}
+	}
+}
+
 func (d *metrics) fillClusterMetricsRespBySamplePair(resp *clusterMetricsResp, label string, nodeId string, pair model.SamplePair) {
 	switch label {
 	case "cluster_msg_incoming_count_total":
@@ -521,6 +638,29 @@ func (d *metrics) fillSystemMetricsRespBySamplePair(resp *systemMetricsResp, lab
 
 }
 
+func (d *metrics) fillDBMetricsRespBySamplePair(resp *dbMetricsResp, label string, nodeId string, pair model.SamplePair) {
+	switch label {
+	case "db_compact_estimated_debt":
+		resp.CompactEstimatedDebt = append(resp.CompactEstimatedDebt, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	case "db_compact_num_in_progress":
+		resp.CompactNumInProgress = append(resp.CompactNumInProgress, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	case "db_flush_bytes":
+		resp.FlushBytes = append(resp.FlushBytes, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	case "db_memtable_size":
+		resp.MemTableSize = append(resp.MemTableSize, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	case "db_table_cache_size":
+		resp.TableCacheSize = append(resp.TableCacheSize, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	case "db_wal_size":
+		resp.WalSize = append(resp.WalSize, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	case "db_disk_space_usage":
+		resp.DiskSpaceUsage = append(resp.DiskSpaceUsage, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	case "db_wal_bytes_written":
+		resp.WalBytesWrittenRate = append(resp.WalBytesWrittenRate, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	case "db_message_append_batch_count_total":
+		resp.MessageAppendBatchCountRate = append(resp.MessageAppendBatchCountRate, &labelValue{Label: nodeId, Value: float64(pair.Value)})
+	}
+}
+
 func getLabelByFilterId(label string, filterId string) string {
 	if filterId != "" {
 		return label + `{id="` + filterId + `"}`
@@ -615,6 +755,21 @@ type systemMetricsResp struct {
 	Timestamp       int64         `json:"timestamp"`        // 时间戳
 }
 
+type dbMetricsResp struct {
+	CompactEstimatedDebt []*labelValue `json:"compact_estimated_debt"`  // 预估的待压缩字节数
+	CompactNumInProgress []*labelValue `json:"compact_num_in_progress"` // 正在进行的压缩数量
+	FlushBytes           []*labelValue `json:"flush_bytes"`             // flush的字节数
+	MemTableSize         []*labelValue `json:"memtable_size"`           // 内存表大小
+	TableCacheSize       []*labelValue `json:"table_cache_size"`        // 表缓存大小
+	WalSize              []*labelValue `json:"wal_size"`                // wal大小
+	DiskSpaceUsage       []*labelValue `json:"disk_space_usage"`        // 磁盘使用量
+
+	WalBytesWrittenRate         []*labelValue `json:"wal_bytes_written_rate"`          // wal写入字节数速率
+	MessageAppendBatchCountRate []*labelValue `json:"message_append_batch_count_rate"` // 消息批量追加次数速率
+
+	Timestamp int64 `json:"timestamp"` // 时间戳
+}
+
 type labelValue struct {
 	Label string  `json:"label"`
 	Value float64 `json:"value"`
@@ -0,0 +1,375 @@
+package trace
+
+import (
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+// MetricsPoint 内置监控的一个采样点
+type MetricsPoint struct {
+	Timestamp int64              `json:"timestamp"` // 采样时间（秒，已按采样间隔对齐）
+	Values    map[string]float64 `json:"values"`    // 指标名 -> 值（计数器为每秒速率）
+}
+
+type historyKind int
+
+const (
+	historyKindGauge    historyKind = iota // 原值
+	historyKindRate                        // 每秒速率
+	historyKindIncrease                    // 采样间隔内的增量
+)
+
+type historySample struct {
+	kind  historyKind
+	value float64
+}
+
+func historyGauge(v int64) historySample {
+	return historySample{kind: historyKindGauge, value: float64(v)}
+}
+
+func historyRate(v int64) historySample {
+	return historySample{kind: historyKindRate, value: float64(v)}
+}
+
+// historyCollector 能提供内置监控数据的指标
+type historyCollector interface {
+	collectHistory(values map[string]historySample)
+}
+
+// metricsHistory 内置的监控时序环形缓冲区，没有外部prometheus时为管理后台提供图表数据
+type metricsHistory struct {
+	interval time.Duration
+	points   []*MetricsPoint // 环形缓冲区
+	head     int             // 下一个写入位置
+	size     int
+	mu       sync.RWMutex
+
+	lastRaw  map[string]float64 // 上一次采样的原始值（用于计算速率）
+	lastTime time.Time
+
+	collect func() map[string]historySample
+	stopC   chan struct{}
+	doneC   chan struct{}
+	wklog.Log
+}
+
+func newMetricsHistory(interval, retention time.Duration, collect func() map[string]historySample) *metricsHistory {
+	if interval <= 0 {
+		interval = time.Second * 10
+	}
+	capacity := int(retention / interval)
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &metricsHistory{
+		interval: interval,
+		points:   make([]*MetricsPoint, capacity),
+		collect:  collect,
+		Log:      wklog.NewWKLog("metricsHistory"),
+	}
+}
+
+func (m *metricsHistory) start() {
+	m.stopC = make(chan struct{})
+	m.doneC = make(chan struct{})
+	go m.loop()
+}
+
+func (m *metricsHistory) stop() {
+	if m.stopC == nil {
+		return
+	}
+	close(m.stopC)
+	<-m.doneC
+}
+
+func (m *metricsHistory) loop() {
+	defer close(m.doneC)
+	tk := time.NewTicker(m.interval)
+	defer tk.Stop()
+
+	m.sample(time.Now())
+	for {
+		select {
+		case now := <-tk.C:
+			m.sample(now)
+		case <-m.stopC:
+			return
+		}
+	}
+}
+
+func (m *metricsHistory) sample(now time.Time) {
+	defer func() {
+		if err := recover(); err != nil {
+			m.Error("sample metrics history panic", zap.Any("err", err))
+		}
+	}()
+	m.record(now, m.collect())
+}
+
+// record 记录一次采样，计数器类指标换算成速率（第一次采样只记录原始值）
+func (m *metricsHistory) record(now time.Time, samples map[string]historySample) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elapsed := now.Sub(m.lastTime).Seconds()
+	first := m.lastRaw == nil
+
+	values := make(map[string]float64, len(samples))
+	raws := make(map[string]float64, len(samples))
+	for name, sample := range samples {
+		if sample.kind == historyKindGauge {
+			values[name] = sample.value
+			continue
+		}
+		raws[name] = sample.value
+		if first || elapsed <= 0 {
+			continue
+		}
+		delta := sample.value - m.lastRaw[name]
+		if delta < 0 { // 计数器被重置
+			delta = 0
+		}
+		if sample.kind == historyKindRate {
+			values[name] = delta / elapsed
+		} else {
+			values[name] = delta
+		}
+	}
+	m.lastRaw = raws
+	m.lastTime = now
+
+	if first {
+		return
+	}
+
+	m.points[m.head] = &MetricsPoint{
+		Timestamp: now.Truncate(m.interval).Unix(),
+		Values:    values,
+	}
+	m.head = (m.head + 1) % len(m.points)
+	if m.size < len(m.points) {
+		m.size++
+	}
+}
+
+// since 获取start（秒）之后的采样点，按时间升序
+func (m *metricsHistory) since(start int64) []*MetricsPoint {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	points := make([]*MetricsPoint, 0, m.size)
+	for i := 0; i < m.size; i++ {
+		idx := (m.head - m.size + i + len(m.points)) % len(m.points)
+		p := m.points[idx]
+		if p.Timestamp < start {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// retention 缓冲区能保存的时长
+func (m *metricsHistory) retention() time.Duration {
+	return m.interval * time.Duration(len(m.points))
+}
+
+// collectRuntimeHistory go运行时相关的内置监控
+func collectRuntimeHistory(values map[string]historySample) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	values["go_memstats_alloc_bytes"] = historyGauge(int64(ms.Alloc))
+	values["go_goroutines"] = historyGauge(int64(runtime.NumGoroutine()))
+	values["go_gc_duration_seconds_count"] = historySample{kind: historyKindIncrease, value: float64(ms.NumGC)}
+}
+
+func (d *metrics) collectHistory() map[string]historySample {
+	values := make(map[string]historySample, 64)
+	for _, m := range []interface{}{d.app, d.cluster, d.system, d.db} {
+		if c, ok := m.(historyCollector); ok {
+			c.collectHistory(values)
+		}
+	}
+	return values
+}
+
+// History 当前节点内置监控中start（秒）之后的采样点
+func (d *metrics) History(start int64) []*MetricsPoint {
+	if d.history == nil {
+		return nil
+	}
+	return d.history.since(start)
+}
+
+// usePrometheus 是否从外部prometheus获取图表数据
+// 只有配置了prometheus，并且内置监控未开启或查询范围超出内置监控的保留时长时才使用prometheus
+func (d *metrics) usePrometheus(start time.Time) bool {
+	if !d.opts.prometheusOn() {
+		return false
+	}
+	if d.history == nil {
+		return true
+	}
+	return time.Since(start) > d.history.retention()+d.history.interval
+}
+
+// nodesHistory 获取节点的内置监控数据，nodeId为0表示所有节点
+func (d *metrics) nodesHistory(nodeId uint64, start int64) map[uint64][]*MetricsPoint {
+	results := make(map[uint64][]*MetricsPoint)
+	if nodeId == 0 || nodeId == d.opts.NodeId {
+		results[d.opts.NodeId] = d.History(start)
+	}
+	if nodeId != d.opts.NodeId && d.opts.RemoteMetricsHistory != nil {
+		for id, points := range d.opts.RemoteMetricsHistory(nodeId, start) {
+			results[id] = points
+		}
+	}
+	return results
+}
+
+func (d *metrics) appMetricsFromHistory(nodeId uint64, start int64) []*appMetricsResp {
+	// 应用指标按时间对齐后所有节点求和
+	sums := make(map[int64]map[string]float64)
+	for _, points := range d.nodesHistory(nodeId, start) {
+		for _, p := range points {
+			sum := sums[p.Timestamp]
+			if sum == nil {
+				sum = make(map[string]float64)
+				sums[p.Timestamp] = sum
+			}
+			for label, v := range p.Values {
+				if strings.HasPrefix(label, "app_") {
+					sum[label] += v
+				}
+			}
+		}
+	}
+	resps := make([]*appMetricsResp, 0, len(sums))
+	for timestamp, sum := range sums {
+		resp := &appMetricsResp{Timestamp: timestamp}
+		for label, v := range sum {
+			d.fillAppMetricsRespBySamplePair(resp, label, historySamplePair(timestamp, v))
+		}
+		resps = append(resps, resp)
+	}
+	sort.Slice(resps, func(i, j int) bool {
+		return resps[i].Timestamp < resps[j].Timestamp
+	})
+	return resps
+}
+
+func (d *metrics) clusterMetricsFromHistory(nodeId uint64, start int64) []*clusterMetricsResp {
+	respMap := make(map[int64]*clusterMetricsResp)
+	d.eachNodeHistory(nodeId, start, func(id string, timestamp int64, label string, v float64) {
+		resp := respMap[timestamp]
+		if resp == nil {
+			resp = &clusterMetricsResp{Timestamp: timestamp}
+			respMap[timestamp] = resp
+		}
+		d.fillClusterMetricsRespBySamplePair(resp, label, id, historySamplePair(timestamp, v))
+	}, "cluster_")
+	resps := make([]*clusterMetricsResp, 0, len(respMap))
+	for _, resp := range respMap {
+		resps = append(resps, resp)
+	}
+	sort.Slice(resps, func(i, j int) bool {
+		return resps[i].Timestamp < resps[j].Timestamp
+	})
+	return resps
+}
+
+func (d *metrics) systemMetricsFromHistory(nodeId uint64, start int64) []*systemMetricsResp {
+	respMap := make(map[int64]*systemMetricsResp)
+	fill := func(id string, timestamp int64, label string, v float64) {
+		resp := respMap[timestamp]
+		if resp == nil {
+			resp = &systemMetricsResp{Timestamp: timestamp}
+			respMap[timestamp] = resp
+		}
+		d.fillSystemMetricsRespBySamplePair(resp, label, id, historySamplePair(timestamp, v))
+	}
+	d.eachNodeHistory(nodeId, start, fill, "system_", "go_")
+	resps := make([]*systemMetricsResp, 0, len(respMap))
+	for _, resp := range respMap {
+		resps = append(resps, resp)
+	}
+	sort.Slice(resps, func(i, j int) bool {
+		return resps[i].Timestamp < resps[j].Timestamp
+	})
+	return resps
+}
+
+func (d *metrics) dbMetricsFromHistory(nodeId uint64, start int64) []*dbMetricsResp {
+	respMap := make(map[int64]*dbMetricsResp)
+	d.eachNodeHistory(nodeId, start, func(id string, timestamp int64, label string, v float64) {
+		resp := respMap[timestamp]
+		if resp == nil {
+			resp = &dbMetricsResp{Timestamp: timestamp}
+			respMap[timestamp] = resp
+		}
+		d.fillDBMetricsRespBySamplePair(resp, label, id, historySamplePair(timestamp, v))
+	}, "db_")
+	resps := make([]*dbMetricsResp, 0, len(respMap))
+	for _, resp := range respMap {
+		resps = append(resps, resp)
+	}
+	sort.Slice(resps, func(i, j int) bool {
+		return resps[i].Timestamp < resps[j].Timestamp
+	})
+	return resps
+}
+
+// eachNodeHistory 遍历节点内置监控中以prefixes开头的指标（按节点id排序，保证图表中节点顺序稳定）
+func (d *metrics) eachNodeHistory(nodeId uint64, start int64, f func(id string, timestamp int64, label string, v float64), prefixes ...string) {
+	nodesHistory := d.nodesHistory(nodeId, start)
+	nodeIds := make([]uint64, 0, len(nodesHistory))
+	for id := range nodesHistory {
+		nodeIds = append(nodeIds, id)
+	}
+	sort.Slice(nodeIds, func(i, j int) bool {
+		return nodeIds[i] < nodeIds[j]
+	})
+	for _, id := range nodeIds {
+		idStr := strconv.FormatUint(id, 10)
+		for _, p := range nodesHistory[id] {
+			for label, v := range p.Values {
+				if hasAnyPrefix(label, prefixes) {
+					f(idStr, p.Timestamp, label, v)
+				}
+			}
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func historySamplePair(timestamp int64, v float64) model.SamplePair {
+	return model.SamplePair{
+		Timestamp: model.TimeFromUnix(timestamp),
+		Value:     model.SampleValue(v),
+	}
+}
+
+func queryNodeId(c *wkhttp.Context) uint64 {
+	nodeId, _ := strconv.ParseUint(c.Query("node_id"), 10, 64)
+	return nodeId
+}
@@ -86,3 +86,11 @@ func (s *systemMetrics) DiskIOReadCountAdd(v int64) {
 func (s *systemMetrics) DiskIOWriteCountAdd(v int64) {
 
 }
+
+func (s *systemMetrics) collectHistory(values map[string]historySample) {
+	values["system_intranet_incoming_bytes_total"] = historyRate(s.intranetIncomingBytes.Load())
+	values["system_intranet_outgoing_bytes_total"] = historyRate(s.intranetOutgoingBytes.Load())
+	values["system_extranet_incoming_bytes_total"] = historyRate(s.extranetIncomingBytes.Load())
+	values["system_extranet_outgoing_bytes_total"] = historyRate(s.extranetOutgoingBytes.Load())
+	collectRuntimeHistory(values)
+}
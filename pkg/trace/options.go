@@ -2,6 +2,7 @@ package trace
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
@@ -19,6 +20,14 @@ type Options struct {
 	ServiceHostName  string
 	PrometheusApiUrl string
 	ReqTimeout       time.Duration
+	SampleRate       float64 // 链路采样率 0 ~ 1，子链路跟随父链路的采样结果
+	NodeId           uint64  // 当前节点id
+
+	MetricsHistoryOn        bool          // 是否开启内置监控历史（没有prometheus时管理后台的图表数据来源）
+	MetricsHistoryInterval  time.Duration // 内置监控采样间隔
+	MetricsHistoryRetention time.Duration // 内置监控保留时长
+	// RemoteMetricsHistory 获取其他节点start（秒）之后的内置监控数据，nodeId为0表示所有其他在线节点
+	RemoteMetricsHistory func(nodeId uint64, start int64) map[uint64][]*MetricsPoint
 
 	prometheusClient api.Client // prometheus client
 	prometheusApi    v1.API
@@ -30,8 +39,13 @@ func NewOptions(opt ...Option) *Options {
 		Endpoint:         "127.0.0.1:4318",
 		ServiceName:      "wukongim",
 		ServiceHostName:  "wukongim",
-		PrometheusApiUrl: "http://127.0.0.1:9090",
+		PrometheusApiUrl: "",
 		ReqTimeout:       5 * time.Second,
+		SampleRate:       1,
+
+		MetricsHistoryOn:        true,
+		MetricsHistoryInterval:  time.Second * 10,
+		MetricsHistoryRetention: time.Hour,
 	}
 
 	for _, o := range opt {
@@ -73,12 +87,53 @@ func WithReqTimeout(timeout time.Duration) Option {
 	}
 }
 
+func WithSampleRate(rate float64) Option {
+	return func(o *Options) {
+		o.SampleRate = rate
+	}
+}
+
+func WithNodeId(nodeId uint64) Option {
+	return func(o *Options) {
+		o.NodeId = nodeId
+	}
+}
+
+func WithMetricsHistoryOn(on bool) Option {
+	return func(o *Options) {
+		o.MetricsHistoryOn = on
+	}
+}
+
+func WithMetricsHistoryInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.MetricsHistoryInterval = interval
+	}
+}
+
+func WithMetricsHistoryRetention(retention time.Duration) Option {
+	return func(o *Options) {
+		o.MetricsHistoryRetention = retention
+	}
+}
+
+func WithRemoteMetricsHistory(f func(nodeId uint64, start int64) map[uint64][]*MetricsPoint) Option {
+	return func(o *Options) {
+		o.RemoteMetricsHistory = f
+	}
+}
+
 func WithTraceOn(on bool) Option {
 	return func(o *Options) {
 		o.TraceOn = on
 	}
 }
 
+// prometheusOn 是否配置了外部prometheus
+func (o *Options) prometheusOn() bool {
+	return strings.TrimSpace(o.PrometheusApiUrl) != ""
+}
+
 func (o *Options) requestPrometheus(query string, r v1.Range, opt ...v1.Option) (model.Value, error) {
 
 	if o.prometheusClient == nil {
@@ -44,7 +44,7 @@ func (t *Trace) setupOTelSDK(ctx context.Context, traceOn bool) (shutdown func(c
 	var meterProvider *metric.MeterProvider
 	if traceOn {
 		var tracerProvider *trace.TracerProvider
-		tracerProvider, err = newJaegerTraceProvider(ctx, t.opts.Endpoint, t.opts.ServiceName, t.opts.ServiceHostName)
+		tracerProvider, err = newJaegerTraceProvider(ctx, t.opts.Endpoint, t.opts.ServiceName, t.opts.ServiceHostName, t.opts.SampleRate)
 		if err != nil {
 			fmt.Println("newJaegerTraceProvider err---->", err)
 			handleErr(err)
@@ -72,7 +72,7 @@ func newPropagator() propagation.TextMapPropagator {
 	)
 }
 
-func newJaegerTraceProvider(ctx context.Context, endpoint string, serviceName, serviceHostname string) (*trace.TracerProvider, error) {
+func newJaegerTraceProvider(ctx context.Context, endpoint string, serviceName, serviceHostname string, sampleRate float64) (*trace.TracerProvider, error) {
 	// 创建一个使用 HTTP 协议连接本机Jaeger的 Exporter
 	traceExporter, err := otlptracehttp.New(ctx,
 		otlptracehttp.WithEndpoint(endpoint),
@@ -96,7 +96,7 @@ func newJaegerTraceProvider(ctx context.Context, endpoint string, serviceName, s
 	}
 	traceProvider := trace.NewTracerProvider(
 		trace.WithResource(res),
-		trace.WithSampler(trace.TraceIDRatioBased(1.0)), // 采样率
+		trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(sampleRate))), // 采样率（有父链路时跟随父链路）
 		trace.WithBatcher(traceExporter,
 			trace.WithBatchTimeout(time.Second*5)),
 	)
@@ -0,0 +1,82 @@
+package trace
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// W3C Trace Context（https://www.w3.org/TR/trace-context/）
+var traceContextPropagator = propagation.TraceContext{}
+
+const traceparentHeader = "traceparent"
+
+// Traceparent 获取上下文中链路的W3C traceparent，没有链路时返回空字符串
+func Traceparent(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	traceContextPropagator.Inject(ctx, carrier)
+	return carrier.Get(traceparentHeader)
+}
+
+// ContextWithTraceparent 将W3C traceparent作为远程父链路放入上下文
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	if traceparent == "" {
+		return ctx
+	}
+	return traceContextPropagator.Extract(ctx, propagation.MapCarrier{traceparentHeader: traceparent})
+}
+
+// ContextWithHTTPHeader 从http请求头中解析W3C链路（traceparent/tracestate）放入上下文
+func ContextWithHTTPHeader(ctx context.Context, header http.Header) context.Context {
+	return traceContextPropagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// ContextWithSpanOf 将from中的链路放入ctx（保留ctx的超时和取消）
+func ContextWithSpanOf(ctx context.Context, from context.Context) context.Context {
+	if from == nil {
+		return ctx
+	}
+	sc := trace.SpanContextFromContext(from)
+	if !sc.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, sc)
+}
+
+// TraceIDFromContext 获取上下文中的链路id，没有链路时返回空字符串
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// shouldSample 根据链路id判断是否采样（和sdk的TraceIDRatioBased算法一致，保证各节点对同一链路的采样结果相同）
+func (t *Trace) shouldSample(traceID trace.TraceID) bool {
+	if t == nil || !t.opts.TraceOn {
+		return false
+	}
+	rate := t.opts.SampleRate
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	upperBound := uint64(rate * (1 << 63))
+	x := binary.BigEndian.Uint64(traceID[8:16]) >> 1
+	return x < upperBound
+}
@@ -43,6 +43,7 @@ func New(ctx context.Context, opts *Options) *Trace {
 }
 
 func (t *Trace) Start() error {
+	t.Metrics.Start()
 	shutdown, err := t.setupOTelSDK(t.ctx, t.opts.TraceOn)
 	if err != nil {
 		return err
@@ -53,6 +54,7 @@ func (t *Trace) Start() error {
 
 func (t *Trace) Stop() {
 	t.Debug("stop...")
+	t.Metrics.Stop()
 	if !t.opts.TraceOn {
 		return
 	}
@@ -73,7 +75,7 @@ func (t *Trace) Route(r *wkhttp.WKHttp) {
 }
 
 func (t *Trace) StartSpan(ctx context.Context, name string) (context.Context, Span) {
-	if !t.opts.TraceOn {
+	if t == nil || !t.opts.TraceOn {
 		return ctx, emptySpan
 	}
 	ctx, span := tracer.Start(ctx, name)
@@ -184,10 +186,14 @@ type SpanContextConfig struct {
 }
 
 func (s SpanContextConfig) SpanContextConfig() trace.SpanContextConfig {
+	var flags trace.TraceFlags
+	if GlobalTrace.shouldSample(trace.TraceID(s.TraceID)) {
+		flags = trace.FlagsSampled
+	}
 	return trace.SpanContextConfig{
 		TraceID:    trace.TraceID(s.TraceID),
 		SpanID:     trace.SpanID(s.SpanID),
-		TraceFlags: trace.FlagsSampled,
+		TraceFlags: flags,
 		TraceState: trace.TraceState(s.TraceState),
 		Remote:     s.Remote,
 	}
@@ -29,3 +29,43 @@ func TestTraceStartSpan(t *testing.T) {
 	time.Sleep(time.Second * 10)
 
 }
+
+func TestTraceparentPropagation(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := trace.ContextWithTraceparent(context.Background(), traceparent)
+	require.Equal(t, traceparent, trace.Traceparent(ctx))
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.TraceIDFromContext(ctx))
+
+	// 保留目标上下文的取消，同时带上链路
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	spanCtx := trace.ContextWithSpanOf(timeoutCtx, ctx)
+	require.Equal(t, traceparent, trace.Traceparent(spanCtx))
+	_, ok := spanCtx.Deadline()
+	require.True(t, ok)
+
+	require.Equal(t, "", trace.Traceparent(context.Background()))
+}
+
+func TestMetricsHistory(t *testing.T) {
+	tr := trace.New(context.Background(), trace.NewOptions(
+		trace.WithMetricsHistoryInterval(time.Millisecond*100),
+		trace.WithMetricsHistoryRetention(time.Millisecond*300),
+	))
+	tr.Metrics.Start()
+	defer tr.Metrics.Stop()
+
+	tr.Metrics.App().ConnCountAdd(2)
+	tr.Metrics.App().SendPacketCountAdd(10)
+	time.Sleep(time.Millisecond * 550)
+
+	points := tr.Metrics.History(0)
+	// 环形缓冲区只保留最近的3个采样点
+	require.Len(t, points, 3)
+	for i := 1; i < len(points); i++ {
+		require.LessOrEqual(t, points[i-1].Timestamp, points[i].Timestamp)
+	}
+	last := points[len(points)-1]
+	require.Equal(t, float64(2), last.Values["app_conn_count_total"])
+	require.Contains(t, last.Values, "app_send_packet_count_total")
+}
@@ -0,0 +1,115 @@
+package wkdb
+
+import (
+	"math"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/cockroachdb/pebble"
+)
+
+func (wk *wukongDB) AppendCDCEvents(events []CDCEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	batch := wk.defaultShardDB().NewBatch()
+	defer batch.Close()
+	for _, event := range events {
+		data, err := event.Marshal()
+		if err != nil {
+			return err
+		}
+		if err = batch.Set(key.NewCDCEventKey(event.SlotId, event.Offset), data, wk.noSync); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(wk.sync)
+}
+
+func (wk *wukongDB) GetCDCEvents(slotId uint32, startOffset uint64, limit int) ([]CDCEvent, error) {
+	iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+		LowerBound: key.NewCDCEventKey(slotId, startOffset),
+		UpperBound: key.NewCDCEventKey(slotId, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	events := make([]CDCEvent, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var event CDCEvent
+		if err := event.Unmarshal(iter.Value()); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+		if limit > 0 && len(events) >= limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+func (wk *wukongDB) RemoveExpiredCDCEvents(slotId uint32, expireAt int64, limit int) (int, error) {
+	db := wk.defaultShardDB()
+	iter := db.NewIter(&pebble.IterOptions{
+		LowerBound: key.NewCDCEventKey(slotId, 0),
+		UpperBound: key.NewCDCEventKey(slotId, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	batch := db.NewBatch()
+	defer batch.Close()
+
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		var event CDCEvent
+		if err := event.Unmarshal(iter.Value()); err != nil {
+			return 0, err
+		}
+		if event.CreatedAt >= expireAt { // 事件按偏移量追加，后面的事件不会更早
+			break
+		}
+		if err := batch.Delete(iter.Key(), wk.noSync); err != nil {
+			return 0, err
+		}
+		count++
+		if limit > 0 && count >= limit {
+			break
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return count, batch.Commit(wk.sync)
+}
+
+func (wk *wukongDB) SetCDCConsumerOffset(consumer string, slotId uint32, offset uint64) error {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteString(consumer)
+	enc.WriteUint64(offset)
+	return wk.defaultShardDB().Set(key.NewCDCConsumerKey(consumer, slotId), enc.Bytes(), wk.sync)
+}
+
+func (wk *wukongDB) GetCDCConsumerOffset(consumer string, slotId uint32) (uint64, error) {
+	value, closer, err := wk.defaultShardDB().Get(key.NewCDCConsumerKey(consumer, slotId))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer closer.Close()
+
+	dec := wkproto.NewDecoder(value)
+	name, err := dec.String()
+	if err != nil {
+		return 0, err
+	}
+	if name != consumer { // hash冲突
+		return 0, nil
+	}
+	return dec.Uint64()
+}
+
+func (wk *wukongDB) RemoveCDCConsumerOffset(consumer string, slotId uint32) error {
+	return wk.defaultShardDB().Delete(key.NewCDCConsumerKey(consumer, slotId), wk.sync)
+}
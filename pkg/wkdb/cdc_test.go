@@ -0,0 +1,94 @@
+package wkdb_test
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCDCEvents(t *testing.T) {
+
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	events := []wkdb.CDCEvent{
+		{SlotId: 1, Offset: 10, Type: 1, Data: []byte("a"), CreatedAt: 100},
+		{SlotId: 1, Offset: 12, Type: 2, Data: []byte("b"), CreatedAt: 200},
+		{SlotId: 1, Offset: 15, Type: 3, Data: []byte("c"), CreatedAt: 300},
+		{SlotId: 2, Offset: 11, Type: 1, Data: []byte("d"), CreatedAt: 100},
+	}
+	err = d.AppendCDCEvents(events)
+	assert.NoError(t, err)
+
+	results, err := d.GetCDCEvents(1, 11, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, uint64(12), results[0].Offset)
+	assert.Equal(t, []byte("b"), results[0].Data)
+	assert.Equal(t, uint64(15), results[1].Offset)
+
+	results, err = d.GetCDCEvents(1, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, uint64(10), results[0].Offset)
+
+	count, err := d.RemoveExpiredCDCEvents(1, 250, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	results, err = d.GetCDCEvents(1, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, uint64(15), results[0].Offset)
+
+	results, err = d.GetCDCEvents(2, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+}
+
+func TestCDCConsumerOffset(t *testing.T) {
+
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	offset, err := d.GetCDCConsumerOffset("search", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), offset)
+
+	err = d.SetCDCConsumerOffset("search", 1, 100)
+	assert.NoError(t, err)
+	err = d.SetCDCConsumerOffset("analytics", 1, 50)
+	assert.NoError(t, err)
+
+	offset, err = d.GetCDCConsumerOffset("search", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), offset)
+
+	offset, err = d.GetCDCConsumerOffset("search", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), offset)
+
+	err = d.RemoveCDCConsumerOffset("search", 1)
+	assert.NoError(t, err)
+
+	offset, err = d.GetCDCConsumerOffset("search", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), offset)
+
+	offset, err = d.GetCDCConsumerOffset("analytics", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50), offset)
+}
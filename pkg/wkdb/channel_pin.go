@@ -0,0 +1,80 @@
+package wkdb
+
+import (
+	"math"
+	"sort"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+func (wk *wukongDB) AddOrUpdateChannelPin(pin ChannelPin) error {
+	data, err := pin.Marshal()
+	if err != nil {
+		return err
+	}
+	return wk.channelDb(pin.ChannelId, pin.ChannelType).Set(key.NewChannelPinKey(pin.ChannelId, pin.ChannelType, pin.MessageSeq), data, wk.sync)
+}
+
+func (wk *wukongDB) RemoveChannelPins(channelId string, channelType uint8, messageSeqs []uint64) error {
+	batch := wk.channelDb(channelId, channelType).NewBatch()
+	defer batch.Close()
+	for _, messageSeq := range messageSeqs {
+		if err := batch.Delete(key.NewChannelPinKey(channelId, channelType, messageSeq), wk.noSync); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(wk.sync)
+}
+
+func (wk *wukongDB) GetChannelPins(channelId string, channelType uint8) ([]ChannelPin, error) {
+	iter := wk.channelDb(channelId, channelType).NewIter(&pebble.IterOptions{
+		LowerBound: key.NewChannelPinKey(channelId, channelType, 0),
+		UpperBound: key.NewChannelPinKey(channelId, channelType, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	pins := make([]ChannelPin, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var pin ChannelPin
+		if err := pin.Unmarshal(iter.Value()); err != nil {
+			return nil, err
+		}
+		if pin.ChannelId != channelId || pin.ChannelType != channelType { // hash冲突
+			continue
+		}
+		pins = append(pins, pin)
+	}
+	sort.SliceStable(pins, func(i, j int) bool {
+		if pins[i].Sort == pins[j].Sort {
+			return pins[i].PinnedAt > pins[j].PinnedAt
+		}
+		return pins[i].Sort > pins[j].Sort
+	})
+	return pins, nil
+}
+
+func (wk *wukongDB) SetChannelAnnouncement(announcement ChannelAnnouncement) error {
+	data, err := announcement.Marshal()
+	if err != nil {
+		return err
+	}
+	return wk.channelDb(announcement.ChannelId, announcement.ChannelType).Set(key.NewChannelAnnouncementKey(announcement.ChannelId, announcement.ChannelType), data, wk.sync)
+}
+
+func (wk *wukongDB) GetChannelAnnouncement(channelId string, channelType uint8) (ChannelAnnouncement, error) {
+	value, closer, err := wk.channelDb(channelId, channelType).Get(key.NewChannelAnnouncementKey(channelId, channelType))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return EmptyChannelAnnouncement, ErrNotFound
+		}
+		return EmptyChannelAnnouncement, err
+	}
+	defer closer.Close()
+
+	var announcement ChannelAnnouncement
+	if err = announcement.Unmarshal(value); err != nil {
+		return EmptyChannelAnnouncement, err
+	}
+	return announcement, nil
+}
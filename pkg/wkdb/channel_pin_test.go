@@ -0,0 +1,77 @@
+package wkdb_test
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelPins(t *testing.T) {
+
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	pins := []wkdb.ChannelPin{
+		{ChannelId: "g1", ChannelType: 2, MessageSeq: 1, PinnedBy: "u1", PinnedAt: 100, Sort: 100},
+		{ChannelId: "g1", ChannelType: 2, MessageSeq: 2, PinnedBy: "u1", PinnedAt: 101, Sort: 300},
+		{ChannelId: "g1", ChannelType: 2, MessageSeq: 3, PinnedBy: "u2", PinnedAt: 102, Sort: 200},
+		{ChannelId: "g2", ChannelType: 2, MessageSeq: 1, PinnedBy: "u2", PinnedAt: 103, Sort: 103},
+	}
+	for _, pin := range pins {
+		err = d.AddOrUpdateChannelPin(pin)
+		assert.NoError(t, err)
+	}
+
+	results, err := d.GetChannelPins("g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, uint64(2), results[0].MessageSeq)
+	assert.Equal(t, uint64(3), results[1].MessageSeq)
+	assert.Equal(t, uint64(1), results[2].MessageSeq)
+	assert.Equal(t, "u2", results[1].PinnedBy)
+
+	err = d.RemoveChannelPins("g1", 2, []uint64{2, 3})
+	assert.NoError(t, err)
+
+	results, err = d.GetChannelPins("g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, uint64(1), results[0].MessageSeq)
+}
+
+func TestChannelAnnouncement(t *testing.T) {
+
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	_, err = d.GetChannelAnnouncement("g1", 2)
+	assert.Equal(t, wkdb.ErrNotFound, err)
+
+	announcement := wkdb.ChannelAnnouncement{
+		ChannelId:   "g1",
+		ChannelType: 2,
+		Content:     "hello",
+		UpdatedBy:   "u1",
+		UpdatedAt:   100,
+		Version:     1,
+	}
+	err = d.SetChannelAnnouncement(announcement)
+	assert.NoError(t, err)
+
+	result, err := d.GetChannelAnnouncement("g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, announcement, result)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	"github.com/cockroachdb/pebble"
 	"go.uber.org/zap"
 )
@@ -26,6 +27,11 @@ func (wk *wukongDB) AddOrUpdateConversations(uid string, conversations []Convers
 	batch := wk.shardDB(uid).NewBatch()
 	defer batch.Close()
 
+	version, err := wk.GetConversationVersion(uid)
+	if err != nil {
+		return err
+	}
+
 	for _, cn := range conversations {
 		oldConversation, err := wk.GetConversation(uid, cn.ChannelId, cn.ChannelType)
 		if err != nil && err != ErrNotFound {
@@ -45,14 +51,27 @@ func (wk *wukongDB) AddOrUpdateConversations(uid string, conversations []Convers
 		}
 
 		if exist {
-			cn.CreatedAt = nil // 更新时不更新创建时间
+			cn.CreatedAt = nil                   // 更新时不更新创建时间
+			cn.SetAttrs(oldConversation.Attrs()) // 用户属性只能通过SetConversationAttrs修改
+		} else {
+			// 会话重新创建，删除之前的删除墓碑
+			if err = wk.deleteConversationTombstone(uid, cn.ChannelId, cn.ChannelType, batch); err != nil {
+				return err
+			}
 		}
 
+		version++
+		cn.Version = version
+
 		if err := wk.writeConversation(cn, batch); err != nil {
 			return err
 		}
 	}
 
+	if err = wk.writeConversationVersion(uid, version, batch); err != nil {
+		return err
+	}
+
 	// err := wk.IncConversationCount(createCount)
 	// if err != nil {
 	// 	return err
@@ -61,6 +80,209 @@ func (wk *wukongDB) AddOrUpdateConversations(uid string, conversations []Convers
 	return batch.Commit(wk.sync)
 }
 
+// SetConversationAttrs 设置最近会话的用户属性（会话不存在则忽略）
+func (wk *wukongDB) SetConversationAttrs(uid string, attrs []ConversationAttrs) error {
+	batch := wk.shardDB(uid).NewBatch()
+	defer batch.Close()
+
+	version, err := wk.GetConversationVersion(uid)
+	if err != nil {
+		return err
+	}
+
+	for _, attr := range attrs {
+		conversation, err := wk.GetConversation(uid, attr.ChannelId, attr.ChannelType)
+		if err != nil {
+			if err == ErrNotFound {
+				continue
+			}
+			return err
+		}
+		if err = wk.deleteConversationIndex(conversation, batch); err != nil {
+			return err
+		}
+		version++
+		conversation.Version = version
+		conversation.SetAttrs(attr)
+		if err = wk.writeConversation(conversation, batch); err != nil {
+			return err
+		}
+	}
+
+	if err = wk.writeConversationVersion(uid, version, batch); err != nil {
+		return err
+	}
+	return batch.Commit(wk.sync)
+}
+
+// GetConversationVersion 获取用户最近会话的当前版本号
+func (wk *wukongDB) GetConversationVersion(uid string) (uint64, error) {
+	value, closer, err := wk.shardDB(uid).Get(key.NewConversationVersionKey(uid))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer closer.Close()
+	return wk.endian.Uint64(value), nil
+}
+
+func (wk *wukongDB) writeConversationVersion(uid string, version uint64, w pebble.Writer) error {
+	var versionBytes = make([]byte, 8)
+	wk.endian.PutUint64(versionBytes, version)
+	return w.Set(key.NewConversationVersionKey(uid), versionBytes, wk.noSync)
+}
+
+// GetConversationsByVersion 获取指定用户版本号大于version的最近会话（按版本号升序）
+func (wk *wukongDB) GetConversationsByVersion(uid string, tp ConversationType, version uint64, limit int) ([]Conversation, error) {
+	db := wk.shardDB(uid)
+	iter := db.NewIter(&pebble.IterOptions{
+		LowerBound: key.NewConversationSecondIndexKey(uid, key.TableConversation.SecondIndex.Version, version+1, 0),
+		UpperBound: key.NewConversationSecondIndexKey(uid, key.TableConversation.SecondIndex.Version, math.MaxUint64, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	conversations := make([]Conversation, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		id, _, _, err := key.ParseConversationSecondIndexKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		conversation, err := wk.getConversation(uid, id)
+		if err != nil {
+			if err == ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if conversation.Type != tp {
+			continue
+		}
+		conversations = append(conversations, conversation)
+		if limit > 0 && len(conversations) >= limit {
+			break
+		}
+	}
+	return conversations, nil
+}
+
+// GetConversationTombstones 获取指定用户版本号大于version的会话删除墓碑（按版本号升序）
+func (wk *wukongDB) GetConversationTombstones(uid string, version uint64) ([]ConversationTombstone, error) {
+	db := wk.shardDB(uid)
+	iter := db.NewIter(&pebble.IterOptions{
+		LowerBound: key.NewConversationTombstoneVersionKey(uid, version+1),
+		UpperBound: key.NewConversationTombstoneVersionKey(uid, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	tombstones := make([]ConversationTombstone, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		channelHash := wk.endian.Uint64(iter.Value())
+		value, closer, err := db.Get(key.NewConversationTombstoneUidKey(uid, channelHash))
+		if err != nil {
+			if err == pebble.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		var tombstone ConversationTombstone
+		err = tombstone.Unmarshal(value)
+		closer.Close()
+		if err != nil {
+			return nil, err
+		}
+		if tombstone.Uid != uid { // hash冲突
+			continue
+		}
+		tombstones = append(tombstones, tombstone)
+	}
+	return tombstones, nil
+}
+
+// RemoveExpiredConversationTombstones 清理删除时间早于deletedBefore的会话删除墓碑，并记录用户已清理的墓碑最大版本号
+func (wk *wukongDB) RemoveExpiredConversationTombstones(deletedBefore uint64, limit int) (int, error) {
+	count := 0
+	for _, db := range wk.dbs {
+		n, err := wk.removeExpiredConversationTombstones(db, deletedBefore, limit-count)
+		if err != nil {
+			return count, err
+		}
+		count += n
+		if limit > 0 && count >= limit {
+			break
+		}
+	}
+	return count, nil
+}
+
+func (wk *wukongDB) removeExpiredConversationTombstones(db *pebble.DB, deletedBefore uint64, limit int) (int, error) {
+	iter := db.NewIter(&pebble.IterOptions{
+		LowerBound: key.NewConversationTombstoneKey("", "", 0)[:4],
+		UpperBound: key.NewConversationTombstoneVersionKey("", 0)[:4],
+	})
+	defer iter.Close()
+
+	batch := db.NewBatch()
+	defer batch.Close()
+
+	count := 0
+	prunedVersions := make(map[string]uint64)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var tombstone ConversationTombstone
+		if err := tombstone.Unmarshal(iter.Value()); err != nil {
+			return 0, err
+		}
+		if tombstone.DeletedAt >= deletedBefore {
+			continue
+		}
+		if err := batch.Delete(iter.Key(), wk.noSync); err != nil {
+			return 0, err
+		}
+		if err := batch.Delete(key.NewConversationTombstoneVersionKey(tombstone.Uid, tombstone.Version), wk.noSync); err != nil {
+			return 0, err
+		}
+		if tombstone.Version > prunedVersions[tombstone.Uid] {
+			prunedVersions[tombstone.Uid] = tombstone.Version
+		}
+		count++
+		if limit > 0 && count >= limit {
+			break
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	for uid, prunedVersion := range prunedVersions {
+		oldPrunedVersion, err := wk.GetConversationPrunedVersion(uid)
+		if err != nil {
+			return 0, err
+		}
+		if prunedVersion <= oldPrunedVersion {
+			continue
+		}
+		var versionBytes = make([]byte, 8)
+		wk.endian.PutUint64(versionBytes, prunedVersion)
+		if err = batch.Set(key.NewConversationPrunedVersionKey(uid), versionBytes, wk.noSync); err != nil {
+			return 0, err
+		}
+	}
+	return count, batch.Commit(wk.sync)
+}
+
+// GetConversationPrunedVersion 获取用户已清理的会话删除墓碑的最大版本号，客户端版本号小于此值时无法增量同步
+func (wk *wukongDB) GetConversationPrunedVersion(uid string) (uint64, error) {
+	value, closer, err := wk.shardDB(uid).Get(key.NewConversationPrunedVersionKey(uid))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer closer.Close()
+	return wk.endian.Uint64(value), nil
+}
+
 // GetConversations 获取指定用户的最近会话
 func (wk *wukongDB) GetConversations(uid string) ([]Conversation, error) {
 
@@ -155,16 +377,7 @@ func (wk *wukongDB) getLastConversationIds(uid string, updatedAt uint64, limit i
 // DeleteConversation 删除最近会话
 func (wk *wukongDB) DeleteConversation(uid string, channelId string, channelType uint8) error {
 
-	batch := wk.shardDB(uid).NewBatch()
-	defer batch.Close()
-
-	err := wk.deleteConversation(uid, channelId, channelType, batch)
-	if err != nil {
-		return err
-	}
-
-	return batch.Commit(wk.sync)
-
+	return wk.DeleteConversations(uid, []Channel{{ChannelId: channelId, ChannelType: channelType}})
 }
 
 // DeleteConversations 批量删除最近会话
@@ -172,11 +385,25 @@ func (wk *wukongDB) DeleteConversations(uid string, channels []Channel) error {
 	batch := wk.shardDB(uid).NewBatch()
 	defer batch.Close()
 
+	version, err := wk.GetConversationVersion(uid)
+	if err != nil {
+		return err
+	}
+	oldVersion := version
+
 	for _, channel := range channels {
-		err := wk.deleteConversation(uid, channel.ChannelId, channel.ChannelType, batch)
+		deleted, err := wk.deleteConversation(uid, channel.ChannelId, channel.ChannelType, version+1, batch)
 		if err != nil {
 			return err
 		}
+		if deleted {
+			version++
+		}
+	}
+	if version != oldVersion {
+		if err = wk.writeConversationVersion(uid, version, batch); err != nil {
+			return err
+		}
 	}
 	return batch.Commit(wk.sync)
 }
@@ -213,26 +440,73 @@ func (wk *wukongDB) SearchConversation(req ConversationSearchReq) ([]Conversatio
 	return conversations, nil
 }
 
-func (wk *wukongDB) deleteConversation(uid string, channelId string, channelType uint8, w pebble.Writer) error {
+// deleteConversation 删除会话并写入删除墓碑，version为墓碑的版本号
+func (wk *wukongDB) deleteConversation(uid string, channelId string, channelType uint8, version uint64, w pebble.Writer) (bool, error) {
 	oldConversation, err := wk.GetConversation(uid, channelId, channelType)
 	if err != nil && err != ErrNotFound {
-		return err
+		return false, err
 	}
 	if IsEmptyConversation(oldConversation) {
-		return nil
+		return false, nil
 	}
 	// 删除索引
 	err = wk.deleteConversationIndex(oldConversation, w)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// 删除数据
 	err = w.DeleteRange(key.NewConversationColumnKey(uid, oldConversation.Id, key.MinColumnKey), key.NewConversationColumnKey(uid, oldConversation.Id, key.MaxColumnKey), wk.noSync)
 	if err != nil {
+		return false, err
+	}
+
+	// 写入删除墓碑（替换之前的墓碑）
+	if err = wk.deleteConversationTombstone(uid, channelId, channelType, w); err != nil {
+		return false, err
+	}
+	tombstone := ConversationTombstone{
+		Uid:         uid,
+		ChannelId:   channelId,
+		ChannelType: channelType,
+		Version:     version,
+		DeletedAt:   uint64(time.Now().Unix()),
+	}
+	data, err := tombstone.Marshal()
+	if err != nil {
+		return false, err
+	}
+	if err = w.Set(key.NewConversationTombstoneKey(uid, channelId, channelType), data, wk.noSync); err != nil {
+		return false, err
+	}
+	var channelHashBytes = make([]byte, 8)
+	wk.endian.PutUint64(channelHashBytes, key.ChannelIdToNum(channelId, channelType))
+	if err = w.Set(key.NewConversationTombstoneVersionKey(uid, version), channelHashBytes, wk.noSync); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteConversationTombstone 删除会话的删除墓碑和墓碑的版本号索引
+func (wk *wukongDB) deleteConversationTombstone(uid string, channelId string, channelType uint8, w pebble.Writer) error {
+	tombstoneKey := key.NewConversationTombstoneKey(uid, channelId, channelType)
+	value, closer, err := wk.shardDB(uid).Get(tombstoneKey)
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil
+		}
 		return err
 	}
-	return nil
+	var tombstone ConversationTombstone
+	err = tombstone.Unmarshal(value)
+	closer.Close()
+	if err != nil {
+		return err
+	}
+	if err = w.Delete(tombstoneKey, wk.noSync); err != nil {
+		return err
+	}
+	return w.Delete(key.NewConversationTombstoneVersionKey(uid, tombstone.Version), wk.noSync)
 }
 
 // GetConversation 获取指定用户的指定会话
@@ -430,6 +704,47 @@ func (wk *wukongDB) writeConversation(conversation Conversation, w pebble.Writer
 		}
 	}
 
+	// version
+	var versionBytes = make([]byte, 8)
+	wk.endian.PutUint64(versionBytes, conversation.Version)
+	if err = w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.Version), versionBytes, wk.noSync); err != nil {
+		return err
+	}
+
+	// pinned
+	if err = w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.Pinned), []byte{wkutil.BoolToUint8(conversation.Pinned)}, wk.noSync); err != nil {
+		return err
+	}
+
+	// muted
+	if err = w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.Muted), []byte{wkutil.BoolToUint8(conversation.Muted)}, wk.noSync); err != nil {
+		return err
+	}
+
+	// draft
+	if err = w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.Draft), []byte(conversation.Draft), wk.noSync); err != nil {
+		return err
+	}
+
+	// extra
+	if err = w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.Extra), []byte(conversation.Extra), wk.noSync); err != nil {
+		return err
+	}
+
+	// mentionCount
+	var mentionCountBytes = make([]byte, 4)
+	wk.endian.PutUint32(mentionCountBytes, conversation.MentionCount)
+	if err = w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.MentionCount), mentionCountBytes, wk.noSync); err != nil {
+		return err
+	}
+
+	// lastMentionSeq
+	var lastMentionSeqBytes = make([]byte, 8)
+	wk.endian.PutUint64(lastMentionSeqBytes, conversation.LastMentionSeq)
+	if err = w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.LastMentionSeq), lastMentionSeqBytes, wk.noSync); err != nil {
+		return err
+	}
+
 	// write index
 	if err = wk.writeConversationIndex(conversation, w); err != nil {
 		return err
@@ -467,6 +782,13 @@ func (wk *wukongDB) writeConversationIndex(conversation Conversation, w pebble.W
 		}
 	}
 
+	if conversation.Version > 0 {
+		// version second index
+		if err := w.Set(key.NewConversationSecondIndexKey(conversation.Uid, key.TableConversation.SecondIndex.Version, conversation.Version, conversation.Id), nil, wk.noSync); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -495,6 +817,13 @@ func (wk *wukongDB) deleteConversationIndex(conversation Conversation, w pebble.
 		}
 	}
 
+	if conversation.Version > 0 {
+		// version second index
+		if err := w.Delete(key.NewConversationSecondIndexKey(conversation.Uid, key.TableConversation.SecondIndex.Version, conversation.Version, conversation.Id), wk.noSync); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -551,6 +880,20 @@ func (wk *wukongDB) iterateConversation(iter *pebble.Iterator, iterFnc func(conv
 				t := time.Unix(tm/1e9, tm%1e9)
 				preConversation.UpdatedAt = &t
 			}
+		case key.TableConversation.Column.Version:
+			preConversation.Version = wk.endian.Uint64(iter.Value())
+		case key.TableConversation.Column.Pinned:
+			preConversation.Pinned = wkutil.Uint8ToBool(iter.Value()[0])
+		case key.TableConversation.Column.Muted:
+			preConversation.Muted = wkutil.Uint8ToBool(iter.Value()[0])
+		case key.TableConversation.Column.Draft:
+			preConversation.Draft = string(iter.Value())
+		case key.TableConversation.Column.Extra:
+			preConversation.Extra = string(iter.Value())
+		case key.TableConversation.Column.MentionCount:
+			preConversation.MentionCount = wk.endian.Uint32(iter.Value())
+		case key.TableConversation.Column.LastMentionSeq:
+			preConversation.LastMentionSeq = wk.endian.Uint64(iter.Value())
 
 		}
 		hasData = true
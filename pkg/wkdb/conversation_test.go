@@ -64,14 +64,16 @@ func TestGetConversations(t *testing.T) {
 	updatedAt := time.Now()
 	conversations := []wkdb.Conversation{
 		{
-			Id:           1,
-			Uid:          uid,
-			ChannelId:    "1234",
-			ChannelType:  1,
-			UnreadCount:  20,
-			ReadToMsgSeq: 2,
-			CreatedAt:    &createdAt,
-			UpdatedAt:    &updatedAt,
+			Id:             1,
+			Uid:            uid,
+			ChannelId:      "1234",
+			ChannelType:    1,
+			UnreadCount:    20,
+			ReadToMsgSeq:   2,
+			MentionCount:   2,
+			LastMentionSeq: 5,
+			CreatedAt:      &createdAt,
+			UpdatedAt:      &updatedAt,
 		},
 		{
 			Id:           2,
@@ -98,6 +100,8 @@ func TestGetConversations(t *testing.T) {
 	assert.Equal(t, conversations[0].ChannelType, conversations2[0].ChannelType)
 	assert.Equal(t, conversations[0].UnreadCount, conversations2[0].UnreadCount)
 	assert.Equal(t, conversations[0].ReadToMsgSeq, conversations2[0].ReadToMsgSeq)
+	assert.Equal(t, conversations[0].MentionCount, conversations2[0].MentionCount)
+	assert.Equal(t, conversations[0].LastMentionSeq, conversations2[0].LastMentionSeq)
 	assert.Equal(t, conversations[0].CreatedAt.Unix(), conversations2[0].CreatedAt.Unix())
 	assert.Equal(t, conversations[0].UpdatedAt.Unix(), conversations2[0].UpdatedAt.Unix())
 
@@ -152,9 +156,172 @@ func TestDeleteConversation(t *testing.T) {
 
 	assert.Len(t, conversations2, 1)
 	conversations[1].Id = conversations2[0].Id
+	conversations[1].Version = 2
 	assert.Equal(t, conversations[1], conversations2[0])
 }
 
+func TestGetConversationsByVersion(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	uid := "test1"
+	err = d.AddOrUpdateConversations(uid, []wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "1234", ChannelType: 1},
+		{Id: 2, Uid: uid, ChannelId: "5678", ChannelType: 1},
+	})
+	assert.NoError(t, err)
+
+	version, err := d.GetConversationVersion(uid)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+
+	// 更新一个会话，只有它的版本号会增加
+	err = d.AddOrUpdateConversations(uid, []wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "1234", ChannelType: 1, UnreadCount: 5},
+	})
+	assert.NoError(t, err)
+
+	conversations, err := d.GetConversationsByVersion(uid, wkdb.ConversationTypeChat, 2, 0)
+	assert.NoError(t, err)
+	assert.Len(t, conversations, 1)
+	assert.Equal(t, "1234", conversations[0].ChannelId)
+	assert.Equal(t, uint64(3), conversations[0].Version)
+	assert.Equal(t, uint32(5), conversations[0].UnreadCount)
+
+	// 删除会话产生墓碑
+	err = d.DeleteConversation(uid, "5678", 1)
+	assert.NoError(t, err)
+
+	tombstones, err := d.GetConversationTombstones(uid, 3)
+	assert.NoError(t, err)
+	assert.Len(t, tombstones, 1)
+	assert.Equal(t, "5678", tombstones[0].ChannelId)
+	assert.Equal(t, uint64(4), tombstones[0].Version)
+
+	tombstones, err = d.GetConversationTombstones(uid, 4)
+	assert.NoError(t, err)
+	assert.Len(t, tombstones, 0)
+
+	// 会话重新创建后墓碑被清除
+	err = d.AddOrUpdateConversations(uid, []wkdb.Conversation{
+		{Id: 2, Uid: uid, ChannelId: "5678", ChannelType: 1},
+	})
+	assert.NoError(t, err)
+
+	tombstones, err = d.GetConversationTombstones(uid, 0)
+	assert.NoError(t, err)
+	assert.Len(t, tombstones, 0)
+}
+
+func TestRemoveExpiredConversationTombstones(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	uid := "test1"
+	err = d.AddOrUpdateConversations(uid, []wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "1234", ChannelType: 1},
+		{Id: 2, Uid: uid, ChannelId: "5678", ChannelType: 1},
+	})
+	assert.NoError(t, err)
+
+	err = d.DeleteConversation(uid, "1234", 1)
+	assert.NoError(t, err)
+
+	// 重新创建后再删除，旧墓碑的版本号索引被替换
+	err = d.AddOrUpdateConversations(uid, []wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "1234", ChannelType: 1},
+	})
+	assert.NoError(t, err)
+	err = d.DeleteConversation(uid, "1234", 1)
+	assert.NoError(t, err)
+	err = d.DeleteConversation(uid, "5678", 1)
+	assert.NoError(t, err)
+
+	tombstones, err := d.GetConversationTombstones(uid, 0)
+	assert.NoError(t, err)
+	assert.Len(t, tombstones, 2)
+	assert.Equal(t, "1234", tombstones[0].ChannelId)
+	assert.Equal(t, uint64(5), tombstones[0].Version)
+	assert.Equal(t, "5678", tombstones[1].ChannelId)
+	assert.Equal(t, uint64(6), tombstones[1].Version)
+
+	// 未过期的墓碑不清理
+	count, err := d.RemoveExpiredConversationTombstones(uint64(time.Now().Add(-time.Hour).Unix()), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	count, err = d.RemoveExpiredConversationTombstones(uint64(time.Now().Add(time.Hour).Unix()), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	tombstones, err = d.GetConversationTombstones(uid, 0)
+	assert.NoError(t, err)
+	assert.Len(t, tombstones, 0)
+
+	prunedVersion, err := d.GetConversationPrunedVersion(uid)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), prunedVersion)
+}
+
+func TestSetConversationAttrs(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	uid := "test1"
+	err = d.AddOrUpdateConversations(uid, []wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "1234", ChannelType: 1},
+	})
+	assert.NoError(t, err)
+
+	err = d.SetConversationAttrs(uid, []wkdb.ConversationAttrs{
+		{ChannelId: "1234", ChannelType: 1, Pinned: true, Muted: true, Draft: "draft", Extra: `{"a":1}`},
+		{ChannelId: "notexist", ChannelType: 1, Pinned: true},
+	})
+	assert.NoError(t, err)
+
+	conversation, err := d.GetConversation(uid, "1234", 1)
+	assert.NoError(t, err)
+	assert.True(t, conversation.Pinned)
+	assert.True(t, conversation.Muted)
+	assert.Equal(t, "draft", conversation.Draft)
+	assert.Equal(t, `{"a":1}`, conversation.Extra)
+	assert.Equal(t, uint64(2), conversation.Version)
+
+	// 普通的会话更新不会覆盖用户属性
+	err = d.AddOrUpdateConversations(uid, []wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "1234", ChannelType: 1, ReadToMsgSeq: 10},
+	})
+	assert.NoError(t, err)
+
+	conversation, err = d.GetConversation(uid, "1234", 1)
+	assert.NoError(t, err)
+	assert.True(t, conversation.Pinned)
+	assert.Equal(t, "draft", conversation.Draft)
+	assert.Equal(t, uint64(10), conversation.ReadToMsgSeq)
+	assert.Equal(t, uint64(3), conversation.Version)
+
+	_, err = d.GetConversation(uid, "notexist", 1)
+	assert.Equal(t, wkdb.ErrNotFound, err)
+}
+
 // func TestGetConversationBySessionIds(t *testing.T) {
 // 	d := newTestDB(t)
 // 	err := d.Open()
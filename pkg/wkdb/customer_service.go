@@ -0,0 +1,132 @@
+package wkdb
+
+import (
+	"sort"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+// 客服队列和队列下的会话存储在同一个分片内
+
+func (wk *wukongDB) AddOrUpdateCustomerServiceQueue(queue CustomerServiceQueue) error {
+	data, err := queue.Marshal()
+	if err != nil {
+		return err
+	}
+	return wk.shardDB(queue.QueueId).Set(key.NewCustomerServiceQueueKey(queue.QueueId), data, wk.sync)
+}
+
+func (wk *wukongDB) DeleteCustomerServiceQueue(queueId string) error {
+	db := wk.shardDB(queueId)
+	batch := db.NewBatch()
+	defer batch.Close()
+	if err := batch.Delete(key.NewCustomerServiceQueueKey(queueId), wk.noSync); err != nil {
+		return err
+	}
+	if err := batch.DeleteRange(key.NewCustomerServiceSessionLowKey(queueId), key.NewCustomerServiceSessionHighKey(queueId), wk.noSync); err != nil {
+		return err
+	}
+	return batch.Commit(wk.sync)
+}
+
+func (wk *wukongDB) GetCustomerServiceQueue(queueId string) (CustomerServiceQueue, error) {
+	value, closer, err := wk.shardDB(queueId).Get(key.NewCustomerServiceQueueKey(queueId))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return EmptyCustomerServiceQueue, ErrNotFound
+		}
+		return EmptyCustomerServiceQueue, err
+	}
+	defer closer.Close()
+
+	var queue CustomerServiceQueue
+	if err = queue.Unmarshal(value); err != nil {
+		return EmptyCustomerServiceQueue, err
+	}
+	if queue.QueueId != queueId { // hash冲突
+		return EmptyCustomerServiceQueue, ErrNotFound
+	}
+	return queue, nil
+}
+
+func (wk *wukongDB) GetCustomerServiceQueues() ([]CustomerServiceQueue, error) {
+	queues := make([]CustomerServiceQueue, 0)
+	for _, db := range wk.dbs {
+		iter := db.NewIter(&pebble.IterOptions{
+			LowerBound: key.NewCustomerServiceQueueLowKey(),
+			UpperBound: key.NewCustomerServiceQueueHighKey(),
+		})
+		for iter.First(); iter.Valid(); iter.Next() {
+			var queue CustomerServiceQueue
+			if err := queue.Unmarshal(iter.Value()); err != nil {
+				iter.Close()
+				return nil, err
+			}
+			queues = append(queues, queue)
+		}
+		iter.Close()
+	}
+	sort.Slice(queues, func(i, j int) bool {
+		return queues[i].QueueId < queues[j].QueueId
+	})
+	return queues, nil
+}
+
+func (wk *wukongDB) AddOrUpdateCustomerServiceSessions(sessions []CustomerServiceSession) error {
+	batchMap := make(map[*pebble.DB]*pebble.Batch)
+	defer func() {
+		for _, batch := range batchMap {
+			batch.Close()
+		}
+	}()
+	for _, session := range sessions {
+		data, err := session.Marshal()
+		if err != nil {
+			return err
+		}
+		db := wk.shardDB(session.QueueId)
+		batch := batchMap[db]
+		if batch == nil {
+			batch = db.NewBatch()
+			batchMap[db] = batch
+		}
+		if err = batch.Set(key.NewCustomerServiceSessionKey(session.QueueId, session.ChannelId), data, wk.noSync); err != nil {
+			return err
+		}
+	}
+	for _, batch := range batchMap {
+		if err := batch.Commit(wk.sync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wk *wukongDB) RemoveCustomerServiceSession(queueId string, channelId string) error {
+	return wk.shardDB(queueId).Delete(key.NewCustomerServiceSessionKey(queueId, channelId), wk.sync)
+}
+
+func (wk *wukongDB) GetCustomerServiceSessions(queueId string) ([]CustomerServiceSession, error) {
+	iter := wk.shardDB(queueId).NewIter(&pebble.IterOptions{
+		LowerBound: key.NewCustomerServiceSessionLowKey(queueId),
+		UpperBound: key.NewCustomerServiceSessionHighKey(queueId),
+	})
+	defer iter.Close()
+
+	sessions := make([]CustomerServiceSession, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var session CustomerServiceSession
+		if err := session.Unmarshal(iter.Value()); err != nil {
+			return nil, err
+		}
+		if session.QueueId != queueId { // hash冲突
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt < sessions[j].CreatedAt
+	})
+	return sessions, nil
+}
@@ -0,0 +1,103 @@
+package wkdb_test
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerServiceQueue(t *testing.T) {
+
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	_, err = d.GetCustomerServiceQueue("q1")
+	assert.Equal(t, wkdb.ErrNotFound, err)
+
+	queue := wkdb.CustomerServiceQueue{
+		QueueId:     "q1",
+		Name:        "售前",
+		Strategy:    "leastBusy",
+		MaxSessions: 5,
+		Agents:      []string{"a1", "a2"},
+		CreatedAt:   100,
+		UpdatedAt:   100,
+	}
+	err = d.AddOrUpdateCustomerServiceQueue(queue)
+	assert.NoError(t, err)
+	err = d.AddOrUpdateCustomerServiceQueue(wkdb.CustomerServiceQueue{QueueId: "q2", Agents: []string{}})
+	assert.NoError(t, err)
+
+	result, err := d.GetCustomerServiceQueue("q1")
+	assert.NoError(t, err)
+	assert.Equal(t, queue, result)
+
+	queues, err := d.GetCustomerServiceQueues()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(queues))
+	assert.Equal(t, "q1", queues[0].QueueId)
+	assert.Equal(t, "q2", queues[1].QueueId)
+
+	err = d.AddOrUpdateCustomerServiceSessions([]wkdb.CustomerServiceSession{
+		{QueueId: "q1", ChannelId: "v1|q1", VisitorUid: "v1", CreatedAt: 100},
+	})
+	assert.NoError(t, err)
+
+	err = d.DeleteCustomerServiceQueue("q1")
+	assert.NoError(t, err)
+
+	_, err = d.GetCustomerServiceQueue("q1")
+	assert.Equal(t, wkdb.ErrNotFound, err)
+
+	sessions, err := d.GetCustomerServiceSessions("q1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(sessions))
+}
+
+func TestCustomerServiceSessions(t *testing.T) {
+
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	err = d.AddOrUpdateCustomerServiceSessions([]wkdb.CustomerServiceSession{
+		{QueueId: "q1", ChannelId: "v2|q1", VisitorUid: "v2", CreatedAt: 200},
+		{QueueId: "q1", ChannelId: "v1|q1", VisitorUid: "v1", CreatedAt: 100},
+		{QueueId: "q2", ChannelId: "v3|q2", VisitorUid: "v3", CreatedAt: 300},
+	})
+	assert.NoError(t, err)
+
+	err = d.AddOrUpdateCustomerServiceSessions([]wkdb.CustomerServiceSession{
+		{QueueId: "q1", ChannelId: "v1|q1", VisitorUid: "v1", AgentUid: "a1", CreatedAt: 100, AssignedAt: 150},
+	})
+	assert.NoError(t, err)
+
+	sessions, err := d.GetCustomerServiceSessions("q1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(sessions))
+	assert.Equal(t, "v1|q1", sessions[0].ChannelId)
+	assert.Equal(t, "a1", sessions[0].AgentUid)
+	assert.False(t, sessions[0].Waiting())
+	assert.Equal(t, "v2|q1", sessions[1].ChannelId)
+	assert.True(t, sessions[1].Waiting())
+
+	err = d.RemoveCustomerServiceSession("q1", "v1|q1")
+	assert.NoError(t, err)
+
+	sessions, err = d.GetCustomerServiceSessions("q1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, "v2|q1", sessions[0].ChannelId)
+}
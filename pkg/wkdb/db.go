@@ -25,6 +25,18 @@ type DB interface {
 	TotalDB
 	//	系统账号
 	SystemUidDB
+	// 在线状态订阅
+	PresenceDB
+	// 定时消息
+	ScheduledMessageDB
+	// 子区
+	ThreadDB
+	// 频道置顶消息和公告
+	ChannelPinDB
+	// 变更数据捕获（CDC）
+	CDCDB
+	// 客服队列和会话
+	CustomerServiceDB
 }
 
 type MessageDB interface {
@@ -48,6 +60,8 @@ type MessageDB interface {
 	LoadNextRangeMsgsForSize(channelId string, channelType uint8, startMessageSeq, endMessageSeq uint64, limitSize uint64) ([]Message, error)
 	// LoadMsg 加载指定seq的消息
 	LoadMsg(channelId string, channelType uint8, seq uint64) (Message, error)
+	// LoadMsgByClientMsgNo 加载发送者在频道内指定clientMsgNo的最新一条消息
+	LoadMsgByClientMsgNo(channelId string, channelType uint8, fromUid string, clientMsgNo string) (Message, error)
 	// // TruncateLogTo 截断消息, 从messageSeq开始截断,messageSeq=0 表示清空所有日志 （保留下来的内容包含messageSeq）
 	TruncateLogTo(channelId string, channelType uint8, messageSeq uint64) error
 
@@ -111,6 +125,12 @@ type UserDB interface {
 
 	// UpdateUser 更新用户
 	UpdateUser(u User) error
+
+	// UpdateUserLastSeen 更新用户最后一次上线（online为true）或离线时间，只写对应的列
+	UpdateUserLastSeen(uid string, online bool, at uint64) error
+
+	// UpdateUserPresenceHidden 更新用户是否隐藏在线状态，只写对应的列
+	UpdateUserPresenceHidden(uid string, hidden bool) error
 }
 
 type ChannelDB interface {
@@ -213,6 +233,24 @@ type ConversationDB interface {
 
 	// SearchConversation 搜索最近会话
 	SearchConversation(req ConversationSearchReq) ([]Conversation, error)
+
+	// SetConversationAttrs 设置最近会话的用户属性（置顶、免打扰、草稿、扩展数据）
+	SetConversationAttrs(uid string, attrs []ConversationAttrs) error
+
+	// GetConversationVersion 获取用户最近会话的当前版本号
+	GetConversationVersion(uid string) (uint64, error)
+
+	// GetConversationsByVersion 获取指定用户版本号大于version的最近会话
+	GetConversationsByVersion(uid string, tp ConversationType, version uint64, limit int) ([]Conversation, error)
+
+	// GetConversationTombstones 获取指定用户版本号大于version的会话删除墓碑
+	GetConversationTombstones(uid string, version uint64) ([]ConversationTombstone, error)
+
+	// RemoveExpiredConversationTombstones 清理删除时间早于deletedBefore（10位时间戳）的会话删除墓碑，返回清理的数量
+	RemoveExpiredConversationTombstones(deletedBefore uint64, limit int) (int, error)
+
+	// GetConversationPrunedVersion 获取用户已清理的会话删除墓碑的最大版本号
+	GetConversationPrunedVersion(uid string) (uint64, error)
 }
 
 type ChannelClusterConfigDB interface {
@@ -338,6 +376,82 @@ type SystemUidDB interface {
 	GetSystemUids() ([]string, error)
 }
 
+type PresenceDB interface {
+	// AddPresenceSubscribers 添加在线状态订阅者（订阅者将收到uid的上下线通知）
+	AddPresenceSubscribers(uid string, subscribers []string) error
+	// RemovePresenceSubscribers 移除在线状态订阅者
+	RemovePresenceSubscribers(uid string, subscribers []string) error
+	// GetPresenceSubscribers 获取订阅了uid在线状态的用户
+	GetPresenceSubscribers(uid string) ([]string, error)
+}
+
+type ScheduledMessageDB interface {
+	// AddOrUpdateScheduledMessage 添加或更新定时消息
+	AddOrUpdateScheduledMessage(m ScheduledMessage) error
+	// GetScheduledMessage 获取指定id的定时消息
+	GetScheduledMessage(id uint64) (ScheduledMessage, error)
+	// RemoveScheduledMessages 移除频道的定时消息（不属于此频道的id会被忽略，channelId为空时不校验）
+	RemoveScheduledMessages(channelId string, channelType uint8, ids []uint64) error
+	// GetDueScheduledMessages 获取发送时间小于等于sendAt的定时消息（按发送时间、id升序），只返回排在(offsetSendAt, offsetId)之后的
+	GetDueScheduledMessages(sendAt uint64, offsetSendAt uint64, offsetId uint64, limit int) ([]ScheduledMessage, error)
+	// GetScheduledMessages 获取频道的定时消息 channelId为空表示获取所有
+	GetScheduledMessages(channelId string, channelType uint8, limit int) ([]ScheduledMessage, error)
+}
+
+type ThreadDB interface {
+	// AddThreadReplies 添加子区的回复（子区不存在则创建）
+	AddThreadReplies(channelId string, channelType uint8, rootMessageSeq uint64, replies []ThreadReply) error
+	// GetThread 获取子区
+	GetThread(channelId string, channelType uint8, rootMessageSeq uint64) (Thread, error)
+	// GetThreads 获取频道的子区（按最后回复时间降序）
+	GetThreads(channelId string, channelType uint8, limit int) ([]Thread, error)
+}
+
+type ChannelPinDB interface {
+	// AddOrUpdateChannelPin 添加或更新频道置顶消息
+	AddOrUpdateChannelPin(pin ChannelPin) error
+	// RemoveChannelPins 移除频道置顶消息
+	RemoveChannelPins(channelId string, channelType uint8, messageSeqs []uint64) error
+	// GetChannelPins 获取频道置顶消息（按排序号降序）
+	GetChannelPins(channelId string, channelType uint8) ([]ChannelPin, error)
+	// SetChannelAnnouncement 设置频道公告
+	SetChannelAnnouncement(announcement ChannelAnnouncement) error
+	// GetChannelAnnouncement 获取频道公告
+	GetChannelAnnouncement(channelId string, channelType uint8) (ChannelAnnouncement, error)
+}
+
+type CustomerServiceDB interface {
+	// AddOrUpdateCustomerServiceQueue 添加或更新客服队列
+	AddOrUpdateCustomerServiceQueue(queue CustomerServiceQueue) error
+	// DeleteCustomerServiceQueue 删除客服队列和队列下的所有会话
+	DeleteCustomerServiceQueue(queueId string) error
+	// GetCustomerServiceQueue 获取客服队列
+	GetCustomerServiceQueue(queueId string) (CustomerServiceQueue, error)
+	// GetCustomerServiceQueues 获取本节点存储的所有客服队列
+	GetCustomerServiceQueues() ([]CustomerServiceQueue, error)
+	// AddOrUpdateCustomerServiceSessions 添加或更新客服会话
+	AddOrUpdateCustomerServiceSessions(sessions []CustomerServiceSession) error
+	// RemoveCustomerServiceSession 移除客服会话
+	RemoveCustomerServiceSession(queueId string, channelId string) error
+	// GetCustomerServiceSessions 获取客服队列下的所有会话（按创建时间升序）
+	GetCustomerServiceSessions(queueId string) ([]CustomerServiceSession, error)
+}
+
+type CDCDB interface {
+	// AppendCDCEvents 追加变更事件（相同槽和偏移量的事件会被覆盖）
+	AppendCDCEvents(events []CDCEvent) error
+	// GetCDCEvents 获取槽内偏移量大于等于startOffset的变更事件（按偏移量升序）
+	GetCDCEvents(slotId uint32, startOffset uint64, limit int) ([]CDCEvent, error)
+	// RemoveExpiredCDCEvents 移除槽内创建时间早于expireAt（纳秒）的变更事件，返回移除的数量
+	RemoveExpiredCDCEvents(slotId uint32, expireAt int64, limit int) (int, error)
+	// SetCDCConsumerOffset 设置消费者在槽上已确认的偏移量
+	SetCDCConsumerOffset(consumer string, slotId uint32, offset uint64) error
+	// GetCDCConsumerOffset 获取消费者在槽上已确认的偏移量，没有确认过返回0
+	GetCDCConsumerOffset(consumer string, slotId uint32) (uint64, error)
+	// RemoveCDCConsumerOffset 移除消费者在槽上的偏移量
+	RemoveCDCConsumerOffset(consumer string, slotId uint32) error
+}
+
 type MessageSearchReq struct {
 	MessageId        int64
 	FromUid          string // 发送者uid
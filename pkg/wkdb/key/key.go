@@ -900,3 +900,261 @@ func NewSystemUidColumnKey(id uint64, columnName [2]byte) []byte {
 	key[13] = columnName[1]
 	return key
 }
+
+// ---------------------- presence subscriber ----------------------
+
+func NewPresenceSubscriberColumnKey(uid string, subscriberId uint64, columnName [2]byte) []byte {
+	key := make([]byte, TablePresenceSubscriber.Size)
+	key[0] = TablePresenceSubscriber.Id[0]
+	key[1] = TablePresenceSubscriber.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(uid))
+	binary.BigEndian.PutUint64(key[12:], subscriberId)
+	key[20] = columnName[0]
+	key[21] = columnName[1]
+	return key
+}
+
+// ---------------------- scheduled message ----------------------
+
+// NewScheduledMessageKey 定时消息的key（按发送时间排序）
+func NewScheduledMessageKey(sendAt uint64, id uint64) []byte {
+	key := make([]byte, TableScheduledMessage.Size)
+	key[0] = TableScheduledMessage.Id[0]
+	key[1] = TableScheduledMessage.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], sendAt)
+	binary.BigEndian.PutUint64(key[12:], id)
+	return key
+}
+
+// NewScheduledMessageIndexKey 定时消息id索引的key 值为发送时间
+func NewScheduledMessageIndexKey(id uint64) []byte {
+	key := make([]byte, TableScheduledMessage.IndexSize)
+	key[0] = TableScheduledMessage.Id[0]
+	key[1] = TableScheduledMessage.Id[1]
+	key[2] = dataTypeIndex
+	key[3] = 0
+	key[4] = TableScheduledMessage.Index.Id[0]
+	key[5] = TableScheduledMessage.Index.Id[1]
+	binary.BigEndian.PutUint64(key[6:], id)
+	return key
+}
+
+// NewScheduledMessageChannelIndexKey 定时消息频道索引的key（同一个频道的定时消息按id排序）
+func NewScheduledMessageChannelIndexKey(channelId string, channelType uint8, id uint64) []byte {
+	key := make([]byte, TableScheduledMessage.ChannelIndexSize)
+	key[0] = TableScheduledMessage.Id[0]
+	key[1] = TableScheduledMessage.Id[1]
+	key[2] = dataTypeIndex
+	key[3] = 0
+	key[4] = TableScheduledMessage.Index.Channel[0]
+	key[5] = TableScheduledMessage.Index.Channel[1]
+	binary.BigEndian.PutUint64(key[6:], channelIdToNum(channelId, channelType))
+	binary.BigEndian.PutUint64(key[14:], id)
+	return key
+}
+
+// ParseScheduledMessageChannelIndexKey 解析定时消息频道索引的key，返回定时消息id
+func ParseScheduledMessageChannelIndexKey(key []byte) (uint64, error) {
+	if len(key) != TableScheduledMessage.ChannelIndexSize {
+		return 0, fmt.Errorf("scheduled message channel index key length error")
+	}
+	return binary.BigEndian.Uint64(key[14:]), nil
+}
+
+// ---------------------- thread ----------------------
+
+// NewThreadKey 子区的key（同一个频道的子区按根消息序号排序）
+func NewThreadKey(channelId string, channelType uint8, rootMessageSeq uint64) []byte {
+	key := make([]byte, TableThread.Size)
+	key[0] = TableThread.Id[0]
+	key[1] = TableThread.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], channelIdToNum(channelId, channelType))
+	binary.BigEndian.PutUint64(key[12:], rootMessageSeq)
+	return key
+}
+
+// ---------------------- channel pin ----------------------
+
+// NewChannelPinKey 频道置顶消息的key
+func NewChannelPinKey(channelId string, channelType uint8, messageSeq uint64) []byte {
+	key := make([]byte, TableChannelPin.Size)
+	key[0] = TableChannelPin.Id[0]
+	key[1] = TableChannelPin.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], channelIdToNum(channelId, channelType))
+	binary.BigEndian.PutUint64(key[12:], messageSeq)
+	return key
+}
+
+// ---------------------- channel announcement ----------------------
+
+// NewChannelAnnouncementKey 频道公告的key
+func NewChannelAnnouncementKey(channelId string, channelType uint8) []byte {
+	key := make([]byte, TableChannelAnnouncement.Size)
+	key[0] = TableChannelAnnouncement.Id[0]
+	key[1] = TableChannelAnnouncement.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], channelIdToNum(channelId, channelType))
+	return key
+}
+
+// ---------------------- conversation tombstone ----------------------
+
+// NewConversationTombstoneKey 最近会话删除墓碑的key
+func NewConversationTombstoneKey(uid string, channelId string, channelType uint8) []byte {
+	key := make([]byte, TableConversationTombstone.Size)
+	key[0] = TableConversationTombstone.Id[0]
+	key[1] = TableConversationTombstone.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(uid))
+	binary.BigEndian.PutUint64(key[12:], channelIdToNum(channelId, channelType))
+	return key
+}
+
+// NewConversationTombstoneUidKey 用户最近会话删除墓碑的key前缀
+func NewConversationTombstoneUidKey(uid string, channelHash uint64) []byte {
+	key := make([]byte, TableConversationTombstone.Size)
+	key[0] = TableConversationTombstone.Id[0]
+	key[1] = TableConversationTombstone.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(uid))
+	binary.BigEndian.PutUint64(key[12:], channelHash)
+	return key
+}
+
+// NewConversationTombstoneVersionKey 用户最近会话删除墓碑的版本号索引key
+func NewConversationTombstoneVersionKey(uid string, version uint64) []byte {
+	key := make([]byte, TableConversationTombstone.Size)
+	key[0] = TableConversationTombstone.Id[0]
+	key[1] = TableConversationTombstone.Id[1]
+	key[2] = dataTypeIndex
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(uid))
+	binary.BigEndian.PutUint64(key[12:], version)
+	return key
+}
+
+// ---------------------- conversation version ----------------------
+
+// NewConversationVersionKey 用户最近会话版本号的key
+func NewConversationVersionKey(uid string) []byte {
+	key := make([]byte, TableConversationVersion.Size)
+	key[0] = TableConversationVersion.Id[0]
+	key[1] = TableConversationVersion.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(uid))
+	return key
+}
+
+// NewConversationPrunedVersionKey 用户已清理的会话删除墓碑最大版本号的key
+func NewConversationPrunedVersionKey(uid string) []byte {
+	key := make([]byte, TableConversationVersion.Size)
+	key[0] = TableConversationVersion.Id[0]
+	key[1] = TableConversationVersion.Id[1]
+	key[2] = dataTypeIndex
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(uid))
+	return key
+}
+
+// ---------------------- cdc event ----------------------
+
+// NewCDCEventKey 变更事件的key（同一个槽的事件按偏移量排序）
+func NewCDCEventKey(slotId uint32, offset uint64) []byte {
+	key := make([]byte, TableCDCEvent.Size)
+	key[0] = TableCDCEvent.Id[0]
+	key[1] = TableCDCEvent.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint32(key[4:], slotId)
+	binary.BigEndian.PutUint64(key[8:], offset)
+	return key
+}
+
+// ---------------------- cdc consumer ----------------------
+
+// NewCDCConsumerKey 变更事件消费者在某个槽上的消费偏移量的key
+func NewCDCConsumerKey(consumer string, slotId uint32) []byte {
+	key := make([]byte, TableCDCConsumer.Size)
+	key[0] = TableCDCConsumer.Id[0]
+	key[1] = TableCDCConsumer.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(consumer))
+	binary.BigEndian.PutUint32(key[12:], slotId)
+	return key
+}
+
+// ---------------------- customer service queue ----------------------
+
+// NewCustomerServiceQueueKey 客服队列的key
+func NewCustomerServiceQueueKey(queueId string) []byte {
+	key := make([]byte, TableCustomerServiceQueue.Size)
+	key[0] = TableCustomerServiceQueue.Id[0]
+	key[1] = TableCustomerServiceQueue.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(queueId))
+	return key
+}
+
+// NewCustomerServiceQueueLowKey 客服队列表的起始key
+func NewCustomerServiceQueueLowKey() []byte {
+	key := make([]byte, TableCustomerServiceQueue.Size)
+	key[0] = TableCustomerServiceQueue.Id[0]
+	key[1] = TableCustomerServiceQueue.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], 0)
+	return key
+}
+
+// NewCustomerServiceQueueHighKey 客服队列表的结束key
+func NewCustomerServiceQueueHighKey() []byte {
+	key := make([]byte, TableCustomerServiceQueue.Size)
+	key[0] = TableCustomerServiceQueue.Id[0]
+	key[1] = TableCustomerServiceQueue.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], math.MaxUint64)
+	return key
+}
+
+// ---------------------- customer service session ----------------------
+
+// NewCustomerServiceSessionKey 客服会话的key
+func NewCustomerServiceSessionKey(queueId string, channelId string) []byte {
+	return newCustomerServiceSessionKey(queueId, HashWithString(channelId))
+}
+
+// NewCustomerServiceSessionLowKey 客服队列下会话的起始key
+func NewCustomerServiceSessionLowKey(queueId string) []byte {
+	return newCustomerServiceSessionKey(queueId, 0)
+}
+
+// NewCustomerServiceSessionHighKey 客服队列下会话的结束key
+func NewCustomerServiceSessionHighKey(queueId string) []byte {
+	return newCustomerServiceSessionKey(queueId, math.MaxUint64)
+}
+
+func newCustomerServiceSessionKey(queueId string, channelHash uint64) []byte {
+	key := make([]byte, TableCustomerServiceSession.Size)
+	key[0] = TableCustomerServiceSession.Id[0]
+	key[1] = TableCustomerServiceSession.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(queueId))
+	binary.BigEndian.PutUint64(key[12:], channelHash)
+	return key
+}
@@ -119,6 +119,9 @@ var TableUser = struct {
 		RecvMsgBytes      [2]byte // 接受消息字节数量
 		CreatedAt         [2]byte // 创建时间
 		UpdatedAt         [2]byte // 更新时间
+		LastOnlineAt      [2]byte // 最后一次上线时间
+		LastOfflineAt     [2]byte // 最后一次离线时间
+		PresenceHidden    [2]byte // 是否隐藏在线状态
 	}
 	Index struct {
 		Uid [2]byte
@@ -143,6 +146,9 @@ var TableUser = struct {
 		RecvMsgBytes      [2]byte // 接受消息字节数量
 		CreatedAt         [2]byte
 		UpdatedAt         [2]byte
+		LastOnlineAt      [2]byte
+		LastOfflineAt     [2]byte
+		PresenceHidden    [2]byte
 	}{
 		Uid:               [2]byte{0x02, 0x01},
 		DeviceCount:       [2]byte{0x02, 0x02},
@@ -154,6 +160,9 @@ var TableUser = struct {
 		RecvMsgBytes:      [2]byte{0x02, 0x08},
 		CreatedAt:         [2]byte{0x02, 0x09},
 		UpdatedAt:         [2]byte{0x02, 0x0A},
+		LastOnlineAt:      [2]byte{0x02, 0x0B},
+		LastOfflineAt:     [2]byte{0x02, 0x0C},
+		PresenceHidden:    [2]byte{0x02, 0x0D},
 	},
 	Index: struct {
 		Uid [2]byte
@@ -490,6 +499,13 @@ var TableConversation = struct {
 		ReadedToMsgSeq [2]byte
 		CreatedAt      [2]byte
 		UpdatedAt      [2]byte
+		Version        [2]byte
+		Pinned         [2]byte
+		Muted          [2]byte
+		Draft          [2]byte
+		Extra          [2]byte
+		MentionCount   [2]byte
+		LastMentionSeq [2]byte
 	}
 	Index struct {
 		Channel [2]byte
@@ -498,6 +514,7 @@ var TableConversation = struct {
 		Type      [2]byte
 		CreatedAt [2]byte
 		UpdatedAt [2]byte
+		Version   [2]byte
 	}
 }{
 	Id:              [2]byte{0x09, 0x01},
@@ -513,6 +530,13 @@ var TableConversation = struct {
 		ReadedToMsgSeq [2]byte
 		CreatedAt      [2]byte
 		UpdatedAt      [2]byte
+		Version        [2]byte
+		Pinned         [2]byte
+		Muted          [2]byte
+		Draft          [2]byte
+		Extra          [2]byte
+		MentionCount   [2]byte
+		LastMentionSeq [2]byte
 	}{
 		Uid:            [2]byte{0x09, 0x01},
 		ChannelId:      [2]byte{0x09, 0x02},
@@ -522,6 +546,13 @@ var TableConversation = struct {
 		ReadedToMsgSeq: [2]byte{0x09, 0x06},
 		CreatedAt:      [2]byte{0x09, 0x07},
 		UpdatedAt:      [2]byte{0x09, 0x08},
+		Version:        [2]byte{0x09, 0x09},
+		Pinned:         [2]byte{0x09, 0x0A},
+		Muted:          [2]byte{0x09, 0x0B},
+		Draft:          [2]byte{0x09, 0x0C},
+		Extra:          [2]byte{0x09, 0x0D},
+		MentionCount:   [2]byte{0x09, 0x0E},
+		LastMentionSeq: [2]byte{0x09, 0x0F},
 	},
 	Index: struct {
 		Channel [2]byte
@@ -532,10 +563,12 @@ var TableConversation = struct {
 		Type      [2]byte
 		CreatedAt [2]byte
 		UpdatedAt [2]byte
+		Version   [2]byte
 	}{
 		Type:      [2]byte{0x09, 0x01},
 		CreatedAt: [2]byte{0x09, 0x02},
 		UpdatedAt: [2]byte{0x09, 0x03},
+		Version:   [2]byte{0x09, 0x04},
 	},
 }
 
@@ -722,3 +755,185 @@ var TableSystemUid = struct {
 		Uid: [2]byte{0x10, 0x01},
 	},
 }
+
+// ======================== PresenceSubscriber ========================
+// ---------------------
+// | tableID  | dataType	| uid hash | subscriber hash   | columnKey |
+// | 2 byte   | 1 byte   	| 8 字节 	| 8 字节	   		| 2 字节		|
+// ---------------------
+
+var TablePresenceSubscriber = struct {
+	Id     [2]byte
+	Size   int
+	Column struct {
+		Uid [2]byte
+	}
+}{
+	Id:   [2]byte{0x11, 0x01},
+	Size: 2 + 2 + 8 + 8 + 2, // tableId + dataType  + uid hash + subscriber hash + columnKey
+	Column: struct {
+		Uid [2]byte
+	}{
+		Uid: [2]byte{0x11, 0x01},
+	},
+}
+
+// ======================== ScheduledMessage ========================
+// ---------------------
+// | tableID  | dataType	| sendAt   | primaryKey   |
+// | 2 byte   | 2 byte   	| 8 字节 	| 8 字节	   	|
+// ---------------------
+
+var TableScheduledMessage = struct {
+	Id               [2]byte
+	Size             int
+	IndexSize        int
+	ChannelIndexSize int
+	Index            struct {
+		Id      [2]byte
+		Channel [2]byte
+	}
+}{
+	Id:               [2]byte{0x12, 0x01},
+	Size:             2 + 2 + 8 + 8,     // tableId + dataType + sendAt + primaryKey
+	IndexSize:        2 + 2 + 2 + 8,     // tableId + dataType + indexName + primaryKey
+	ChannelIndexSize: 2 + 2 + 2 + 8 + 8, // tableId + dataType + indexName + channel hash + primaryKey
+	Index: struct {
+		Id      [2]byte
+		Channel [2]byte
+	}{
+		Id:      [2]byte{0x12, 0x01},
+		Channel: [2]byte{0x12, 0x02},
+	},
+}
+
+// ======================== Thread ========================
+// ---------------------
+// | tableID  | dataType	| channel hash | rootMessageSeq   |
+// | 2 byte   | 2 byte   	| 8 字节 		| 8 字节	   		|
+// ---------------------
+
+var TableThread = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x13, 0x01},
+	Size: 2 + 2 + 8 + 8, // tableId + dataType + channel hash + rootMessageSeq
+}
+
+// ======================== ChannelPin ========================
+// ---------------------
+// | tableID  | dataType	| channel hash | messageSeq   |
+// | 2 byte   | 2 byte   	| 8 字节 		| 8 字节	   	|
+// ---------------------
+
+var TableChannelPin = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x14, 0x01},
+	Size: 2 + 2 + 8 + 8, // tableId + dataType + channel hash + messageSeq
+}
+
+// ======================== ChannelAnnouncement ========================
+// ---------------------
+// | tableID  | dataType	| channel hash |
+// | 2 byte   | 2 byte   	| 8 字节 		|
+// ---------------------
+
+var TableChannelAnnouncement = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x15, 0x01},
+	Size: 2 + 2 + 8, // tableId + dataType + channel hash
+}
+
+// ======================== ConversationTombstone ========================
+// ---------------------
+// | tableID  | dataType	| uid hash | channel hash |
+// | 2 byte   | 2 byte   	| 8 字节   | 8 字节	   	  |
+// ---------------------
+// 版本号索引（值为channel hash）
+// | tableID  | dataType	| uid hash | version      |
+// | 2 byte   | 2 byte   	| 8 字节   | 8 字节	   	  |
+// ---------------------
+
+var TableConversationTombstone = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x16, 0x01},
+	Size: 2 + 2 + 8 + 8, // tableId + dataType + uid hash + channel hash
+}
+
+// ======================== ConversationVersion ========================
+// ---------------------
+// | tableID  | dataType	| uid hash |
+// | 2 byte   | 2 byte   	| 8 字节   |
+// ---------------------
+// dataType为table时值为用户当前的会话版本号，为index时值为已清理的墓碑的最大版本号
+
+var TableConversationVersion = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x17, 0x01},
+	Size: 2 + 2 + 8, // tableId + dataType + uid hash
+}
+
+// ======================== CDCEvent ========================
+// ---------------------
+// | tableID  | dataType	| slotId  | offset   |
+// | 2 byte   | 2 byte   	| 4 字节  | 8 字节	 |
+// ---------------------
+
+var TableCDCEvent = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x18, 0x01},
+	Size: 2 + 2 + 4 + 8, // tableId + dataType + slotId + offset
+}
+
+// ======================== CDCConsumer ========================
+// ---------------------
+// | tableID  | dataType	| consumer hash | slotId  |
+// | 2 byte   | 2 byte   	| 8 字节        | 4 字节  |
+// ---------------------
+
+var TableCDCConsumer = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x19, 0x01},
+	Size: 2 + 2 + 8 + 4, // tableId + dataType + consumer hash + slotId
+}
+
+// ======================== CustomerServiceQueue ========================
+// ---------------------
+// | tableID  | dataType	| queue hash |
+// | 2 byte   | 2 byte   	| 8 字节     |
+// ---------------------
+
+var TableCustomerServiceQueue = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x1a, 0x01},
+	Size: 2 + 2 + 8, // tableId + dataType + queue hash
+}
+
+// ======================== CustomerServiceSession ========================
+// ---------------------
+// | tableID  | dataType	| queue hash | channel hash |
+// | 2 byte   | 2 byte   	| 8 字节     | 8 字节       |
+// ---------------------
+
+var TableCustomerServiceSession = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x1b, 0x01},
+	Size: 2 + 2 + 8 + 8, // tableId + dataType + queue hash + channel hash
+}
@@ -315,6 +315,39 @@ func (wk *wukongDB) LoadMsg(channelId string, channelType uint8, seq uint64) (Me
 
 }
 
+func (wk *wukongDB) LoadMsgByClientMsgNo(channelId string, channelType uint8, fromUid string, clientMsgNo string) (Message, error) {
+	// clientMsgNo索引的主键前8个字节为频道hash，所以可以只扫描此频道下的索引
+	channelNum := key.ChannelIdToNum(channelId, channelType)
+	lowPrimary, highPrimary := minMessagePrimaryKey, maxMessagePrimaryKey
+	wk.endian.PutUint64(lowPrimary[:], channelNum)
+	wk.endian.PutUint64(highPrimary[:], channelNum)
+
+	iter := wk.channelDb(channelId, channelType).NewIter(&pebble.IterOptions{
+		LowerBound: key.NewMessageSecondIndexClientMsgNoKey(clientMsgNo, lowPrimary),
+		UpperBound: key.NewMessageSecondIndexClientMsgNoKey(clientMsgNo, highPrimary),
+	})
+	defer iter.Close()
+
+	for iter.Last(); iter.Valid(); iter.Prev() {
+		primaryBytes, err := key.ParseMessageSecondIndexKey(iter.Key())
+		if err != nil {
+			return EmptyMessage, err
+		}
+		msg, err := wk.LoadMsg(channelId, channelType, wk.endian.Uint64(primaryBytes[8:]))
+		if err != nil {
+			if err == ErrNotFound { // 消息已被截断，索引还在
+				continue
+			}
+			return EmptyMessage, err
+		}
+		// 索引为hash值，需要排除hash冲突
+		if msg.FromUID == fromUid && msg.ClientMsgNo == clientMsgNo {
+			return msg, nil
+		}
+	}
+	return EmptyMessage, ErrNotFound
+}
+
 func (wk *wukongDB) LoadLastMsgs(channelID string, channelType uint8, limit int) ([]Message, error) {
 	lastSeq, _, err := wk.GetChannelLastMessageSeq(channelID, channelType)
 	if err != nil {
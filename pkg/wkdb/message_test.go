@@ -137,6 +137,50 @@ func TestTruncateLogTo(t *testing.T) {
 	assert.Equal(t, uint32(50), resultMessages[len(resultMessages)-1].MessageSeq)
 }
 
+func TestLoadMsgByClientMsgNo(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	channelId := "channel"
+	channelType := uint8(2)
+
+	err = d.AppendMessages(channelId, channelType, []wkdb.Message{
+		{RecvPacket: wkproto.RecvPacket{MessageID: 1001, MessageSeq: 1, FromUID: "u1", ClientMsgNo: "c1", ChannelID: channelId, ChannelType: channelType}},
+		{RecvPacket: wkproto.RecvPacket{MessageID: 1002, MessageSeq: 2, FromUID: "u2", ClientMsgNo: "c1", ChannelID: channelId, ChannelType: channelType}},
+		{RecvPacket: wkproto.RecvPacket{MessageID: 1003, MessageSeq: 3, FromUID: "u1", ClientMsgNo: "c2", ChannelID: channelId, ChannelType: channelType}},
+	})
+	assert.NoError(t, err)
+	// 其他频道相同的clientMsgNo
+	err = d.AppendMessages("channel2", channelType, []wkdb.Message{
+		{RecvPacket: wkproto.RecvPacket{MessageID: 2001, MessageSeq: 1, FromUID: "u1", ClientMsgNo: "c1", ChannelID: "channel2", ChannelType: channelType}},
+	})
+	assert.NoError(t, err)
+
+	msg, err := d.LoadMsgByClientMsgNo(channelId, channelType, "u1", "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1001), msg.MessageID)
+	assert.Equal(t, uint32(1), msg.MessageSeq)
+
+	msg, err = d.LoadMsgByClientMsgNo(channelId, channelType, "u2", "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1002), msg.MessageID)
+
+	_, err = d.LoadMsgByClientMsgNo(channelId, channelType, "u3", "c1")
+	assert.Equal(t, wkdb.ErrNotFound, err)
+
+	// 截断后的消息查询不到
+	err = d.TruncateLogTo(channelId, channelType, 1)
+	assert.NoError(t, err)
+	_, err = d.LoadMsgByClientMsgNo(channelId, channelType, "u1", "c1")
+	assert.Equal(t, wkdb.ErrNotFound, err)
+}
+
 func BenchmarkAppendMessages(b *testing.B) {
 	d := newTestDB(b)
 	err := d.Open()
@@ -107,6 +107,9 @@ type User struct {
 	RecvMsgBytes      uint64     `json:"recv_msg_bytes,omitempty"`      // 接收消息字节数
 	CreatedAt         *time.Time `json:"created_at,omitempty"`          // 创建时间
 	UpdatedAt         *time.Time `json:"updated_at,omitempty"`          // 更新时间
+	LastOnlineAt      uint64     `json:"last_online_at,omitempty"`      // 最后一次上线时间（10位时间戳）
+	LastOfflineAt     uint64     `json:"last_offline_at,omitempty"`     // 最后一次离线时间（10位时间戳）
+	PresenceHidden    bool       `json:"presence_hidden,omitempty"`     // 是否对订阅者隐藏在线状态
 }
 
 var EmptyChannelInfo = ChannelInfo{}
@@ -277,6 +280,9 @@ const (
 
 	// ConversationTypeCMD 指令
 	ConversationTypeCMD
+
+	// ConversationTypeThread 子区
+	ConversationTypeThread
 )
 
 // Conversation Conversation
@@ -288,11 +294,40 @@ type Conversation struct {
 	ChannelType  uint8            `json:"channel_type,omitempty"`      // 频道类型
 	UnreadCount  uint32           `json:"unread_count,omitempty"`      // 未读消息数量（这个可以用户自己设置）
 	ReadToMsgSeq uint64           `json:"readed_to_msg_seq,omitempty"` // 已经读至的消息序号
+	Version      uint64           `json:"version,omitempty"`           // 会话版本号（用户维度递增，由数据库写入时分配）
+
+	MentionCount   uint32 `json:"mention_count,omitempty"`    // 未读的@我的消息数量
+	LastMentionSeq uint64 `json:"last_mention_seq,omitempty"` // 最后一条@我的消息序号
+
+	Pinned bool   `json:"pinned,omitempty"` // 是否置顶
+	Muted  bool   `json:"muted,omitempty"`  // 是否免打扰
+	Draft  string `json:"draft,omitempty"`  // 草稿
+	Extra  string `json:"extra,omitempty"`  // 自定义扩展数据
 
 	CreatedAt *time.Time `json:"created_at,omitempty"` // 创建时间
 	UpdatedAt *time.Time `json:"updated_at,omitempty"` // 更新时间
 }
 
+// SetAttrs 设置会话的用户属性
+func (c *Conversation) SetAttrs(attrs ConversationAttrs) {
+	c.Pinned = attrs.Pinned
+	c.Muted = attrs.Muted
+	c.Draft = attrs.Draft
+	c.Extra = attrs.Extra
+}
+
+// Attrs 获取会话的用户属性
+func (c *Conversation) Attrs() ConversationAttrs {
+	return ConversationAttrs{
+		ChannelId:   c.ChannelId,
+		ChannelType: c.ChannelType,
+		Pinned:      c.Pinned,
+		Muted:       c.Muted,
+		Draft:       c.Draft,
+		Extra:       c.Extra,
+	}
+}
+
 func (c *Conversation) Marshal() ([]byte, error) {
 	enc := wkproto.NewEncoder()
 	defer enc.End()
@@ -316,6 +351,14 @@ func (c *Conversation) Marshal() ([]byte, error) {
 		enc.WriteUint64(0)
 	}
 
+	enc.WriteUint64(c.Version)
+	enc.WriteUint8(wkutil.BoolToUint8(c.Pinned))
+	enc.WriteUint8(wkutil.BoolToUint8(c.Muted))
+	enc.WriteString(c.Draft)
+	enc.WriteString(c.Extra)
+	enc.WriteUint32(c.MentionCount)
+	enc.WriteUint64(c.LastMentionSeq)
+
 	return enc.Bytes(), nil
 }
 
@@ -369,6 +412,138 @@ func (c *Conversation) Unmarshal(data []byte) error {
 		c.UpdatedAt = &ct
 	}
 
+	// 兼容旧版本数据
+	if dec.Len() == 0 {
+		return nil
+	}
+
+	if c.Version, err = dec.Uint64(); err != nil {
+		return err
+	}
+	var pinned, muted uint8
+	if pinned, err = dec.Uint8(); err != nil {
+		return err
+	}
+	c.Pinned = wkutil.Uint8ToBool(pinned)
+	if muted, err = dec.Uint8(); err != nil {
+		return err
+	}
+	c.Muted = wkutil.Uint8ToBool(muted)
+	if c.Draft, err = dec.String(); err != nil {
+		return err
+	}
+	if c.Extra, err = dec.String(); err != nil {
+		return err
+	}
+
+	if dec.Len() == 0 {
+		return nil
+	}
+	if c.MentionCount, err = dec.Uint32(); err != nil {
+		return err
+	}
+	if c.LastMentionSeq, err = dec.Uint64(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConversationAttrs 会话的用户属性（置顶、免打扰、草稿、扩展数据）
+type ConversationAttrs struct {
+	ChannelId   string `json:"channel_id,omitempty"`   // 频道id
+	ChannelType uint8  `json:"channel_type,omitempty"` // 频道类型
+	Pinned      bool   `json:"pinned,omitempty"`       // 是否置顶
+	Muted       bool   `json:"muted,omitempty"`        // 是否免打扰
+	Draft       string `json:"draft,omitempty"`        // 草稿
+	Extra       string `json:"extra,omitempty"`        // 自定义扩展数据
+}
+
+func (a *ConversationAttrs) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteString(a.ChannelId)
+	enc.WriteUint8(a.ChannelType)
+	enc.WriteUint8(wkutil.BoolToUint8(a.Pinned))
+	enc.WriteUint8(wkutil.BoolToUint8(a.Muted))
+	enc.WriteString(a.Draft)
+	enc.WriteString(a.Extra)
+	return enc.Bytes(), nil
+}
+
+func (a *ConversationAttrs) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if a.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if a.ChannelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	var pinned, muted uint8
+	if pinned, err = dec.Uint8(); err != nil {
+		return err
+	}
+	a.Pinned = wkutil.Uint8ToBool(pinned)
+	if muted, err = dec.Uint8(); err != nil {
+		return err
+	}
+	a.Muted = wkutil.Uint8ToBool(muted)
+	if a.Draft, err = dec.String(); err != nil {
+		return err
+	}
+	if a.Extra, err = dec.String(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConversationTombstone 最近会话删除墓碑（用于增量同步时告知客户端会话已被删除）
+type ConversationTombstone struct {
+	Uid         string `json:"uid,omitempty"`          // 用户uid
+	ChannelId   string `json:"channel_id,omitempty"`   // 频道id
+	ChannelType uint8  `json:"channel_type,omitempty"` // 频道类型
+	Version     uint64 `json:"version,omitempty"`      // 删除时的会话版本号
+	DeletedAt   uint64 `json:"deleted_at,omitempty"`   // 删除时间（10位时间戳）
+
+	version uint16 // 数据版本
+}
+
+func (t *ConversationTombstone) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+
+	enc.WriteUint16(t.version) // 数据版本
+
+	enc.WriteString(t.Uid)
+	enc.WriteString(t.ChannelId)
+	enc.WriteUint8(t.ChannelType)
+	enc.WriteUint64(t.Version)
+	enc.WriteUint64(t.DeletedAt)
+	return enc.Bytes(), nil
+}
+
+func (t *ConversationTombstone) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if t.version, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if t.Uid, err = dec.String(); err != nil {
+		return err
+	}
+	if t.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if t.ChannelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	if t.Version, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if t.DeletedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -787,3 +962,505 @@ func (m *Member) Unmarshal(data []byte) error {
 	}
 	return nil
 }
+
+// ScheduledMessage 定时消息
+type ScheduledMessage struct {
+	Id          uint64 `json:"id,omitempty"`
+	ChannelId   string `json:"channel_id,omitempty"`   // 目标频道
+	ChannelType uint8  `json:"channel_type,omitempty"` // 目标频道类型
+	FromUid     string `json:"from_uid,omitempty"`     // 发送者
+	SendAt      uint64 `json:"send_at,omitempty"`      // 发送时间（10位时间戳）
+	Data        []byte `json:"data,omitempty"`         // 消息发送请求的内容
+	CreatedAt   uint64 `json:"created_at,omitempty"`   // 创建时间（10位时间戳）
+	FailCount   uint32 `json:"fail_count,omitempty"`   // 发送失败次数
+	Dead        bool   `json:"dead,omitempty"`         // 是否已进入死信（发送失败次数过多，不再到期发送，重新设置发送时间后恢复）
+
+	version uint16 // 数据版本
+}
+
+var EmptyScheduledMessage = ScheduledMessage{}
+
+func IsEmptyScheduledMessage(m ScheduledMessage) bool {
+	return m.Id == 0
+}
+
+func (m *ScheduledMessage) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+
+	enc.WriteUint16(m.version) // 数据版本
+
+	enc.WriteUint64(m.Id)
+	enc.WriteString(m.ChannelId)
+	enc.WriteUint8(m.ChannelType)
+	enc.WriteString(m.FromUid)
+	enc.WriteUint64(m.SendAt)
+	enc.WriteBinary(m.Data)
+	enc.WriteUint64(m.CreatedAt)
+	enc.WriteUint32(m.FailCount)
+	enc.WriteUint8(wkutil.BoolToUint8(m.Dead))
+	return enc.Bytes(), nil
+}
+
+func (m *ScheduledMessage) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if m.version, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if m.Id, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if m.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if m.ChannelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	if m.FromUid, err = dec.String(); err != nil {
+		return err
+	}
+	if m.SendAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if m.Data, err = dec.Binary(); err != nil {
+		return err
+	}
+	if m.CreatedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+
+	// 兼容旧版本数据
+	if dec.Len() == 0 {
+		return nil
+	}
+	if m.FailCount, err = dec.Uint32(); err != nil {
+		return err
+	}
+	var dead uint8
+	if dead, err = dec.Uint8(); err != nil {
+		return err
+	}
+	m.Dead = wkutil.Uint8ToBool(dead)
+	return nil
+}
+
+// ThreadParticipantMaxCount 子区最多记录的参与者数量
+const ThreadParticipantMaxCount = 10
+
+// Thread 子区（以频道内某条消息为根消息的回复链）
+type Thread struct {
+	ChannelId      string   `json:"channel_id,omitempty"`       // 父频道id
+	ChannelType    uint8    `json:"channel_type,omitempty"`     // 父频道类型
+	RootMessageSeq uint64   `json:"root_message_seq,omitempty"` // 根消息在父频道内的序号
+	ReplyCount     uint32   `json:"reply_count,omitempty"`      // 回复数量
+	LastReplyUid   string   `json:"last_reply_uid,omitempty"`   // 最后回复的用户
+	LastReplySeq   uint64   `json:"last_reply_seq,omitempty"`   // 最后一条回复在子区频道内的序号
+	LastReplyAt    uint64   `json:"last_reply_at,omitempty"`    // 最后回复时间（10位时间戳）
+	Participants   []string `json:"participants,omitempty"`     // 最近参与回复的用户（最近回复的在前）
+	CreatedAt      uint64   `json:"created_at,omitempty"`       // 创建时间（10位时间戳）
+
+	version uint16 // 数据版本
+}
+
+var EmptyThread = Thread{}
+
+func IsEmptyThread(t Thread) bool {
+	return t.RootMessageSeq == 0
+}
+
+// ThreadReply 子区的回复
+type ThreadReply struct {
+	Uid        string // 回复者
+	MessageSeq uint64 // 回复在子区频道内的序号
+	ReplyAt    uint64 // 回复时间（10位时间戳）
+}
+
+// AddReply 添加回复，更新回复数量、最后回复和参与者（已经统计过的回复忽略）
+// 子区频道内存储的消息都是回复，回复数量直接取回复的消息序号，中间有回复漏统计也会被后面的回复修正
+func (t *Thread) AddReply(reply ThreadReply) {
+	if reply.MessageSeq != 0 && reply.MessageSeq <= t.LastReplySeq {
+		return
+	}
+	if t.CreatedAt == 0 {
+		t.CreatedAt = reply.ReplyAt
+	}
+	if reply.MessageSeq != 0 {
+		t.ReplyCount = uint32(reply.MessageSeq)
+	} else {
+		t.ReplyCount++
+	}
+	t.LastReplyUid = reply.Uid
+	t.LastReplySeq = reply.MessageSeq
+	t.LastReplyAt = reply.ReplyAt
+
+	participants := make([]string, 0, len(t.Participants)+1)
+	participants = append(participants, reply.Uid)
+	for _, participant := range t.Participants {
+		if participant == reply.Uid {
+			continue
+		}
+		participants = append(participants, participant)
+	}
+	if len(participants) > ThreadParticipantMaxCount {
+		participants = participants[:ThreadParticipantMaxCount]
+	}
+	t.Participants = participants
+}
+
+func (t *Thread) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+
+	enc.WriteUint16(t.version) // 数据版本
+
+	enc.WriteString(t.ChannelId)
+	enc.WriteUint8(t.ChannelType)
+	enc.WriteUint64(t.RootMessageSeq)
+	enc.WriteUint32(t.ReplyCount)
+	enc.WriteString(t.LastReplyUid)
+	enc.WriteUint64(t.LastReplySeq)
+	enc.WriteUint64(t.LastReplyAt)
+	enc.WriteUint16(uint16(len(t.Participants)))
+	for _, participant := range t.Participants {
+		enc.WriteString(participant)
+	}
+	enc.WriteUint64(t.CreatedAt)
+	return enc.Bytes(), nil
+}
+
+func (t *Thread) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if t.version, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if t.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if t.ChannelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	if t.RootMessageSeq, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if t.ReplyCount, err = dec.Uint32(); err != nil {
+		return err
+	}
+	if t.LastReplyUid, err = dec.String(); err != nil {
+		return err
+	}
+	if t.LastReplySeq, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if t.LastReplyAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	var count uint16
+	if count, err = dec.Uint16(); err != nil {
+		return err
+	}
+	t.Participants = make([]string, 0, count)
+	for i := 0; i < int(count); i++ {
+		participant, err := dec.String()
+		if err != nil {
+			return err
+		}
+		t.Participants = append(t.Participants, participant)
+	}
+	if t.CreatedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ChannelPin 频道置顶消息
+type ChannelPin struct {
+	ChannelId   string `json:"channel_id,omitempty"`   // 频道id
+	ChannelType uint8  `json:"channel_type,omitempty"` // 频道类型
+	MessageSeq  uint64 `json:"message_seq,omitempty"`  // 置顶的消息序号
+	PinnedBy    string `json:"pinned_by,omitempty"`    // 置顶操作者
+	PinnedAt    uint64 `json:"pinned_at,omitempty"`    // 置顶时间（10位时间戳）
+	Sort        uint64 `json:"sort,omitempty"`         // 排序号（越大越靠前）
+
+	version uint16 // 数据版本
+}
+
+func (p *ChannelPin) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+
+	enc.WriteUint16(p.version) // 数据版本
+
+	enc.WriteString(p.ChannelId)
+	enc.WriteUint8(p.ChannelType)
+	enc.WriteUint64(p.MessageSeq)
+	enc.WriteString(p.PinnedBy)
+	enc.WriteUint64(p.PinnedAt)
+	enc.WriteUint64(p.Sort)
+	return enc.Bytes(), nil
+}
+
+func (p *ChannelPin) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if p.version, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if p.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if p.ChannelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	if p.MessageSeq, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if p.PinnedBy, err = dec.String(); err != nil {
+		return err
+	}
+	if p.PinnedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if p.Sort, err = dec.Uint64(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ChannelAnnouncement 频道公告
+type ChannelAnnouncement struct {
+	ChannelId   string `json:"channel_id,omitempty"`   // 频道id
+	ChannelType uint8  `json:"channel_type,omitempty"` // 频道类型
+	Content     string `json:"content,omitempty"`      // 公告内容
+	UpdatedBy   string `json:"updated_by,omitempty"`   // 最后修改者
+	UpdatedAt   uint64 `json:"updated_at,omitempty"`   // 最后修改时间（10位时间戳）
+	Version     uint64 `json:"version,omitempty"`      // 公告版本（每次修改加1）
+
+	version uint16 // 数据版本
+}
+
+var EmptyChannelAnnouncement = ChannelAnnouncement{}
+
+func (a *ChannelAnnouncement) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+
+	enc.WriteUint16(a.version) // 数据版本
+
+	enc.WriteString(a.ChannelId)
+	enc.WriteUint8(a.ChannelType)
+	enc.WriteString(a.Content)
+	enc.WriteString(a.UpdatedBy)
+	enc.WriteUint64(a.UpdatedAt)
+	enc.WriteUint64(a.Version)
+	return enc.Bytes(), nil
+}
+
+func (a *ChannelAnnouncement) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if a.version, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if a.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if a.ChannelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	if a.Content, err = dec.String(); err != nil {
+		return err
+	}
+	if a.UpdatedBy, err = dec.String(); err != nil {
+		return err
+	}
+	if a.UpdatedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if a.Version, err = dec.Uint64(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CDCEvent 变更事件（由槽日志应用时产生，偏移量为槽日志的下标）
+type CDCEvent struct {
+	SlotId    uint32 // 槽id
+	Offset    uint64 // 偏移量（槽日志下标，同一个槽内单调递增）
+	Type      uint16 // 事件类型（槽日志的命令类型）
+	Data      []byte // 事件数据（槽日志的命令数据）
+	CreatedAt int64  // 创建时间（纳秒）
+
+	version uint16 // 数据版本
+}
+
+func (e *CDCEvent) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+
+	enc.WriteUint16(e.version) // 数据版本
+
+	enc.WriteUint32(e.SlotId)
+	enc.WriteUint64(e.Offset)
+	enc.WriteUint16(e.Type)
+	enc.WriteInt64(e.CreatedAt)
+	enc.WriteBinary(e.Data)
+	return enc.Bytes(), nil
+}
+
+func (e *CDCEvent) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if e.version, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if e.SlotId, err = dec.Uint32(); err != nil {
+		return err
+	}
+	if e.Offset, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if e.Type, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if e.CreatedAt, err = dec.Int64(); err != nil {
+		return err
+	}
+	if e.Data, err = dec.Binary(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CustomerServiceQueue 客服队列
+type CustomerServiceQueue struct {
+	QueueId     string   `json:"queue_id,omitempty"`     // 队列id
+	Name        string   `json:"name,omitempty"`         // 队列名称
+	Strategy    string   `json:"strategy,omitempty"`     // 分配策略（roundRobin：轮询，leastBusy：最空闲）
+	MaxSessions uint32   `json:"max_sessions,omitempty"` // 每个客服同时接待的最大会话数（0表示不限制）
+	Agents      []string `json:"agents,omitempty"`       // 客服uid列表
+	CreatedAt   uint64   `json:"created_at,omitempty"`   // 创建时间（10位时间戳）
+	UpdatedAt   uint64   `json:"updated_at,omitempty"`   // 更新时间（10位时间戳）
+
+	version uint16 // 数据版本
+}
+
+var EmptyCustomerServiceQueue = CustomerServiceQueue{}
+
+func (q *CustomerServiceQueue) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+
+	enc.WriteUint16(q.version) // 数据版本
+
+	enc.WriteString(q.QueueId)
+	enc.WriteString(q.Name)
+	enc.WriteString(q.Strategy)
+	enc.WriteUint32(q.MaxSessions)
+	enc.WriteUint32(uint32(len(q.Agents)))
+	for _, agent := range q.Agents {
+		enc.WriteString(agent)
+	}
+	enc.WriteUint64(q.CreatedAt)
+	enc.WriteUint64(q.UpdatedAt)
+	return enc.Bytes(), nil
+}
+
+func (q *CustomerServiceQueue) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if q.version, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if q.QueueId, err = dec.String(); err != nil {
+		return err
+	}
+	if q.Name, err = dec.String(); err != nil {
+		return err
+	}
+	if q.Strategy, err = dec.String(); err != nil {
+		return err
+	}
+	if q.MaxSessions, err = dec.Uint32(); err != nil {
+		return err
+	}
+	var count uint32
+	if count, err = dec.Uint32(); err != nil {
+		return err
+	}
+	q.Agents = make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var agent string
+		if agent, err = dec.String(); err != nil {
+			return err
+		}
+		q.Agents = append(q.Agents, agent)
+	}
+	if q.CreatedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if q.UpdatedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CustomerServiceSession 客服会话（一个访客频道在队列中的接待状态）
+type CustomerServiceSession struct {
+	QueueId    string `json:"queue_id,omitempty"`    // 队列id
+	ChannelId  string `json:"channel_id,omitempty"`  // 客服频道id
+	VisitorUid string `json:"visitor_uid,omitempty"` // 访客uid
+	AgentUid   string `json:"agent_uid,omitempty"`   // 接待的客服uid（为空表示排队中）
+	CreatedAt  uint64 `json:"created_at,omitempty"`  // 会话创建时间（13位时间戳）
+	AssignedAt uint64 `json:"assigned_at,omitempty"` // 分配给客服的时间（13位时间戳）
+
+	version uint16 // 数据版本
+}
+
+// Waiting 是否在排队中
+func (s CustomerServiceSession) Waiting() bool {
+	return s.AgentUid == ""
+}
+
+func (s *CustomerServiceSession) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+
+	enc.WriteUint16(s.version) // 数据版本
+
+	enc.WriteString(s.QueueId)
+	enc.WriteString(s.ChannelId)
+	enc.WriteString(s.VisitorUid)
+	enc.WriteString(s.AgentUid)
+	enc.WriteUint64(s.CreatedAt)
+	enc.WriteUint64(s.AssignedAt)
+	return enc.Bytes(), nil
+}
+
+func (s *CustomerServiceSession) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if s.version, err = dec.Uint16(); err != nil {
+		return err
+	}
+	if s.QueueId, err = dec.String(); err != nil {
+		return err
+	}
+	if s.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if s.VisitorUid, err = dec.String(); err != nil {
+		return err
+	}
+	if s.AgentUid, err = dec.String(); err != nil {
+		return err
+	}
+	if s.CreatedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if s.AssignedAt, err = dec.Uint64(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package wkdb
+
+import (
+	"math"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+func (wk *wukongDB) AddPresenceSubscribers(uid string, subscribers []string) error {
+	w := wk.shardDB(uid).NewBatch()
+	defer w.Close()
+	for _, subscriber := range subscribers {
+		if err := wk.writePresenceSubscriber(uid, subscriber, w); err != nil {
+			return err
+		}
+	}
+	return w.Commit(wk.sync)
+}
+
+func (wk *wukongDB) RemovePresenceSubscribers(uid string, subscribers []string) error {
+	w := wk.shardDB(uid).NewBatch()
+	defer w.Close()
+	for _, subscriber := range subscribers {
+		if err := w.Delete(key.NewPresenceSubscriberColumnKey(uid, key.HashWithString(subscriber), key.TablePresenceSubscriber.Column.Uid), wk.noSync); err != nil {
+			return err
+		}
+	}
+	return w.Commit(wk.sync)
+}
+
+func (wk *wukongDB) GetPresenceSubscribers(uid string) ([]string, error) {
+	iter := wk.shardDB(uid).NewIter(&pebble.IterOptions{
+		LowerBound: key.NewPresenceSubscriberColumnKey(uid, 0, key.MinColumnKey),
+		UpperBound: key.NewPresenceSubscriberColumnKey(uid, math.MaxUint64, key.MaxColumnKey),
+	})
+	defer iter.Close()
+
+	subscribers := make([]string, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		subscribers = append(subscribers, string(iter.Value()))
+	}
+	return subscribers, nil
+}
+
+func (wk *wukongDB) writePresenceSubscriber(uid string, subscriber string, w *pebble.Batch) error {
+	return w.Set(key.NewPresenceSubscriberColumnKey(uid, key.HashWithString(subscriber), key.TablePresenceSubscriber.Column.Uid), []byte(subscriber), wk.noSync)
+}
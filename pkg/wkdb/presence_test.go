@@ -0,0 +1,36 @@
+package wkdb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAndGetPresenceSubscribers(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	err = d.AddPresenceSubscribers("u1", []string{"u2", "u3", "u4"})
+	assert.NoError(t, err)
+
+	subscribers, err := d.GetPresenceSubscribers("u1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "u3", "u4"}, subscribers)
+
+	err = d.RemovePresenceSubscribers("u1", []string{"u3"})
+	assert.NoError(t, err)
+
+	subscribers, err = d.GetPresenceSubscribers("u1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "u4"}, subscribers)
+
+	subscribers, err = d.GetPresenceSubscribers("u2")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(subscribers))
+}
@@ -0,0 +1,192 @@
+package wkdb
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+func (wk *wukongDB) AddOrUpdateScheduledMessage(m ScheduledMessage) error {
+	db := wk.defaultShardDB()
+	batch := db.NewBatch()
+	defer batch.Close()
+
+	// 删除旧的定时消息（重新设置发送时间的情况）
+	oldSendAt, err := wk.getScheduledMessageSendAt(m.Id)
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+	if err == nil {
+		if err = batch.Delete(key.NewScheduledMessageKey(oldSendAt, m.Id), wk.sync); err != nil {
+			return err
+		}
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	sendAt := scheduledMessageKeySendAt(m)
+	if err = batch.Set(key.NewScheduledMessageKey(sendAt, m.Id), data, wk.sync); err != nil {
+		return err
+	}
+
+	// id索引
+	var sendAtBytes = make([]byte, 8)
+	binary.BigEndian.PutUint64(sendAtBytes, sendAt)
+	if err = batch.Set(key.NewScheduledMessageIndexKey(m.Id), sendAtBytes, wk.sync); err != nil {
+		return err
+	}
+
+	// 频道索引
+	if err = batch.Set(key.NewScheduledMessageChannelIndexKey(m.ChannelId, m.ChannelType, m.Id), nil, wk.sync); err != nil {
+		return err
+	}
+	return batch.Commit(wk.sync)
+}
+
+func (wk *wukongDB) GetScheduledMessage(id uint64) (ScheduledMessage, error) {
+	sendAt, err := wk.getScheduledMessageSendAt(id)
+	if err != nil {
+		return EmptyScheduledMessage, err
+	}
+	value, closer, err := wk.defaultShardDB().Get(key.NewScheduledMessageKey(sendAt, id))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return EmptyScheduledMessage, ErrNotFound
+		}
+		return EmptyScheduledMessage, err
+	}
+	defer closer.Close()
+
+	var m ScheduledMessage
+	if err = m.Unmarshal(value); err != nil {
+		return EmptyScheduledMessage, err
+	}
+	return m, nil
+}
+
+func (wk *wukongDB) RemoveScheduledMessages(channelId string, channelType uint8, ids []uint64) error {
+	batch := wk.defaultShardDB().NewBatch()
+	defer batch.Close()
+
+	for _, id := range ids {
+		m, err := wk.GetScheduledMessage(id)
+		if err != nil {
+			if err == ErrNotFound {
+				continue
+			}
+			return err
+		}
+		if channelId != "" && (m.ChannelId != channelId || m.ChannelType != channelType) { // 不是此频道的定时消息
+			continue
+		}
+		if err = batch.Delete(key.NewScheduledMessageKey(scheduledMessageKeySendAt(m), id), wk.sync); err != nil {
+			return err
+		}
+		if err = batch.Delete(key.NewScheduledMessageIndexKey(id), wk.sync); err != nil {
+			return err
+		}
+		if err = batch.Delete(key.NewScheduledMessageChannelIndexKey(m.ChannelId, m.ChannelType, id), wk.sync); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(wk.sync)
+}
+
+func (wk *wukongDB) GetDueScheduledMessages(sendAt uint64, offsetSendAt uint64, offsetId uint64, limit int) ([]ScheduledMessage, error) {
+	iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+		LowerBound: key.NewScheduledMessageKey(offsetSendAt, offsetId+1),
+		UpperBound: key.NewScheduledMessageKey(sendAt, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	return wk.parseScheduledMessages(iter, func(m ScheduledMessage) bool {
+		return true
+	}, limit)
+}
+
+func (wk *wukongDB) GetScheduledMessages(channelId string, channelType uint8, limit int) ([]ScheduledMessage, error) {
+	if channelId == "" {
+		iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+			LowerBound: key.NewScheduledMessageKey(0, 0),
+			UpperBound: key.NewScheduledMessageKey(math.MaxUint64, math.MaxUint64),
+		})
+		defer iter.Close()
+
+		return wk.parseScheduledMessages(iter, func(m ScheduledMessage) bool {
+			return true
+		}, limit)
+	}
+
+	// 通过频道索引只扫描此频道的定时消息
+	iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+		LowerBound: key.NewScheduledMessageChannelIndexKey(channelId, channelType, 0),
+		UpperBound: key.NewScheduledMessageChannelIndexKey(channelId, channelType, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	messages := make([]ScheduledMessage, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		id, err := key.ParseScheduledMessageChannelIndexKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		m, err := wk.GetScheduledMessage(id)
+		if err != nil {
+			if err == ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		// 索引为hash值，需要排除hash冲突
+		if m.ChannelId != channelId || m.ChannelType != channelType {
+			continue
+		}
+		messages = append(messages, m)
+		if limit > 0 && len(messages) >= limit {
+			break
+		}
+	}
+	return messages, nil
+}
+
+// scheduledMessageKeySendAt 定时消息数据key中的发送时间，死信的发送时间为最大值，永远不会到期
+func scheduledMessageKeySendAt(m ScheduledMessage) uint64 {
+	if m.Dead {
+		return math.MaxUint64
+	}
+	return m.SendAt
+}
+
+func (wk *wukongDB) getScheduledMessageSendAt(id uint64) (uint64, error) {
+	value, closer, err := wk.defaultShardDB().Get(key.NewScheduledMessageIndexKey(id))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	defer closer.Close()
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func (wk *wukongDB) parseScheduledMessages(iter *pebble.Iterator, filter func(m ScheduledMessage) bool, limit int) ([]ScheduledMessage, error) {
+	messages := make([]ScheduledMessage, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var m ScheduledMessage
+		if err := m.Unmarshal(iter.Value()); err != nil {
+			return nil, err
+		}
+		if !filter(m) {
+			continue
+		}
+		messages = append(messages, m)
+		if limit > 0 && len(messages) >= limit {
+			break
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,134 @@
+package wkdb_test
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAndGetScheduledMessages(t *testing.T) {
+
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	messages := []wkdb.ScheduledMessage{
+		{Id: 1, ChannelId: "g1", ChannelType: 2, FromUid: "u1", SendAt: 300, Data: []byte("m1")},
+		{Id: 2, ChannelId: "g2", ChannelType: 2, FromUid: "u1", SendAt: 100, Data: []byte("m2")},
+		{Id: 3, ChannelId: "g1", ChannelType: 2, FromUid: "u2", SendAt: 200, Data: []byte("m3")},
+	}
+	for _, m := range messages {
+		err = d.AddOrUpdateScheduledMessage(m)
+		assert.NoError(t, err)
+	}
+
+	dueMessages, err := d.GetDueScheduledMessages(200, 0, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(dueMessages))
+	assert.Equal(t, uint64(2), dueMessages[0].Id)
+	assert.Equal(t, uint64(3), dueMessages[1].Id)
+
+	channelMessages, err := d.GetScheduledMessages("g1", 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(channelMessages))
+
+	// 重新设置发送时间
+	m := messages[0]
+	m.SendAt = 50
+	err = d.AddOrUpdateScheduledMessage(m)
+	assert.NoError(t, err)
+
+	dueMessages, err = d.GetDueScheduledMessages(50, 0, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(dueMessages))
+	assert.Equal(t, uint64(1), dueMessages[0].Id)
+	assert.Equal(t, []byte("m1"), dueMessages[0].Data)
+
+	allMessages, err := d.GetScheduledMessages("", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(allMessages))
+
+	// 不属于此频道的定时消息不会被移除
+	err = d.RemoveScheduledMessages("g1", 2, []uint64{1, 2})
+	assert.NoError(t, err)
+	_, err = d.GetScheduledMessage(2)
+	assert.NoError(t, err)
+
+	err = d.RemoveScheduledMessages("g2", 2, []uint64{2})
+	assert.NoError(t, err)
+	_, err = d.GetScheduledMessage(2)
+	assert.Equal(t, wkdb.ErrNotFound, err)
+
+	_, err = d.GetScheduledMessage(1)
+	assert.Equal(t, wkdb.ErrNotFound, err)
+
+	result, err := d.GetScheduledMessage(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "u2", result.FromUid)
+}
+
+func TestScheduledMessagesChannelIndexAndDead(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	messages := []wkdb.ScheduledMessage{
+		{Id: 1, ChannelId: "g1", ChannelType: 2, FromUid: "u1", SendAt: 100},
+		{Id: 2, ChannelId: "g2", ChannelType: 2, FromUid: "u1", SendAt: 100},
+		{Id: 3, ChannelId: "g1", ChannelType: 2, FromUid: "u1", SendAt: 200},
+		{Id: 4, ChannelId: "g1", ChannelType: 1, FromUid: "u1", SendAt: 200},
+	}
+	for _, m := range messages {
+		err = d.AddOrUpdateScheduledMessage(m)
+		assert.NoError(t, err)
+	}
+
+	channelMessages, err := d.GetScheduledMessages("g1", 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(channelMessages))
+	assert.Equal(t, uint64(1), channelMessages[0].Id)
+	assert.Equal(t, uint64(3), channelMessages[1].Id)
+
+	// 分页获取到期的定时消息
+	dueMessages, err := d.GetDueScheduledMessages(200, 0, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, []uint64{dueMessages[0].Id, dueMessages[1].Id})
+	dueMessages, err = d.GetDueScheduledMessages(200, dueMessages[1].SendAt, dueMessages[1].Id, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3, 4}, []uint64{dueMessages[0].Id, dueMessages[1].Id})
+
+	// 死信不再到期，但是可以查询到
+	m := messages[0]
+	m.FailCount = 3
+	m.Dead = true
+	err = d.AddOrUpdateScheduledMessage(m)
+	assert.NoError(t, err)
+
+	dueMessages, err = d.GetDueScheduledMessages(200, 0, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(dueMessages))
+
+	result, err := d.GetScheduledMessage(1)
+	assert.NoError(t, err)
+	assert.True(t, result.Dead)
+	assert.Equal(t, uint32(3), result.FailCount)
+	assert.Equal(t, uint64(100), result.SendAt)
+
+	// 移除死信
+	err = d.RemoveScheduledMessages("g1", 2, []uint64{1})
+	assert.NoError(t, err)
+	channelMessages, err = d.GetScheduledMessages("g1", 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(channelMessages))
+}
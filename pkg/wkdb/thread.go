@@ -0,0 +1,71 @@
+package wkdb
+
+import (
+	"math"
+	"sort"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+func (wk *wukongDB) AddThreadReplies(channelId string, channelType uint8, rootMessageSeq uint64, replies []ThreadReply) error {
+	thread, err := wk.GetThread(channelId, channelType, rootMessageSeq)
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+	thread.ChannelId = channelId
+	thread.ChannelType = channelType
+	thread.RootMessageSeq = rootMessageSeq
+	for _, reply := range replies {
+		thread.AddReply(reply)
+	}
+	data, err := thread.Marshal()
+	if err != nil {
+		return err
+	}
+	return wk.channelDb(channelId, channelType).Set(key.NewThreadKey(channelId, channelType, rootMessageSeq), data, wk.sync)
+}
+
+func (wk *wukongDB) GetThread(channelId string, channelType uint8, rootMessageSeq uint64) (Thread, error) {
+	value, closer, err := wk.channelDb(channelId, channelType).Get(key.NewThreadKey(channelId, channelType, rootMessageSeq))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return EmptyThread, ErrNotFound
+		}
+		return EmptyThread, err
+	}
+	defer closer.Close()
+
+	var thread Thread
+	if err = thread.Unmarshal(value); err != nil {
+		return EmptyThread, err
+	}
+	return thread, nil
+}
+
+func (wk *wukongDB) GetThreads(channelId string, channelType uint8, limit int) ([]Thread, error) {
+	iter := wk.channelDb(channelId, channelType).NewIter(&pebble.IterOptions{
+		LowerBound: key.NewThreadKey(channelId, channelType, 0),
+		UpperBound: key.NewThreadKey(channelId, channelType, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	threads := make([]Thread, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var thread Thread
+		if err := thread.Unmarshal(iter.Value()); err != nil {
+			return nil, err
+		}
+		if thread.ChannelId != channelId || thread.ChannelType != channelType { // hash冲突
+			continue
+		}
+		threads = append(threads, thread)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].LastReplyAt > threads[j].LastReplyAt
+	})
+	if limit > 0 && len(threads) > limit {
+		threads = threads[:limit]
+	}
+	return threads, nil
+}
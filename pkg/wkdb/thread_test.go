@@ -0,0 +1,64 @@
+package wkdb_test
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddThreadReplies(t *testing.T) {
+
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	err = d.AddThreadReplies("g1", 2, 10, []wkdb.ThreadReply{
+		{Uid: "u1", MessageSeq: 1, ReplyAt: 100},
+		{Uid: "u2", MessageSeq: 2, ReplyAt: 101},
+	})
+	assert.NoError(t, err)
+
+	err = d.AddThreadReplies("g1", 2, 10, []wkdb.ThreadReply{
+		{Uid: "u2", MessageSeq: 2, ReplyAt: 101}, // 重复的回复不统计
+		{Uid: "u1", MessageSeq: 3, ReplyAt: 102},
+	})
+	assert.NoError(t, err)
+
+	thread, err := d.GetThread("g1", 2, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), thread.ReplyCount)
+	assert.Equal(t, "u1", thread.LastReplyUid)
+	assert.Equal(t, uint64(3), thread.LastReplySeq)
+	assert.Equal(t, uint64(102), thread.LastReplyAt)
+	assert.Equal(t, uint64(100), thread.CreatedAt)
+	assert.Equal(t, []string{"u1", "u2"}, thread.Participants)
+
+	// 中间的回复漏统计了，回复数量按回复的消息序号修正
+	err = d.AddThreadReplies("g1", 2, 10, []wkdb.ThreadReply{
+		{Uid: "u2", MessageSeq: 6, ReplyAt: 103},
+	})
+	assert.NoError(t, err)
+	thread, err = d.GetThread("g1", 2, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(6), thread.ReplyCount)
+
+	err = d.AddThreadReplies("g1", 2, 20, []wkdb.ThreadReply{
+		{Uid: "u3", MessageSeq: 1, ReplyAt: 200},
+	})
+	assert.NoError(t, err)
+
+	threads, err := d.GetThreads("g1", 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(threads))
+	assert.Equal(t, uint64(20), threads[0].RootMessageSeq)
+	assert.Equal(t, uint64(10), threads[1].RootMessageSeq)
+
+	_, err = d.GetThread("g1", 2, 30)
+	assert.Equal(t, wkdb.ErrNotFound, err)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	"github.com/cockroachdb/pebble"
 )
 
@@ -237,6 +238,34 @@ func (wk *wukongDB) UpdateUser(u User) error {
 	return batch.Commit(wk.sync)
 }
 
+func (wk *wukongDB) UpdateUserLastSeen(uid string, online bool, at uint64) error {
+	column := key.TableUser.Column.LastOfflineAt
+	if online {
+		column = key.TableUser.Column.LastOnlineAt
+	}
+	var atBytes = make([]byte, 8)
+	wk.endian.PutUint64(atBytes, at)
+	return wk.updateUserColumn(uid, column, atBytes)
+}
+
+func (wk *wukongDB) UpdateUserPresenceHidden(uid string, hidden bool) error {
+	return wk.updateUserColumn(uid, key.TableUser.Column.PresenceHidden, []byte{wkutil.BoolToUint8(hidden)})
+}
+
+// updateUserColumn 只更新用户的某一列，用户不存在时会同时写入uid列
+func (wk *wukongDB) updateUserColumn(uid string, column [2]byte, value []byte) error {
+	id := key.HashWithString(uid)
+	batch := wk.shardDB(uid).NewBatch()
+	defer batch.Close()
+	if err := batch.Set(key.NewUserColumnKey(id, key.TableUser.Column.Uid), []byte(uid), wk.noSync); err != nil {
+		return err
+	}
+	if err := batch.Set(key.NewUserColumnKey(id, column), value, wk.noSync); err != nil {
+		return err
+	}
+	return batch.Commit(wk.sync)
+}
+
 // func (wk *wukongDB) incUserDeviceCount(uid string, count int, db *pebble.DB) error {
 
 // 	wk.dblock.userLock.Lock(uid)
@@ -323,6 +352,27 @@ func (wk *wukongDB) writeUser(u User, w pebble.Writer) error {
 
 	}
 
+	if u.LastOnlineAt > 0 {
+		var lastOnlineAtBytes = make([]byte, 8)
+		wk.endian.PutUint64(lastOnlineAtBytes, u.LastOnlineAt)
+		if err = w.Set(key.NewUserColumnKey(u.Id, key.TableUser.Column.LastOnlineAt), lastOnlineAtBytes, wk.noSync); err != nil {
+			return err
+		}
+	}
+
+	if u.LastOfflineAt > 0 {
+		var lastOfflineAtBytes = make([]byte, 8)
+		wk.endian.PutUint64(lastOfflineAtBytes, u.LastOfflineAt)
+		if err = w.Set(key.NewUserColumnKey(u.Id, key.TableUser.Column.LastOfflineAt), lastOfflineAtBytes, wk.noSync); err != nil {
+			return err
+		}
+	}
+
+	// presenceHidden
+	if err = w.Set(key.NewUserColumnKey(u.Id, key.TableUser.Column.PresenceHidden), []byte{wkutil.BoolToUint8(u.PresenceHidden)}, wk.noSync); err != nil {
+		return err
+	}
+
 	// write index
 	if err = wk.writeUserIndex(u, w); err != nil {
 		return err
@@ -423,6 +473,12 @@ func (wk *wukongDB) iteratorUser(iter *pebble.Iterator, iterFnc func(u User) boo
 				t := time.Unix(tm/1e9, tm%1e9)
 				preUser.UpdatedAt = &t
 			}
+		case key.TableUser.Column.LastOnlineAt:
+			preUser.LastOnlineAt = wk.endian.Uint64(iter.Value())
+		case key.TableUser.Column.LastOfflineAt:
+			preUser.LastOfflineAt = wk.endian.Uint64(iter.Value())
+		case key.TableUser.Column.PresenceHidden:
+			preUser.PresenceHidden = wkutil.Uint8ToBool(iter.Value()[0])
 
 		}
 		lastNeedAppend = true
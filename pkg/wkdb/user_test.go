@@ -125,3 +125,79 @@ func TestExistUser(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, exist)
 }
+
+func TestUpdateUserPresence(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	tn := time.Now()
+	err = d.AddUser(wkdb.User{
+		Uid:       "test",
+		CreatedAt: &tn,
+		UpdatedAt: &tn,
+	})
+	assert.NoError(t, err)
+
+	u, err := d.GetUser("test")
+	assert.NoError(t, err)
+	u.LastOnlineAt = uint64(tn.Unix())
+	u.LastOfflineAt = uint64(tn.Unix()) + 10
+	u.PresenceHidden = true
+	err = d.UpdateUser(u)
+	assert.NoError(t, err)
+
+	u2, err := d.GetUser("test")
+	assert.NoError(t, err)
+	assert.Equal(t, u.LastOnlineAt, u2.LastOnlineAt)
+	assert.Equal(t, u.LastOfflineAt, u2.LastOfflineAt)
+	assert.True(t, u2.PresenceHidden)
+	assert.Equal(t, tn.Unix(), u2.CreatedAt.Unix())
+}
+
+func TestUpdateUserPresenceColumns(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	tn := time.Now()
+	err = d.AddUser(wkdb.User{
+		Uid:       "test",
+		CreatedAt: &tn,
+		UpdatedAt: &tn,
+	})
+	assert.NoError(t, err)
+
+	err = d.UpdateUserLastSeen("test", true, 100)
+	assert.NoError(t, err)
+	err = d.UpdateUserLastSeen("test", false, 200)
+	assert.NoError(t, err)
+	err = d.UpdateUserPresenceHidden("test", true)
+	assert.NoError(t, err)
+
+	u, err := d.GetUser("test")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), u.LastOnlineAt)
+	assert.Equal(t, uint64(200), u.LastOfflineAt)
+	assert.True(t, u.PresenceHidden)
+	// 其他列不受影响
+	assert.Equal(t, tn.Unix(), u.CreatedAt.Unix())
+
+	// 用户不存在时也可以更新
+	err = d.UpdateUserLastSeen("test2", true, 300)
+	assert.NoError(t, err)
+	u, err = d.GetUser("test2")
+	assert.NoError(t, err)
+	assert.Equal(t, "test2", u.Uid)
+	assert.Equal(t, uint64(300), u.LastOnlineAt)
+}
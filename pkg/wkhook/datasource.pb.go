@@ -0,0 +1,689 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.18.1
+// source: pkg/wkhook/datasource.proto
+
+package wkhook
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ChannelReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChannelId   string `protobuf:"bytes,1,opt,name=channelId,proto3" json:"channelId,omitempty"`
+	ChannelType uint32 `protobuf:"varint,2,opt,name=channelType,proto3" json:"channelType,omitempty"`
+}
+
+func (x *ChannelReq) Reset() {
+	*x = ChannelReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_datasource_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelReq) ProtoMessage() {}
+
+func (x *ChannelReq) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_datasource_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelReq.ProtoReflect.Descriptor instead.
+func (*ChannelReq) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_datasource_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ChannelReq) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *ChannelReq) GetChannelType() uint32 {
+	if x != nil {
+		return x.ChannelType
+	}
+	return 0
+}
+
+type ChannelBatchReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channels []*ChannelReq `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+}
+
+func (x *ChannelBatchReq) Reset() {
+	*x = ChannelBatchReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_datasource_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelBatchReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelBatchReq) ProtoMessage() {}
+
+func (x *ChannelBatchReq) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_datasource_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelBatchReq.ProtoReflect.Descriptor instead.
+func (*ChannelBatchReq) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_datasource_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChannelBatchReq) GetChannels() []*ChannelReq {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type SystemUIDsReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SystemUIDsReq) Reset() {
+	*x = SystemUIDsReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_datasource_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SystemUIDsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemUIDsReq) ProtoMessage() {}
+
+func (x *SystemUIDsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_datasource_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemUIDsReq.ProtoReflect.Descriptor instead.
+func (*SystemUIDsReq) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_datasource_proto_rawDescGZIP(), []int{2}
+}
+
+type UidsResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uids []string `protobuf:"bytes,1,rep,name=uids,proto3" json:"uids,omitempty"`
+}
+
+func (x *UidsResp) Reset() {
+	*x = UidsResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_datasource_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UidsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UidsResp) ProtoMessage() {}
+
+func (x *UidsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_datasource_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UidsResp.ProtoReflect.Descriptor instead.
+func (*UidsResp) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_datasource_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UidsResp) GetUids() []string {
+	if x != nil {
+		return x.Uids
+	}
+	return nil
+}
+
+type ChannelUids struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChannelId   string   `protobuf:"bytes,1,opt,name=channelId,proto3" json:"channelId,omitempty"`
+	ChannelType uint32   `protobuf:"varint,2,opt,name=channelType,proto3" json:"channelType,omitempty"`
+	Uids        []string `protobuf:"bytes,3,rep,name=uids,proto3" json:"uids,omitempty"`
+}
+
+func (x *ChannelUids) Reset() {
+	*x = ChannelUids{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_datasource_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelUids) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelUids) ProtoMessage() {}
+
+func (x *ChannelUids) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_datasource_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelUids.ProtoReflect.Descriptor instead.
+func (*ChannelUids) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_datasource_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ChannelUids) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *ChannelUids) GetChannelType() uint32 {
+	if x != nil {
+		return x.ChannelType
+	}
+	return 0
+}
+
+func (x *ChannelUids) GetUids() []string {
+	if x != nil {
+		return x.Uids
+	}
+	return nil
+}
+
+type ChannelUidsBatchResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channels []*ChannelUids `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+}
+
+func (x *ChannelUidsBatchResp) Reset() {
+	*x = ChannelUidsBatchResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_datasource_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelUidsBatchResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelUidsBatchResp) ProtoMessage() {}
+
+func (x *ChannelUidsBatchResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_datasource_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelUidsBatchResp.ProtoReflect.Descriptor instead.
+func (*ChannelUidsBatchResp) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_datasource_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ChannelUidsBatchResp) GetChannels() []*ChannelUids {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type ChannelInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChannelId   string `protobuf:"bytes,1,opt,name=channelId,proto3" json:"channelId,omitempty"`
+	ChannelType uint32 `protobuf:"varint,2,opt,name=channelType,proto3" json:"channelType,omitempty"`
+	Large       bool   `protobuf:"varint,3,opt,name=large,proto3" json:"large,omitempty"`
+	Ban         bool   `protobuf:"varint,4,opt,name=ban,proto3" json:"ban,omitempty"`
+	Disband     bool   `protobuf:"varint,5,opt,name=disband,proto3" json:"disband,omitempty"`
+}
+
+func (x *ChannelInfo) Reset() {
+	*x = ChannelInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_datasource_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelInfo) ProtoMessage() {}
+
+func (x *ChannelInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_datasource_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelInfo.ProtoReflect.Descriptor instead.
+func (*ChannelInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_datasource_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ChannelInfo) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *ChannelInfo) GetChannelType() uint32 {
+	if x != nil {
+		return x.ChannelType
+	}
+	return 0
+}
+
+func (x *ChannelInfo) GetLarge() bool {
+	if x != nil {
+		return x.Large
+	}
+	return false
+}
+
+func (x *ChannelInfo) GetBan() bool {
+	if x != nil {
+		return x.Ban
+	}
+	return false
+}
+
+func (x *ChannelInfo) GetDisband() bool {
+	if x != nil {
+		return x.Disband
+	}
+	return false
+}
+
+type ChannelInfoBatchResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channels []*ChannelInfo `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+}
+
+func (x *ChannelInfoBatchResp) Reset() {
+	*x = ChannelInfoBatchResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_datasource_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelInfoBatchResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelInfoBatchResp) ProtoMessage() {}
+
+func (x *ChannelInfoBatchResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_datasource_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelInfoBatchResp.ProtoReflect.Descriptor instead.
+func (*ChannelInfoBatchResp) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_datasource_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ChannelInfoBatchResp) GetChannels() []*ChannelInfo {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+var File_pkg_wkhook_datasource_proto protoreflect.FileDescriptor
+
+var file_pkg_wkhook_datasource_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x70, 0x6b, 0x67, 0x2f, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2f, 0x64, 0x61, 0x74,
+	0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x77,
+	0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x22, 0x4c, 0x0a, 0x0a, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x52, 0x65, 0x71, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49,
+	0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54,
+	0x79, 0x70, 0x65, 0x22, 0x41, 0x0a, 0x0f, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x12, 0x2e, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f,
+	0x6b, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x52, 0x08, 0x63, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0x0f, 0x0a, 0x0d, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x55, 0x49, 0x44, 0x73, 0x52, 0x65, 0x71, 0x22, 0x1e, 0x0a, 0x08, 0x55, 0x69, 0x64, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x04, 0x75, 0x69, 0x64, 0x73, 0x22, 0x61, 0x0a, 0x0b, 0x43, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x55, 0x69, 0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54,
+	0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x69, 0x64, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x75, 0x69, 0x64, 0x73, 0x22, 0x47, 0x0a, 0x14, 0x43, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x55, 0x69, 0x64, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x2f, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x55, 0x69, 0x64, 0x73, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x73, 0x22, 0x8f, 0x01, 0x0a, 0x0b, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49,
+	0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x05, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x62, 0x61, 0x6e,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x62, 0x61, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x64,
+	0x69, 0x73, 0x62, 0x61, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x69,
+	0x73, 0x62, 0x61, 0x6e, 0x64, 0x22, 0x47, 0x0a, 0x14, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x49, 0x6e, 0x66, 0x6f, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x12, 0x2f, 0x0a,
+	0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x13, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x32, 0xc8,
+	0x03, 0x0a, 0x11, 0x44, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x39, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e,
+	0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x1a, 0x13, 0x2e, 0x77, 0x6b, 0x68,
+	0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x6e, 0x66, 0x6f, 0x12,
+	0x4c, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x6e, 0x66,
+	0x6f, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x17, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e,
+	0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x1a,
+	0x1c, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x49, 0x6e, 0x66, 0x6f, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x12, 0x36, 0x0a,
+	0x0e, 0x47, 0x65, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x12,
+	0x12, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x55, 0x69, 0x64,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x4c, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x17, 0x2e, 0x77,
+	0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x1c, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x43,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x55, 0x69, 0x64, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x65, 0x73, 0x70, 0x12, 0x34, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x61, 0x63, 0x6b, 0x6c,
+	0x69, 0x73, 0x74, 0x12, 0x12, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b,
+	0x2e, 0x55, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x34, 0x0a, 0x0c, 0x47, 0x65, 0x74,
+	0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x12, 0x2e, 0x77, 0x6b, 0x68, 0x6f,
+	0x6f, 0x6b, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e,
+	0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x55, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12,
+	0x38, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x55, 0x49, 0x44, 0x73,
+	0x12, 0x15, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x55, 0x49, 0x44, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b,
+	0x2e, 0x55, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x42, 0x0b, 0x5a, 0x09, 0x2e, 0x2f, 0x3b,
+	0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_wkhook_datasource_proto_rawDescOnce sync.Once
+	file_pkg_wkhook_datasource_proto_rawDescData = file_pkg_wkhook_datasource_proto_rawDesc
+)
+
+func file_pkg_wkhook_datasource_proto_rawDescGZIP() []byte {
+	file_pkg_wkhook_datasource_proto_rawDescOnce.Do(func() {
+		file_pkg_wkhook_datasource_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_wkhook_datasource_proto_rawDescData)
+	})
+	return file_pkg_wkhook_datasource_proto_rawDescData
+}
+
+var file_pkg_wkhook_datasource_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_pkg_wkhook_datasource_proto_goTypes = []interface{}{
+	(*ChannelReq)(nil),           // 0: wkhook.ChannelReq
+	(*ChannelBatchReq)(nil),      // 1: wkhook.ChannelBatchReq
+	(*SystemUIDsReq)(nil),        // 2: wkhook.SystemUIDsReq
+	(*UidsResp)(nil),             // 3: wkhook.UidsResp
+	(*ChannelUids)(nil),          // 4: wkhook.ChannelUids
+	(*ChannelUidsBatchResp)(nil), // 5: wkhook.ChannelUidsBatchResp
+	(*ChannelInfo)(nil),          // 6: wkhook.ChannelInfo
+	(*ChannelInfoBatchResp)(nil), // 7: wkhook.ChannelInfoBatchResp
+}
+var file_pkg_wkhook_datasource_proto_depIdxs = []int32{
+	0,  // 0: wkhook.ChannelBatchReq.channels:type_name -> wkhook.ChannelReq
+	4,  // 1: wkhook.ChannelUidsBatchResp.channels:type_name -> wkhook.ChannelUids
+	6,  // 2: wkhook.ChannelInfoBatchResp.channels:type_name -> wkhook.ChannelInfo
+	0,  // 3: wkhook.DatasourceService.GetChannelInfo:input_type -> wkhook.ChannelReq
+	1,  // 4: wkhook.DatasourceService.GetChannelInfoBatch:input_type -> wkhook.ChannelBatchReq
+	0,  // 5: wkhook.DatasourceService.GetSubscribers:input_type -> wkhook.ChannelReq
+	1,  // 6: wkhook.DatasourceService.GetSubscribersBatch:input_type -> wkhook.ChannelBatchReq
+	0,  // 7: wkhook.DatasourceService.GetBlacklist:input_type -> wkhook.ChannelReq
+	0,  // 8: wkhook.DatasourceService.GetWhitelist:input_type -> wkhook.ChannelReq
+	2,  // 9: wkhook.DatasourceService.GetSystemUIDs:input_type -> wkhook.SystemUIDsReq
+	6,  // 10: wkhook.DatasourceService.GetChannelInfo:output_type -> wkhook.ChannelInfo
+	7,  // 11: wkhook.DatasourceService.GetChannelInfoBatch:output_type -> wkhook.ChannelInfoBatchResp
+	3,  // 12: wkhook.DatasourceService.GetSubscribers:output_type -> wkhook.UidsResp
+	5,  // 13: wkhook.DatasourceService.GetSubscribersBatch:output_type -> wkhook.ChannelUidsBatchResp
+	3,  // 14: wkhook.DatasourceService.GetBlacklist:output_type -> wkhook.UidsResp
+	3,  // 15: wkhook.DatasourceService.GetWhitelist:output_type -> wkhook.UidsResp
+	3,  // 16: wkhook.DatasourceService.GetSystemUIDs:output_type -> wkhook.UidsResp
+	10, // [10:17] is the sub-list for method output_type
+	3,  // [3:10] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_pkg_wkhook_datasource_proto_init() }
+func file_pkg_wkhook_datasource_proto_init() {
+	if File_pkg_wkhook_datasource_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_wkhook_datasource_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_datasource_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelBatchReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_datasource_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SystemUIDsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_datasource_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UidsResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_datasource_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelUids); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_datasource_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelUidsBatchResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_datasource_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_datasource_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelInfoBatchResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_wkhook_datasource_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_wkhook_datasource_proto_goTypes,
+		DependencyIndexes: file_pkg_wkhook_datasource_proto_depIdxs,
+		MessageInfos:      file_pkg_wkhook_datasource_proto_msgTypes,
+	}.Build()
+	File_pkg_wkhook_datasource_proto = out.File
+	file_pkg_wkhook_datasource_proto_rawDesc = nil
+	file_pkg_wkhook_datasource_proto_goTypes = nil
+	file_pkg_wkhook_datasource_proto_depIdxs = nil
+}
@@ -0,0 +1,335 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.18.1
+// source: pkg/wkhook/datasource.proto
+
+package wkhook
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DatasourceServiceClient is the client API for DatasourceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DatasourceServiceClient interface {
+	// 获取频道信息
+	GetChannelInfo(ctx context.Context, in *ChannelReq, opts ...grpc.CallOption) (*ChannelInfo, error)
+	// 批量获取频道信息
+	GetChannelInfoBatch(ctx context.Context, in *ChannelBatchReq, opts ...grpc.CallOption) (*ChannelInfoBatchResp, error)
+	// 获取频道的订阅者
+	GetSubscribers(ctx context.Context, in *ChannelReq, opts ...grpc.CallOption) (*UidsResp, error)
+	// 批量获取频道的订阅者
+	GetSubscribersBatch(ctx context.Context, in *ChannelBatchReq, opts ...grpc.CallOption) (*ChannelUidsBatchResp, error)
+	// 获取频道的黑名单
+	GetBlacklist(ctx context.Context, in *ChannelReq, opts ...grpc.CallOption) (*UidsResp, error)
+	// 获取频道的白名单
+	GetWhitelist(ctx context.Context, in *ChannelReq, opts ...grpc.CallOption) (*UidsResp, error)
+	// 获取系统账号
+	GetSystemUIDs(ctx context.Context, in *SystemUIDsReq, opts ...grpc.CallOption) (*UidsResp, error)
+}
+
+type datasourceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDatasourceServiceClient(cc grpc.ClientConnInterface) DatasourceServiceClient {
+	return &datasourceServiceClient{cc}
+}
+
+func (c *datasourceServiceClient) GetChannelInfo(ctx context.Context, in *ChannelReq, opts ...grpc.CallOption) (*ChannelInfo, error) {
+	out := new(ChannelInfo)
+	err := c.cc.Invoke(ctx, "/wkhook.DatasourceService/GetChannelInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *datasourceServiceClient) GetChannelInfoBatch(ctx context.Context, in *ChannelBatchReq, opts ...grpc.CallOption) (*ChannelInfoBatchResp, error) {
+	out := new(ChannelInfoBatchResp)
+	err := c.cc.Invoke(ctx, "/wkhook.DatasourceService/GetChannelInfoBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *datasourceServiceClient) GetSubscribers(ctx context.Context, in *ChannelReq, opts ...grpc.CallOption) (*UidsResp, error) {
+	out := new(UidsResp)
+	err := c.cc.Invoke(ctx, "/wkhook.DatasourceService/GetSubscribers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *datasourceServiceClient) GetSubscribersBatch(ctx context.Context, in *ChannelBatchReq, opts ...grpc.CallOption) (*ChannelUidsBatchResp, error) {
+	out := new(ChannelUidsBatchResp)
+	err := c.cc.Invoke(ctx, "/wkhook.DatasourceService/GetSubscribersBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *datasourceServiceClient) GetBlacklist(ctx context.Context, in *ChannelReq, opts ...grpc.CallOption) (*UidsResp, error) {
+	out := new(UidsResp)
+	err := c.cc.Invoke(ctx, "/wkhook.DatasourceService/GetBlacklist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *datasourceServiceClient) GetWhitelist(ctx context.Context, in *ChannelReq, opts ...grpc.CallOption) (*UidsResp, error) {
+	out := new(UidsResp)
+	err := c.cc.Invoke(ctx, "/wkhook.DatasourceService/GetWhitelist", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *datasourceServiceClient) GetSystemUIDs(ctx context.Context, in *SystemUIDsReq, opts ...grpc.CallOption) (*UidsResp, error) {
+	out := new(UidsResp)
+	err := c.cc.Invoke(ctx, "/wkhook.DatasourceService/GetSystemUIDs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DatasourceServiceServer is the server API for DatasourceService service.
+// All implementations must embed UnimplementedDatasourceServiceServer
+// for forward compatibility
+type DatasourceServiceServer interface {
+	// 获取频道信息
+	GetChannelInfo(context.Context, *ChannelReq) (*ChannelInfo, error)
+	// 批量获取频道信息
+	GetChannelInfoBatch(context.Context, *ChannelBatchReq) (*ChannelInfoBatchResp, error)
+	// 获取频道的订阅者
+	GetSubscribers(context.Context, *ChannelReq) (*UidsResp, error)
+	// 批量获取频道的订阅者
+	GetSubscribersBatch(context.Context, *ChannelBatchReq) (*ChannelUidsBatchResp, error)
+	// 获取频道的黑名单
+	GetBlacklist(context.Context, *ChannelReq) (*UidsResp, error)
+	// 获取频道的白名单
+	GetWhitelist(context.Context, *ChannelReq) (*UidsResp, error)
+	// 获取系统账号
+	GetSystemUIDs(context.Context, *SystemUIDsReq) (*UidsResp, error)
+	mustEmbedUnimplementedDatasourceServiceServer()
+}
+
+// UnimplementedDatasourceServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDatasourceServiceServer struct {
+}
+
+func (UnimplementedDatasourceServiceServer) GetChannelInfo(context.Context, *ChannelReq) (*ChannelInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChannelInfo not implemented")
+}
+func (UnimplementedDatasourceServiceServer) GetChannelInfoBatch(context.Context, *ChannelBatchReq) (*ChannelInfoBatchResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChannelInfoBatch not implemented")
+}
+func (UnimplementedDatasourceServiceServer) GetSubscribers(context.Context, *ChannelReq) (*UidsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubscribers not implemented")
+}
+func (UnimplementedDatasourceServiceServer) GetSubscribersBatch(context.Context, *ChannelBatchReq) (*ChannelUidsBatchResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubscribersBatch not implemented")
+}
+func (UnimplementedDatasourceServiceServer) GetBlacklist(context.Context, *ChannelReq) (*UidsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlacklist not implemented")
+}
+func (UnimplementedDatasourceServiceServer) GetWhitelist(context.Context, *ChannelReq) (*UidsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWhitelist not implemented")
+}
+func (UnimplementedDatasourceServiceServer) GetSystemUIDs(context.Context, *SystemUIDsReq) (*UidsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSystemUIDs not implemented")
+}
+func (UnimplementedDatasourceServiceServer) mustEmbedUnimplementedDatasourceServiceServer() {}
+
+// UnsafeDatasourceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DatasourceServiceServer will
+// result in compilation errors.
+type UnsafeDatasourceServiceServer interface {
+	mustEmbedUnimplementedDatasourceServiceServer()
+}
+
+func RegisterDatasourceServiceServer(s grpc.ServiceRegistrar, srv DatasourceServiceServer) {
+	s.RegisterService(&DatasourceService_ServiceDesc, srv)
+}
+
+func _DatasourceService_GetChannelInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatasourceServiceServer).GetChannelInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wkhook.DatasourceService/GetChannelInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatasourceServiceServer).GetChannelInfo(ctx, req.(*ChannelReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatasourceService_GetChannelInfoBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelBatchReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatasourceServiceServer).GetChannelInfoBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wkhook.DatasourceService/GetChannelInfoBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatasourceServiceServer).GetChannelInfoBatch(ctx, req.(*ChannelBatchReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatasourceService_GetSubscribers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatasourceServiceServer).GetSubscribers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wkhook.DatasourceService/GetSubscribers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatasourceServiceServer).GetSubscribers(ctx, req.(*ChannelReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatasourceService_GetSubscribersBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelBatchReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatasourceServiceServer).GetSubscribersBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wkhook.DatasourceService/GetSubscribersBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatasourceServiceServer).GetSubscribersBatch(ctx, req.(*ChannelBatchReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatasourceService_GetBlacklist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatasourceServiceServer).GetBlacklist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wkhook.DatasourceService/GetBlacklist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatasourceServiceServer).GetBlacklist(ctx, req.(*ChannelReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatasourceService_GetWhitelist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatasourceServiceServer).GetWhitelist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wkhook.DatasourceService/GetWhitelist",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatasourceServiceServer).GetWhitelist(ctx, req.(*ChannelReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatasourceService_GetSystemUIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SystemUIDsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatasourceServiceServer).GetSystemUIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wkhook.DatasourceService/GetSystemUIDs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatasourceServiceServer).GetSystemUIDs(ctx, req.(*SystemUIDsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DatasourceService_ServiceDesc is the grpc.ServiceDesc for DatasourceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DatasourceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "wkhook.DatasourceService",
+	HandlerType: (*DatasourceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetChannelInfo",
+			Handler:    _DatasourceService_GetChannelInfo_Handler,
+		},
+		{
+			MethodName: "GetChannelInfoBatch",
+			Handler:    _DatasourceService_GetChannelInfoBatch_Handler,
+		},
+		{
+			MethodName: "GetSubscribers",
+			Handler:    _DatasourceService_GetSubscribers_Handler,
+		},
+		{
+			MethodName: "GetSubscribersBatch",
+			Handler:    _DatasourceService_GetSubscribersBatch_Handler,
+		},
+		{
+			MethodName: "GetBlacklist",
+			Handler:    _DatasourceService_GetBlacklist_Handler,
+		},
+		{
+			MethodName: "GetWhitelist",
+			Handler:    _DatasourceService_GetWhitelist_Handler,
+		},
+		{
+			MethodName: "GetSystemUIDs",
+			Handler:    _DatasourceService_GetSystemUIDs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/wkhook/datasource.proto",
+}
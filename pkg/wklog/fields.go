@@ -0,0 +1,29 @@
+package wklog
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// 日志的固定字段，便于日志系统（Loki/ELK）按字段检索
+
+// UID 用户uid字段
+func UID(uid string) zap.Field {
+	return zap.String("uid", uid)
+}
+
+// Channel 频道字段，格式为 频道id-频道类型
+func Channel(channelId string, channelType uint8) zap.Field {
+	return zap.String("channel", fmt.Sprintf("%s-%d", channelId, channelType))
+}
+
+// TraceID 追踪id字段
+func TraceID(traceId string) zap.Field {
+	return zap.String("trace_id", traceId)
+}
+
+// NodeID 节点id字段
+func NodeID(nodeId uint64) zap.Field {
+	return zap.Uint64("node_id", nodeId)
+}
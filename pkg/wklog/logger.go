@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -18,77 +19,64 @@ var warnLogger *zap.Logger
 var panicLogger *zap.Logger
 var atom = zap.NewAtomicLevel()
 
+var configuredOpts = NewOptions()
+
+var moduleLevels = map[string]zapcore.Level{} // 模块的日志级别
+var moduleLevelsLock sync.RWMutex
+
 func Configure(opts *Options) {
 	atom.SetLevel(opts.Level)
+	configuredOpts = opts
 
-	infoWriter := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   path.Join(opts.LogDir, "info.log"),
-		MaxSize:    500, // megabytes
-		MaxBackups: 3,
-		MaxAge:     28, // days
-	})
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(newEncoderConfig()),
-		zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(infoWriter)),
-		atom,
-	)
-	if opts.LineNum {
-		logger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2))
-	} else {
-		logger = zap.New(core)
+	moduleLevelsLock.Lock()
+	moduleLevels = make(map[string]zapcore.Level, len(opts.ModuleLevels))
+	for module, level := range opts.ModuleLevels {
+		moduleLevels[module] = level
 	}
+	moduleLevelsLock.Unlock()
 
-	errorWriter := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   path.Join(opts.LogDir, "error.log"),
-		MaxSize:    500, // megabytes
-		MaxBackups: 3,
-		MaxAge:     28, // days
-	})
-	core = zapcore.NewCore(
-		zapcore.NewJSONEncoder(newEncoderConfig()),
-		zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(errorWriter)),
-		zap.ErrorLevel,
-	)
-	if opts.LineNum {
-		errorLogger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2))
-	} else {
-		errorLogger = zap.New(core)
-	}
+	// 日志级别在写日志前判断（支持模块级别），所以这里不做限制
+	logger = newLogger(opts, "info.log", zapcore.DebugLevel)
+	errorLogger = newLogger(opts, "error.log", zap.ErrorLevel)
+	warnLogger = newLogger(opts, "warn.log", zap.WarnLevel)
+	panicLogger = newLogger(opts, "panic.log", zap.PanicLevel, zap.AddStacktrace(zapcore.PanicLevel))
+}
 
-	warnWriter := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   path.Join(opts.LogDir, "warn.log"),
-		MaxSize:    500, // megabytes
-		MaxBackups: 3,
-		MaxAge:     28, // days
+func newLogger(opts *Options, filename string, level zapcore.LevelEnabler, zapOpts ...zap.Option) *zap.Logger {
+	writer := zapcore.AddSync(&lumberjack.Logger{
+		Filename:   path.Join(opts.LogDir, filename),
+		MaxSize:    valueOrDefault(opts.MaxSize, 500), // megabytes
+		MaxBackups: valueOrDefault(opts.MaxBackups, 3),
+		MaxAge:     valueOrDefault(opts.MaxAge, 28), // days
+		Compress:   opts.Compress,
 	})
-	core = zapcore.NewCore(
-		zapcore.NewJSONEncoder(newEncoderConfig()),
-		zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(warnWriter)),
-		zap.WarnLevel,
+	core := zapcore.NewCore(
+		newEncoder(opts),
+		zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(writer)),
+		level,
 	)
 	if opts.LineNum {
-		warnLogger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2))
-	} else {
-		warnLogger = zap.New(core)
+		zapOpts = append([]zap.Option{zap.AddCaller(), zap.AddCallerSkip(2)}, zapOpts...)
 	}
+	l := zap.New(core, zapOpts...)
+	if opts.NodeId != 0 {
+		l = l.With(zap.Uint64("node_id", opts.NodeId))
+	}
+	return l
+}
 
-	panicWriter := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   path.Join(opts.LogDir, "panic.log"),
-		MaxSize:    500, // megabytes
-		MaxBackups: 3,
-		MaxAge:     28, // days
-	})
-	core = zapcore.NewCore(
-		zapcore.NewJSONEncoder(newEncoderConfig()),
-		zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(panicWriter)),
-		zap.PanicLevel,
-	)
-	if opts.LineNum {
-		panicLogger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2), zap.AddStacktrace(zapcore.PanicLevel))
-	} else {
-		panicLogger = zap.New(core, zap.AddStacktrace(zapcore.PanicLevel))
+func valueOrDefault(v int, defaultValue int) int {
+	if v <= 0 {
+		return defaultValue
 	}
+	return v
+}
 
+func newEncoder(opts *Options) zapcore.Encoder {
+	if opts.Format == FormatConsole {
+		return zapcore.NewConsoleEncoder(newEncoderConfig())
+	}
+	return zapcore.NewJSONEncoder(newEncoderConfig())
 }
 
 func newEncoderConfig() zapcore.EncoderConfig {
@@ -105,39 +93,104 @@ func newEncoderConfig() zapcore.EncoderConfig {
 		EncodeCaller:  zapcore.FullCallerEncoder,     // 全路径编码器
 		EncodeName:    zapcore.FullNameEncoder,
 		EncodeTime: func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-			enc.AppendString(t.Format("2006-01-02 15:04:05.000"))
+			enc.AppendString(t.Format(TimeLayout))
 		},
 		EncodeDuration: func(d time.Duration, enc zapcore.PrimitiveArrayEncoder) {
 			enc.AppendInt64(int64(d) / 1000000)
 		},
 	}
 }
-func timeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-	enc.AppendString(t.Format("2006-01-02 15:04:05.000"))
+
+// TimeLayout 日志时间格式
+const TimeLayout = "2006-01-02 15:04:05.000"
+
+// SetLevel 设置全局日志级别（运行时生效）
+func SetLevel(level zapcore.Level) {
+	atom.SetLevel(level)
+}
+
+// GetLevel 获取全局日志级别
+func GetLevel() zapcore.Level {
+	return atom.Level()
+}
+
+// SetModuleLevel 设置模块的日志级别（运行时生效）
+func SetModuleLevel(module string, level zapcore.Level) {
+	moduleLevelsLock.Lock()
+	defer moduleLevelsLock.Unlock()
+	moduleLevels[module] = level
+}
+
+// RemoveModuleLevel 移除模块的日志级别，模块将使用全局日志级别
+func RemoveModuleLevel(module string) {
+	moduleLevelsLock.Lock()
+	defer moduleLevelsLock.Unlock()
+	delete(moduleLevels, module)
+}
+
+// ModuleLevels 获取所有设置了日志级别的模块
+func ModuleLevels() map[string]zapcore.Level {
+	moduleLevelsLock.RLock()
+	defer moduleLevelsLock.RUnlock()
+	levels := make(map[string]zapcore.Level, len(moduleLevels))
+	for module, level := range moduleLevels {
+		levels[module] = level
+	}
+	return levels
+}
+
+// levelEnabled 模块的日志级别是否开启（模块没有设置日志级别则使用全局日志级别）
+func levelEnabled(module string, level zapcore.Level) bool {
+	if module != "" {
+		moduleLevelsLock.RLock()
+		moduleLevel, ok := moduleLevels[module]
+		moduleLevelsLock.RUnlock()
+		if ok {
+			return moduleLevel.Enabled(level)
+		}
+	}
+	return atom.Enabled(level)
 }
 
 // Info Info
 func Info(msg string, fields ...zap.Field) {
+	if !atom.Enabled(zapcore.InfoLevel) {
+		return
+	}
+	info(msg, fields...)
+}
 
+func info(msg string, fields ...zap.Field) {
 	if logger == nil {
 		Configure(NewOptions())
 	}
 	logger.Info(msg, fields...)
-
 }
 
 // Debug Debug
 func Debug(msg string, fields ...zap.Field) {
+	if !atom.Enabled(zapcore.DebugLevel) {
+		return
+	}
+	debug(msg, fields...)
+}
 
+func debug(msg string, fields ...zap.Field) {
 	if logger == nil {
 		Configure(NewOptions())
 	}
 	logger.Debug(msg, fields...)
-
 }
 
 // Error Error
 func Error(msg string, fields ...zap.Field) {
+	if !atom.Enabled(zapcore.ErrorLevel) {
+		return
+	}
+	errorf(msg, fields...)
+}
+
+func errorf(msg string, fields ...zap.Field) {
 	if errorLogger == nil {
 		Configure(NewOptions())
 	}
@@ -145,12 +198,21 @@ func Error(msg string, fields ...zap.Field) {
 }
 
 func Fatal(msg string, fields ...zap.Field) {
+	fatal(msg, fields...)
+}
+
+func fatal(msg string, fields ...zap.Field) {
 	if panicLogger == nil {
 		Configure(NewOptions())
 	}
 	panicLogger.Fatal(msg, fields...)
 }
+
 func Panic(msg string, fields ...zap.Field) {
+	panicf(msg, fields...)
+}
+
+func panicf(msg string, fields ...zap.Field) {
 	if panicLogger == nil {
 		Configure(NewOptions())
 	}
@@ -159,7 +221,13 @@ func Panic(msg string, fields ...zap.Field) {
 
 // Warn Warn
 func Warn(msg string, fields ...zap.Field) {
+	if !atom.Enabled(zapcore.WarnLevel) {
+		return
+	}
+	warn(msg, fields...)
+}
 
+func warn(msg string, fields ...zap.Field) {
 	if warnLogger == nil {
 		Configure(NewOptions())
 	}
@@ -199,67 +267,86 @@ type Log interface {
 // WKLog TLog
 type WKLog struct {
 	prefix string // 日志前缀
+	module string // 模块名（前缀中[]之前的部分）
+	key    string // 模块标识（前缀中[]内的部分，例如节点id、频道key）
 }
 
 // NewWKLog NewWKLog
 func NewWKLog(prefix string) *WKLog {
+	module, key := parsePrefix(prefix)
+	return &WKLog{prefix: prefix, module: module, key: key}
+}
 
-	return &WKLog{prefix: prefix}
+// parsePrefix 解析日志前缀，例如 cluster[1001] 解析为模块cluster和标识1001
+func parsePrefix(prefix string) (module string, key string) {
+	idx := strings.Index(prefix, "[")
+	if idx <= 0 || !strings.HasSuffix(prefix, "]") {
+		return prefix, ""
+	}
+	return prefix[:idx], prefix[idx+1 : len(prefix)-1]
 }
 
-// Info Info
-func (t *WKLog) Info(msg string, fields ...zap.Field) {
+// message json格式下前缀以module字段输出，console格式下前缀拼接在消息前
+func (t *WKLog) message(msg string) string {
+	if configuredOpts.Format != FormatConsole {
+		return msg
+	}
 	var b strings.Builder
 	b.WriteString("【")
 	b.WriteString(t.prefix)
 	b.WriteString("】")
 	b.WriteString(msg)
-	Info(b.String(), fields...)
+	return b.String()
+}
+
+func (t *WKLog) fields(fields []zap.Field) []zap.Field {
+	if configuredOpts.Format == FormatConsole {
+		return fields
+	}
+	newFields := make([]zap.Field, 0, len(fields)+2)
+	newFields = append(newFields, zap.String("module", t.module))
+	if t.key != "" {
+		newFields = append(newFields, zap.String("module_key", t.key))
+	}
+	return append(newFields, fields...)
+}
+
+// Info Info
+func (t *WKLog) Info(msg string, fields ...zap.Field) {
+	if !levelEnabled(t.module, zapcore.InfoLevel) {
+		return
+	}
+	info(t.message(msg), t.fields(fields)...)
 }
 
 // Debug Debug
 func (t *WKLog) Debug(msg string, fields ...zap.Field) {
-	var b strings.Builder
-	b.WriteString("【")
-	b.WriteString(t.prefix)
-	b.WriteString("】")
-	b.WriteString(msg)
-	Debug(b.String(), fields...)
+	if !levelEnabled(t.module, zapcore.DebugLevel) {
+		return
+	}
+	debug(t.message(msg), t.fields(fields)...)
 }
 
 // Error Error
 func (t *WKLog) Error(msg string, fields ...zap.Field) {
-	var b strings.Builder
-	b.WriteString("【")
-	b.WriteString(t.prefix)
-	b.WriteString("】")
-	b.WriteString(msg)
-	Error(b.String(), fields...)
+	if !levelEnabled(t.module, zapcore.ErrorLevel) {
+		return
+	}
+	errorf(t.message(msg), t.fields(fields)...)
 }
 
 // Warn Warn
 func (t *WKLog) Warn(msg string, fields ...zap.Field) {
-	var b strings.Builder
-	b.WriteString("【")
-	b.WriteString(t.prefix)
-	b.WriteString("】")
-	b.WriteString(msg)
-	Warn(b.String(), fields...)
+	if !levelEnabled(t.module, zapcore.WarnLevel) {
+		return
+	}
+	warn(t.message(msg), t.fields(fields)...)
 }
 
 func (t *WKLog) Fatal(msg string, fields ...zap.Field) {
-	var b strings.Builder
-	b.WriteString("【")
-	b.WriteString(t.prefix)
-	b.WriteString("】")
-	b.WriteString(msg)
-	Fatal(b.String(), fields...)
+	fatal(t.message(msg), t.fields(fields)...)
 }
+
 func (t *WKLog) Panic(msg string, fields ...zap.Field) {
-	var b strings.Builder
-	b.WriteString("【")
-	b.WriteString(t.prefix)
-	b.WriteString("】")
-	b.WriteString(msg)
-	Panic(b.String(), fields...)
+	panicf(t.message(msg), t.fields(fields)...)
 }
@@ -2,8 +2,11 @@ package wklog
 
 import (
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func TestLogger(t *testing.T) {
@@ -16,3 +19,55 @@ func TestLogger(t *testing.T) {
 	Debug("this is debug")
 	Error("this is error", zap.String("key", "value"))
 }
+
+func TestModuleLevel(t *testing.T) {
+	opts := NewOptions()
+	opts.Level = zap.InfoLevel
+	opts.LogDir = t.TempDir()
+	opts.ModuleLevels = map[string]zapcore.Level{"cluster": zap.WarnLevel}
+	Configure(opts)
+
+	assert.False(t, levelEnabled("cluster", zap.InfoLevel))
+	assert.True(t, levelEnabled("cluster", zap.WarnLevel))
+	assert.True(t, levelEnabled("slot", zap.InfoLevel))
+	assert.False(t, levelEnabled("slot", zap.DebugLevel))
+
+	SetModuleLevel("slot", zap.DebugLevel)
+	assert.True(t, levelEnabled("slot", zap.DebugLevel))
+
+	RemoveModuleLevel("cluster")
+	assert.True(t, levelEnabled("cluster", zap.InfoLevel))
+
+	SetLevel(zap.ErrorLevel)
+	assert.False(t, levelEnabled("cluster", zap.InfoLevel))
+	assert.Equal(t, zap.ErrorLevel, GetLevel())
+}
+
+func TestQueryLogs(t *testing.T) {
+	opts := NewOptions()
+	opts.Level = zap.InfoLevel
+	opts.LogDir = t.TempDir()
+	Configure(opts)
+
+	start := time.Now().Add(-time.Second)
+	NewWKLog("cluster[1001]").Info("cluster log", Channel("test", 2))
+	NewWKLog("slot[1]").Info("slot log")
+	NewWKLog("cluster[1001]").Info("cluster log2", UID("u1"))
+	_ = Sync()
+
+	logs, err := QueryLogs(LogQuery{Module: "cluster", Start: start, End: time.Now().Add(time.Second)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, "cluster log2", logs[0]["msg"])
+	assert.Equal(t, "u1", logs[0]["uid"])
+	assert.Equal(t, "1001", logs[1]["module_key"])
+	assert.Equal(t, "test-2", logs[1]["channel"])
+
+	logs, err = QueryLogs(LogQuery{Keyword: "slot"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(logs))
+
+	logs, err = QueryLogs(LogQuery{Module: "cluster", Start: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(logs))
+}
@@ -2,13 +2,36 @@ package wklog
 
 import "go.uber.org/zap/zapcore"
 
+// 日志格式
+const (
+	FormatJSON    = "json"    // json格式（默认），适合Loki/ELK等日志系统采集
+	FormatConsole = "console" // 控制台格式，适合本地开发查看
+)
+
 type Options struct {
 	Level   zapcore.Level
 	LogDir  string
 	LineNum bool
+
+	Format string // 日志格式 json或console，默认json
+
+	NodeId uint64 // 节点id，不为0时每条日志都会带上node_id字段
+
+	// 日志切割
+	MaxSize    int  // 单个日志文件的最大大小（单位MB），默认500
+	MaxAge     int  // 日志文件保留的最大天数，默认28
+	MaxBackups int  // 保留的历史日志文件的最大数量，默认3
+	Compress   bool // 历史日志文件是否压缩
+
+	ModuleLevels map[string]zapcore.Level // 模块的日志级别（模块为NewWKLog的前缀，不包含[]内的部分）
 }
 
 func NewOptions() *Options {
 
-	return &Options{}
+	return &Options{
+		Format:     FormatJSON,
+		MaxSize:    500,
+		MaxAge:     28,
+		MaxBackups: 3,
+	}
 }
@@ -0,0 +1,109 @@
+package wklog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// LogQuery 日志查询条件
+type LogQuery struct {
+	File    string    // 日志文件 info, warn, error, panic（默认info）
+	Module  string    // 模块
+	Keyword string    // 关键字
+	Start   time.Time // 开始时间（包含）
+	End     time.Time // 结束时间（包含）
+	Limit   int       // 最多返回的日志数量
+}
+
+var logFiles = []string{"info", "warn", "error", "panic"}
+
+// QueryLogs 查询本节点的日志，返回满足条件的最新的Limit条日志（最新的在前）
+// 只支持json格式的日志按模块和时间过滤
+func QueryLogs(q LogQuery) ([]map[string]interface{}, error) {
+	file := q.File
+	if file == "" {
+		file = "info"
+	}
+	if !arrayContains(logFiles, file) {
+		return nil, errors.New("unsupported log file")
+	}
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	f, err := os.Open(path.Join(configuredOpts.LogDir, file+".log"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []map[string]interface{}{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	// 只保留最新的Limit条
+	results := make([]map[string]interface{}, 0, q.Limit)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if q.Keyword != "" && !strings.Contains(string(line), q.Keyword) {
+			continue
+		}
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		if !matchLogEntry(entry, q) {
+			continue
+		}
+		if len(results) >= q.Limit {
+			results = results[1:]
+		}
+		results = append(results, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results, nil
+}
+
+func matchLogEntry(entry map[string]interface{}, q LogQuery) bool {
+	if q.Module != "" {
+		module, _ := entry["module"].(string)
+		if module != q.Module {
+			return false
+		}
+	}
+	if !q.Start.IsZero() || !q.End.IsZero() {
+		timeStr, _ := entry["time"].(string)
+		t, err := time.ParseInLocation(TimeLayout, timeStr, time.Local)
+		if err != nil {
+			return false
+		}
+		if !q.Start.IsZero() && t.Before(q.Start) {
+			return false
+		}
+		if !q.End.IsZero() && t.After(q.End) {
+			return false
+		}
+	}
+	return true
+}
+
+func arrayContains(items []string, item string) bool {
+	for _, v := range items {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/WuKongIM/WuKongIM/pkg/socket"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wknet/netpoll"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	"golang.org/x/sys/unix"
 )
@@ -25,10 +26,11 @@ type Acceptor struct {
 	listenWSPoller    *netpoll.Poller
 	listenWSSPoller   *netpoll.Poller
 	listen            *listener
-	listenWS          *listener // websocket
-	listenWSS         *listener // websocket
-	tcpRealListenAddr net.Addr  // tcp real listen addr
-	wsRealListenAddr  net.Addr  // websocket real listen addr
+	listenWS          *listener   // websocket
+	listenWSS         *listener   // websocket
+	tcpRealListenAddr net.Addr    // tcp real listen addr
+	wsRealListenAddr  net.Addr    // websocket real listen addr
+	draining          atomic.Bool // 是否停止接收新连接（排空中）
 
 	wklog.Log
 }
@@ -144,6 +146,16 @@ func (a *Acceptor) Stop() error {
 	return nil
 }
 
+// StopAccepting 停止接收新连接，监听保持不变，新连接接入后立即关闭
+func (a *Acceptor) StopAccepting() {
+	a.draining.Store(true)
+}
+
+// Accepting 是否正在接收新连接
+func (a *Acceptor) Accepting() bool {
+	return !a.draining.Load()
+}
+
 func (a *Acceptor) initTCPListener(wg *sync.WaitGroup) error {
 	// tcp
 	a.listen = newListener(a.eg.options.Addr, a.eg.options)
@@ -211,6 +223,10 @@ func (a *Acceptor) acceptConn(listenFd int, ws bool, wss bool) error {
 		a.Error("Accept() failed", zap.Error(err))
 		return perrors.ErrAcceptSocket
 	}
+	if a.draining.Load() { // 排空中，直接关闭新连接
+		_ = unix.Close(connFd)
+		return nil
+	}
 	if err = os.NewSyscallError("fcntl nonblock", unix.SetNonblock(connFd, true)); err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
 
@@ -14,8 +15,9 @@ type Acceptor struct {
 	eg          *Engine
 	wklog.Log
 	listen    *listener
-	listenWS  *listener // websocket
-	listenWSS *listener // websocket
+	listenWS  *listener   // websocket
+	listenWSS *listener   // websocket
+	draining  atomic.Bool // 是否停止接收新连接（排空中）
 }
 
 func NewAcceptor(eg *Engine) *Acceptor {
@@ -54,6 +56,16 @@ func (a *Acceptor) Stop() error {
 	return nil
 }
 
+// StopAccepting 停止接收新连接，监听保持不变，新连接接入后立即关闭
+func (a *Acceptor) StopAccepting() {
+	a.draining.Store(true)
+}
+
+// Accepting 是否正在接收新连接
+func (a *Acceptor) Accepting() bool {
+	return !a.draining.Load()
+}
+
 func (a *Acceptor) tcpRealAddr() net.Addr {
 
 	return a.listen.realAddr
@@ -154,6 +166,10 @@ func (a *Acceptor) acceptConn(connNetFd NetFd, ws bool, wss bool) error {
 		conn Conn
 		err  error
 	)
+	if a.draining.Load() { // 排空中，直接关闭新连接
+		_ = connNetFd.conn.Close()
+		return nil
+	}
 	connFd := connNetFd.fd
 
 	remoteAddr := connNetFd.conn.RemoteAddr()
@@ -30,6 +30,25 @@ type ConnStats struct {
 	OutPackets     atomic.Int64 // 下发包数量
 	InPacketBytes  atomic.Int64 // 收到包字节数
 	OutPacketBytes atomic.Int64 // 下发包字节数
+
+	PendingPackets  atomic.Int64 // 发送缓冲区内待发送的包数量（每次写入算一个包，缓冲区清空后归零）
+	MaxPendingBytes atomic.Int64 // 发送缓冲区待发送字节数的最高值
+	SlowCount       atomic.Int64 // 超出发送缓冲区限制的次数
+	SlowAt          atomic.Int64 // 最近一次超出发送缓冲区限制的时间（毫秒）
+	DroppedPackets  atomic.Int64 // 超出发送缓冲区限制被丢弃的包数量
+	DroppedBytes    atomic.Int64 // 超出发送缓冲区限制被丢弃的字节数
+}
+
+// Slow 记录一次超出发送缓冲区的限制
+func (c *ConnStats) Slow() {
+	c.SlowCount.Inc()
+	c.SlowAt.Store(time.Now().UnixMilli())
+}
+
+// Dropped 记录超出发送缓冲区限制被丢弃的包
+func (c *ConnStats) Dropped(packets int, bytes int) {
+	c.DroppedPackets.Add(int64(packets))
+	c.DroppedBytes.Add(int64(bytes))
 }
 
 func NewConnStats() *ConnStats {
@@ -118,6 +137,10 @@ type Conn interface {
 
 	// ConnStats returns the connection stats.
 	ConnStats() *ConnStats
+	// OutboundOverflow returns true if writing n bytes and the packets to the outbound buffer exceeds MaxOutboundBytes or MaxOutboundPackets.
+	OutboundOverflow(n int, packets int) bool
+	// DisableOutboundLimit disables the outbound limit, used to write the last packets before closing a slow connection.
+	DisableOutboundLimit()
 }
 
 type IWSConn interface {
@@ -125,25 +148,26 @@ type IWSConn interface {
 }
 
 type DefaultConn struct {
-	fd             NetFd
-	remoteAddr     net.Addr
-	localAddr      net.Addr
-	eg             *Engine
-	reactorSub     *ReactorSub
-	inboundBuffer  InboundBuffer  // inboundBuffer InboundBuffer
-	outboundBuffer OutboundBuffer // outboundBuffer OutboundBuffer
-	closed         atomic.Bool    // if the connection is closed
-	isWAdded       bool           // if the connection is added to the write event
-	mu             deadlock.RWMutex
-	context        interface{}
-	authed         bool // if the connection is authed
-	protoVersion   int
-	id             int64
-	uid            string
-	deviceFlag     uint8
-	deviceLevel    uint8
-	deviceID       string
-	valueMap       map[string]interface{}
+	fd               NetFd
+	remoteAddr       net.Addr
+	localAddr        net.Addr
+	eg               *Engine
+	reactorSub       *ReactorSub
+	inboundBuffer    InboundBuffer  // inboundBuffer InboundBuffer
+	outboundBuffer   OutboundBuffer // outboundBuffer OutboundBuffer
+	closed           atomic.Bool    // if the connection is closed
+	outboundLimitOff atomic.Bool    // if the outbound limit is disabled
+	isWAdded         bool           // if the connection is added to the write event
+	mu               deadlock.RWMutex
+	context          interface{}
+	authed           bool // if the connection is authed
+	protoVersion     int
+	id               int64
+	uid              string
+	deviceFlag       uint8
+	deviceLevel      uint8
+	deviceID         string
+	valueMap         map[string]interface{}
 
 	uptime       time.Time
 	lastActivity time.Time
@@ -164,6 +188,7 @@ func GetDefaultConn(id int64, connFd NetFd, localAddr, remoteAddr net.Addr, eg *
 	defaultConn.isWAdded = false
 	defaultConn.authed = false
 	defaultConn.closed.Store(false)
+	defaultConn.outboundLimitOff.Store(false)
 	defaultConn.uid = ""
 	defaultConn.deviceFlag = 0
 	defaultConn.deviceLevel = 0
@@ -277,10 +302,51 @@ func (d *DefaultConn) WriteToOutboundBuffer(b []byte) (int, error) {
 	}
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	return d.outboundBuffer.Write(b)
+	return d.writeToOutboundBuffer(b)
 
 }
 
+// writeToOutboundBuffer 写入发送缓冲区，超出发送缓冲区的限制时返回ErrOutboundOverflow
+func (d *DefaultConn) writeToOutboundBuffer(b []byte) (int, error) {
+	if d.OutboundOverflow(len(b), 1) {
+		d.connStats.Slow()
+		return 0, ErrOutboundOverflow
+	}
+	n, err := d.outboundBuffer.Write(b)
+	if err != nil {
+		return n, err
+	}
+	d.outboundPacketWritten()
+	return n, nil
+}
+
+// DisableOutboundLimit 关闭发送缓冲区的限制，用于断开慢消费者前写入最后的包
+func (d *DefaultConn) DisableOutboundLimit() {
+	d.outboundLimitOff.Store(true)
+}
+
+// outboundPacketWritten 一个包写入发送缓冲区后更新统计
+func (d *DefaultConn) outboundPacketWritten() {
+	d.connStats.PendingPackets.Inc()
+	pendingBytes := int64(d.outboundBuffer.BoundBufferSize())
+	if pendingBytes > d.connStats.MaxPendingBytes.Load() {
+		d.connStats.MaxPendingBytes.Store(pendingBytes)
+	}
+}
+
+// OutboundOverflow 写入n个字节和packets个包后是否超出发送缓冲区的限制
+func (d *DefaultConn) OutboundOverflow(n int, packets int) bool {
+	if d.outboundLimitOff.Load() {
+		return false
+	}
+	maxOutboundBytes := d.eg.options.MaxOutboundBytes
+	if maxOutboundBytes > 0 && d.outboundBuffer.BoundBufferSize()+n > maxOutboundBytes {
+		return true
+	}
+	maxOutboundPackets := d.eg.options.MaxOutboundPackets
+	return maxOutboundPackets > 0 && int(d.connStats.PendingPackets.Load())+packets > maxOutboundPackets
+}
+
 func (d *DefaultConn) WakeWrite() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -613,6 +679,7 @@ func (d *DefaultConn) flush() error {
 	// All data have been drained, it's no need to monitor the writable events,
 	// remove the writable event from poller to help the future event-loops.
 	if d.outboundBuffer.IsEmpty() {
+		d.connStats.PendingPackets.Store(0)
 		_ = d.removeWriteIfExist()
 	}
 	return nil
@@ -914,7 +981,15 @@ func (t *TLSConn) Uptime() time.Time {
 }
 
 func (t *TLSConn) WriteToOutboundBuffer(b []byte) (int, error) {
-	return t.d.outboundBuffer.Write(b)
+	return t.d.writeToOutboundBuffer(b)
+}
+
+func (t *TLSConn) OutboundOverflow(n int, packets int) bool {
+	return t.d.OutboundOverflow(n, packets)
+}
+
+func (t *TLSConn) DisableOutboundLimit() {
+	t.d.DisableOutboundLimit()
 }
 
 func (t *TLSConn) SetMaxIdle(maxIdle time.Duration) {
@@ -49,6 +49,33 @@ func TestConn(t *testing.T) {
 	time.Sleep(time.Second * 1)
 }
 
+func TestConnOutboundOverflow(t *testing.T) {
+	d := &DefaultConn{
+		eg:             &Engine{options: &Options{MaxOutboundBytes: 10, MaxOutboundPackets: 2}},
+		outboundBuffer: NewDefaultBuffer(),
+		connStats:      NewConnStats(),
+	}
+
+	_, err := d.writeToOutboundBuffer([]byte("hello"))
+	assert.NoError(t, err)
+	assert.False(t, d.OutboundOverflow(5, 1))
+	assert.True(t, d.OutboundOverflow(6, 1))
+
+	// 超出字节限制
+	_, err = d.writeToOutboundBuffer([]byte("world!"))
+	assert.Equal(t, ErrOutboundOverflow, err)
+	assert.Equal(t, int64(1), d.connStats.SlowCount.Load())
+
+	// 超出包数量限制
+	_, err = d.writeToOutboundBuffer([]byte("a"))
+	assert.NoError(t, err)
+	_, err = d.writeToOutboundBuffer([]byte("b"))
+	assert.Equal(t, ErrOutboundOverflow, err)
+	assert.Equal(t, int64(2), d.connStats.PendingPackets.Load())
+	assert.Equal(t, int64(6), d.connStats.MaxPendingBytes.Load())
+	assert.Equal(t, int64(2), d.connStats.SlowCount.Load())
+}
+
 func TestTlsConn(t *testing.T) {
 	cert, err := stls.X509KeyPair(rsaCertPEM, rsaKeyPEM)
 	if err != nil {
@@ -5,4 +5,6 @@ import "errors"
 var (
 	// ErrUnsupportedOp occurs when calling some methods that has not been implemented yet.
 	ErrUnsupportedOp = errors.New("unsupported operation")
+	// ErrOutboundOverflow occurs when the outbound buffer of the connection exceeds MaxOutboundBytes or MaxOutboundPackets.
+	ErrOutboundOverflow = errors.New("outbound buffer overflow")
 )
@@ -110,6 +110,16 @@ func (e *Engine) TCPRealListenAddr() net.Addr {
 	return e.reactorMain.acceptor.tcpRealAddr()
 }
 
+// StopAccepting 停止接收新连接（已建立的连接不受影响）
+func (e *Engine) StopAccepting() {
+	e.reactorMain.acceptor.StopAccepting()
+}
+
+// Accepting 是否正在接收新连接
+func (e *Engine) Accepting() bool {
+	return e.reactorMain.acceptor.Accepting()
+}
+
 func (e *Engine) WSRealListenAddr() net.Addr {
 	return e.reactorMain.acceptor.wsRealAddr()
 }
@@ -96,3 +96,32 @@ func TestEngine(t *testing.T) {
 	// fmt.Println("finishChan wait")
 	<-finishChan
 }
+
+func TestEngineStopAccepting(t *testing.T) {
+	e := NewEngine()
+	err := e.Start()
+	assert.NoError(t, err)
+	defer e.Stop()
+
+	cli, err := net.Dial("tcp", e.TCPRealListenAddr().String())
+	assert.NoError(t, err)
+	defer cli.Close()
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, 1, e.ConnCount())
+
+	e.StopAccepting()
+	assert.False(t, e.Accepting())
+
+	// 新连接接入后会被直接关闭
+	cli2, err := net.Dial("tcp", e.TCPRealListenAddr().String())
+	assert.NoError(t, err)
+	defer cli2.Close()
+	_ = cli2.SetReadDeadline(time.Now().Add(time.Second * 2))
+	_, err = cli2.Read(make([]byte, 1))
+	assert.Error(t, err)
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		assert.False(t, netErr.Timeout())
+	}
+	assert.Equal(t, 1, e.ConnCount())
+}
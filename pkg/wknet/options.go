@@ -1,6 +1,7 @@
 package wknet
 
 import (
+	"compress/flate"
 	"runtime"
 	"time"
 
@@ -28,16 +29,29 @@ type Options struct {
 	MaxWriteBufferSize int
 	// MaxReadBufferSize is the read maximum size of the buffer for each connection
 	MaxReadBufferSize int
+	// MaxOutboundBytes is the maximum bytes waiting in the outbound buffer for each connection, 0 means no limit
+	MaxOutboundBytes int
+	// MaxOutboundPackets is the maximum packets (one write is one packet) waiting in the outbound buffer for each connection, 0 means no limit
+	MaxOutboundPackets int
 	// SocketRecvBuffer sets the maximum socket receive buffer in bytes.
 	SocketRecvBuffer int
 	// SocketSendBuffer sets the maximum socket send buffer in bytes.
 	SocketSendBuffer int
 	// TCPKeepAlive sets up a duration for (SO_KEEPALIVE) socket option.
 	TCPKeepAlive time.Duration
+	// WSCompression enables permessage-deflate (RFC 7692) negotiation for ws/wss connections
+	WSCompression bool
+	// WSCompressionLevel is the flate compression level of ws messages
+	WSCompressionLevel int
+	// WSCompressionThreshold is the minimum size of ws messages to compress, smaller messages are sent uncompressed
+	WSCompressionThreshold int
+	// WSContextTakeover allows reusing the compression context across messages, better ratio but 32KB more memory per direction for each connection
+	WSContextTakeover bool
 
 	Event struct {
-		OnReadBytes  func(n int) // 读到的字节大小
-		OnWirteBytes func(n int) // 写出字节大小
+		OnReadBytes  func(n int)                                             // 读到的字节大小
+		OnWirteBytes func(n int)                                             // 写出字节大小
+		OnCompress   func(rawBytes, compressedBytes int, cost time.Duration) // 压缩了一条消息
 	}
 }
 
@@ -49,6 +63,9 @@ func NewOptions() *Options {
 		ReadBufferSize:     1024 * 32,
 		MaxWriteBufferSize: 1024 * 1024 * 50,
 		MaxReadBufferSize:  1024 * 1024 * 50,
+
+		WSCompressionLevel:     flate.BestSpeed,
+		WSCompressionThreshold: 256,
 	}
 }
 
@@ -120,6 +137,48 @@ func WithTCPKeepAlive(v time.Duration) Option {
 	}
 }
 
+// WithMaxOutboundBytes sets the maximum bytes waiting in the outbound buffer for each connection.
+func WithMaxOutboundBytes(v int) Option {
+	return func(opts *Options) {
+		opts.MaxOutboundBytes = v
+	}
+}
+
+// WithMaxOutboundPackets sets the maximum packets waiting in the outbound buffer for each connection.
+func WithMaxOutboundPackets(v int) Option {
+	return func(opts *Options) {
+		opts.MaxOutboundPackets = v
+	}
+}
+
+// WithWSCompression enables permessage-deflate negotiation for ws/wss connections.
+func WithWSCompression(v bool) Option {
+	return func(opts *Options) {
+		opts.WSCompression = v
+	}
+}
+
+// WithWSCompressionLevel sets the flate compression level of ws messages.
+func WithWSCompressionLevel(v int) Option {
+	return func(opts *Options) {
+		opts.WSCompressionLevel = v
+	}
+}
+
+// WithWSCompressionThreshold sets the minimum size of ws messages to compress.
+func WithWSCompressionThreshold(v int) Option {
+	return func(opts *Options) {
+		opts.WSCompressionThreshold = v
+	}
+}
+
+// WithWSContextTakeover allows reusing the compression context across ws messages.
+func WithWSContextTakeover(v bool) Option {
+	return func(opts *Options) {
+		opts.WSContextTakeover = v
+	}
+}
+
 func WithOnReadBytes(f func(n int)) Option {
 	return func(opts *Options) {
 		opts.Event.OnReadBytes = f
@@ -132,3 +191,9 @@ func WithOnWirteBytes(f func(n int)) Option {
 		opts.Event.OnWirteBytes = f
 	}
 }
+
+func WithOnCompress(f func(rawBytes, compressedBytes int, cost time.Duration)) Option {
+	return func(opts *Options) {
+		opts.Event.OnCompress = f
+	}
+}
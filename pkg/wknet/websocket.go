@@ -14,6 +14,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
 	"github.com/gobwas/ws/wsutil"
 )
 
@@ -34,6 +35,7 @@ type WSConn struct {
 	*DefaultConn
 	upgraded         bool
 	tmpInboundBuffer InboundBuffer // inboundBuffer InboundBuffer
+	deflate          *wsDeflate    // 协商了permessage-deflate时不为nil
 }
 
 func NewWSConn(d *DefaultConn) *WSConn {
@@ -67,7 +69,21 @@ func (w *WSConn) ReadToInboundBuffer() (int, error) {
 func (w *WSConn) WriteServerBinary(data []byte) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return wsutil.WriteServerBinary(w.outboundBuffer, data)
+	if w.OutboundOverflow(len(data), 1) {
+		w.connStats.Slow()
+		return ErrOutboundOverflow
+	}
+	var err error
+	if w.deflate != nil {
+		err = w.deflate.writeServerBinary(w.outboundBuffer, data)
+	} else {
+		err = wsutil.WriteServerBinary(w.outboundBuffer, data)
+	}
+	if err != nil {
+		return err
+	}
+	w.outboundPacketWritten()
+	return nil
 }
 
 // 解包ws的数据
@@ -133,7 +149,7 @@ func (w *WSConn) decode() ([]wsutil.Message, error) {
 		tmpReader.Reset(buff)
 		remLen := tmpReader.Len()
 		for tmpReader.Len() > 0 {
-			messages, err = wsutil.ReadClientMessage(tmpReader, messages)
+			messages, err = readWSClientMessage(tmpReader, messages, w.deflate)
 			if err != nil {
 				w.Warn("read client message error", zap.Error(err))
 				break
@@ -155,7 +171,9 @@ func (w *WSConn) upgrade() error {
 	}
 	tmpReader := bytes.NewReader(buff)
 	tmpWriter := bytes.NewBuffer(nil)
-	_, err = ws.Upgrade(&readWrite{
+	var deflateParams *wsflate.Parameters
+	upgrader := newWSUpgrader(w.eg.options, &deflateParams)
+	_, err = upgrader.Upgrade(&readWrite{
 		Reader: tmpReader,
 		Writer: tmpWriter,
 	})
@@ -196,6 +214,9 @@ func (w *WSConn) upgrade() error {
 	}
 
 	w.DiscardFromTemp(len(buff) - tmpReader.Len())
+	if deflateParams != nil {
+		w.deflate = newWSDeflate(*deflateParams, w.eg.options)
+	}
 	w.upgraded = true
 	return nil
 }
@@ -259,6 +280,7 @@ type WSSConn struct {
 	upgraded bool
 
 	wsTmpInboundBuffer InboundBuffer // inboundBuffer InboundBuffer
+	deflate            *wsDeflate    // 协商了permessage-deflate时不为nil
 }
 
 func NewWSSConn(tlsConn *TLSConn) *WSSConn {
@@ -340,7 +362,9 @@ func (w *WSSConn) upgrade() error {
 
 	tmpReader := bytes.NewReader(buff)
 	tmpWriter := bytes.NewBuffer(nil)
-	_, err = ws.Upgrade(&readWrite{
+	var deflateParams *wsflate.Parameters
+	upgrader := newWSUpgrader(w.d.eg.options, &deflateParams)
+	_, err = upgrader.Upgrade(&readWrite{
 		Reader: tmpReader,
 		Writer: tmpWriter,
 	})
@@ -357,6 +381,9 @@ func (w *WSSConn) upgrade() error {
 	}
 
 	w.discardFromWSTemp(len(buff) - tmpReader.Len())
+	if deflateParams != nil {
+		w.deflate = newWSDeflate(*deflateParams, w.d.eg.options)
+	}
 
 	w.upgraded = true
 
@@ -397,6 +424,7 @@ func (w *WSSConn) unpacketWSData() error {
 
 func (w *WSSConn) Close() error {
 	w.upgraded = false
+	w.deflate = nil
 	_ = w.wsTmpInboundBuffer.Release()
 	return w.TLSConn.Close()
 }
@@ -404,7 +432,21 @@ func (w *WSSConn) Close() error {
 func (w *WSSConn) WriteServerBinary(data []byte) error {
 	w.d.mu.Lock()
 	defer w.d.mu.Unlock()
-	return wsutil.WriteServerBinary(w.TLSConn, data)
+	if w.d.OutboundOverflow(len(data), 1) {
+		w.d.connStats.Slow()
+		return ErrOutboundOverflow
+	}
+	var err error
+	if w.deflate != nil {
+		err = w.deflate.writeServerBinary(w.TLSConn, data)
+	} else {
+		err = wsutil.WriteServerBinary(w.TLSConn, data)
+	}
+	if err != nil {
+		return err
+	}
+	w.d.outboundPacketWritten()
+	return nil
 }
 
 func (w *WSSConn) decode() ([]wsutil.Message, error) {
@@ -437,7 +479,7 @@ func (w *WSSConn) decode() ([]wsutil.Message, error) {
 		tmpReader.Reset(buff)
 		remLen := tmpReader.Len()
 		for tmpReader.Len() > 0 {
-			messages, err = wsutil.ReadClientMessage(tmpReader, messages)
+			messages, err = readWSClientMessage(tmpReader, messages, w.deflate)
 			if err != nil {
 				w.d.Warn("read client message error", zap.Error(err))
 				break
@@ -0,0 +1,188 @@
+package wknet
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/gobwas/httphead"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"github.com/gobwas/ws/wsutil"
+)
+
+var (
+	// 压缩后的数据以sync flush结尾，按RFC 7692发送前需要去掉这4个字节
+	wsDeflateTail = []byte{0x00, 0x00, 0xff, 0xff}
+	// 解压时补上去掉的结尾，再补一个空的final块让解压器读到EOF
+	wsDeflateFinal = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+
+	ErrWSMessageTooLarge = errors.New("ws message too large")
+)
+
+// wsDeflate 连接协商的permessage-deflate参数和压缩上下文
+// 压缩在写锁内进行，解压在读事件内进行，两者互不影响
+type wsDeflate struct {
+	params     wsflate.Parameters
+	level      int
+	threshold  int
+	maxSize    int // 解压后的最大字节数
+	onCompress func(rawBytes, compressedBytes int, cost time.Duration)
+
+	writer   *flate.Writer
+	writeBuf bytes.Buffer
+
+	reader   io.ReadCloser
+	readDict []byte // 开启客户端上下文接管时，最近32KB解压后的数据作为下一条消息的字典
+}
+
+func newWSDeflate(params wsflate.Parameters, opts *Options) *wsDeflate {
+	return &wsDeflate{
+		params:     params,
+		level:      opts.WSCompressionLevel,
+		threshold:  opts.WSCompressionThreshold,
+		maxSize:    opts.MaxReadBufferSize,
+		onCompress: opts.Event.OnCompress,
+	}
+}
+
+// newWSUpgrader 创建ws升级器，开启压缩时协商permessage-deflate，协商结果写入accepted
+func newWSUpgrader(opts *Options, accepted **wsflate.Parameters) ws.Upgrader {
+	upgrader := ws.Upgrader{}
+	if !opts.WSCompression {
+		return upgrader
+	}
+	upgrader.Negotiate = func(opt httphead.Option) (httphead.Option, error) {
+		if *accepted != nil || !bytes.Equal(opt.Name, wsflate.ExtensionNameBytes) {
+			return httphead.Option{}, nil
+		}
+		var offer wsflate.Parameters
+		if err := offer.Parse(opt); err != nil { // 参数不合法的提议直接忽略
+			return httphead.Option{}, nil
+		}
+		// flate固定使用32KB的窗口，客户端要求更小的服务端窗口时无法满足
+		if offer.ServerMaxWindowBits.Defined() && offer.ServerMaxWindowBits.Bytes() < wsflate.MaxLZ77WindowSize {
+			return httphead.Option{}, nil
+		}
+		params := wsflate.Parameters{
+			ServerNoContextTakeover: offer.ServerNoContextTakeover || !opts.WSContextTakeover,
+			ClientNoContextTakeover: offer.ClientNoContextTakeover || !opts.WSContextTakeover,
+		}
+		*accepted = &params
+		return params.Option(), nil
+	}
+	return upgrader
+}
+
+// writeServerBinary 写入二进制消息，达到压缩阈值的消息压缩后写入
+func (d *wsDeflate) writeServerBinary(w io.Writer, data []byte) error {
+	if len(data) < d.threshold {
+		return wsutil.WriteServerBinary(w, data)
+	}
+	start := time.Now()
+	payload, err := d.compress(data)
+	if err != nil {
+		return err
+	}
+	frame := ws.NewBinaryFrame(payload)
+	frame.Header.Rsv = ws.Rsv(true, false, false)
+	if err = ws.WriteFrame(w, frame); err != nil {
+		return err
+	}
+	if d.onCompress != nil {
+		d.onCompress(len(data), len(payload), time.Since(start))
+	}
+	return nil
+}
+
+func (d *wsDeflate) compress(data []byte) ([]byte, error) {
+	var err error
+	d.writeBuf.Reset()
+	if d.writer == nil {
+		if d.writer, err = flate.NewWriter(&d.writeBuf, d.level); err != nil {
+			return nil, err
+		}
+	} else if d.params.ServerNoContextTakeover {
+		d.writer.Reset(&d.writeBuf)
+	}
+	if _, err = d.writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err = d.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(d.writeBuf.Bytes(), wsDeflateTail), nil
+}
+
+func (d *wsDeflate) decompress(data []byte) ([]byte, error) {
+	src := io.MultiReader(bytes.NewReader(data), bytes.NewReader(wsDeflateFinal))
+	var dict []byte
+	if !d.params.ClientNoContextTakeover {
+		dict = d.readDict
+	}
+	if d.reader == nil {
+		d.reader = flate.NewReaderDict(src, dict)
+	} else if err := d.reader.(flate.Resetter).Reset(src, dict); err != nil {
+		return nil, err
+	}
+	var (
+		buf    bytes.Buffer
+		reader io.Reader = d.reader
+	)
+	if d.maxSize > 0 {
+		reader = io.LimitReader(d.reader, int64(d.maxSize)+1)
+	}
+	n, err := buf.ReadFrom(reader)
+	if err != nil {
+		return nil, err
+	}
+	if d.maxSize > 0 && n > int64(d.maxSize) {
+		return nil, ErrWSMessageTooLarge
+	}
+	payload := buf.Bytes()
+	if !d.params.ClientNoContextTakeover {
+		d.readDict = append(d.readDict, payload...)
+		if len(d.readDict) > wsflate.MaxLZ77WindowSize {
+			d.readDict = append([]byte(nil), d.readDict[len(d.readDict)-wsflate.MaxLZ77WindowSize:]...)
+		}
+	}
+	return payload, nil
+}
+
+// readWSClientMessage 读取客户端的一条消息，协商了压缩时解压压缩过的消息
+func readWSClientMessage(r io.Reader, m []wsutil.Message, d *wsDeflate) ([]wsutil.Message, error) {
+	if d == nil {
+		return wsutil.ReadClientMessage(r, m)
+	}
+	var state wsflate.MessageState
+	rd := wsutil.Reader{
+		Source:     r,
+		State:      ws.StateServerSide | ws.StateExtended,
+		Extensions: []wsutil.RecvExtension{&state},
+		OnIntermediate: func(hdr ws.Header, src io.Reader) error {
+			bts, err := io.ReadAll(src)
+			if err != nil {
+				return err
+			}
+			m = append(m, wsutil.Message{OpCode: hdr.OpCode, Payload: bts})
+			return nil
+		},
+	}
+	hdr, err := rd.NextFrame()
+	if err != nil {
+		return m, err
+	}
+	var buf bytes.Buffer
+	if _, err = buf.ReadFrom(&rd); err != nil {
+		return m, err
+	}
+	payload := buf.Bytes()
+	if state.IsCompressed() {
+		if payload, err = d.decompress(payload); err != nil {
+			return m, err
+		}
+	}
+	return append(m, wsutil.Message{OpCode: hdr.OpCode, Payload: payload}), nil
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	stls "github.com/WuKongIM/crypto/tls"
+	"github.com/gobwas/ws/wsflate"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 )
@@ -280,3 +281,80 @@ func testWSSConnSend(msgNum int, addr string, t *testing.T, wg *sync.WaitGroup,
 		assert.NoError(t, err)
 	}
 }
+
+func TestWebsocketCompression(t *testing.T) {
+	var (
+		compressCount int
+		compressLock  sync.Mutex
+	)
+	e := NewEngine(WithWSAddr("ws://0.0.0.0:0"), WithWSCompression(true), WithWSCompressionThreshold(16), WithOnCompress(func(rawBytes, compressedBytes int, cost time.Duration) {
+		compressLock.Lock()
+		compressCount++
+		compressLock.Unlock()
+	}))
+	e.Start()
+	defer e.Stop()
+
+	msg := bytes.Repeat([]byte("hello wukongim "), 100)
+	e.OnData(func(conn Conn) error {
+		data, err := conn.Peek(-1)
+		assert.NoError(t, err)
+		if len(data) < len(msg) {
+			return nil
+		}
+		conn.Discard(len(data))
+		assert.Equal(t, msg, data)
+
+		// 回写一条压缩的消息和一条低于阈值不压缩的消息
+		wsConn := conn.(IWSConn)
+		assert.NoError(t, wsConn.WriteServerBinary(data))
+		assert.NoError(t, wsConn.WriteServerBinary([]byte("pong")))
+		return conn.WakeWrite()
+	})
+
+	u := url.URL{Scheme: "ws", Host: e.WSRealListenAddr().String(), Path: "/"}
+	dialer := websocket.Dialer{EnableCompression: true}
+	c1, resp, err := dialer.Dial(u.String(), nil)
+	assert.NoError(t, err)
+	defer c1.Close()
+	assert.Contains(t, resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+
+	err = c1.WriteMessage(websocket.BinaryMessage, msg)
+	assert.NoError(t, err)
+
+	_ = c1.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, data, err := c1.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, msg, data)
+	_, data, err = c1.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(data))
+
+	compressLock.Lock()
+	assert.Equal(t, 1, compressCount)
+	compressLock.Unlock()
+}
+
+func TestWSDeflateContextTakeover(t *testing.T) {
+	opts := NewOptions()
+	opts.WSCompressionThreshold = 0
+	params := wsflate.Parameters{}
+	server := newWSDeflate(params, opts)
+	client := newWSDeflate(params, opts)
+
+	// 开启上下文接管时，后面的消息可以引用前面消息的内容
+	msg := bytes.Repeat([]byte("wukongim"), 64)
+	first, err := server.compress(msg)
+	assert.NoError(t, err)
+	first = append([]byte(nil), first...)
+	second, err := server.compress(msg)
+	assert.NoError(t, err)
+	assert.Less(t, len(second), len(first))
+
+	data, err := client.decompress(first)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, data)
+	data, err = client.decompress(second)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, data)
+}
@@ -10,6 +10,7 @@ import (
 
 	"go.uber.org/atomic"
 
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
 	"go.etcd.io/etcd/pkg/v3/idutil"
@@ -371,9 +372,10 @@ func (c *Client) RequestWithContext(ctx context.Context, p string, body []byte)
 	}
 
 	r := &proto.Request{
-		Id:   c.reqIDGen.Next(),
-		Path: p,
-		Body: body,
+		Id:          c.reqIDGen.Next(),
+		Path:        p,
+		Body:        body,
+		Traceparent: trace.Traceparent(ctx), // 传递链路上下文
 	}
 
 	data, err := r.Marshal()
@@ -1,6 +1,7 @@
 package wkserver
 
 import (
+	"context"
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
@@ -13,6 +14,7 @@ import (
 type Handler func(c *Context)
 
 type Context struct {
+	ctx     context.Context // 链路上下文
 	conn    wknet.Conn
 	req     *proto.Request
 	connReq *proto.Connect
@@ -170,6 +172,14 @@ func (c *Context) Body() []byte {
 	return c.req.Body
 }
 
+// Context 请求的上下文（携带请求方传递过来的链路）
+func (c *Context) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
 func (c *Context) ConnReq() *proto.Connect {
 	return c.connReq
 }
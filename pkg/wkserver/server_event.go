@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wknet"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
 	"go.uber.org/zap"
@@ -167,6 +168,12 @@ func (s *Server) handleRequest(conn wknet.Conn, req *proto.Request) {
 	ctx := NewContext(conn)
 	ctx.req = req
 	ctx.proto = s.proto
+	if req.Traceparent != "" { // 请求方携带了链路，处理请求作为其子链路
+		spanCtx, span := trace.GlobalTrace.StartSpan(trace.ContextWithTraceparent(context.Background(), req.Traceparent), req.Path)
+		span.SetString("from", conn.UID())
+		ctx.ctx = spanCtx
+		defer span.End()
+	}
 	h(ctx)
 	s.Debug("request path", zap.String("path", req.Path), zap.Duration("cost", time.Since(start)), zap.String("from", conn.UID()))
 